@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/url"
+	"sync"
 
 	"feedrewind.com/crawler"
 	"feedrewind.com/db/pgw"
@@ -17,6 +18,8 @@ type MockHttpClient struct {
 	conn        *pgw.Conn
 	startLinkId int
 	httpClient  crawler.HttpClient
+	// Titles are fetched concurrently but the connection can only run one query at a time
+	mutex sync.Mutex
 }
 
 func NewMockHttpClient(conn *pgw.Conn, startLinkId int) MockHttpClient {
@@ -25,6 +28,7 @@ func NewMockHttpClient(conn *pgw.Conn, startLinkId int) MockHttpClient {
 		conn:                conn,
 		startLinkId:         startLinkId,
 		httpClient:          crawler.NewHttpClientImpl(context.Background(), nil, true),
+		mutex:               sync.Mutex{},
 	}
 }
 
@@ -32,6 +36,7 @@ func (c *MockHttpClient) Request(
 	uri *url.URL, shouldThrottle bool, maybeRobotsClient *crawler.RobotsClient, logger crawler.Logger,
 ) (*crawler.HttpResponse, error) {
 	fetchUrl := uri.String()
+	c.mutex.Lock()
 	row := c.conn.QueryRow(`
 		select code, content_type, location, body from mock_responses
 		where start_link_id = $1 and fetch_url = $2
@@ -39,14 +44,17 @@ func (c *MockHttpClient) Request(
 
 	var r crawler.HttpResponse
 	err := row.Scan(&r.Code, &r.MaybeContentType, &r.MaybeLocation, &r.Body)
+	c.mutex.Unlock()
 	if errors.Is(err, pgx.ErrNoRows) {
 		logger.Info("URI not in mock tables, falling back on http client: %s", fetchUrl)
-		c.NetworkRequestsMade++
 		r, err := c.httpClient.Request(uri, shouldThrottle, maybeRobotsClient, logger)
 		if err != nil {
 			return nil, err
 		}
 
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.NetworkRequestsMade++
 		_, err = c.conn.Exec(`
 			insert into mock_responses (start_link_id, fetch_url, code, content_type, location, body)
 			values ($1, $2, $3, $4, $5, $6) 
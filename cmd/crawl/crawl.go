@@ -44,6 +44,8 @@ func init() {
 	}
 	Crawl.Flags().IntVar(&threads, "threads", 16, "(only used when crawling all)")
 	Crawl.Flags().BoolVar(&allowJS, "allow-js", false, "")
+	addMemoryFlags(Crawl)
+	addStrategyFlags(Crawl)
 
 	CrawlRobots = &cobra.Command{
 		Use: "crawl-robots",
@@ -72,6 +74,11 @@ var threads int
 var allowJS bool
 
 func crawl(args []string) error {
+	err := parseStrategyFlags()
+	if err != nil {
+		return err
+	}
+
 	cpuFile, err := os.Create("cpuprofile")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,225 @@
+package crawl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/oops"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+)
+
+// Each crawl runs in its own process so that the peak RSS is not shared between runs. The "before" run keeps
+// the parsed pages around and doesn't spill, like the crawler did before the page memory budget.
+
+var MemoryScaleTest *cobra.Command
+var memoryScaleTestSingle *cobra.Command
+
+var memoryBudgetMb int
+var keepParsedPages bool
+
+// These are slow because they are huge
+var defaultMemoryScaleTestIds = []int{603, 1321, 1010, 1501, 1132, 1271}
+
+func init() {
+	MemoryScaleTest = &cobra.Command{
+		Use:  "memory-scale-test [start link ids...]",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return memoryScaleTest(args)
+		},
+	}
+	MemoryScaleTest.Flags().IntVar(
+		&memoryBudgetMb, "memory-budget-mb", crawler.DefaultPageMemoryBudget/1024/1024,
+		"page memory budget for the \"after\" runs, 0 for unlimited",
+	)
+
+	memoryScaleTestSingle = &cobra.Command{
+		Use:    "memory-scale-test-single",
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return memoryScaleTestRunSingle(args[0])
+		},
+	}
+	addMemoryFlags(memoryScaleTestSingle)
+	MemoryScaleTest.AddCommand(memoryScaleTestSingle)
+}
+
+func addMemoryFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&memoryBudgetMb, "memory-budget-mb", crawler.DefaultPageMemoryBudget/1024/1024,
+		"page memory budget per crawl, 0 for unlimited",
+	)
+	cmd.Flags().BoolVar(
+		&keepParsedPages, "keep-parsed-pages", false, "don't compact pages, only for comparing memory usage",
+	)
+}
+
+type memoryScaleTestResult struct {
+	StartLinkId   int    `json:"start_link_id"`
+	PeakRssKb     int    `json:"peak_rss_kb"`
+	PeakParkedKb  int    `json:"peak_parked_kb"`
+	PagesSpilled  int    `json:"pages_spilled"`
+	LinksCount    string `json:"links_count"`
+	MaybeErrorStr string `json:"error"`
+}
+
+func memoryScaleTest(args []string) error {
+	startLinkIds := defaultMemoryScaleTestIds
+	if len(args) > 0 {
+		startLinkIds = nil
+		for _, arg := range args {
+			startLinkId, err := strconv.Atoi(arg)
+			if err != nil {
+				return oops.Newf("Expected start link id, got: %s", arg)
+			}
+			startLinkIds = append(startLinkIds, startLinkId)
+		}
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return oops.Wrap(err)
+	}
+
+	runChild := func(startLinkId int, childArgs ...string) (*memoryScaleTestResult, error) {
+		allArgs := append(
+			[]string{MemoryScaleTest.Name(), memoryScaleTestSingle.Name(), fmt.Sprint(startLinkId)},
+			childArgs...,
+		)
+		cmd := exec.Command(executable, allArgs...)
+		cmd.Stderr = os.Stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, oops.Wrapf(err, "start link %d", startLinkId)
+		}
+
+		var lastLine string
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			lastLine = scanner.Text()
+		}
+		var result memoryScaleTestResult
+		err = json.Unmarshal([]byte(lastLine), &result)
+		if err != nil {
+			return nil, oops.Wrapf(err, "start link %d output: %s", startLinkId, lastLine)
+		}
+		return &result, nil
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(
+		writer,
+		"start link\tbefore rss mb\tafter rss mb\tafter parked kb\tafter spilled\tbefore links\tafter links",
+	)
+	var totalBeforeKb, totalAfterKb int
+	for _, startLinkId := range startLinkIds {
+		before, err := runChild(startLinkId, "--keep-parsed-pages", "--memory-budget-mb=0")
+		if err != nil {
+			return err
+		}
+		after, err := runChild(startLinkId, fmt.Sprintf("--memory-budget-mb=%d", memoryBudgetMb))
+		if err != nil {
+			return err
+		}
+		totalBeforeKb += before.PeakRssKb
+		totalAfterKb += after.PeakRssKb
+		fmt.Fprintf(
+			writer, "%d\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			startLinkId, before.PeakRssKb/1024, after.PeakRssKb/1024, after.PeakParkedKb, after.PagesSpilled,
+			formatMemoryScaleTestLinks(before), formatMemoryScaleTestLinks(after),
+		)
+		if err := writer.Flush(); err != nil {
+			return oops.Wrap(err)
+		}
+	}
+	fmt.Fprintf(writer, "total\t%d\t%d\t\t\t\t\n", totalBeforeKb/1024, totalAfterKb/1024)
+	if err := writer.Flush(); err != nil {
+		return oops.Wrap(err)
+	}
+	return nil
+}
+
+func formatMemoryScaleTestLinks(result *memoryScaleTestResult) string {
+	if result.MaybeErrorStr != "" {
+		return "error"
+	}
+	return result.LinksCount
+}
+
+func memoryScaleTestRunSingle(arg string) error {
+	startLinkId, err := strconv.Atoi(arg)
+	if err != nil {
+		return oops.Newf("Expected start link id, got: %s", arg)
+	}
+
+	pool := connectDB()
+	conn, err := pool.AcquireBackground()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.Create(os.DevNull)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	defer logFile.Close()
+	logger := &FileLogger{File: logFile}
+
+	result := memoryScaleTestResult{
+		StartLinkId:   startLinkId,
+		PeakRssKb:     0,
+		PeakParkedKb:  0,
+		PagesSpilled:  0,
+		LinksCount:    "",
+		MaybeErrorStr: "",
+	}
+	guidedCrawlingResult, err := runGuidedCrawl(startLinkId, false, false, conn, logger)
+	if err != nil {
+		result.MaybeErrorStr = err.Error()
+	}
+	if guidedCrawlingResult != nil {
+		result.PeakParkedKb = guidedCrawlingResult.PeakParkedKb
+		result.PagesSpilled = guidedCrawlingResult.PagesSpilled
+		result.LinksCount = guidedCrawlingResult.HistoricalLinksCount
+	}
+	result.PeakRssKb, err = readPeakRssKb()
+	if err != nil {
+		return err
+	}
+
+	resultBytes, err := json.Marshal(&result)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	fmt.Println(string(resultBytes))
+	return nil
+}
+
+func readPeakRssKb() (int, error) {
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0, oops.Wrap(err)
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		valueStr, ok := strings.CutPrefix(line, "VmHWM:")
+		if !ok {
+			continue
+		}
+		valueStr = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(valueStr), "kB"))
+		value, err := strconv.Atoi(valueStr)
+		if err != nil {
+			return 0, oops.Wrap(err)
+		}
+		return value, nil
+	}
+	return 0, oops.New("VmHWM not found")
+}
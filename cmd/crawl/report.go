@@ -139,6 +139,55 @@ func outputReport(filename string, results []Result, expectedTotal int) error {
 	fmt.Fprintf(&b, "Weissman score: %s%%\n", weissmanScore)
 	fmt.Fprintf(&b, "<br>\n")
 
+	titleTimeSaved := 0
+	for _, result := range results {
+		if result.Result != nil {
+			titleTimeSaved += result.Result.TitleTimeSaved
+		}
+	}
+	fmt.Fprintf(&b, "Title time saved: %ds\n", titleTimeSaved)
+	fmt.Fprintf(&b, "<br>\n")
+
+	fmt.Fprintf(&b, "Strategies: %s\n", historicalStrategies)
+	fmt.Fprintf(&b, "<br>\n")
+	type StrategyCounter struct {
+		SuccessCount int
+		FailureCount int
+	}
+	strategyCounters := make(map[string]*StrategyCounter)
+	for _, result := range results {
+		if result.Result == nil || result.Result.HistoricalLinksStrategy == "" {
+			continue
+		}
+		counter, ok := strategyCounters[result.Result.HistoricalLinksStrategy]
+		if !ok {
+			counter = &StrategyCounter{
+				SuccessCount: 0,
+				FailureCount: 0,
+			}
+			strategyCounters[result.Result.HistoricalLinksStrategy] = counter
+		}
+		if slices.Contains(result.Result.ColumnStatuses(), crawler.StatusFailure) {
+			counter.FailureCount++
+		} else {
+			counter.SuccessCount++
+		}
+	}
+	fmt.Fprintf(&b, "<table>\n")
+	fmt.Fprintf(&b, "<tr><th>strategy</th><th>cost</th><th>success</th><th>failure</th></tr>\n")
+	for _, strategy := range crawler.HistoricalStrategies() {
+		counter, ok := strategyCounters[string(strategy.Name())]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(
+			&b, "<tr><td>%s</td><td>%s</td><td>%d</td><td>%d</td></tr>\n",
+			strategy.Name(), strategy.Cost(), counter.SuccessCount, counter.FailureCount,
+		)
+	}
+	fmt.Fprintf(&b, "</table>\n")
+	fmt.Fprintf(&b, "<br>\n")
+
 	fmt.Fprintf(&b, "<table>\n")
 	fmt.Fprintf(&b, "<tr><th>id</th>\n")
 	for i, columnName := range GuidedCrawlingColumnNames {
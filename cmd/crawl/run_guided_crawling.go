@@ -30,6 +30,7 @@ type GuidedCrawlingResult struct {
 	HistoricalLinksMatchingStatus                crawler.Status
 	HistoricalLinksPattern                       string `eval:"neutral_present"`
 	HistoricalLinksPatternStatus                 crawler.Status
+	HistoricalLinksStrategy                      string `eval:"neutral"`
 	HistoricalLinksCount                         string `eval:"neutral_present"`
 	HistoricalLinksCountStatus                   crawler.Status
 	HistoricalLinksTitlesPartiallyMatching       string `eval:"neutral_present"`
@@ -50,6 +51,9 @@ type GuidedCrawlingResult struct {
 	TotalNetworkRequests                         int      `eval:"neutral"`
 	DuplicateFetches                             int      `eval:"neutral"`
 	TitleRequests                                int      `eval:"neutral"`
+	TitleTimeSaved                               int      `eval:"neutral"`
+	PeakParkedKb                                 int      `eval:"neutral"`
+	PagesSpilled                                 int      `eval:"neutral"`
 	TotalTime                                    int      `eval:"neutral"`
 }
 
@@ -211,6 +215,9 @@ func runGuidedCrawl(
 
 	tempProgressLogger := crawler.NewMockProgressLogger(crawler.NewDummyLogger())
 	crawlCtx := crawler.NewCrawlContext(&mockHttpClient, puppeteerClient, tempProgressLogger)
+	crawlCtx.PageMemory.Budget = int64(memoryBudgetMb) * 1024 * 1024
+	crawlCtx.PageMemory.KeepParsedPages = keepParsedPages
+	crawlCtx.HistoricalStrategies = historicalStrategies
 	startTime := time.Now()
 
 	defer func() {
@@ -219,6 +226,9 @@ func runGuidedCrawl(
 		result.TotalPages = crawlCtx.FetchedCuris.Length
 		result.TotalNetworkRequests = mockHttpClient.NetworkRequestsMade + crawlCtx.PuppeteerRequestsMade
 		result.TitleRequests = crawlCtx.TitleRequestsMade
+		result.TitleTimeSaved = int(math.Round(crawlCtx.TitleFetchTimeSaved))
+		result.PeakParkedKb = int(crawlCtx.PageMemory.PeakParkedBytes / 1024)
+		result.PagesSpilled = crawlCtx.PageMemory.SpilledPagesCount
 		result.TotalTime = int(math.Round(time.Since(startTime).Seconds()))
 	}()
 
@@ -320,7 +330,7 @@ tables:
 	}
 
 	crawlCtx.ProgressLogger = crawler.NewMockProgressLogger(logger)
-	guidedCrawlResult, err := crawler.GuidedCrawl(maybeStartPage, feed, &crawlCtx, logger)
+	guidedCrawlResult, err := crawler.GuidedCrawl(maybeStartPage, feed, nil, nil, &crawlCtx, logger)
 	if err != nil {
 		return &result, newError(err, result)
 	}
@@ -371,6 +381,7 @@ tables:
 		return &result, newError(err, result)
 	}
 
+	result.HistoricalLinksStrategy = string(historicalResult.Strategy)
 	historicalBlogLinkStr := historicalResult.BlogLink.Curi.String()
 	historicalMainLinkStr := historicalResult.MainLink.Curi.String()
 	entriesCount := len(historicalResult.Links)
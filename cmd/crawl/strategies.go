@@ -0,0 +1,75 @@
+package crawl
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/oops"
+
+	"github.com/spf13/cobra"
+)
+
+// Strategies can be enabled, disabled or forced for a crawl so that a new one can be compared against the
+// ground truth before it competes with the rest
+
+var CrawlStrategies *cobra.Command
+
+var enableStrategies []string
+var disableStrategies []string
+var forceStrategy string
+var historicalStrategies = crawler.NewDefaultHistoricalStrategySelection()
+
+func init() {
+	CrawlStrategies = &cobra.Command{
+		Use: "crawl-strategies",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return listStrategies()
+		},
+	}
+}
+
+func addStrategyFlags(cmd *cobra.Command) {
+	namesStr := strings.Join(crawler.HistoricalStrategyNames(), ", ")
+	cmd.Flags().StringSliceVar(
+		&enableStrategies, "enable-strategy", nil, "historical strategies to enable: "+namesStr,
+	)
+	cmd.Flags().StringSliceVar(
+		&disableStrategies, "disable-strategy", nil, "historical strategies to disable: "+namesStr,
+	)
+	cmd.Flags().StringVar(
+		&forceStrategy, "force-strategy", "", "the only historical strategy to run: "+namesStr,
+	)
+}
+
+func parseStrategyFlags() error {
+	var maybeForceStrategy *string
+	if forceStrategy != "" {
+		maybeForceStrategy = &forceStrategy
+	}
+	selection, err := crawler.NewHistoricalStrategySelection(
+		enableStrategies, disableStrategies, maybeForceStrategy,
+	)
+	if err != nil {
+		return err
+	}
+	historicalStrategies = selection
+	return nil
+}
+
+func listStrategies() error {
+	defaultSelection := crawler.NewDefaultHistoricalStrategySelection()
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "name\tcost\tenabled by default")
+	for _, strategy := range crawler.HistoricalStrategies() {
+		fmt.Fprintf(
+			writer, "%s\t%s\t%t\n", strategy.Name(), strategy.Cost(), defaultSelection.IsEnabled(strategy.Name()),
+		)
+	}
+	if err := writer.Flush(); err != nil {
+		return oops.Wrap(err)
+	}
+	return nil
+}
@@ -958,7 +958,7 @@ func crawl() {
 				Content:  singleFeed.Feed.Content,
 			}
 			guidedCrawlResult, crawlErr :=
-				crawler.GuidedCrawl(singleFeed.MaybeStartPage, feed, &crawlCtx, logger)
+				crawler.GuidedCrawl(singleFeed.MaybeStartPage, feed, nil, nil, &crawlCtx, logger)
 			if crawlErr == nil && guidedCrawlResult.HardcodedError != nil {
 				crawlErr = fmt.Errorf("hardcoded error: %v", guidedCrawlResult.HardcodedError)
 			}
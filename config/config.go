@@ -18,6 +18,7 @@ type Config struct {
 	AwsSecretAccessKey        string
 	SlackWebhook              string
 	TumblrApiKey              string
+	Smtp                      SmtpConfig
 	AdminUserIds              map[int64]bool
 }
 
@@ -49,6 +50,15 @@ func (c DBConfig) DSN() string {
 	return fmt.Sprintf("user=%s%s host=%s port=%d dbname=%s", c.User, password, c.Host, c.Port, c.DBName)
 }
 
+// Any server that speaks SMTP with STARTTLS or without TLS at all, e.g. a local catcher like Mailpit in dev
+type SmtpConfig struct {
+	Host        string
+	Port        int
+	Username    string // empty to skip auth
+	Password    string
+	FromAddress string
+}
+
 const AuthTokenLength = 16
 
 var Cfg Config
@@ -25,6 +25,7 @@ func testingConfig() Config {
 		AwsSecretAccessKey:        devCfg.AwsSecretAccessKey,
 		SlackWebhook:              devCfg.SlackWebhook,
 		TumblrApiKey:              devCfg.TumblrApiKey,
+		Smtp:                      devCfg.Smtp,
 		AdminUserIds:              nil,
 	}
 }
@@ -105,10 +105,34 @@ func developmentConfig() Config {
 		AwsSecretAccessKey:        getStringOrDemo("aws_secret_access_key"),
 		SlackWebhook:              getStringOrDemo("slack_webhook"),
 		TumblrApiKey:              getStringOrDemo("tumblr_api_key"),
+		Smtp:                      developmentSmtpConfig(jsonConfig),
 		AdminUserIds:              nil,
 	}
 }
 
+// Defaults to a local SMTP catcher (Mailpit or MailHog on their default port) so that emails can be looked at
+// without any setup
+func developmentSmtpConfig(jsonConfig map[string]any) SmtpConfig {
+	getStringOr := func(key string, defaultValue string) string {
+		if value, ok := jsonConfig[key]; ok {
+			return value.(string)
+		}
+		return defaultValue
+	}
+	port := 1025
+	if value, ok := jsonConfig["smtp_port"]; ok {
+		port = int(value.(float64))
+	}
+
+	return SmtpConfig{
+		Host:        getStringOr("smtp_host", "localhost"),
+		Port:        port,
+		Username:    getStringOr("smtp_username", ""),
+		Password:    getStringOr("smtp_password", ""),
+		FromAddress: getStringOr("smtp_from_address", "digest@feedrewind.localhost"),
+	}
+}
+
 func mustReadJsonConfig() map[string]any {
 	configBytes, err := os.ReadFile("config/devbox.json")
 	if errors.Is(err, os.ErrNotExist) {
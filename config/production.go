@@ -42,6 +42,11 @@ func productionConfig() Config {
 		panic(err)
 	}
 
+	smtpPort, err := strconv.Atoi(mustLookupEnv("SMTP_PORT"))
+	if err != nil {
+		panic(err)
+	}
+
 	return Config{
 		Env:  EnvProduction,
 		Dyno: mustLookupEnv("DYNO"),
@@ -61,6 +66,13 @@ func productionConfig() Config {
 		AwsSecretAccessKey:        mustLookupEnv("AWS_SECRET_ACCESS_KEY"),
 		SlackWebhook:              mustLookupEnv("SLACK_WEBHOOK"),
 		TumblrApiKey:              mustLookupEnv("TUMBLR_API_KEY"),
+		Smtp: SmtpConfig{
+			Host:        mustLookupEnv("SMTP_HOST"),
+			Port:        smtpPort,
+			Username:    mustLookupEnv("SMTP_USERNAME"),
+			Password:    mustLookupEnv("SMTP_PASSWORD"),
+			FromAddress: mustLookupEnv("SMTP_FROM_ADDRESS"),
+		},
 		AdminUserIds: map[int64]bool{
 			adminUserId: true,
 		},
@@ -0,0 +1,256 @@
+package crawler
+
+import (
+	"errors"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"feedrewind.com/oops"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Reader view extraction is readability-style: paragraphs score their parent and grandparent, the best
+// scoring container wins, and it goes through the same sanitizer as the feed content.
+
+var ErrNoArticle = errors.New("no article found")
+
+const minArticleTextLength = 250
+
+func ExtractArticleAtUrl(url string, crawlCtx *CrawlContext, logger Logger) (string, error) {
+	link, ok := ToCanonicalLink(url, logger, nil)
+	if !ok {
+		return "", oops.Newf("Couldn't canonicalize post url: %s", url)
+	}
+
+	type CrawlResult struct {
+		Page  *htmlPage
+		Error error
+	}
+	ch := make(chan CrawlResult, 1)
+	go func() {
+		page, err := crawlHtmlPage(link, crawlCtx, logger)
+		ch <- CrawlResult{
+			Page:  page,
+			Error: err,
+		}
+	}()
+
+	var page *htmlPage
+	select {
+	case <-time.After(10 * time.Second):
+		return "", ErrTimeout
+	case result := <-ch:
+		if result.Error != nil {
+			return "", result.Error
+		}
+		page = result.Page
+	}
+
+	content, ok := extractArticle(page.Document, page.FetchUri, logger)
+	if !ok {
+		return "", ErrNoArticle
+	}
+	return content, nil
+}
+
+const articleExcerptLength = 500
+
+// The leading blocks of an extracted article, for feeds that don't carry the post content. Wrappers
+// around the whole article are looked through so that the excerpt doesn't end up being all of it.
+func ArticleExcerpt(content string) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body} //nolint:exhaustruct
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		var blocks []*html.Node
+		for _, node := range nodes {
+			if node.Type == html.TextNode && strings.TrimSpace(node.Data) == "" {
+				continue
+			}
+			blocks = append(blocks, node)
+		}
+		if len(blocks) != 1 || blocks[0].Type != html.ElementNode || blocks[0].Data != "div" {
+			nodes = blocks
+			break
+		}
+		nodes = nil
+		for child := blocks[0].FirstChild; child != nil; child = child.NextSibling {
+			nodes = append(nodes, child)
+		}
+	}
+
+	var sb strings.Builder
+	textLength := 0
+	for _, node := range nodes {
+		if textLength >= articleExcerptLength {
+			break
+		}
+		if err := html.Render(&sb, node); err != nil {
+			return ""
+		}
+		textLength += len(strings.TrimSpace(innerText(node)))
+	}
+	return sb.String()
+}
+
+var unlikelyArticleTags = map[string]bool{
+	"aside":    true,
+	"footer":   true,
+	"form":     true,
+	"header":   true,
+	"nav":      true,
+	"noscript": true,
+	"script":   true,
+	"style":    true,
+}
+
+var unlikelyArticleRegex = regexp.MustCompile(
+	`(?i)banner|breadcrumb|comment|cookie|disqus|footer|header|menu|modal|nav|popup|related|share|sidebar|` +
+		`social|sponsor|subscribe|widget|advert|promo`,
+)
+var maybeArticleRegex = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+var negativeArticleRegex = regexp.MustCompile(
+	`(?i)comment|footer|footnote|masthead|meta|related|share|sidebar|sponsor|social|tags|widget`,
+)
+
+func extractArticle(document *html.Node, fetchUri *neturl.URL, logger Logger) (string, bool) {
+	root := htmlquery.FindOne(document, "//body")
+	if root == nil {
+		root = document
+	}
+	removeUnlikelyArticleNodes(root)
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(node *html.Node, score float64) {
+		if node == nil || node.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[node]; !ok {
+			scores[node] = articleClassWeight(node)
+			candidates = append(candidates, node)
+		}
+		scores[node] += score
+	}
+	for _, paragraph := range htmlquery.Find(root, "//p | //pre | //td | //blockquote") {
+		text := strings.TrimSpace(innerText(paragraph))
+		if len(text) < 25 {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text)/100), 3)
+		addScore(paragraph.Parent, score)
+		if paragraph.Parent != nil {
+			addScore(paragraph.Parent.Parent, score/2)
+		}
+	}
+
+	var bestCandidate *html.Node
+	var bestScore float64
+	for _, candidate := range candidates {
+		score := scores[candidate] * (1 - articleLinkDensity(candidate))
+		if bestCandidate == nil || score > bestScore {
+			bestCandidate = candidate
+			bestScore = score
+		}
+	}
+	if bestCandidate == nil {
+		logger.Info("Article extraction: no paragraphs")
+		return "", false
+	}
+	textLength := len(strings.TrimSpace(innerText(bestCandidate)))
+	if textLength < minArticleTextLength {
+		logger.Info("Article extraction: best candidate is too short (%d)", textLength)
+		return "", false
+	}
+
+	var sb strings.Builder
+	for child := bestCandidate.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&sb, child); err != nil {
+			logger.Info("Article extraction: couldn't render: %v", err)
+			return "", false
+		}
+	}
+	content, ok := SanitizePostContent(sb.String(), fetchUri)
+	if !ok {
+		logger.Info("Article extraction: nothing left after sanitizing")
+		return "", false
+	}
+	logger.Info(
+		"Article extraction: %s with score %.1f, %d chars", bestCandidate.Data, bestScore, len(content),
+	)
+	return content, true
+}
+
+func removeUnlikelyArticleNodes(root *html.Node) {
+	var toRemove []*html.Node
+	var traverse func(node *html.Node)
+	traverse = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			if unlikelyArticleTags[child.Data] {
+				toRemove = append(toRemove, child)
+				continue
+			}
+			if child.Data != "article" && child.Data != "main" {
+				classAndId := findAttr(child, "class") + " " + findAttr(child, "id")
+				if unlikelyArticleRegex.MatchString(classAndId) &&
+					!maybeArticleRegex.MatchString(classAndId) {
+					toRemove = append(toRemove, child)
+					continue
+				}
+			}
+			traverse(child)
+		}
+	}
+	traverse(root)
+
+	for _, node := range toRemove {
+		node.Parent.RemoveChild(node)
+	}
+}
+
+func articleClassWeight(node *html.Node) float64 {
+	weight := 0.0
+	switch node.Data {
+	case "article":
+		weight += 10
+	case "div", "main", "section":
+		weight += 5
+	case "li", "ol", "ul", "form", "th":
+		weight -= 3
+	}
+	for _, value := range []string{findAttr(node, "class"), findAttr(node, "id")} {
+		if value == "" {
+			continue
+		}
+		if maybeArticleRegex.MatchString(value) {
+			weight += 25
+		}
+		if negativeArticleRegex.MatchString(value) {
+			weight -= 25
+		}
+	}
+	return weight
+}
+
+func articleLinkDensity(node *html.Node) float64 {
+	textLength := len(strings.TrimSpace(innerText(node)))
+	if textLength == 0 {
+		return 0
+	}
+	linkTextLength := 0
+	for _, link := range htmlquery.Find(node, ".//a") {
+		linkTextLength += len(strings.TrimSpace(innerText(link)))
+	}
+	return float64(linkTextLength) / float64(textLength)
+}
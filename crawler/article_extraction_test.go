@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	neturl "net/url"
+	"strings"
+	"testing"
+
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractArticle(t *testing.T) {
+	longParagraph := strings.TrimSpace(
+		strings.Repeat("This is a sentence about the topic of the post, with some commas. ", 5),
+	)
+
+	type Test struct {
+		description     string
+		html            string
+		expectedContent string
+		expectedOk      bool
+	}
+
+	tests := []Test{
+		{
+			description: "pick the article over the sidebar and comments",
+			html: `<html><body>
+<nav><a href="/">Home</a></nav>
+<div class="sidebar"><p>` + longParagraph + `</p></div>
+<article><div class="post-content"><p>` + longParagraph + `</p><p>` + longParagraph + `</p></div></article>
+<div id="comments"><p>` + longParagraph + `</p></div>
+</body></html>`,
+			expectedContent: `<p>` + longParagraph + `</p><p>` + longParagraph + `</p>`,
+			expectedOk:      true,
+		},
+		{
+			description: "resolve relative urls",
+			html: `<html><body><div class="content"><p>` + longParagraph + `<a href="/next">next</a></p>` +
+				`<p>` + longParagraph + `</p></div></body></html>`,
+			expectedContent: `<p>` + longParagraph + `<a href="https://blog/next">next</a></p>` +
+				`<p>` + longParagraph + `</p>`,
+			expectedOk: true,
+		},
+		{
+			description: "skip link lists",
+			html: `<html><body><ul class="archive">` +
+				`<li><p><a href="/1">A long post title that goes on and on</a></p></li>` +
+				`<li><p><a href="/2">Another long post title that goes on and on</a></p></li>` +
+				`</ul></body></html>`,
+			expectedContent: "",
+			expectedOk:      false,
+		},
+		{
+			description:     "fail when too short",
+			html:            `<html><body><div><p>Just a short note, nothing else here.</p></div></body></html>`,
+			expectedContent: "",
+			expectedOk:      false,
+		},
+	}
+
+	postUri, err := neturl.Parse("https://blog/post")
+	oops.RequireNoError(t, err)
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			logger := NewDummyLogger()
+			document, err := parseHtml(tc.html, logger)
+			oops.RequireNoError(t, err)
+			content, ok := extractArticle(document, postUri, logger)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedContent, content)
+		})
+	}
+}
+
+func TestArticleExcerpt(t *testing.T) {
+	paragraph := "<p>" + strings.Repeat("Twenty characters.. ", 10) + "</p>"
+
+	type Test struct {
+		description     string
+		content         string
+		expectedExcerpt string
+	}
+
+	tests := []Test{
+		{
+			description:     "stop after enough text",
+			content:         paragraph + paragraph + paragraph + paragraph,
+			expectedExcerpt: paragraph + paragraph + paragraph,
+		},
+		{
+			description:     "keep short articles whole",
+			content:         "<h2>Intro</h2>" + paragraph,
+			expectedExcerpt: "<h2>Intro</h2>" + paragraph,
+		},
+		{
+			description:     "look through wrappers",
+			content:         "<div>\n<div>" + paragraph + paragraph + paragraph + paragraph + "</div>\n</div>",
+			expectedExcerpt: paragraph + paragraph + paragraph,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expectedExcerpt, ArticleExcerpt(tc.content))
+		})
+	}
+}
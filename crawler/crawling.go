@@ -27,9 +27,12 @@ type CrawlContext struct {
 	DuplicateFetches      int
 	TitleRequestsMade     int
 	TitleFetchDuration    float64
+	TitleFetchTimeSaved   float64
 	HttpClient            HttpClient
 	MaybePuppeteerClient  PuppeteerClient
 	ProgressLogger        *ProgressLogger
+	PageMemory            *PageMemory
+	HistoricalStrategies  *HistoricalStrategySelection
 	RobotsClient          *RobotsClient // initialized by the crawler and not the caller
 }
 
@@ -46,13 +49,54 @@ func NewCrawlContext(
 		DuplicateFetches:      0,
 		TitleRequestsMade:     0,
 		TitleFetchDuration:    0,
+		TitleFetchTimeSaved:   0,
 		HttpClient:            httpClient,
 		MaybePuppeteerClient:  maybePuppeteerClient,
 		ProgressLogger:        progressLogger,
+		PageMemory:            NewPageMemory(DefaultPageMemoryBudget),
+		HistoricalStrategies:  NewDefaultHistoricalStrategySelection(),
 		RobotsClient:          nil,
 	}
 }
 
+// A fork can crawl on another goroutine while sharing the clients. The caller is responsible for joining it
+// back on the goroutine that owns the original context.
+func (c *CrawlContext) fork() *CrawlContext {
+	return &CrawlContext{
+		FetchedCuris:          NewCanonicalUriSet(nil, c.FetchedCuris.curiEqCfg),
+		PptrFetchedCuris:      NewCanonicalUriSet(nil, c.PptrFetchedCuris.curiEqCfg),
+		Redirects:             make(map[string]*Link),
+		RequestsMade:          0,
+		PuppeteerRequestsMade: 0,
+		DuplicateFetches:      0,
+		TitleRequestsMade:     0,
+		TitleFetchDuration:    0,
+		TitleFetchTimeSaved:   0,
+		HttpClient:            c.HttpClient,
+		MaybePuppeteerClient:  nil,
+		ProgressLogger:        NewProgressLogger(nil),
+		PageMemory:            c.PageMemory, // forks don't park pages
+		HistoricalStrategies:  c.HistoricalStrategies,
+		RobotsClient:          c.RobotsClient,
+	}
+}
+
+func (c *CrawlContext) join(fork *CrawlContext) {
+	for _, curi := range fork.FetchedCuris.Curis {
+		if c.FetchedCuris.Contains(curi) {
+			c.DuplicateFetches++
+		} else {
+			c.FetchedCuris.add(curi)
+		}
+	}
+	for url, link := range fork.Redirects {
+		c.Redirects[url] = link
+	}
+	c.RequestsMade += fork.RequestsMade
+	c.DuplicateFetches += fork.DuplicateFetches
+	c.ProgressLogger.Status += fork.ProgressLogger.Status
+}
+
 type pageBase struct {
 	Curi     CanonicalUri
 	FetchUri *url.URL
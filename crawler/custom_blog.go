@@ -0,0 +1,289 @@
+package crawler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"feedrewind.com/oops"
+
+	"github.com/antchfx/xmlquery"
+)
+
+// Custom blogs are assembled from a list of post urls that the user provides when the crawl fails.
+// The list is checked against the feed and the missing titles are fetched the same way as for the crawled
+// blogs.
+
+type CustomBlogPost struct {
+	Url        string
+	MaybeTitle *string
+}
+
+type CustomBlogFormat string
+
+const (
+	CustomBlogFormatText CustomBlogFormat = "text"
+	CustomBlogFormatCsv  CustomBlogFormat = "csv"
+	CustomBlogFormatOpml CustomBlogFormat = "opml"
+)
+
+const CustomBlogMaxPosts = 5000
+
+var ErrCustomBlogEmpty = errors.New("custom blog has no posts")
+var ErrCustomBlogTooManyPosts = fmt.Errorf("custom blog has more than %d posts", CustomBlogMaxPosts)
+var ErrCustomBlogNotMatchingFeed = errors.New("custom blog posts don't match the feed")
+
+func GetCustomBlogFormat(fileName string) CustomBlogFormat {
+	lowerFileName := strings.ToLower(fileName)
+	switch {
+	case strings.HasSuffix(lowerFileName, ".csv"):
+		return CustomBlogFormatCsv
+	case strings.HasSuffix(lowerFileName, ".opml"), strings.HasSuffix(lowerFileName, ".xml"):
+		return CustomBlogFormatOpml
+	default:
+		return CustomBlogFormatText
+	}
+}
+
+// The order of the posts is preserved
+func ParseCustomBlogPosts(content string, format CustomBlogFormat, logger Logger) ([]CustomBlogPost, error) {
+	var posts []CustomBlogPost
+	var err error
+	switch format {
+	case CustomBlogFormatText:
+		posts, err = parseCustomBlogText(content)
+	case CustomBlogFormatCsv:
+		posts, err = parseCustomBlogCsv(content)
+	case CustomBlogFormatOpml:
+		posts, err = parseCustomBlogOpml(content, logger)
+	default:
+		panic(fmt.Errorf("Unknown custom blog format: %s", format))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(posts) == 0 {
+		return nil, ErrCustomBlogEmpty
+	}
+	if len(posts) > CustomBlogMaxPosts {
+		return nil, ErrCustomBlogTooManyPosts
+	}
+	return posts, nil
+}
+
+func isFullUrl(str string) bool {
+	return strings.HasPrefix(str, "http://") || strings.HasPrefix(str, "https://")
+}
+
+func newCustomBlogPost(url, title string) CustomBlogPost {
+	var maybeTitle *string
+	title = strings.TrimSpace(title)
+	if title != "" {
+		maybeTitle = &title
+	}
+	return CustomBlogPost{
+		Url:        url,
+		MaybeTitle: maybeTitle,
+	}
+}
+
+// Every line is a url, optionally followed by a space and a title
+func parseCustomBlogText(content string) ([]CustomBlogPost, error) {
+	var posts []CustomBlogPost
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		url, title, _ := strings.Cut(line, " ")
+		if !isFullUrl(url) {
+			return nil, oops.Newf("Line %d doesn't start with a full url: %s", i+1, line)
+		}
+		posts = append(posts, newCustomBlogPost(url, title))
+	}
+	return posts, nil
+}
+
+// The first column that looks like a url is the post, the next non-empty column is the title.
+// Rows without a url (e.g. the header) are skipped.
+func parseCustomBlogCsv(content string) ([]CustomBlogPost, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var posts []CustomBlogPost
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, oops.Wrap(err)
+		}
+
+		urlIdx := -1
+		for i, field := range record {
+			if isFullUrl(strings.TrimSpace(field)) {
+				urlIdx = i
+				break
+			}
+		}
+		if urlIdx == -1 {
+			continue
+		}
+
+		var title string
+		for _, field := range record[urlIdx+1:] {
+			if strings.TrimSpace(field) != "" {
+				title = field
+				break
+			}
+		}
+		posts = append(posts, newCustomBlogPost(strings.TrimSpace(record[urlIdx]), title))
+	}
+	return posts, nil
+}
+
+// Every outline with a url is a post, nesting is ignored
+func parseCustomBlogOpml(content string, logger Logger) ([]CustomBlogPost, error) {
+	opml, err := parseXML(content, logger)
+	if err != nil {
+		return nil, oops.Wrap(err)
+	}
+	if xmlquery.FindOne(opml, "/opml") == nil {
+		return nil, oops.New("Not an OPML file")
+	}
+
+	var posts []CustomBlogPost
+	for _, outline := range xmlquery.Find(opml, "//outline") {
+		var url string
+		for _, attr := range []string{"url", "htmlUrl"} {
+			if value := strings.TrimSpace(outline.SelectAttr(attr)); isFullUrl(value) {
+				url = value
+				break
+			}
+		}
+		if url == "" {
+			continue
+		}
+
+		title := outline.SelectAttr("title")
+		if strings.TrimSpace(title) == "" {
+			title = outline.SelectAttr("text")
+		}
+		posts = append(posts, newCustomBlogPost(url, title))
+	}
+	return posts, nil
+}
+
+type CustomBlogResult struct {
+	Links                    []*titledLink
+	DiscardedFeedEntryUrls   []string
+	MissingFromFeedEntryUrls []string
+	CuriEqCfg                *CanonicalEqualityConfig
+}
+
+// Posts are expected newest first, same as the result
+func CrawlCustomBlog(
+	posts []CustomBlogPost, feed Feed, crawlCtx *CrawlContext, logger Logger,
+) (*CustomBlogResult, error) {
+	logger.Info("Custom blog: %d posts", len(posts))
+	feedLink, ok := ToCanonicalLink(feed.Url, logger, nil)
+	if !ok {
+		return nil, oops.Newf("Bad feed url: %s", feed.Url)
+	}
+	feedFinalLink, ok := ToCanonicalLink(feed.FinalUrl, logger, nil)
+	if !ok {
+		return nil, oops.Newf("Bad feed final url: %s", feed.FinalUrl)
+	}
+	parsedFeed, err := ParseFeed(feed.Content, feedFinalLink.Uri, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	crawlCtx.RobotsClient = NewRobotsClient(feedLink.Uri, crawlCtx.HttpClient, logger)
+	curiEqCfg := NewCanonicalEqualityConfig()
+
+	feedEntryLinks := parsedFeed.EntryLinks.ToSlice()
+	feedEntryCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](&curiEqCfg)
+	for _, entryLink := range feedEntryLinks {
+		feedEntryCurisTitlesMap.Add(entryLink.Link, entryLink.MaybeTitle)
+	}
+
+	links := make([]*maybeTitledLink, 0, len(posts))
+	seenCurisSet := NewCanonicalUriSet(nil, &curiEqCfg)
+	for _, post := range posts {
+		link, ok := ToCanonicalLink(post.Url, logger, nil)
+		if !ok {
+			return nil, oops.Newf("Bad post url: %s", post.Url)
+		}
+		if seenCurisSet.Contains(link.Curi) {
+			logger.Info("Skipping duplicate post: %s", link.Url)
+			continue
+		}
+		seenCurisSet.add(link.Curi)
+
+		var maybeTitle *LinkTitle
+		if post.MaybeTitle != nil {
+			title := NewLinkTitle(*post.MaybeTitle, LinkTitleSourceUser, nil)
+			maybeTitle = &title
+		} else if feedTitle, ok := feedEntryCurisTitlesMap.Get(link.Curi); ok {
+			maybeTitle = feedTitle
+		}
+		links = append(links, &maybeTitledLink{
+			Link:       *link,
+			MaybeTitle: maybeTitle,
+		})
+	}
+
+	var discardedFeedEntryUrls []string
+	matchingFeedEntriesCount := 0
+	for _, entryLink := range feedEntryLinks {
+		if seenCurisSet.Contains(entryLink.Curi) {
+			matchingFeedEntriesCount++
+		} else {
+			discardedFeedEntryUrls = append(discardedFeedEntryUrls, entryLink.Url)
+		}
+	}
+	if matchingFeedEntriesCount == 0 {
+		logger.Info("Custom blog doesn't have any of the %d feed entries", len(feedEntryLinks))
+		return nil, ErrCustomBlogNotMatchingFeed
+	}
+	logger.Info(
+		"Custom blog has %d/%d feed entries (%d discarded)",
+		matchingFeedEntriesCount, len(feedEntryLinks), len(discardedFeedEntryUrls),
+	)
+
+	// Posts newer than the oldest matching feed entry are expected to be in the feed, the ones that aren't
+	// need to be remembered so that they don't look new on the next update
+	feedCurisSet := NewCanonicalUriSet(ToCanonicalUris(feedEntryLinks), &curiEqCfg)
+	oldestFeedPostIndex := -1
+	for i, link := range links {
+		if feedCurisSet.Contains(link.Curi) {
+			oldestFeedPostIndex = i
+		}
+	}
+	var missingFromFeedEntryUrls []string
+	for _, link := range links[:oldestFeedPostIndex+1] {
+		if !feedCurisSet.Contains(link.Curi) {
+			missingFromFeedEntryUrls = append(missingFromFeedEntryUrls, link.Url)
+		}
+	}
+
+	titledLinks, err := fetchMissingTitles(
+		links, &parsedFeed.EntryLinks, &feedEntryCurisTitlesMap, parsedFeed.Generator, &curiEqCfg, crawlCtx,
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Custom blog titles: %s", countLinkTitleSources(titledLinks))
+
+	return &CustomBlogResult{
+		Links:                    titledLinks,
+		DiscardedFeedEntryUrls:   discardedFeedEntryUrls,
+		MissingFromFeedEntryUrls: missingFromFeedEntryUrls,
+		CuriEqCfg:                &curiEqCfg,
+	}, nil
+}
@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCustomBlogPosts(t *testing.T) {
+	type Test struct {
+		description    string
+		content        string
+		format         CustomBlogFormat
+		expectedUrls   []string
+		expectedTitles []string
+	}
+
+	tests := []Test{
+		{
+			description:    "text with and without titles",
+			content:        "https://blog.com/post2 Second post\n\n  https://blog.com/post1  \n",
+			format:         CustomBlogFormatText,
+			expectedUrls:   []string{"https://blog.com/post2", "https://blog.com/post1"},
+			expectedTitles: []string{"Second post", ""},
+		},
+		{
+			description: "csv with header",
+			content: "date,url,title\n" +
+				"2020-01-02,https://blog.com/post2,\"Second, post\"\n" +
+				"2020-01-01,https://blog.com/post1,\n",
+			format:         CustomBlogFormatCsv,
+			expectedUrls:   []string{"https://blog.com/post2", "https://blog.com/post1"},
+			expectedTitles: []string{"Second, post", ""},
+		},
+		{
+			description: "opml with nested outlines",
+			content: `<?xml version="1.0"?>
+<opml version="2.0">
+  <body>
+    <outline text="2020">
+      <outline text="Second post" url="https://blog.com/post2"/>
+      <outline title="First post" text="ignored" htmlUrl="https://blog.com/post1"/>
+    </outline>
+  </body>
+</opml>`,
+			format:         CustomBlogFormatOpml,
+			expectedUrls:   []string{"https://blog.com/post2", "https://blog.com/post1"},
+			expectedTitles: []string{"Second post", "First post"},
+		},
+	}
+
+	logger := NewDummyLogger()
+	for _, tc := range tests {
+		posts, err := ParseCustomBlogPosts(tc.content, tc.format, logger)
+		require.NoError(t, err, tc.description)
+		var urls []string
+		var titles []string
+		for _, post := range posts {
+			urls = append(urls, post.Url)
+			if post.MaybeTitle != nil {
+				titles = append(titles, *post.MaybeTitle)
+			} else {
+				titles = append(titles, "")
+			}
+		}
+		require.Equal(t, tc.expectedUrls, urls, tc.description)
+		require.Equal(t, tc.expectedTitles, titles, tc.description)
+	}
+}
+
+func TestParseCustomBlogPostsErrors(t *testing.T) {
+	logger := NewDummyLogger()
+
+	_, err := ParseCustomBlogPosts("https://blog.com/post1\nblog.com/post2", CustomBlogFormatText, logger)
+	require.Error(t, err)
+
+	_, err = ParseCustomBlogPosts("url,title\n", CustomBlogFormatCsv, logger)
+	require.ErrorIs(t, err, ErrCustomBlogEmpty)
+
+	_, err = ParseCustomBlogPosts(`<rss><channel></channel></rss>`, CustomBlogFormatOpml, logger)
+	require.Error(t, err)
+}
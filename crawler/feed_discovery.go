@@ -45,7 +45,9 @@ type DiscoverFeedsErrorCouldNotReach struct {
 	Error error
 }
 
-type DiscoverFeedsErrorNoFeeds struct{}
+type DiscoverFeedsErrorNoFeeds struct {
+	MaybeStartPage *DiscoveredStartPage
+}
 
 type DiscoverFeedsErrorBadFeed struct{}
 
@@ -127,7 +129,9 @@ func DiscoverFeedsAtUrl(
 	startPage, err := crawlFeedWithTimeout(startLink, enforceTimeout, crawlCtx, logger)
 	if errors.Is(err, ErrNotAFeedOrHtmlPage) {
 		logger.Info("Page is not a feed or html: %s", startLink.Url)
-		return &DiscoverFeedsErrorNoFeeds{}
+		return &DiscoverFeedsErrorNoFeeds{
+			MaybeStartPage: nil,
+		}
 	} else if err != nil {
 		logger.Info("Error while getting start_link: %v", err)
 		return &DiscoverFeedsErrorCouldNotReach{
@@ -256,7 +260,9 @@ func DiscoverFeedsAtUrl(
 
 		switch len(dedupFeeds) {
 		case 0:
-			return &DiscoverFeedsErrorNoFeeds{}
+			return &DiscoverFeedsErrorNoFeeds{
+				MaybeStartPage: &startPage,
+			}
 		case 1:
 			singleFeedResult := FetchFeedAtUrl(dedupFeeds[0].Url, enforceTimeout, crawlCtx, logger)
 			switch r := singleFeedResult.(type) {
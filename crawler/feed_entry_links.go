@@ -9,7 +9,8 @@ import (
 
 type FeedEntryLink struct {
 	maybeTitledLink
-	MaybeDate *time.Time
+	MaybeDate    *time.Time
+	MaybeContent *string // sanitized html
 }
 
 type FeedEntryLinks struct {
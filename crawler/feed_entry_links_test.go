@@ -148,7 +148,8 @@ func TestSequenceSuffixLength(t *testing.T) {
 						Link:       *link,
 						MaybeTitle: nil,
 					},
-					MaybeDate: nil,
+					MaybeDate:    nil,
+					MaybeContent: nil,
 				})
 			}
 			linkBuckets = append(linkBuckets, linkBucket)
@@ -123,6 +123,7 @@ type feedEntry struct {
 	title   string
 	pubDate time.Time
 	url     string
+	content string // unsanitized html
 }
 
 func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed, error) {
@@ -179,6 +180,8 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 				}
 			}
 
+			itemContent := getRssItemContent(itemNode)
+
 			if hasFeedburnerNamespace {
 				logger.Info("Feed is from Feedburner")
 				feedburnerOrigLinkNode := xmlquery.FindOne(itemNode, "feedburner:origLink")
@@ -187,6 +190,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 						title:   itemTitle,
 						pubDate: pubDate,
 						url:     feedburnerOrigLinkNode.InnerText(),
+						content: itemContent,
 					})
 					continue
 				}
@@ -198,6 +202,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 					title:   itemTitle,
 					pubDate: pubDate,
 					url:     linkNode.InnerText(),
+					content: itemContent,
 				})
 				continue
 			}
@@ -209,6 +214,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 					title:   itemTitle,
 					pubDate: pubDate,
 					url:     permalinkGuidNode.InnerText(),
+					content: itemContent,
 				})
 				continue
 			}
@@ -277,6 +283,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 					title:   itemTitle,
 					pubDate: pubDate,
 					url:     linkNode.InnerText(),
+					content: getRssItemContent(itemNode),
 				})
 				continue
 			}
@@ -342,6 +349,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 				title:   entryTitle,
 				pubDate: pubDate,
 				url:     url,
+				content: getAtomEntryContent(entryNode),
 			})
 		}
 
@@ -398,6 +406,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 	sortedEntries, areDatesCertain := trySortReverseChronological(entries, logger)
 	entryTitleCount := 0
 	entryTitleNeedsDecodingCount := 0
+	entryContentCount := 0
 	var entryLinks []FeedEntryLink
 	for _, entry := range sortedEntries {
 		link, ok := ToCanonicalLink(entry.url, logger, fetchUri)
@@ -420,12 +429,20 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 			date := entry.pubDate
 			maybeDate = &date
 		}
+		var maybeContent *string
+		if entry.content != "" {
+			if content, ok := SanitizePostContent(entry.content, link.Uri); ok {
+				entryContentCount++
+				maybeContent = &content
+			}
+		}
 		entryLinks = append(entryLinks, FeedEntryLink{
 			maybeTitledLink: maybeTitledLink{
 				Link:       *link,
 				MaybeTitle: maybeLinkTitle,
 			},
-			MaybeDate: maybeDate,
+			MaybeDate:    maybeDate,
+			MaybeContent: maybeContent,
 		})
 	}
 
@@ -433,6 +450,7 @@ func ParseFeed(content string, fetchUri *neturl.URL, logger Logger) (*ParsedFeed
 	logger.Info("Feed entries: %d", feedEntryLinks.Length)
 	logger.Info("Feed entry titles present: %d", entryTitleCount)
 	logger.Info("Feed entry titles needed HTML decoding: %d", entryTitleNeedsDecodingCount)
+	logger.Info("Feed entry contents present: %d", entryContentCount)
 	logger.Info("Feed entry order certain: %t", feedEntryLinks.IsOrderCertain)
 
 	return &ParsedFeed{
@@ -498,6 +516,49 @@ func getAtomUrl(nodeWithLink *xmlquery.Node, hasFeedburnerNamespace bool) (strin
 	return url, nil
 }
 
+// Full content is preferred over the description, which is often just an excerpt
+func getRssItemContent(itemNode *xmlquery.Node) string {
+	contentNode := xmlquery.FindOne(itemNode, "content:encoded")
+	if contentNode != nil && strings.TrimSpace(contentNode.InnerText()) != "" {
+		return contentNode.InnerText()
+	}
+	descriptionNode := xmlquery.FindOne(itemNode, "description")
+	if descriptionNode != nil {
+		return descriptionNode.InnerText()
+	}
+	return ""
+}
+
+func getAtomEntryContent(entryNode *xmlquery.Node) string {
+	contentNode := xmlquery.FindOne(entryNode, "content")
+	isContentPresent := contentNode != nil &&
+		contentNode.SelectAttr("src") == "" &&
+		strings.TrimSpace(contentNode.InnerText()) != ""
+	if !isContentPresent {
+		contentNode = xmlquery.FindOne(entryNode, "summary")
+	}
+	if contentNode == nil {
+		return ""
+	}
+	switch contentNode.SelectAttr("type") {
+	case "html":
+		return contentNode.InnerText()
+	case "xhtml":
+		// The wrapping div is not a part of the content
+		parentNode := contentNode
+		if divNode := xmlquery.FindOne(contentNode, "div"); divNode != nil {
+			parentNode = divNode
+		}
+		var sb strings.Builder
+		for child := parentNode.FirstChild; child != nil; child = child.NextSibling {
+			sb.WriteString(child.OutputXML(true))
+		}
+		return sb.String()
+	default:
+		return html.EscapeString(contentNode.InnerText())
+	}
+}
+
 func decodeHtmlTitle(title string) string {
 	title = html.UnescapeString(title)
 	title = strings.ReplaceAll(title, "<br>", "\n")
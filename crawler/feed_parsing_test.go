@@ -1082,3 +1082,108 @@ func TestParseFeedGenerator(t *testing.T) {
 		require.Equal(t, parsedFeed.Generator, tc.expectedGenerator)
 	}
 }
+
+func TestParseFeedEntryContent(t *testing.T) {
+	type Test struct {
+		description     string
+		content         string
+		expectedContent *string
+	}
+
+	paragraph := "<p>Hello</p>"
+	escapedText := "1 &lt; 2"
+	tests := []Test{
+		{
+			description: "prefer RSS content:encoded over description",
+			content: `
+				<rss xmlns:content="http://purl.org/rss/1.0/modules/content/">
+					<channel>
+						<item>
+							<link>https://blog/post</link>
+							<description>Excerpt</description>
+							<content:encoded><![CDATA[<p>Hello</p>]]></content:encoded>
+						</item>
+					</channel>
+				</rss>
+			`,
+			expectedContent: &paragraph,
+		},
+		{
+			description: "fall back to RSS description",
+			content: `
+				<rss>
+					<channel>
+						<item>
+							<link>https://blog/post</link>
+							<description>&lt;p&gt;Hello&lt;/p&gt;</description>
+						</item>
+					</channel>
+				</rss>
+			`,
+			expectedContent: &paragraph,
+		},
+		{
+			description: "handle RSS without content",
+			content: `
+				<rss>
+					<channel>
+						<item>
+							<link>https://blog/post</link>
+						</item>
+					</channel>
+				</rss>
+			`,
+			expectedContent: nil,
+		},
+		{
+			description: "parse Atom html content",
+			content: `
+				<feed xmlns="http://www.w3.org/2005/Atom">
+					<entry>
+						<link href="https://blog/post"/>
+						<summary>Excerpt</summary>
+						<content type="html">&lt;p&gt;Hello&lt;/p&gt;</content>
+					</entry>
+				</feed>
+			`,
+			expectedContent: &paragraph,
+		},
+		{
+			description: "parse Atom xhtml content",
+			content: `
+				<feed xmlns="http://www.w3.org/2005/Atom">
+					<entry>
+						<link href="https://blog/post"/>
+						<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hello</p></div></content>
+					</entry>
+				</feed>
+			`,
+			expectedContent: &paragraph,
+		},
+		{
+			description: "escape Atom text summary",
+			content: `
+				<feed xmlns="http://www.w3.org/2005/Atom">
+					<entry>
+						<link href="https://blog/post"/>
+						<summary>1 &lt; 2</summary>
+					</entry>
+				</feed>
+			`,
+			expectedContent: &escapedText,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			logger := NewDummyLogger()
+			uri, err := neturl.Parse("https://blog/feed")
+			oops.RequireNoError(t, err)
+			parsedFeed, err := ParseFeed(tc.content, uri, logger)
+			oops.RequireNoError(t, err)
+			entryLinks := parsedFeed.EntryLinks.ToSlice()
+			require.Len(t, entryLinks, 1)
+			require.Equal(t, tc.expectedContent, entryLinks[0].MaybeContent)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -26,11 +27,12 @@ type Feed struct {
 }
 
 type GuidedCrawlResult struct {
-	FeedResult       FeedResult
-	CuriEqCfg        *CanonicalEqualityConfig
-	HistoricalResult *HistoricalResult
-	HistoricalError  error
-	HardcodedError   error
+	FeedResult        FeedResult
+	CuriEqCfg         *CanonicalEqualityConfig
+	FeedEntryContents *CanonicalUriMap[string] // sanitized html
+	HistoricalResult  *HistoricalResult
+	HistoricalError   error
+	HardcodedError    error
 }
 
 type FeedResult struct {
@@ -53,6 +55,7 @@ type HistoricalResult struct {
 	BlogLink               Link
 	MainLink               Link
 	Pattern                string
+	Strategy               HistoricalStrategyName
 	Links                  []*titledLink
 	DiscardedFeedEntryUrls []string
 	PostCategories         []HistoricalBlogPostCategory
@@ -94,7 +97,8 @@ func areCategoriesEqual(
 }
 
 func GuidedCrawl(
-	maybeStartPage *DiscoveredStartPage, feed Feed, crawlCtx *CrawlContext, logger Logger,
+	maybeStartPage *DiscoveredStartPage, feed Feed, maybeArchivesUrl *string, maybeRecrawlHint *RecrawlHint,
+	crawlCtx *CrawlContext, logger Logger,
 ) (*GuidedCrawlResult, error) {
 	guidedCrawlResult := GuidedCrawlResult{} //nolint:exhaustruct
 	feedResult := &guidedCrawlResult.FeedResult
@@ -117,6 +121,18 @@ func GuidedCrawl(
 	}
 
 	crawlCtx.RobotsClient = NewRobotsClient(feedLink.Uri, crawlCtx.HttpClient, logger)
+	defer crawlCtx.PageMemory.release(logger)
+
+	var maybeArchivesLink *Link
+	if maybeArchivesUrl != nil {
+		archivesLink, ok := ToCanonicalLink(*maybeArchivesUrl, logger, nil)
+		if ok {
+			logger.Info("Provided archives url: %s", archivesLink.Url)
+			maybeArchivesLink = archivesLink
+		} else {
+			logger.Info("Couldn't parse provided archives url: %s", *maybeArchivesUrl)
+		}
+	}
 
 	var startPageLink *Link
 	var startPageFinalLink *Link
@@ -221,9 +237,14 @@ func GuidedCrawl(
 	guidedCrawlResult.CuriEqCfg = &curiEqCfg
 
 	feedEntryCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](&curiEqCfg)
+	feedEntryContents := NewCanonicalUriMap[string](&curiEqCfg)
 	for _, entryLink := range parsedFeed.EntryLinks.ToSlice() {
 		feedEntryCurisTitlesMap.Add(entryLink.Link, entryLink.MaybeTitle)
+		if entryLink.MaybeContent != nil {
+			feedEntryContents.Add(entryLink.Link, *entryLink.MaybeContent)
+		}
 	}
+	guidedCrawlResult.FeedEntryContents = &feedEntryContents
 	initialBlogLink := startPageFinalLink
 	if parsedFeed.RootLink != nil {
 		initialBlogLink = parsedFeed.RootLink
@@ -232,78 +253,41 @@ func GuidedCrawl(
 	var historicalResult *HistoricalResult
 	var historicalMaybeTitledLinks []*maybeTitledLink
 	var historicalError error
-	shouldUseFeed := (parsedFeed.EntryLinks.Length > 50 &&
-		parsedFeed.EntryLinks.Length%100 != 0 &&
-		!CanonicalUriEqual(feedLink.Curi, HardcodedDanLuuFeed, &curiEqCfg)) ||
-		CanonicalUriEqual(feedLink.Curi, hardcodedInkAndSwitchFeed, &curiEqCfg)
-	if !shouldUseFeed {
-		var postprocessedResult *postprocessedResult
-		if parsedFeed.Generator != FeedGeneratorTumblr {
-			postprocessedResult, historicalError = guidedCrawlHistorical(
-				startPage, parsedFeed, feedEntryCurisTitlesMap, initialBlogLink, crawlCtx, &curiEqCfg, logger,
-			)
-		} else {
-			postprocessedResult, historicalError = getTumblrApiHistorical(
-				parsedFeed.RootLink.Uri.Hostname(), crawlCtx, logger,
-			)
+	strategies := crawlCtx.HistoricalStrategies
+	historicalFeed := historicalFeed{
+		FeedLink:                feedLink,
+		ParsedFeed:              parsedFeed,
+		FeedEntryCurisTitlesMap: feedEntryCurisTitlesMap,
+		InitialBlogLink:         initialBlogLink,
+		MaybeRecrawlHint:        maybeRecrawlHint,
+		CuriEqCfg:               &curiEqCfg,
+		Hardcoded:               strategies.hardcoded(),
+	}
+	var feedHistorical *feedHistoricalResult
+	for _, strategy := range strategies.feedStrategies() {
+		feedHistorical, err = strategy.tryFeed(&historicalFeed, crawlCtx, logger)
+		if err != nil {
+			return nil, err
 		}
-
-		if postprocessedResult != nil {
-			var historicalCuris []CanonicalUri
-			for _, link := range postprocessedResult.Links {
-				historicalCuris = append(historicalCuris, link.Curi())
-			}
-			historicalCurisSet := NewCanonicalUriSet(historicalCuris, &curiEqCfg)
-
-			var discardedFeedEntryUrls []string
-			for _, entryLink := range parsedFeed.EntryLinks.ToSlice() {
-				if !historicalCurisSet.Contains(entryLink.Curi) {
-					discardedFeedEntryUrls = append(discardedFeedEntryUrls, entryLink.Url)
-				}
-			}
-
-			historicalMaybeTitledLinks = make([]*maybeTitledLink, len(postprocessedResult.Links))
-			for i, link := range postprocessedResult.Links {
-				historicalMaybeTitledLinks[i] = link.Unwrap()
-			}
-			postCategories := PristineHistoricalBlogPostCategoriesUnwrap(postprocessedResult.PostCategories)
-			historicalResult = &HistoricalResult{
-				BlogLink:               *postprocessedResult.MainLnk.Unwrap(),
-				MainLink:               *postprocessedResult.MainLnk.Unwrap(),
-				Pattern:                postprocessedResult.Pattern,
-				Links:                  nil,
-				DiscardedFeedEntryUrls: discardedFeedEntryUrls,
-				PostCategories:         postCategories,
-				Extra:                  postprocessedResult.Extra,
-			}
+		if feedHistorical != nil {
+			break
 		}
+	}
+	if feedHistorical != nil {
+		historicalResult = feedHistorical.MaybeResult
+		historicalMaybeTitledLinks = feedHistorical.Links
+		historicalError = feedHistorical.Error
 	} else {
-		logger.Info("Feed is long with %d entries", parsedFeed.EntryLinks.Length)
-
-		var postCategories []pristineHistoricalBlogPostCategory
-		var postCategoriesExtra []string
-		if CanonicalUriEqual(feedLink.Curi, hardcodedPaulGraham, &curiEqCfg) {
-			postCategories = hardcodedPaulGrahamCategories
-			postCategoriesStr := categoryCountsString(postCategories)
-			logger.Info("Categories: %s", postCategoriesStr)
-			appendLogLinef(&postCategoriesExtra, "categories: %s", postCategoriesStr)
-		}
-
-		historicalResult = &HistoricalResult{
-			BlogLink:               *initialBlogLink,
-			MainLink:               *feedLink,
-			Pattern:                "long_feed",
-			Links:                  nil,
-			DiscardedFeedEntryUrls: nil,
-			PostCategories:         PristineHistoricalBlogPostCategoriesUnwrap(postCategories),
-			Extra:                  postCategoriesExtra,
-		}
-
-		if CanonicalUriEqual(feedLink.Curi, hardcodedInkAndSwitchFeed, &curiEqCfg) {
-			historicalMaybeTitledLinks =
-				extractInkAndSwitch(parsedFeed.EntryLinks.ToMaybeTitledSlice(), logger)
-		} else {
-			historicalMaybeTitledLinks = parsedFeed.EntryLinks.ToMaybeTitledSlice()
+		var postprocessedResult *postprocessedResult
+		postprocessedResult, historicalError = guidedCrawlHistorical(
+			startPage, parsedFeed, feedEntryCurisTitlesMap, initialBlogLink, maybeArchivesLink,
+			crawlCtx, &curiEqCfg, logger,
+		)
+		if postprocessedResult != nil {
+			strategyName := historicalStrategyForResult(postprocessedResult, strategies, &curiEqCfg)
+			historicalResult, historicalMaybeTitledLinks = newPostprocessedHistoricalResult(
+				postprocessedResult, strategyName, parsedFeed, &curiEqCfg,
+			)
 		}
 	}
 
@@ -372,6 +356,40 @@ func GuidedCrawl(
 	return &guidedCrawlResult, nil
 }
 
+func newPostprocessedHistoricalResult(
+	postprocessedResult *postprocessedResult, strategyName HistoricalStrategyName, parsedFeed *ParsedFeed,
+	curiEqCfg *CanonicalEqualityConfig,
+) (*HistoricalResult, []*maybeTitledLink) {
+	var historicalCuris []CanonicalUri
+	for _, link := range postprocessedResult.Links {
+		historicalCuris = append(historicalCuris, link.Curi())
+	}
+	historicalCurisSet := NewCanonicalUriSet(historicalCuris, curiEqCfg)
+
+	var discardedFeedEntryUrls []string
+	for _, entryLink := range parsedFeed.EntryLinks.ToSlice() {
+		if !historicalCurisSet.Contains(entryLink.Curi) {
+			discardedFeedEntryUrls = append(discardedFeedEntryUrls, entryLink.Url)
+		}
+	}
+
+	historicalMaybeTitledLinks := make([]*maybeTitledLink, len(postprocessedResult.Links))
+	for i, link := range postprocessedResult.Links {
+		historicalMaybeTitledLinks[i] = link.Unwrap()
+	}
+	postCategories := PristineHistoricalBlogPostCategoriesUnwrap(postprocessedResult.PostCategories)
+	return &HistoricalResult{
+		BlogLink:               *postprocessedResult.MainLnk.Unwrap(),
+		MainLink:               *postprocessedResult.MainLnk.Unwrap(),
+		Pattern:                postprocessedResult.Pattern,
+		Strategy:               strategyName,
+		Links:                  nil,
+		DiscardedFeedEntryUrls: discardedFeedEntryUrls,
+		PostCategories:         postCategories,
+		Extra:                  postprocessedResult.Extra,
+	}, historicalMaybeTitledLinks
+}
+
 func getFeedStartPage(
 	feedLink *Link, parsedFeed *ParsedFeed, crawlCtx *CrawlContext, logger Logger,
 ) (*Link, *htmlPage, error) {
@@ -497,7 +515,8 @@ var ErrPatternNotDetected = errors.New("pattern not detected")
 
 func guidedCrawlHistorical(
 	startPage *htmlPage, parsedFeed *ParsedFeed, feedEntryCurisTitlesMap CanonicalUriMap[MaybeLinkTitle],
-	initialBlogLink *Link, crawlCtx *CrawlContext, curiEqCfg *CanonicalEqualityConfig, logger Logger,
+	initialBlogLink *Link, maybeArchivesLink *Link, crawlCtx *CrawlContext, curiEqCfg *CanonicalEqualityConfig,
+	logger Logger,
 ) (*postprocessedResult, error) {
 	progressLogger := crawlCtx.ProgressLogger
 
@@ -514,7 +533,8 @@ func guidedCrawlHistorical(
 	var startPageAllowedHostsLinks []*Link
 	for _, link := range startPageAllLinks {
 		if allowedHosts[link.Uri.Host] {
-			startPageAllowedHostsLinks = append(startPageAllowedHostsLinks, &link.Link)
+			startPageLink := link.Link // not pointing into xpathLink so that the element can be collected
+			startPageAllowedHostsLinks = append(startPageAllowedHostsLinks, &startPageLink)
 		}
 	}
 
@@ -562,30 +582,70 @@ func guidedCrawlHistorical(
 		}
 	}
 
+	var maybeProvidedArchivesCuri *CanonicalUri
+	if maybeArchivesLink != nil {
+		isSameCuri := func(lop linkOrHtmlPage) bool {
+			switch l := lop.(type) {
+			case *pristineLink:
+				return CanonicalUriEqual(l.Curi(), maybeArchivesLink.Curi, curiEqCfg)
+			case *htmlPage:
+				return CanonicalUriEqual(l.Curi, maybeArchivesLink.Curi, curiEqCfg)
+			default:
+				panic("Unknown link or page type")
+			}
+		}
+
+		switch {
+		case !allowedHosts[maybeArchivesLink.Uri.Host]:
+			logger.Info("Provided archives url is on another host, ignoring: %s", maybeArchivesLink.Url)
+		case !crawlCtx.RobotsClient.Test(maybeArchivesLink.Uri, logger):
+			logger.Info("Provided archives url is disallowed by robots.txt, ignoring: %s", maybeArchivesLink.Url)
+		default:
+			archivesQueue = slices.DeleteFunc(archivesQueue, isSameCuri)
+			mainPageQueue = slices.DeleteFunc(mainPageQueue, isSameCuri)
+			if CanonicalUriEqual(startPage.Curi, maybeArchivesLink.Curi, curiEqCfg) {
+				archivesQueue = slices.Insert(archivesQueue, 0, linkOrHtmlPage(startPage))
+			} else {
+				seenCurisSet.add(maybeArchivesLink.Curi)
+				archivesQueue = slices.Insert(archivesQueue, 0, linkOrHtmlPage(NewPristineLink(maybeArchivesLink)))
+			}
+			maybeProvidedArchivesCuri = &maybeArchivesLink.Curi
+			logger.Info("Prioritized provided archives: %s", maybeArchivesLink.Url)
+		}
+	}
+
 	logger.Info(
 		"Start page and links: %d archives, %d main page, %d others",
 		len(archivesQueue), len(mainPageQueue), len(startPageOtherLinks),
 	)
 
 	guidedCtx := guidedCrawlContext{
-		SeenCurisSet:            seenCurisSet,
-		ArchivesCategoriesState: &archivesCategoriesState,
-		FeedEntryLinks:          &parsedFeed.EntryLinks,
-		FeedEntryCurisTitlesMap: feedEntryCurisTitlesMap,
-		FeedGenerator:           parsedFeed.Generator,
-		FeedRootLinkCuri:        parsedFeed.RootLink.Curi,
-		CuriEqCfg:               curiEqCfg,
-		AllowedHosts:            allowedHosts,
-		HardcodedError:          nil,
-	}
-
+		SeenCurisSet:                seenCurisSet,
+		ArchivesCategoriesState:     &archivesCategoriesState,
+		FeedEntryLinks:              &parsedFeed.EntryLinks,
+		FeedEntryCurisTitlesMap:     feedEntryCurisTitlesMap,
+		FeedGenerator:               parsedFeed.Generator,
+		FeedRootLinkCuri:            parsedFeed.RootLink.Curi,
+		CuriEqCfg:                   curiEqCfg,
+		AllowedHosts:                allowedHosts,
+		Strategies:                  crawlCtx.HistoricalStrategies,
+		Hardcoded:                   crawlCtx.HistoricalStrategies.hardcoded(),
+		HardcodedError:              nil,
+		MaybeProvidedArchivesCuri:   maybeProvidedArchivesCuri,
+		MaybeProvidedArchivesResult: nil,
+	}
+
+	crawlCtx.PageMemory.park(startPage, logger)
 	result, err := guidedCrawlFetchLoop(
 		[]*guidedCrawlQueue{&archivesQueue, &mainPageQueue}, nil, 1, &guidedCtx, crawlCtx, logger,
 	)
 	if errors.Is(err, ErrCrawlCanceled) || errors.Is(err, ErrBlogTooLong) {
 		return nil, err
 	} else if err == nil {
-		if len(result.Links) >= 11 {
+		if result == guidedCtx.MaybeProvidedArchivesResult {
+			logger.Info("Phase 1 succeeded with provided archives")
+			return result, nil
+		} else if len(result.Links) >= 11 {
 			logger.Info("Phase 1 succeeded")
 			return result, nil
 		} else {
@@ -772,6 +832,20 @@ func guidedCrawlHistorical(
 		return result, nil
 	}
 
+	var maybeAllowedArchivesLink *Link
+	if maybeProvidedArchivesCuri != nil {
+		maybeAllowedArchivesLink = maybeArchivesLink
+	}
+	for _, strategy := range guidedCtx.Strategies.crawlStrategies() {
+		result, err = strategy.crawl(startPage, maybeAllowedArchivesLink, &guidedCtx, crawlCtx, logger)
+		if errors.Is(err, ErrCrawlCanceled) {
+			return nil, err
+		} else if err == nil {
+			logger.Info("Crawl strategy %s succeeded", strategy.Name())
+			return result, nil
+		}
+	}
+
 	return nil, ErrPatternNotDetected
 }
 
@@ -784,7 +858,12 @@ type guidedCrawlContext struct {
 	FeedRootLinkCuri        CanonicalUri
 	CuriEqCfg               *CanonicalEqualityConfig
 	AllowedHosts            map[string]bool
+	Strategies              *HistoricalStrategySelection
+	Hardcoded               *hardcodedStrategy
 	HardcodedError          error
+	// Provided by the user, its result is preferred if it matches the feed
+	MaybeProvidedArchivesCuri   *CanonicalUri
+	MaybeProvidedArchivesResult *postprocessedResult
 }
 
 type guidedSeenCurisSet struct {
@@ -820,6 +899,10 @@ type RobotsClient struct {
 	MaybeBackupGroup      *robotstxt.Group
 	FeedRewindBlockLogged bool
 	LastRequestTimestamp  time.Time
+	TotalThrottleDuration time.Duration
+
+	// Title fetching throttles from multiple goroutines
+	throttleMutex sync.Mutex
 }
 
 func NewRobotsClient(rootUri *url.URL, httpClient HttpClient, logger Logger) *RobotsClient {
@@ -853,6 +936,8 @@ func NewRobotsClient(rootUri *url.URL, httpClient HttpClient, logger Logger) *Ro
 		MaybeBackupGroup:      backupGroup,
 		LastRequestTimestamp:  time.Time{}, //nolint:exhaustruct
 		FeedRewindBlockLogged: false,
+		TotalThrottleDuration: 0,
+		throttleMutex:         sync.Mutex{},
 	}
 }
 
@@ -868,31 +953,44 @@ func (c *RobotsClient) Test(uri *url.URL, logger Logger) bool {
 	return result
 }
 
+func (c *RobotsClient) CrawlDelay(uri *url.URL) time.Duration {
+	crawlDelay := time.Second
+	if c.MaybeGroup != nil && c.MaybeGroup.CrawlDelay > time.Second {
+		crawlDelay = c.MaybeGroup.CrawlDelay
+	}
+	if uri.Host == HardcodedTheOldNewThingUri.Host &&
+		strings.HasPrefix(uri.Path, HardcodedTheOldNewThingUri.Path) {
+		// sorry Microsoft
+		crawlDelay = 500 * time.Millisecond
+	}
+	return crawlDelay
+}
+
+// Concurrent callers reserve consecutive slots, so requests still start at least a crawl delay apart
 func (c *RobotsClient) Throttle(ctx context.Context, uri *url.URL) error {
+	c.throttleMutex.Lock()
 	now := time.Now().UTC()
+	requestTimestamp := now
 	if !c.LastRequestTimestamp.IsZero() {
-		timeDelta := now.Sub(c.LastRequestTimestamp)
-		crawlDelay := time.Second
-		if c.MaybeGroup != nil && c.MaybeGroup.CrawlDelay > time.Second {
-			crawlDelay = c.MaybeGroup.CrawlDelay
-		}
-		if uri.Host == HardcodedTheOldNewThingUri.Host &&
-			strings.HasPrefix(uri.Path, HardcodedTheOldNewThingUri.Path) {
-			// sorry Microsoft
-			crawlDelay = 500 * time.Millisecond
-		}
-		if timeDelta < crawlDelay {
-			sleepDelay := crawlDelay - timeDelta
-			timer := time.NewTimer(sleepDelay)
-			select {
-			case <-ctx.Done():
-				return ErrCrawlCanceled
-			case <-timer.C:
-			}
-			now = time.Now().UTC()
+		earliestTimestamp := c.LastRequestTimestamp.Add(c.CrawlDelay(uri))
+		if earliestTimestamp.After(now) {
+			requestTimestamp = earliestTimestamp
+		}
+	}
+	c.LastRequestTimestamp = requestTimestamp
+	sleepDelay := requestTimestamp.Sub(now)
+	c.TotalThrottleDuration += sleepDelay
+	c.throttleMutex.Unlock()
+
+	if sleepDelay > 0 {
+		timer := time.NewTimer(sleepDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ErrCrawlCanceled
+		case <-timer.C:
 		}
 	}
-	c.LastRequestTimestamp = now
 	return nil
 }
 
@@ -942,6 +1040,7 @@ func guidedCrawlFetchLoop(
 		linkOrPage, *activeQueue = (*activeQueue)[0], (*activeQueue)[1:]
 		var link *pristineLink
 		var page *htmlPage
+		var maybeParkedPage *htmlPage
 		switch lop := linkOrPage.(type) {
 		case *pristineLink:
 			link = lop
@@ -963,6 +1062,11 @@ func guidedCrawlFetchLoop(
 			}
 		case *htmlPage:
 			page = lop
+			err := crawlCtx.PageMemory.unpark(page, logger)
+			if err != nil {
+				return nil, err
+			}
+			maybeParkedPage = page
 			rawLink, ok := ToCanonicalLink(page.FetchUri.String(), logger, nil)
 			if !ok {
 				panic("Couldn't parse page fetch uri as a link")
@@ -977,6 +1081,9 @@ func guidedCrawlFetchLoop(
 		)
 		if err != nil {
 			logger.Info("Couldn't crawl with Puppeteer: %v", err)
+			if maybeParkedPage != nil {
+				crawlCtx.PageMemory.park(maybeParkedPage, logger)
+			}
 			continue
 		}
 		if puppeteerPage != page {
@@ -1021,6 +1128,26 @@ func guidedCrawlFetchLoop(
 		pageResults := tryExtractHistorical(
 			link, puppeteerPage, pageAllLinks, &pageCurisSet, guidedCtx, logger,
 		)
+		if maybeParkedPage != nil {
+			crawlCtx.PageMemory.park(maybeParkedPage, logger)
+		}
+		if guidedCtx.MaybeProvidedArchivesCuri != nil &&
+			guidedCtx.MaybeProvidedArchivesResult == nil &&
+			CanonicalUriEqual(link.Curi(), *guidedCtx.MaybeProvidedArchivesCuri, guidedCtx.CuriEqCfg) &&
+			len(pageResults) > 0 {
+
+			ppResult, err := postprocessProvidedArchivesResults(pageResults, guidedCtx, crawlCtx, logger)
+			if errors.Is(err, ErrCrawlCanceled) || errors.Is(err, ErrBlogTooLong) {
+				return nil, err
+			} else if err == nil {
+				guidedCtx.MaybeProvidedArchivesResult = ppResult
+				logger.Info(
+					"Guided crawl loop finished (phase %d) with provided archives result of %d links",
+					phaseNumber, len(ppResult.Links),
+				)
+				return ppResult, nil
+			}
+		}
 	pgResults:
 		for _, pageResult := range pageResults {
 			historicalMatchesCount++
@@ -1029,6 +1156,7 @@ func guidedCrawlFetchLoop(
 					continue pgResults
 				}
 			}
+			crawlCtx.PageMemory.compactResult(pageResult)
 			insertSortedResult(&sortedResults, pageResult)
 		}
 
@@ -1098,33 +1226,54 @@ func tryExtractHistorical(
 		guidedCtx.CuriEqCfg, archivesAlmostMatchThreshold, logger,
 	)
 
-	archivesResults := tryExtractArchives(
-		fetchLink, page, pageLinks, pageCurisSet, extractionsByStarCount, archivesAlmostMatchThreshold,
-		guidedCtx, logger,
-	)
-	results = append(results, archivesResults...)
-	if len(archivesResults) == 0 {
-		if page.MaybeTopScreenshot != nil {
-			logger.Screenshot(page.FetchUri.String(), "top", page.MaybeTopScreenshot)
-		}
-		if page.MaybeBottomScreenshot != nil {
-			logger.Screenshot(page.FetchUri.String(), "bottom", page.MaybeBottomScreenshot)
-		}
+	historicalPage := historicalPage{
+		FetchLink:                    fetchLink,
+		Page:                         page,
+		PageLinks:                    pageLinks,
+		PageCurisSet:                 pageCurisSet,
+		ExtractionsByStarCount:       extractionsByStarCount,
+		ArchivesAlmostMatchThreshold: archivesAlmostMatchThreshold,
+		MaybeArchivesPass:            nil,
 	}
+	for _, strategy := range guidedCtx.Strategies.pageStrategies() {
+		results = append(results, strategy.tryExtract(&historicalPage, guidedCtx, logger)...)
+	}
+
+	return results
+}
 
-	if archivesCategoriesResult, ok := tryExtractArchivesCategories(
-		page, pageCurisSet, extractionsByStarCount, guidedCtx, logger,
-	); ok {
-		results = append(results, archivesCategoriesResult)
+func postprocessProvidedArchivesResults(
+	pageResults []crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+	logger Logger,
+) (*postprocessedResult, error) {
+	logger.Info("Postprocess provided archives start")
+	var sortedResults []crawlHistoricalResult
+	for _, pageResult := range pageResults {
+		insertSortedResult(&sortedResults, pageResult)
+	}
+	ppResult, err := postprocessResults(&sortedResults, guidedCtx, crawlCtx, logger)
+	if errors.Is(err, ErrCrawlCanceled) || errors.Is(err, ErrBlogTooLong) {
+		return nil, err
+	} else if err != nil {
+		logger.Info("Postprocess provided archives failed")
+		return nil, err
 	}
 
-	if page1Result, ok := tryExtractPage1(
-		fetchLink, page, pageLinks, pageCurisSet, extractionsByStarCount, guidedCtx, logger,
-	); ok {
-		results = append(results, page1Result)
+	if !ppResult.IsMatchingFeed {
+		logger.Info("Postprocess provided archives failed: not matching feed")
+		return nil, errPostprocessingFailed
+	}
+	if len(ppResult.Links) < guidedCtx.FeedEntryLinks.Length {
+		logger.Info(
+			"Postprocess provided archives failed: %d links is less than %d in feed",
+			len(ppResult.Links), guidedCtx.FeedEntryLinks.Length,
+		)
+		return nil, errPostprocessingFailed
 	}
 
-	return results
+	appendLogLinef(&ppResult.Extra, "archives: provided")
+	logger.Info("Postprocess provided archives finish")
+	return ppResult, nil
 }
 
 func insertSortedResult(sortedResults *[]crawlHistoricalResult, newResult crawlHistoricalResult) {
@@ -1184,33 +1333,7 @@ func postprocessResults(
 		result, *sortedResults = (*sortedResults)[0], (*sortedResults)[1:]
 		logger.Info("Postprocessing %v", printResult(result))
 
-		var ppResult *postprocessedResult
-		var ppErr error
-		switch res := result.(type) {
-		case *postprocessedResult:
-			if res.MaybePartialPagedResult == nil {
-				ppResult, ppErr = res, nil
-			} else {
-				ppResult, ppErr = postprocessPartialPagedResult(
-					res.MaybePartialPagedResult, guidedCtx, crawlCtx, logger,
-				)
-			}
-		case *archivesSortedResult:
-			ppResult, ppErr = postprocessArchivesSortedResult(res, guidedCtx, crawlCtx, logger)
-		case *archivesShuffledResults:
-			ppResult, ppErr = postprocessArchivesShuffledResults(res, guidedCtx, crawlCtx, logger)
-		case *archivesMediumPinnedEntryResult:
-			ppResult, ppErr = postprocessArchivesMediumPinnedEntryResult(res, guidedCtx, crawlCtx, logger)
-		case *archivesLongFeedResult:
-			ppResult, ppErr = postprocessArchivesLongFeedResult(res), nil
-		case *ArchivesCategoriesResult:
-			ppResult, ppErr = postprocessArchviesCategoriesResult(res, guidedCtx, crawlCtx, logger)
-		case *page1Result:
-			// If page 1 result looks the best, check just the page 2 in case it was a scam
-			ppResult, ppErr = postprocessPage1Result(res, guidedCtx, crawlCtx, logger)
-		default:
-			panic("Unknown result type")
-		}
+		ppResult, ppErr := guidedCtx.Strategies.postprocess(result, guidedCtx, crawlCtx, logger)
 
 		if errors.Is(ppErr, ErrCrawlCanceled) || errors.Is(ppErr, ErrBlogTooLong) {
 			return nil, ppErr
@@ -1226,9 +1349,7 @@ func postprocessResults(
 
 			if ppResult.MaybePartialPagedResult != nil {
 				var err error
-				ppResult, err = postprocessPartialPagedResult(
-					ppResult.MaybePartialPagedResult, guidedCtx, crawlCtx, logger,
-				)
+				ppResult, err = guidedCtx.Strategies.postprocess(ppResult, guidedCtx, crawlCtx, logger)
 				if errors.Is(err, ErrCrawlCanceled) || errors.Is(err, ErrBlogTooLong) {
 					return nil, err
 				} else if err != nil {
@@ -1258,67 +1379,7 @@ func printResult(result crawlHistoricalResult) string {
 	return fmt.Sprintf("[%s, %s, %d]", name, result.mainLink().Url, result.speculativeCount())
 }
 
-func postprocessArchivesSortedResult(
-	archivesSortedResult *archivesSortedResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
-	logger Logger,
-) (*postprocessedResult, error) {
-	progressLogger := crawlCtx.ProgressLogger
-	if CanonicalUriEqual(archivesSortedResult.MainLnk.Curi(), hardcodedBenKuhnArchives, guidedCtx.CuriEqCfg) {
-		logger.Info("Postprocess archives sorted result start")
-		logger.Info("Extra request for Ben Kuhn categories")
-		titleCount := countLinkTitles(archivesSortedResult.Links)
-		err := progressLogger.LogAndSaveFetchedCount(&titleCount)
-		if err != nil {
-			return nil, err
-		}
-		page, err := crawlHtmlPage(hardcodedBenKuhn, crawlCtx, logger)
-		err2 := progressLogger.LogAndSavePostprocessing()
-		if err2 != nil {
-			return nil, err2
-		}
-		if err != nil {
-			logger.Info("Ben Kuhn categories fetch error: %v", err)
-			guidedCtx.HardcodedError = oops.Wrapf(err, "Ben Kuhn categories fetch error")
-		} else {
-			postCategories, err := extractBenKuhnCategories(page, logger)
-			if err != nil {
-				logger.Warn("Ben Kuhn categories extract error: %v", err)
-				guidedCtx.HardcodedError = err
-			} else {
-				logger.Info("Categories: %s", categoryCountsString(postCategories))
-				logger.Info("Postprocess archives sorted result finish")
-				return &postprocessedResult{
-					MainLnk:                 archivesSortedResult.MainLnk,
-					Pattern:                 archivesSortedResult.Pattern,
-					Links:                   archivesSortedResult.Links,
-					IsMatchingFeed:          true,
-					PostCategories:          postCategories,
-					Extra:                   archivesSortedResult.Extra,
-					MaybePartialPagedResult: nil,
-				}, nil
-			}
-		}
-	} else if CanonicalUriEqual(
-		archivesSortedResult.MainLnk.Curi(), hardcodedTransformerCircuits, guidedCtx.CuriEqCfg,
-	) {
-		logger.Info("Removing an extra Transformer Circuits link")
-		filteredLinks := make([]*pristineMaybeTitledLink, 0, len(archivesSortedResult.Links))
-		for _, link := range archivesSortedResult.Links {
-			if !hardcodedTransformerCircuitsEntriesToExclude.Contains(link.Curi()) {
-				filteredLinks = append(filteredLinks, link)
-			}
-		}
-		return &postprocessedResult{
-			MainLnk:                 archivesSortedResult.MainLnk,
-			Pattern:                 archivesSortedResult.Pattern,
-			Links:                   filteredLinks,
-			IsMatchingFeed:          true,
-			PostCategories:          archivesSortedResult.PostCategories,
-			Extra:                   archivesSortedResult.Extra,
-			MaybePartialPagedResult: nil,
-		}, nil
-	}
-
+func postprocessArchivesSortedResult(archivesSortedResult *archivesSortedResult) *postprocessedResult {
 	return &postprocessedResult{
 		MainLnk:                 archivesSortedResult.MainLnk,
 		Pattern:                 archivesSortedResult.Pattern,
@@ -1327,7 +1388,7 @@ func postprocessArchivesSortedResult(
 		PostCategories:          archivesSortedResult.PostCategories,
 		Extra:                   archivesSortedResult.Extra,
 		MaybePartialPagedResult: nil,
-	}, nil
+	}
 }
 
 func postprocessArchivesShuffledResults(
@@ -1580,7 +1641,7 @@ func postprocessPartialPagedResult(
 
 	var postCategories []pristineHistoricalBlogPostCategory
 	var links []*pristineMaybeTitledLink
-	if CanonicalUriEqual(fullResult.MainLnk.Curi(), hardcodedCaseyHandmer, guidedCtx.CuriEqCfg) {
+	if guidedCtx.Hardcoded.isBlog(fullResult.MainLnk.Curi(), hardcodedCaseyHandmer, guidedCtx.CuriEqCfg) {
 		var err error
 		postCategories, err = crawlCaseyHandmerCategories(fullResult, guidedCtx, crawlCtx, logger)
 		if err != nil {
@@ -1589,7 +1650,9 @@ func postprocessPartialPagedResult(
 		} else {
 			logger.Info("Categories: %s", categoryCountsString(postCategories))
 		}
-	} else if CanonicalUriEqual(guidedCtx.FeedRootLinkCuri, hardcodedTheOldNewThing, guidedCtx.CuriEqCfg) {
+	} else if guidedCtx.Hardcoded.isBlog(
+		guidedCtx.FeedRootLinkCuri, hardcodedTheOldNewThing, guidedCtx.CuriEqCfg,
+	) {
 		var err error
 		postCategories, links, err =
 			crawlTheOldNewThingCategories(fullResult, guidedCtx, crawlCtx, logger)
@@ -1917,37 +1980,47 @@ func fetchMissingTitles(
 		return nil, err
 	}
 	requestsMadeStart := crawlCtx.RequestsMade
+	throttleDurationStart := crawlCtx.RobotsClient.TotalThrottleDuration
+
+	var fetchLinkIdxs []int
+	for linkIdx, link := range linksWithFeedTitles {
+		if link.MaybeTitle == nil {
+			fetchLinkIdxs = append(fetchLinkIdxs, linkIdx)
+		}
+	}
+	maybePageTitles := make([]*string, len(linksWithFeedTitles))
+	fetchedTitlesCount := 0
+	var totalFetchDuration time.Duration
+	err = fetchTitlesConcurrently(
+		linksWithFeedTitles, fetchLinkIdxs, feedGenerator, crawlCtx, logger,
+		func(result *titleFetchResult) error {
+			maybePageTitles[result.LinkIdx] = result.MaybeTitle
+			totalFetchDuration += result.Duration
+			fetchedTitlesCount++
+			return progressLogger.LogAndSavePostprocessingCounts(
+				feedPresentTitlesCount+fetchedTitlesCount, feedMissingTitlesCount-fetchedTitlesCount,
+			)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
 
 	titledLinks := make([]*titledLink, len(linksWithFeedTitles))
 	var pageTitleLinks []*Link
 	var pageTitles []string
-	fetchedTitlesCount := 0
 	for linkIdx, link := range linksWithFeedTitles {
 		var title LinkTitle
-		if link.MaybeTitle != nil {
+		switch {
+		case link.MaybeTitle != nil:
 			title = *link.MaybeTitle
-		} else {
-			// Always making a request may produce some duplicate requests, but hopefully not too many
-			page, err := crawlHtmlPage(&link.Link, crawlCtx, logger)
-			if errors.Is(err, ErrCrawlCanceled) {
-				return nil, err
-			} else if err != nil {
-				logger.Info("Couldn't fetch link title, going with url: %s (%v)", link.Url, err)
-				title = NewLinkTitle(link.Url, LinkTitleSourceUrl, nil)
-			} else {
-				pageTitle := strings.Clone(getPageTitle(page, feedGenerator, logger))
-				pageTitles = append(pageTitles, pageTitle)
-				pageTitleLinks = append(pageTitleLinks, &link.Link)
-				title = NewLinkTitle(pageTitle, LinkTitleSourcePageTitle, nil)
-			}
-
-			fetchedTitlesCount++
-			err = progressLogger.LogAndSavePostprocessingCounts(
-				feedPresentTitlesCount+fetchedTitlesCount, feedMissingTitlesCount-fetchedTitlesCount,
-			)
-			if err != nil {
-				return nil, err
-			}
+		case maybePageTitles[linkIdx] != nil:
+			pageTitle := *maybePageTitles[linkIdx]
+			pageTitles = append(pageTitles, pageTitle)
+			pageTitleLinks = append(pageTitleLinks, &link.Link)
+			title = NewLinkTitle(pageTitle, LinkTitleSourcePageTitle, nil)
+		default:
+			title = NewLinkTitle(link.Url, LinkTitleSourceUrl, nil)
 		}
 
 		titledLinks[linkIdx] = &titledLink{
@@ -1960,6 +2033,18 @@ func fetchMissingTitles(
 	finishTime := time.Now()
 	crawlCtx.TitleFetchDuration = finishTime.Sub(startTime).Seconds()
 
+	// One at a time, every request would take its own time but no less than the crawl delay
+	throttleDuration := crawlCtx.RobotsClient.TotalThrottleDuration - throttleDurationStart
+	sequentialDuration := max(
+		totalFetchDuration-throttleDuration,
+		time.Duration(crawlCtx.TitleRequestsMade)*crawlCtx.RobotsClient.CrawlDelay(linksWithFeedTitles[0].Uri),
+	)
+	crawlCtx.TitleFetchTimeSaved = max(sequentialDuration.Seconds()-crawlCtx.TitleFetchDuration, 0)
+	logger.Info(
+		"Fetched %d titles in %.1fs, saved %.1fs over sequential fetching",
+		len(fetchLinkIdxs), crawlCtx.TitleFetchDuration, crawlCtx.TitleFetchTimeSaved,
+	)
+
 	if len(pageTitles) == 0 {
 		logger.Info("Page titles are empty, skipped the prefix/suffix discovery")
 		logger.Info("Fetch missing titles finish")
@@ -2180,6 +2265,8 @@ func ExtractSubstackPublicAndTotalCounts(
 		FeedRootLinkCuri:        parsedFeed.RootLink.Curi,
 		CuriEqCfg:               curiEqCfg,
 		AllowedHosts:            nil,
+		Strategies:              crawlCtx.HistoricalStrategies,
+		Hardcoded:               crawlCtx.HistoricalStrategies.hardcoded(),
 		HardcodedError:          nil,
 	}
 
@@ -2227,10 +2314,16 @@ func ExtractSubstackPublicAndTotalCounts(
 		pageAllLinks, guidedCtx.FeedGenerator, &filteredFeedEntryLinks, &feedEntryCurisTitlesMap, curiEqCfg,
 		archivesAlmostMatchThreshold, logger,
 	)
-	historicalResults := tryExtractArchives(
-		pristineArchivesLink, archivesHtmlPage, pageAllLinks, &pageCurisSet, extractionsByStarCount,
-		archivesAlmostMatchThreshold, &guidedCtx, logger,
-	)
+	archivesPage := historicalPage{
+		FetchLink:                    pristineArchivesLink,
+		Page:                         archivesHtmlPage,
+		PageLinks:                    pageAllLinks,
+		PageCurisSet:                 &pageCurisSet,
+		ExtractionsByStarCount:       extractionsByStarCount,
+		ArchivesAlmostMatchThreshold: archivesAlmostMatchThreshold,
+		MaybeArchivesPass:            nil,
+	}
+	historicalResults := tryExtractArchives(&archivesPage, &guidedCtx, logger)
 	if len(historicalResults) != 1 {
 		return 0, 0, oops.Newf("Expected 1 historical result, got %d", len(historicalResults))
 	}
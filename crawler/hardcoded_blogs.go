@@ -142,6 +142,221 @@ func hardcodedMustParse(url string) CanonicalUri {
 	return CanonicalUriFromUri(uri)
 }
 
+// The branches for the blogs that have their archives extracted by hand or their results fixed up.
+// Branches inside the other strategies ask it through isBlog, which matches nothing when it's disabled.
+type hardcodedStrategy struct{}
+
+func (*hardcodedStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyHardcoded
+}
+
+func (*hardcodedStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostFewPages
+}
+
+// Reuses the patterns of the strategies it's layered on, told apart by historicalStrategyForResult
+func (*hardcodedStrategy) patternPrefixes() []string {
+	return nil
+}
+
+func (s *hardcodedStrategy) isBlog(
+	curi CanonicalUri, hardcodedCuri CanonicalUri, curiEqCfg *CanonicalEqualityConfig,
+) bool {
+	return s != nil && CanonicalUriEqual(curi, hardcodedCuri, curiEqCfg)
+}
+
+func (s *hardcodedStrategy) isBlogHost(host string, hardcodedCuri CanonicalUri) bool {
+	return s != nil && host == hardcodedCuri.Host
+}
+
+// The blogs that have branches in the historical strategies
+func (s *hardcodedStrategy) isHistoricalBlog(mainCuri CanonicalUri, curiEqCfg *CanonicalEqualityConfig) bool {
+	if s.isBlogHost(mainCuri.Host, hardcodedAntirez) {
+		return true
+	}
+	for _, curi := range []CanonicalUri{
+		hardcodedACOUP, hardcodedBenKuhnArchives, hardcodedCaseyHandmer, hardcodedCryptographyEngineering,
+		hardcodedDanLuu, hardcodedFactorio, hardcodedGwern, hardcodedJuliaEvans, hardcodedKalzumeus,
+		hardcodedMrMoneyMustache, hardcodedTheOldNewThing, hardcodedTransformerCircuits,
+	} {
+		if s.isBlog(mainCuri, curi, curiEqCfg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *hardcodedStrategy) archivesSteps() map[archivesStep]archivesStepFunc {
+	return map[archivesStep]archivesStepFunc{
+		archivesStepHardcoded: s.extractArchives,
+	}
+}
+
+func (*hardcodedStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	return page.archivesPass(guidedCtx, logger).HardcodedResults
+}
+
+func (s *hardcodedStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	mainLink := result.mainLink()
+	mainCuri := mainLink.Curi()
+	curiEqCfg := guidedCtx.CuriEqCfg
+	switch r := result.(type) {
+	case *archivesSortedResult:
+		switch {
+		case s.isBlog(mainCuri, hardcodedBenKuhnArchives, curiEqCfg):
+			ppResult, err := postprocessBenKuhnArchives(r, guidedCtx, crawlCtx, logger)
+			return ppResult, true, err
+		case s.isBlog(mainCuri, hardcodedTransformerCircuits, curiEqCfg):
+			return postprocessTransformerCircuitsArchives(r, logger), true, nil
+		case s.isBlog(mainCuri, hardcodedDanLuu, curiEqCfg):
+			return postprocessArchivesSortedResult(r), true, nil
+		}
+	case *archivesShuffledResults:
+		if s.isBlog(mainCuri, hardcodedGwern, curiEqCfg) || s.isBlog(mainCuri, hardcodedJuliaEvans, curiEqCfg) {
+			ppResult, err := postprocessArchivesShuffledResults(r, guidedCtx, crawlCtx, logger)
+			return ppResult, true, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (s *hardcodedStrategy) extractArchives(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
+	fetchLink := pass.Page.FetchLink
+	curiEqCfg := guidedCtx.CuriEqCfg
+	switch {
+	case s.isBlog(fetchLink.Curi(), hardcodedCryptographyEngineeringAll, curiEqCfg):
+		logger.Info("Skipping archives for Cryptography Engineering to pick up categories from paged")
+		pass.IsDone = true
+	case s.isBlog(fetchLink.Curi(), hardcodedDanLuu, curiEqCfg):
+		logger.Info("Extracting archives for Dan Luu")
+		links := pass.Page.ExtractionsByStarCount[0].Extractions[0].LinksExtraction.Links
+		firstIdx := 0
+		for !(links[firstIdx].Curi.Host == "danluu.com" && links[firstIdx].Curi.TrimmedPath != "") {
+			firstIdx++
+		}
+		lastIdx := 0
+		for !(links[lastIdx].Curi.Host == "danluu.com" &&
+			links[lastIdx].Curi.TrimmedPath == "/why-hardware-development-is-hard") {
+			lastIdx++
+		}
+		postLinks := links[firstIdx : lastIdx+1]
+		pass.HardcodedResults = []crawlHistoricalResult{
+			&archivesSortedResult{
+				MainLnk:        *fetchLink,
+				Pattern:        "archives",
+				Links:          NewPristineMaybeTitledLinks(dropHtml(postLinks)),
+				HasDates:       false,
+				PostCategories: nil,
+				Extra:          nil,
+			},
+		}
+		pass.IsDone = true
+	case s.isBlog(fetchLink.Curi(), hardcodedGwern, curiEqCfg):
+		logger.Info("Extracting archives for Gwern")
+		pass.IsDone = true
+		result, err := extractGwern(fetchLink, pass.Page.Page, curiEqCfg, logger)
+		if err != nil {
+			logger.Error("Couldn't extract gwern archives: %v", err)
+			return
+		}
+		pass.HardcodedResults = []crawlHistoricalResult{
+			&archivesShuffledResults{
+				MainLnk:        *fetchLink,
+				Results:        []*archivesShuffledResult{result},
+				SpeculativeCnt: result.SpeculativeCount(),
+			},
+		}
+	case s.isBlog(fetchLink.Curi(), hardcodedJuliaEvans, curiEqCfg):
+		logger.Info("Extracting archives for Julia Evans")
+		starCountExtractions := pass.Page.ExtractionsByStarCount[1] // 2 stars
+		shuffledResult, ok := tryExtractShuffled(
+			pass.Page.Page, starCountExtractions, -1, pass.MinLinksCount, fetchLink, guidedCtx, logger,
+		)
+		if !ok {
+			logger.Error("Couldn't extract archives for Julia Evans")
+			return
+		}
+		pass.HardcodedResults = []crawlHistoricalResult{
+			&archivesShuffledResults{
+				MainLnk:        *fetchLink,
+				Results:        []*archivesShuffledResult{shuffledResult},
+				SpeculativeCnt: shuffledResult.SpeculativeCount(),
+			},
+		}
+		pass.IsDone = true
+	}
+}
+
+func postprocessBenKuhnArchives(
+	archivesSortedResult *archivesSortedResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+	logger Logger,
+) (*postprocessedResult, error) {
+	progressLogger := crawlCtx.ProgressLogger
+	logger.Info("Postprocess archives sorted result start")
+	logger.Info("Extra request for Ben Kuhn categories")
+	titleCount := countLinkTitles(archivesSortedResult.Links)
+	err := progressLogger.LogAndSaveFetchedCount(&titleCount)
+	if err != nil {
+		return nil, err
+	}
+	page, err := crawlHtmlPage(hardcodedBenKuhn, crawlCtx, logger)
+	err2 := progressLogger.LogAndSavePostprocessing()
+	if err2 != nil {
+		return nil, err2
+	}
+	if err != nil {
+		logger.Info("Ben Kuhn categories fetch error: %v", err)
+		guidedCtx.HardcodedError = oops.Wrapf(err, "Ben Kuhn categories fetch error")
+		return postprocessArchivesSortedResult(archivesSortedResult), nil
+	}
+
+	postCategories, err := extractBenKuhnCategories(page, logger)
+	if err != nil {
+		logger.Warn("Ben Kuhn categories extract error: %v", err)
+		guidedCtx.HardcodedError = err
+		return postprocessArchivesSortedResult(archivesSortedResult), nil
+	}
+
+	logger.Info("Categories: %s", categoryCountsString(postCategories))
+	logger.Info("Postprocess archives sorted result finish")
+	return &postprocessedResult{
+		MainLnk:                 archivesSortedResult.MainLnk,
+		Pattern:                 archivesSortedResult.Pattern,
+		Links:                   archivesSortedResult.Links,
+		IsMatchingFeed:          true,
+		PostCategories:          postCategories,
+		Extra:                   archivesSortedResult.Extra,
+		MaybePartialPagedResult: nil,
+	}, nil
+}
+
+func postprocessTransformerCircuitsArchives(
+	archivesSortedResult *archivesSortedResult, logger Logger,
+) *postprocessedResult {
+	logger.Info("Removing an extra Transformer Circuits link")
+	filteredLinks := make([]*pristineMaybeTitledLink, 0, len(archivesSortedResult.Links))
+	for _, link := range archivesSortedResult.Links {
+		if !hardcodedTransformerCircuitsEntriesToExclude.Contains(link.Curi()) {
+			filteredLinks = append(filteredLinks, link)
+		}
+	}
+	return &postprocessedResult{
+		MainLnk:                 archivesSortedResult.MainLnk,
+		Pattern:                 archivesSortedResult.Pattern,
+		Links:                   filteredLinks,
+		IsMatchingFeed:          true,
+		PostCategories:          archivesSortedResult.PostCategories,
+		Extra:                   archivesSortedResult.Extra,
+		MaybePartialPagedResult: nil,
+	}
+}
+
 var hardcodedCryptographyEngineeringCategories []pristineHistoricalBlogPostCategory
 var hardcodedKalzumeusCategories []pristineHistoricalBlogPostCategory
 var hardcodedMrMoneyMustacheCategories []pristineHistoricalBlogPostCategory
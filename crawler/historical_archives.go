@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -35,6 +36,9 @@ func (r *archivesSortedResult) isSame(other crawlHistoricalResult, curiEqCfg *Ca
 		areCategoriesEqual(r.PostCategories, aOther.PostCategories, curiEqCfg)
 }
 
+// Medium pinned entry results share a pattern with shuffled ones, this line in extra tells them apart
+const mediumPinnedEntryExtraPrefix = "pinned_link_xpath: "
+
 type archivesMediumPinnedEntryResult struct {
 	MainLnk         pristineLink
 	Pattern         string
@@ -192,227 +196,408 @@ func getArchivesAlmostMatchThreshold(feedLength int) int {
 	}
 }
 
-func tryExtractArchives(
-	fetchLink *pristineLink, page *htmlPage, pageLinks []*xpathLink, pageCurisSet *CanonicalUriSet,
-	extractionsByStarCount []starCountExtractions, almostMatchThreshold int,
-	guidedCtx *guidedCrawlContext, logger Logger,
-) []crawlHistoricalResult {
-	if guidedCtx.FeedEntryLinks.countIncluded(pageCurisSet) < almostMatchThreshold &&
-		!CanonicalUriEqual(fetchLink.Curi(), hardcodedDanLuu, guidedCtx.CuriEqCfg) {
-		return nil
-	}
+// Archives strategies share one pass over the page, where every match raises the min links count for the
+// steps after it. The steps run in this order, each by the strategy that owns it.
+type archivesStep int
+
+const (
+	archivesStepHardcoded archivesStep = iota
+	archivesStepSorted
+	archivesStepSortedHighlight
+	archivesStepMediumPinned
+	archivesStepSorted2XPaths
+	archivesStepAlmostFeed
+	archivesStepShuffled
+	archivesStepSortedAlmost
+	archivesStepShuffledAlmost
+	archivesStepLongFeed
+	archivesStepCount
+)
 
-	if CanonicalUriEqual(fetchLink.Curi(), hardcodedCryptographyEngineeringAll, guidedCtx.CuriEqCfg) {
-		logger.Info("Skipping archives for Cryptography Engineering to pick up categories from paged")
-		return nil
+type archivesStepFunc func(pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger)
+
+type archivesPageStrategy interface {
+	historicalPageStrategy
+	archivesSteps() map[archivesStep]archivesStepFunc
+}
+
+type archivesPass struct {
+	Page                         *historicalPage
+	MinLinksCount                int
+	MainResult                   crawlHistoricalResult
+	TentativeBetterResults       []*archivesShuffledResult
+	MaybeMediumPinnedEntryResult *archivesMediumPinnedEntryResult
+	HardcodedResults             []crawlHistoricalResult
+	// Set by the steps that leave nothing for the steps after them
+	IsDone bool
+}
+
+func (p *historicalPage) archivesPass(guidedCtx *guidedCrawlContext, logger Logger) *archivesPass {
+	if p.MaybeArchivesPass == nil {
+		p.MaybeArchivesPass = runArchivesPass(p, guidedCtx, logger)
 	}
+	return p.MaybeArchivesPass
+}
 
-	logger.Info("Possible archives page: %s", page.Curi)
-
-	var mainResult crawlHistoricalResult
-	minLinksCount := 1
-
-	if CanonicalUriEqual(fetchLink.Curi(), hardcodedDanLuu, guidedCtx.CuriEqCfg) {
-		logger.Info("Extracting archives for Dan Luu")
-		links := extractionsByStarCount[0].Extractions[0].LinksExtraction.Links
-		firstIdx := 0
-		for !(links[firstIdx].Curi.Host == "danluu.com" && links[firstIdx].Curi.TrimmedPath != "") {
-			firstIdx++
-		}
-		lastIdx := 0
-		for !(links[lastIdx].Curi.Host == "danluu.com" &&
-			links[lastIdx].Curi.TrimmedPath == "/why-hardware-development-is-hard") {
-			lastIdx++
-		}
-		postLinks := links[firstIdx : lastIdx+1]
-		return []crawlHistoricalResult{
-			&archivesSortedResult{
-				MainLnk:        *fetchLink,
-				Pattern:        "archives",
-				Links:          NewPristineMaybeTitledLinks(dropHtml(postLinks)),
-				HasDates:       false,
-				PostCategories: nil,
-				Extra:          nil,
-			},
+func runArchivesPass(page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger) *archivesPass {
+	pass := &archivesPass{
+		Page:                         page,
+		MinLinksCount:                1,
+		MainResult:                   nil,
+		TentativeBetterResults:       nil,
+		MaybeMediumPinnedEntryResult: nil,
+		HardcodedResults:             nil,
+		IsDone:                       false,
+	}
+	isDanLuu := guidedCtx.Hardcoded.isBlog(page.FetchLink.Curi(), hardcodedDanLuu, guidedCtx.CuriEqCfg)
+	if guidedCtx.FeedEntryLinks.countIncluded(page.PageCurisSet) >= page.ArchivesAlmostMatchThreshold ||
+		isDanLuu {
+
+		logger.Info("Possible archives page: %s", page.Page.Curi)
+		stepFuncs := make(map[archivesStep]archivesStepFunc)
+		for _, strategy := range guidedCtx.Strategies.pageStrategies() {
+			if archivesStrategy, ok := strategy.(archivesPageStrategy); ok {
+				maps.Copy(stepFuncs, archivesStrategy.archivesSteps())
+			}
+		}
+		for step := archivesStep(0); step < archivesStepCount && !pass.IsDone; step++ {
+			if stepFunc, ok := stepFuncs[step]; ok {
+				stepFunc(pass, guidedCtx, logger)
+			}
 		}
 	}
 
-	if CanonicalUriEqual(fetchLink.Curi(), hardcodedGwern, guidedCtx.CuriEqCfg) {
-		logger.Info("Extracting archives for Gwern")
-		result, err := extractGwern(fetchLink, page, guidedCtx.CuriEqCfg, logger)
-		if err != nil {
-			logger.Error("Couldn't extract gwern archives: %v", err)
-			return nil
-		}
-		return []crawlHistoricalResult{
-			&archivesShuffledResults{
-				MainLnk:        *fetchLink,
-				Results:        []*archivesShuffledResult{result},
-				SpeculativeCnt: result.SpeculativeCount(),
-			},
+	if pass.MainResult == nil && len(pass.TentativeBetterResults) == 0 &&
+		pass.MaybeMediumPinnedEntryResult == nil && len(pass.HardcodedResults) == 0 {
+
+		if page.Page.MaybeTopScreenshot != nil {
+			logger.Screenshot(page.Page.FetchUri.String(), "top", page.Page.MaybeTopScreenshot)
+		}
+		if page.Page.MaybeBottomScreenshot != nil {
+			logger.Screenshot(page.Page.FetchUri.String(), "bottom", page.Page.MaybeBottomScreenshot)
 		}
 	}
+	return pass
+}
 
-	if CanonicalUriEqual(fetchLink.Curi(), hardcodedJuliaEvans, guidedCtx.CuriEqCfg) {
-		logger.Info("Extracting archives for Julia Evans")
-		starCountExtractions := extractionsByStarCount[1] // 2 stars
-		if shuffledResult, ok := tryExtractShuffled(
-			page, starCountExtractions, -1, minLinksCount, fetchLink, guidedCtx, logger,
-		); ok {
-			return []crawlHistoricalResult{
-				&archivesShuffledResults{
-					MainLnk:        *fetchLink,
-					Results:        []*archivesShuffledResult{shuffledResult},
-					SpeculativeCnt: shuffledResult.SpeculativeCount(),
-				},
-			}
-		} else {
-			logger.Error("Couldn't extract archives for Julia Evans")
+// Only the archives strategies, for a page that is known to be archives
+func tryExtractArchives(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	var results []crawlHistoricalResult
+	for _, strategy := range guidedCtx.Strategies.pageStrategies() {
+		if _, ok := strategy.(archivesPageStrategy); ok {
+			results = append(results, strategy.tryExtract(page, guidedCtx, logger)...)
 		}
 	}
+	return results
+}
+
+// Sorted archives, and the long feed found on an archives page
+type archivesStrategy struct{}
+
+func (*archivesStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyArchives
+}
+
+func (*archivesStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostNone
+}
+
+func (*archivesStrategy) patternPrefixes() []string {
+	return []string{"archives"}
+}
+
+func (s *archivesStrategy) archivesSteps() map[archivesStep]archivesStepFunc {
+	return map[archivesStep]archivesStepFunc{
+		archivesStepSorted:          s.extractSorted,
+		archivesStepSortedHighlight: s.extractSortedHighlight,
+		archivesStepSorted2XPaths:   s.extractSorted2XPaths,
+		archivesStepAlmostFeed:      s.extractAlmostFeed,
+		archivesStepSortedAlmost:    s.extractSortedAlmost,
+		archivesStepLongFeed:        s.extractLongFeed,
+	}
+}
+
+func (*archivesStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	pass := page.archivesPass(guidedCtx, logger)
+	if pass.MainResult == nil {
+		return nil
+	}
+	return []crawlHistoricalResult{pass.MainResult}
+}
 
+func (*archivesStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	switch r := result.(type) {
+	case *archivesSortedResult:
+		return postprocessArchivesSortedResult(r), true, nil
+	case *archivesLongFeedResult:
+		return postprocessArchivesLongFeedResult(r), true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func (*archivesStrategy) extractSorted(pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger) {
 	var sortedFewerStarsCuris []CanonicalUri
 	var sortedFewerStarsHaveDates bool
-	for _, starCountExtractions := range extractionsByStarCount {
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
 		if sortedResult, ok := tryExtractSorted(
-			starCountExtractions, -1, sortedFewerStarsCuris, sortedFewerStarsHaveDates, 0, fetchLink,
+			starCountExtractions, -1, sortedFewerStarsCuris, sortedFewerStarsHaveDates, 0, pass.Page.FetchLink,
 			guidedCtx, logger,
 		); ok {
-			mainResult = sortedResult
-			minLinksCount = len(sortedResult.Links) + 1
+			pass.MainResult = sortedResult
+			pass.MinLinksCount = len(sortedResult.Links) + 1
 			sortedFewerStarsCuris = ToCanonicalUris(sortedResult.Links)
 			sortedFewerStarsHaveDates = sortedResult.HasDates
 		}
 	}
+}
 
+func (*archivesStrategy) extractSortedHighlight(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
 	if guidedCtx.FeedEntryLinks.Length < 3 {
 		logger.Info(
 			"Skipping sorted match with highlighted first link because the feed is small (%d)",
 			guidedCtx.FeedEntryLinks.Length,
 		)
-	} else {
-		var sortedHighlightFewerStarsCuris []CanonicalUri
-		for _, starCountExtractions := range extractionsByStarCount {
-			if sortedHighlightResult, ok := tryExtractSortedHighlightFirstLink(
-				starCountExtractions, pageCurisSet, sortedHighlightFewerStarsCuris, minLinksCount,
-				fetchLink, guidedCtx, logger,
-			); ok {
-				mainResult = sortedHighlightResult
-				minLinksCount = len(sortedHighlightResult.Links) + 1
-				sortedHighlightFewerStarsCuris = ToCanonicalUris(sortedHighlightResult.Links)
-			}
-		}
+		return
 	}
 
-	if mediumPinnedEntryResult, ok := tryExtractMediumPinnedEntry(
-		extractionsByStarCount[0].Extractions, pageLinks, minLinksCount, fetchLink, guidedCtx, logger,
-	); ok {
-		// Medium with pinned entry is a very specific match
-		return []crawlHistoricalResult{mediumPinnedEntryResult}
+	var sortedHighlightFewerStarsCuris []CanonicalUri
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
+		if sortedHighlightResult, ok := tryExtractSortedHighlightFirstLink(
+			starCountExtractions, pass.Page.PageCurisSet, sortedHighlightFewerStarsCuris, pass.MinLinksCount,
+			pass.Page.FetchLink, guidedCtx, logger,
+		); ok {
+			pass.MainResult = sortedHighlightResult
+			pass.MinLinksCount = len(sortedHighlightResult.Links) + 1
+			sortedHighlightFewerStarsCuris = ToCanonicalUris(sortedHighlightResult.Links)
+		}
 	}
+}
 
+func (*archivesStrategy) extractSorted2XPaths(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
 	var sorted2XPathsFewerStarsCuris []CanonicalUri
-	oneStarExtractions := extractionsByStarCount[0].Extractions
-	for _, starCountExtractions := range extractionsByStarCount {
+	oneStarExtractions := pass.Page.ExtractionsByStarCount[0].Extractions
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
 		if sorted2XPathsResult, ok := tryExtractSorted2XPaths(
-			oneStarExtractions, starCountExtractions, sorted2XPathsFewerStarsCuris, minLinksCount,
-			fetchLink, guidedCtx, logger,
+			oneStarExtractions, starCountExtractions, sorted2XPathsFewerStarsCuris, pass.MinLinksCount,
+			pass.Page.FetchLink, guidedCtx, logger,
 		); ok {
-			mainResult = sorted2XPathsResult
-			minLinksCount = len(sorted2XPathsResult.Links) + 1
+			pass.MainResult = sorted2XPathsResult
+			pass.MinLinksCount = len(sorted2XPathsResult.Links) + 1
 			sorted2XPathsFewerStarsCuris = ToCanonicalUris(sorted2XPathsResult.Links)
 		}
 	}
+}
 
-	if guidedCtx.FeedEntryLinks.Length < minLinksCount {
+func (*archivesStrategy) extractAlmostFeed(pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger) {
+	if guidedCtx.FeedEntryLinks.Length < pass.MinLinksCount {
 		logger.Info(
 			"Skipping almost feed match because min links count is already greater (%d > %d)",
-			minLinksCount, guidedCtx.FeedEntryLinks.Length,
+			pass.MinLinksCount, guidedCtx.FeedEntryLinks.Length,
 		)
-	} else {
-		var almostFeedFewerStarsCuris []CanonicalUri
-		for _, starCountExtractions := range extractionsByStarCount {
-			if almostFeedResult, ok := tryExtractAlmostMatchingFeed(
-				starCountExtractions, almostMatchThreshold, almostFeedFewerStarsCuris, fetchLink, guidedCtx,
-				logger,
-			); ok {
-				mainResult = almostFeedResult
-				minLinksCount = len(almostFeedResult.Links) + 1
-				almostFeedFewerStarsCuris = ToCanonicalUris(almostFeedResult.Links)
-			}
-		}
+		return
 	}
 
-	var tentativeBetterResults []*archivesShuffledResult
-
-	if sortedResult, ok := mainResult.(*archivesSortedResult); ok && sortedResult.HasDates {
-		logger.Info("Skipping shuffled match because there's already a sorted result with dates")
-	} else {
-		for _, starCountExtractions := range extractionsByStarCount {
-			if shuffledResult, ok := tryExtractShuffled(
-				page, starCountExtractions, -1, minLinksCount, fetchLink, guidedCtx, logger,
-			); ok {
-				tentativeBetterResults = append(tentativeBetterResults, shuffledResult)
-				minLinksCount = len(shuffledResult.Links) + 1
-			}
+	var almostFeedFewerStarsCuris []CanonicalUri
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
+		if almostFeedResult, ok := tryExtractAlmostMatchingFeed(
+			starCountExtractions, pass.Page.ArchivesAlmostMatchThreshold, almostFeedFewerStarsCuris,
+			pass.Page.FetchLink, guidedCtx, logger,
+		); ok {
+			pass.MainResult = almostFeedResult
+			pass.MinLinksCount = len(almostFeedResult.Links) + 1
+			almostFeedFewerStarsCuris = ToCanonicalUris(almostFeedResult.Links)
 		}
 	}
+}
 
-	// Haven't ported try_extract_shuffled_2xpaths because only one blog needs it
-
+func (*archivesStrategy) extractSortedAlmost(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
 	var sortedAlmostFewerStarsCuris []CanonicalUri
 	var sortedAlmostFewerStarsHaveDates bool
-	for _, starCountExtractions := range extractionsByStarCount {
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
 		if sortedAlmostResult, ok := tryExtractSorted(
-			starCountExtractions, almostMatchThreshold, sortedAlmostFewerStarsCuris,
-			sortedAlmostFewerStarsHaveDates, minLinksCount, fetchLink, guidedCtx, logger,
+			starCountExtractions, pass.Page.ArchivesAlmostMatchThreshold, sortedAlmostFewerStarsCuris,
+			sortedAlmostFewerStarsHaveDates, pass.MinLinksCount, pass.Page.FetchLink, guidedCtx, logger,
 		); ok {
-			mainResult = sortedAlmostResult
-			minLinksCount = len(sortedAlmostResult.Links) + 1
+			pass.MainResult = sortedAlmostResult
+			pass.MinLinksCount = len(sortedAlmostResult.Links) + 1
 			sortedAlmostFewerStarsCuris = ToCanonicalUris(sortedAlmostResult.Links)
 			sortedAlmostFewerStarsHaveDates = sortedAlmostResult.HasDates
 		}
 	}
+}
 
-	if sortedResult, ok := mainResult.(*archivesSortedResult); ok && sortedResult.HasDates {
-		logger.Info("Skipping shuffled_almost match because there's already a sorted match with dates")
-	} else {
-		for _, starCountExtractions := range extractionsByStarCount {
-			if shuffledAlmostResult, ok := tryExtractShuffled(
-				page, starCountExtractions, almostMatchThreshold, minLinksCount, fetchLink, guidedCtx,
-				logger,
-			); ok {
-				tentativeBetterResults = append(tentativeBetterResults, shuffledAlmostResult)
-				minLinksCount = len(shuffledAlmostResult.Links) + 1
-			}
-		}
-	}
-
+func (*archivesStrategy) extractLongFeed(pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger) {
 	if longFeedResult, ok := tryExtractLongFeed(
-		guidedCtx.FeedEntryLinks, pageCurisSet, minLinksCount, fetchLink, logger,
+		guidedCtx.FeedEntryLinks, pass.Page.PageCurisSet, pass.MinLinksCount, pass.Page.FetchLink, logger,
 	); ok {
-		mainResult = longFeedResult
-		minLinksCount = len(longFeedResult.Links) + 1 //nolint:ineffassign,staticcheck
+		pass.MainResult = longFeedResult
+		pass.MinLinksCount = len(longFeedResult.Links) + 1
 	}
+}
 
-	var results []crawlHistoricalResult
-	if mainResult != nil {
-		results = append(results, mainResult)
+type archivesShuffledStrategy struct{}
+
+func (*archivesShuffledStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyArchivesShuffled
+}
+
+func (*archivesShuffledStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostAllPages
+}
+
+func (*archivesShuffledStrategy) patternPrefixes() []string {
+	return []string{"archives_shuffled"}
+}
+
+func (s *archivesShuffledStrategy) archivesSteps() map[archivesStep]archivesStepFunc {
+	return map[archivesStep]archivesStepFunc{
+		archivesStepShuffled:       s.extractShuffled,
+		archivesStepShuffledAlmost: s.extractShuffledAlmost,
 	}
-	if len(tentativeBetterResults) > 0 {
-		speculativeCount := 0
-		for _, result := range tentativeBetterResults {
-			if len(result.Links) > speculativeCount {
-				speculativeCount = len(result.Links)
-			}
+}
+
+func (*archivesShuffledStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	pass := page.archivesPass(guidedCtx, logger)
+	if len(pass.TentativeBetterResults) == 0 {
+		return nil
+	}
+	speculativeCount := 0
+	for _, result := range pass.TentativeBetterResults {
+		if len(result.Links) > speculativeCount {
+			speculativeCount = len(result.Links)
 		}
-		results = append(results, &archivesShuffledResults{
-			MainLnk:        *fetchLink,
-			Results:        tentativeBetterResults,
+	}
+	return []crawlHistoricalResult{
+		&archivesShuffledResults{
+			MainLnk:        *page.FetchLink,
+			Results:        pass.TentativeBetterResults,
 			SpeculativeCnt: speculativeCount,
-		})
+		},
+	}
+}
+
+func (*archivesShuffledStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	shuffledResults, ok := result.(*archivesShuffledResults)
+	if !ok {
+		return nil, false, nil
+	}
+	ppResult, err := postprocessArchivesShuffledResults(shuffledResults, guidedCtx, crawlCtx, logger)
+	return ppResult, true, err
+}
+
+func (*archivesShuffledStrategy) extractShuffled(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
+	if sortedResult, ok := pass.MainResult.(*archivesSortedResult); ok && sortedResult.HasDates {
+		logger.Info("Skipping shuffled match because there's already a sorted result with dates")
+		return
+	}
+
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
+		if shuffledResult, ok := tryExtractShuffled(
+			pass.Page.Page, starCountExtractions, -1, pass.MinLinksCount, pass.Page.FetchLink, guidedCtx,
+			logger,
+		); ok {
+			pass.TentativeBetterResults = append(pass.TentativeBetterResults, shuffledResult)
+			pass.MinLinksCount = len(shuffledResult.Links) + 1
+		}
+	}
+
+	// Haven't ported try_extract_shuffled_2xpaths because only one blog needs it
+}
+
+func (*archivesShuffledStrategy) extractShuffledAlmost(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
+	if sortedResult, ok := pass.MainResult.(*archivesSortedResult); ok && sortedResult.HasDates {
+		logger.Info("Skipping shuffled_almost match because there's already a sorted match with dates")
+		return
+	}
+
+	for _, starCountExtractions := range pass.Page.ExtractionsByStarCount {
+		if shuffledAlmostResult, ok := tryExtractShuffled(
+			pass.Page.Page, starCountExtractions, pass.Page.ArchivesAlmostMatchThreshold, pass.MinLinksCount,
+			pass.Page.FetchLink, guidedCtx, logger,
+		); ok {
+			pass.TentativeBetterResults = append(pass.TentativeBetterResults, shuffledAlmostResult)
+			pass.MinLinksCount = len(shuffledAlmostResult.Links) + 1
+		}
+	}
+}
+
+type archivesMediumPinnedStrategy struct{}
+
+func (*archivesMediumPinnedStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyArchivesMediumPinned
+}
+
+func (*archivesMediumPinnedStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostFewPages
+}
+
+// Shares the archives_shuffled_2xpaths pattern, told apart by historicalStrategyForResult
+func (*archivesMediumPinnedStrategy) patternPrefixes() []string {
+	return nil
+}
+
+func (s *archivesMediumPinnedStrategy) archivesSteps() map[archivesStep]archivesStepFunc {
+	return map[archivesStep]archivesStepFunc{
+		archivesStepMediumPinned: s.extractMediumPinnedEntry,
+	}
+}
+
+func (*archivesMediumPinnedStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	pass := page.archivesPass(guidedCtx, logger)
+	if pass.MaybeMediumPinnedEntryResult == nil {
+		return nil
+	}
+	return []crawlHistoricalResult{pass.MaybeMediumPinnedEntryResult}
+}
+
+func (*archivesMediumPinnedStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	mediumPinnedEntryResult, ok := result.(*archivesMediumPinnedEntryResult)
+	if !ok {
+		return nil, false, nil
+	}
+	ppResult, err := postprocessArchivesMediumPinnedEntryResult(
+		mediumPinnedEntryResult, guidedCtx, crawlCtx, logger,
+	)
+	return ppResult, true, err
+}
+
+func (*archivesMediumPinnedStrategy) extractMediumPinnedEntry(
+	pass *archivesPass, guidedCtx *guidedCrawlContext, logger Logger,
+) {
+	if mediumPinnedEntryResult, ok := tryExtractMediumPinnedEntry(
+		pass.Page.ExtractionsByStarCount[0].Extractions, pass.Page.PageLinks, pass.MinLinksCount,
+		pass.Page.FetchLink, guidedCtx, logger,
+	); ok {
+		// Medium with pinned entry is a very specific match
+		pass.MaybeMediumPinnedEntryResult = mediumPinnedEntryResult
+		pass.MainResult = nil
+		pass.IsDone = true
 	}
-	return results
 }
 
 func tryExtractSorted(
@@ -649,7 +834,7 @@ func tryExtractSorted(
 		extra := []string{fmt.Sprintf("xpath: %s%s", bestXPath, bestLogStr)}
 
 		var postCategories []pristineHistoricalBlogPostCategory
-		if CanonicalUriEqual(mainLink.Curi(), hardcodedKalzumeus, curiEqCfg) {
+		if guidedCtx.Hardcoded.isBlog(mainLink.Curi(), hardcodedKalzumeus, curiEqCfg) {
 			postCategories = hardcodedKalzumeusCategories
 		} else if guidedCtx.FeedGenerator == FeedGeneratorSubstack && bestHtmlLinks != nil {
 			postCategories = extractSubstackCategories(bestHtmlLinks, bestDistanceToTopParent)
@@ -855,7 +1040,7 @@ func tryExtractMediumPinnedEntry(
 			OtherLinksDates: otherLinksDates,
 			Extra: []string{
 				fmt.Sprintf("counts: 1 + %d", len(otherLinksDates)),
-				fmt.Sprintf("pinned_link_xpath: %s", pinnedEntryLink.XPath),
+				fmt.Sprintf("%s%s", mediumPinnedEntryExtraPrefix, pinnedEntryLink.XPath),
 				fmt.Sprintf("suffix_xpath: %s%s", extraction.MaskedXPath, logStr),
 			},
 		}, true
@@ -1210,7 +1395,7 @@ func tryExtractShuffled(
 		}
 
 		var postCategories []pristineHistoricalBlogPostCategory
-		if CanonicalUriEqual(mainLink.Curi(), hardcodedJuliaEvans, curiEqCfg) {
+		if guidedCtx.Hardcoded.isBlog(mainLink.Curi(), hardcodedJuliaEvans, curiEqCfg) {
 			logger.Info("Extracting Julia Evans categories")
 			jvnsCategories, err := extractJuliaEvansCategories(page, logger)
 			if err != nil {
@@ -69,6 +69,43 @@ func (r *ArchivesCategoriesResult) isSame(
 	return true
 }
 
+type archivesCategoriesStrategy struct{}
+
+func (*archivesCategoriesStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyArchivesCategories
+}
+
+func (*archivesCategoriesStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostAllPages
+}
+
+func (*archivesCategoriesStrategy) patternPrefixes() []string {
+	return []string{"archives_categories"}
+}
+
+func (*archivesCategoriesStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	result, ok := tryExtractArchivesCategories(
+		page.Page, page.PageCurisSet, page.ExtractionsByStarCount, guidedCtx, logger,
+	)
+	if !ok {
+		return nil
+	}
+	return []crawlHistoricalResult{result}
+}
+
+func (*archivesCategoriesStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	categoriesResult, ok := result.(*ArchivesCategoriesResult)
+	if !ok {
+		return nil, false, nil
+	}
+	ppResult, err := postprocessArchviesCategoriesResult(categoriesResult, guidedCtx, crawlCtx, logger)
+	return ppResult, true, err
+}
+
 func tryExtractArchivesCategories(
 	page *htmlPage, pageCurisSet *CanonicalUriSet, extractionsByStarCount []starCountExtractions,
 	guidedCtx *guidedCrawlContext, logger Logger,
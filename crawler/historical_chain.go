@@ -0,0 +1,249 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Chain crawling is the last resort for blogs that have neither archives nor paging but link every post
+// to the previous one. It starts from the oldest feed entry and follows the prev links until the first post.
+
+type chainLinkKind string
+
+const (
+	chainLinkKindRel  chainLinkKind = "rel"
+	chainLinkKindText chainLinkKind = "text"
+)
+
+type chainPrevLinkPattern struct {
+	Kind  chainLinkKind
+	XPath string
+}
+
+const chainRequestsBudget = 1000
+
+var chainPrevTextRegex *regexp.Regexp
+
+func init() {
+	chainPrevTextRegex = regexp.MustCompile(`(?i)^[^\pL\pN]*(?:previous|prev|older)\b`)
+}
+
+func isChainPrevLink(link *xpathLink, kind chainLinkKind) bool {
+	switch kind {
+	case chainLinkKindRel:
+		for _, rel := range strings.Fields(strings.ToLower(findAttr(link.Element, "rel"))) {
+			if rel == "prev" || rel == "previous" {
+				return true
+			}
+		}
+		return false
+	case chainLinkKindText:
+		if link.Element.Data != "a" {
+			return false
+		}
+		text := strings.TrimSpace(innerText(link.Element))
+		if text == "" {
+			text = findAttr(link.Element, "aria-label")
+		}
+		return chainPrevTextRegex.MatchString(text)
+	default:
+		panic("Unknown chain link kind")
+	}
+}
+
+var chainLinkKinds = []chainLinkKind{chainLinkKindRel, chainLinkKindText}
+
+// The pattern is only detected if the newer page links to the older one through it
+func detectChainPrevLinkPattern(
+	newerPageLinks []*xpathLink, olderCuri CanonicalUri, curiEqCfg *CanonicalEqualityConfig,
+) (*chainPrevLinkPattern, bool) {
+	for _, kind := range chainLinkKinds {
+		for _, link := range newerPageLinks {
+			if !isChainPrevLink(link, kind) {
+				continue
+			}
+			if !CanonicalUriEqual(link.Curi, olderCuri, curiEqCfg) {
+				continue
+			}
+
+			return &chainPrevLinkPattern{
+				Kind:  kind,
+				XPath: link.XPath,
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// Prefers the link at the same xpath, otherwise accepts the links of the same kind if they all agree
+func findChainPrevLink(
+	pageLinks []*xpathLink, pattern *chainPrevLinkPattern, curiEqCfg *CanonicalEqualityConfig,
+) (*Link, bool) {
+	var candidates []*xpathLink
+	for _, link := range pageLinks {
+		if !isChainPrevLink(link, pattern.Kind) {
+			continue
+		}
+		if link.XPath == pattern.XPath {
+			return &link.Link, true
+		}
+		candidates = append(candidates, link)
+	}
+
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	for _, candidate := range candidates[1:] {
+		if !CanonicalUriEqual(candidate.Curi, candidates[0].Curi, curiEqCfg) {
+			return nil, false
+		}
+	}
+	return &candidates[0].Link, true
+}
+
+type chainStrategy struct{}
+
+func (*chainStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyChain
+}
+
+func (*chainStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostAllPages
+}
+
+func (*chainStrategy) patternPrefixes() []string {
+	return []string{"chain"}
+}
+
+func (*chainStrategy) crawl(
+	startPage *htmlPage, maybeArchivesLink *Link, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+	logger Logger,
+) (*postprocessedResult, error) {
+	return guidedCrawlChain(guidedCtx, crawlCtx, logger)
+}
+
+func guidedCrawlChain(
+	guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, error) {
+	progressLogger := crawlCtx.ProgressLogger
+	curiEqCfg := guidedCtx.CuriEqCfg
+	logger.Info("Chain crawl start")
+
+	if !guidedCtx.FeedEntryLinks.IsOrderCertain {
+		logger.Info("Chain crawl skipped: feed order is not certain")
+		return nil, ErrPatternNotDetected
+	}
+
+	feedEntryLinksSlice := guidedCtx.FeedEntryLinks.ToSlice()
+	oldestEntryLink := feedEntryLinksSlice[len(feedEntryLinksSlice)-1]
+	secondOldestEntryLink := feedEntryLinksSlice[len(feedEntryLinksSlice)-2]
+
+	secondOldestPage, err := crawlHtmlPage(&secondOldestEntryLink.Link, crawlCtx, logger)
+	err2 := progressLogger.LogAndSavePostprocessing()
+	if err2 != nil {
+		return nil, err2
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil, ErrCrawlCanceled
+	} else if err != nil {
+		logger.Info("Chain crawl failed, couldn't fetch the second oldest entry: %v", err)
+		return nil, ErrPatternNotDetected
+	}
+
+	secondOldestPageLinks := extractLinks(
+		secondOldestPage.Document, secondOldestPage.FetchUri, nil, crawlCtx.Redirects, logger,
+		includeXPathOnly,
+	)
+	pattern, ok := detectChainPrevLinkPattern(secondOldestPageLinks, oldestEntryLink.Curi, curiEqCfg)
+	if !ok {
+		logger.Info("Chain crawl failed: no prev link from %s to %s", secondOldestEntryLink.Url, oldestEntryLink.Url)
+		return nil, ErrPatternNotDetected
+	}
+	logger.Info("Chain prev link pattern: %s %s", pattern.Kind, pattern.XPath)
+
+	links := make([]*pristineMaybeTitledLink, 0, len(feedEntryLinksSlice))
+	for _, entryLink := range feedEntryLinksSlice {
+		links = append(links, NewPristineMaybeTitledLink(&entryLink.maybeTitledLink))
+	}
+	seenCurisSet := NewCanonicalUriSet(ToCanonicalUris(feedEntryLinksSlice), curiEqCfg)
+
+	currentLink := &oldestEntryLink.Link
+	isOldestEntry := true
+	chainRequestsMade := 0
+	for {
+		if chainRequestsMade >= chainRequestsBudget {
+			logger.Info("Chain crawl failed: ran out of %d requests at %s", chainRequestsBudget, currentLink.Url)
+			return nil, ErrPatternNotDetected
+		}
+
+		page, err := crawlHtmlPage(currentLink, crawlCtx, logger)
+		chainRequestsMade++
+		err2 := progressLogger.LogAndSavePostprocessing()
+		if err2 != nil {
+			return nil, err2
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil, ErrCrawlCanceled
+		} else if err != nil {
+			logger.Info("Chain crawl failed, couldn't fetch %s: %v", currentLink.Url, err)
+			return nil, ErrPatternNotDetected
+		}
+
+		if !isOldestEntry {
+			titleValue := getPageTitle(page, guidedCtx.FeedGenerator, logger)
+			title := NewLinkTitle(titleValue, LinkTitleSourcePageTitle, nil)
+			links = append(links, NewPristineMaybeTitledLink(&maybeTitledLink{
+				Link:       *currentLink,
+				MaybeTitle: &title,
+			}))
+			titleCount := countLinkTitles(links)
+			err := progressLogger.LogAndSaveFetchedCount(&titleCount)
+			if err != nil {
+				return nil, err
+			}
+		}
+		isOldestEntry = false
+
+		pageLinks := extractLinks(
+			page.Document, page.FetchUri, nil, crawlCtx.Redirects, logger, includeXPathOnly,
+		)
+		prevLink, ok := findChainPrevLink(pageLinks, pattern, curiEqCfg)
+		if !ok {
+			logger.Info("Chain ended at %s", currentLink.Url)
+			break
+		}
+		if !guidedCtx.AllowedHosts[prevLink.Uri.Host] {
+			logger.Info("Chain crawl failed: prev link points to another host (%s)", prevLink.Url)
+			return nil, ErrPatternNotDetected
+		}
+		if seenCurisSet.Contains(prevLink.Curi) {
+			logger.Info("Chain crawl failed: loop detected at %s -> %s", currentLink.Url, prevLink.Url)
+			return nil, ErrPatternNotDetected
+		}
+		if !crawlCtx.RobotsClient.Test(prevLink.Uri, logger) {
+			logger.Info("Chain crawl failed: prev link is disallowed by robots.txt (%s)", prevLink.Url)
+			return nil, ErrPatternNotDetected
+		}
+
+		seenCurisSet.add(prevLink.Curi)
+		currentLink = prevLink
+	}
+
+	var extra []string
+	appendLogLinef(&extra, "prev_link: %s %s", pattern.Kind, pattern.XPath)
+	appendLogLinef(&extra, "chain_requests: %d", chainRequestsMade)
+
+	logger.Info("Chain crawl finish: %d links (%d from feed)", len(links), len(feedEntryLinksSlice))
+	return &postprocessedResult{
+		MainLnk:                 *NewPristineLink(&oldestEntryLink.Link),
+		Pattern:                 "chain_prev",
+		Links:                   links,
+		IsMatchingFeed:          true,
+		PostCategories:          nil,
+		Extra:                   extra,
+		MaybePartialPagedResult: nil,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package crawler
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainPrevLink(t *testing.T) {
+	type Test struct {
+		description     string
+		newerHtml       string
+		olderUrl        string
+		pageHtml        string
+		expectedKind    chainLinkKind
+		expectedPrevUrl string
+	}
+
+	tests := []Test{
+		{
+			description:     "rel prev in head",
+			newerHtml:       `<html><head><link rel="prev" href="/post1"></head><body><a href="/">Home</a></body></html>`,
+			olderUrl:        "https://blog.com/post1",
+			pageHtml:        `<html><head><link rel="prev" href="/post0"></head><body><a href="/">Home</a></body></html>`,
+			expectedKind:    chainLinkKindRel,
+			expectedPrevUrl: "https://blog.com/post0",
+		},
+		{
+			description:     "previous link text",
+			newerHtml:       `<html><body><nav><a href="/post1">« Previous: Hello</a><a href="/post3">Next »</a></nav></body></html>`,
+			olderUrl:        "https://blog.com/post1",
+			pageHtml:        `<html><body><nav><a href="/post0">« Previous: World</a><a href="/post2">Next »</a></nav></body></html>`,
+			expectedKind:    chainLinkKindText,
+			expectedPrevUrl: "https://blog.com/post0",
+		},
+		{
+			description:     "previous link at a different xpath",
+			newerHtml:       `<html><body><nav><a href="/post1">Older post</a></nav></body></html>`,
+			olderUrl:        "https://blog.com/post1",
+			pageHtml:        `<html><body><p>Extra</p><div><a href="/post0">Older post</a></div></body></html>`,
+			expectedKind:    chainLinkKindText,
+			expectedPrevUrl: "https://blog.com/post0",
+		},
+		{
+			description:     "first post",
+			newerHtml:       `<html><body><nav><a href="/post1">Previous</a></nav></body></html>`,
+			olderUrl:        "https://blog.com/post1",
+			pageHtml:        `<html><body><nav><a href="/post2">Next</a></nav></body></html>`,
+			expectedKind:    chainLinkKindText,
+			expectedPrevUrl: "",
+		},
+		{
+			description:     "ambiguous previous links",
+			newerHtml:       `<html><body><nav><a href="/post1">Previous</a></nav></body></html>`,
+			olderUrl:        "https://blog.com/post1",
+			pageHtml:        `<html><body><div><a href="/post0">Previous</a><a href="/comments/1">Previous comments</a></div></body></html>`,
+			expectedKind:    chainLinkKindText,
+			expectedPrevUrl: "",
+		},
+	}
+
+	logger := NewDummyLogger()
+	curiEqCfg := NewCanonicalEqualityConfig()
+	fetchUri, err := url.Parse("https://blog.com/post2")
+	require.NoError(t, err)
+	for _, tc := range tests {
+		newerDocument, err := parseHtml(tc.newerHtml, logger)
+		require.NoError(t, err, tc.description)
+		newerLinks := extractLinks(newerDocument, fetchUri, nil, nil, logger, includeXPathOnly)
+		olderLink, ok := ToCanonicalLink(tc.olderUrl, logger, nil)
+		require.True(t, ok, tc.description)
+
+		pattern, ok := detectChainPrevLinkPattern(newerLinks, olderLink.Curi, &curiEqCfg)
+		require.True(t, ok, tc.description)
+		require.Equal(t, tc.expectedKind, pattern.Kind, tc.description)
+
+		pageDocument, err := parseHtml(tc.pageHtml, logger)
+		require.NoError(t, err, tc.description)
+		pageLinks := extractLinks(pageDocument, fetchUri, nil, nil, logger, includeXPathOnly)
+		prevLink, ok := findChainPrevLink(pageLinks, pattern, &curiEqCfg)
+		if tc.expectedPrevUrl == "" {
+			require.False(t, ok, tc.description)
+		} else {
+			require.True(t, ok, tc.description)
+			require.Equal(t, tc.expectedPrevUrl, prevLink.Url, tc.description)
+		}
+	}
+}
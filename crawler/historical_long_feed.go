@@ -0,0 +1,66 @@
+package crawler
+
+// A feed that is long and doesn't look cut off at a round number is likely to have all the posts already
+
+type longFeedStrategy struct{}
+
+func (*longFeedStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyLongFeed
+}
+
+func (*longFeedStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostNone
+}
+
+func (*longFeedStrategy) patternPrefixes() []string {
+	return []string{"long_feed"}
+}
+
+func (*longFeedStrategy) tryFeed(
+	feed *historicalFeed, crawlCtx *CrawlContext, logger Logger,
+) (*feedHistoricalResult, error) {
+	parsedFeed := feed.ParsedFeed
+	feedCuri := feed.FeedLink.Curi
+	isDanLuuFeed := feed.Hardcoded.isBlog(feedCuri, HardcodedDanLuuFeed, feed.CuriEqCfg)
+	isInkAndSwitchFeed := feed.Hardcoded.isBlog(feedCuri, hardcodedInkAndSwitchFeed, feed.CuriEqCfg)
+	isLongFeed := (parsedFeed.EntryLinks.Length > 50 &&
+		parsedFeed.EntryLinks.Length%100 != 0 &&
+		!isDanLuuFeed) ||
+		isInkAndSwitchFeed
+	if !isLongFeed {
+		return nil, nil
+	}
+
+	logger.Info("Feed is long with %d entries", parsedFeed.EntryLinks.Length)
+
+	var postCategories []pristineHistoricalBlogPostCategory
+	var postCategoriesExtra []string
+	if feed.Hardcoded.isBlog(feedCuri, hardcodedPaulGraham, feed.CuriEqCfg) {
+		postCategories = hardcodedPaulGrahamCategories
+		postCategoriesStr := categoryCountsString(postCategories)
+		logger.Info("Categories: %s", postCategoriesStr)
+		appendLogLinef(&postCategoriesExtra, "categories: %s", postCategoriesStr)
+	}
+
+	var links []*maybeTitledLink
+	if isInkAndSwitchFeed {
+		links = extractInkAndSwitch(parsedFeed.EntryLinks.ToMaybeTitledSlice(), logger)
+	} else {
+		links = parsedFeed.EntryLinks.ToMaybeTitledSlice()
+	}
+
+	return &feedHistoricalResult{
+		MaybeResult: &HistoricalResult{
+			BlogLink:               *feed.InitialBlogLink,
+			MainLink:               *feed.FeedLink,
+			Pattern:                "long_feed",
+			Strategy:               HistoricalStrategyLongFeed,
+			Links:                  nil,
+			DiscardedFeedEntryUrls: nil,
+			PostCategories:         PristineHistoricalBlogPostCategoriesUnwrap(postCategories),
+			Extra:                  postCategoriesExtra,
+		},
+		Links: links,
+		Error: nil,
+	}, nil
+}
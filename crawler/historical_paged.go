@@ -18,7 +18,7 @@ import (
 // Paging with class xpaths came out of one weird blog but keeping it just in case
 
 // Page 1 is allowed to refer to the htmls, as we're still deciding then and making everything pristine from
-// the page 2 onwards
+// the page 2 onwards. Page 1 results that are kept until postprocessing get compacted by PageMemory
 
 type page1Result struct {
 	MainLnk      pristineLink
@@ -60,6 +60,54 @@ type page1Extraction struct {
 	PostTags            postCategoriesMap
 }
 
+type pagedStrategy struct{}
+
+func (*pagedStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyPaged
+}
+
+func (*pagedStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostAllPages
+}
+
+func (*pagedStrategy) patternPrefixes() []string {
+	return []string{"paged"}
+}
+
+func (*pagedStrategy) tryExtract(
+	page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger,
+) []crawlHistoricalResult {
+	result, ok := tryExtractPage1(
+		page.FetchLink, page.Page, page.PageLinks, page.PageCurisSet, page.ExtractionsByStarCount, guidedCtx,
+		logger,
+	)
+	if !ok {
+		return nil
+	}
+	return []crawlHistoricalResult{result}
+}
+
+// Page 1 is postprocessed into a partial result, and the rest of the pages are only fetched if the partial
+// result is still the best
+func (*pagedStrategy) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, bool, error) {
+	switch r := result.(type) {
+	case *page1Result:
+		// If page 1 result looks the best, check just the page 2 in case it was a scam
+		ppResult, err := postprocessPage1Result(r, guidedCtx, crawlCtx, logger)
+		return ppResult, true, err
+	case *postprocessedResult:
+		if r.MaybePartialPagedResult == nil {
+			return nil, false, nil
+		}
+		ppResult, err := postprocessPartialPagedResult(r.MaybePartialPagedResult, guidedCtx, crawlCtx, logger)
+		return ppResult, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
 var blogspotPostsByDateRegex *regexp.Regexp
 
 func init() {
@@ -74,7 +122,7 @@ func tryExtractPage1(
 	curiEqCfg := guidedCtx.CuriEqCfg
 
 	linkPatternToPage2, ok := findLinkToPage2(
-		page1Links, page1, guidedCtx.FeedGenerator, guidedCtx.CuriEqCfg, logger,
+		page1Links, page1, guidedCtx.FeedGenerator, guidedCtx.Hardcoded, guidedCtx.CuriEqCfg, logger,
 	)
 	if !ok {
 		return nil, false
@@ -184,7 +232,7 @@ func tryExtractPage1(
 	for _, extraction := range maskedXPathExtractions {
 		links := extraction.UnfilteredLinks
 
-		if CanonicalUriEqual(page1Link.Curi(), hardcodedCaseyHandmer, curiEqCfg) {
+		if guidedCtx.Hardcoded.isBlog(page1Link.Curi(), hardcodedCaseyHandmer, curiEqCfg) {
 			for i, link := range links {
 				if CanonicalUriEqual(link.Link.Curi, feedEntryLinks.LinkBuckets[0][0].Curi, curiEqCfg) {
 					links = links[i:]
@@ -830,11 +878,13 @@ func tryExtractNextPage(
 
 	var postCategoriesOrTags []pristineHistoricalBlogPostCategory
 	var postCategoriesSource string
+	mainCuri := pagedState.MainLnk.Curi()
+	hardcoded := guidedCtx.Hardcoded
 	switch {
-	case CanonicalUriEqual(pagedState.MainLnk.Curi(), hardcodedMrMoneyMustache, curiEqCfg):
+	case hardcoded.isBlog(mainCuri, hardcodedMrMoneyMustache, curiEqCfg):
 		postCategoriesOrTags = hardcodedMrMoneyMustacheCategories
 		postCategoriesSource = " hardcoded"
-	case CanonicalUriEqual(pagedState.MainLnk.Curi(), hardcodedFactorio, curiEqCfg):
+	case hardcoded.isBlog(mainCuri, hardcodedFactorio, curiEqCfg):
 		factorioCategories, err := extractFactorioCategories(nextEntryLinks)
 		if err != nil {
 			logger.Warn("Couldn't extract Factorio categories: %v", err)
@@ -843,7 +893,7 @@ func tryExtractNextPage(
 			postCategoriesOrTags = factorioCategories
 			postCategoriesSource = " hardcoded"
 		}
-	case CanonicalUriEqual(pagedState.MainLnk.Curi(), hardcodedACOUP, curiEqCfg):
+	case hardcoded.isBlog(mainCuri, hardcodedACOUP, curiEqCfg):
 		acoupCategoires, err := extractACOUPCategories(nextEntryLinks)
 		if err != nil {
 			logger.Warn("Couldn't extract ACOUP categories: %v", err)
@@ -854,7 +904,7 @@ func tryExtractNextPage(
 		}
 		postCategoriesOrTags = append(postCategoriesOrTags, postTagsMap.flatten()...)
 		postCategoriesSource += " from tags"
-	case CanonicalUriEqual(pagedState.MainLnk.Curi(), hardcodedCryptographyEngineering, curiEqCfg):
+	case hardcoded.isBlog(mainCuri, hardcodedCryptographyEngineering, curiEqCfg):
 		postCategoriesOrTags = append(
 			slices.Clone(hardcodedCryptographyEngineeringCategories),
 			postTagsMap.flatten()...,
@@ -968,10 +1018,10 @@ func init() {
 }
 
 func findLinkToPage2(
-	page1Links []*xpathLink, page1 *htmlPage, feedGenerator FeedGenerator, curiEqCfg *CanonicalEqualityConfig,
-	logger Logger,
+	page1Links []*xpathLink, page1 *htmlPage, feedGenerator FeedGenerator, hardcoded *hardcodedStrategy,
+	curiEqCfg *CanonicalEqualityConfig, logger Logger,
 ) (*linkPatternToPage2, bool) {
-	if page1.FetchUri.Host == hardcodedAntirez.Host {
+	if hardcoded.isBlogHost(page1.FetchUri.Host, hardcodedAntirez) {
 		for _, link := range page1Links {
 			if strings.HasSuffix(link.Curi.TrimmedPath, "/latest/100") {
 				return &linkPatternToPage2{
@@ -0,0 +1,322 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"feedrewind.com/oops"
+)
+
+// Historical strategies are registered here in the order they are tried. Feed strategies get the history
+// from the feed alone, and the first one to take the feed skips the guided crawl. Page strategies run on
+// every page that the guided crawl fetches, their results compete on the speculative count and each
+// strategy postprocesses its own. Crawl strategies are the last resort of the guided crawl and are tried
+// one by one. A new strategy can be registered as disabled by default, so that it can be compared against
+// the ground truth in isolation before it competes in production. Hardcoded blogs are special cases layered
+// on top of the rest, disabling them shows how the generic strategies do on those blogs.
+
+type HistoricalStrategyName string
+
+const (
+	HistoricalStrategyRecrawl              HistoricalStrategyName = "recrawl"
+	HistoricalStrategyLongFeed             HistoricalStrategyName = "long_feed"
+	HistoricalStrategyTumblrApi            HistoricalStrategyName = "tumblr_api"
+	HistoricalStrategyArchives             HistoricalStrategyName = "archives"
+	HistoricalStrategyArchivesShuffled     HistoricalStrategyName = "archives_shuffled"
+	HistoricalStrategyArchivesMediumPinned HistoricalStrategyName = "archives_medium_pinned"
+	HistoricalStrategyArchivesCategories   HistoricalStrategyName = "archives_categories"
+	HistoricalStrategyPaged                HistoricalStrategyName = "paged"
+	HistoricalStrategyTableOfContents      HistoricalStrategyName = "table_of_contents"
+	HistoricalStrategyChain                HistoricalStrategyName = "chain"
+	HistoricalStrategyHardcoded            HistoricalStrategyName = "hardcoded"
+)
+
+// Rough number of requests the strategy makes on top of the pages the guided crawl fetches anyway
+type HistoricalStrategyCost int
+
+const (
+	HistoricalStrategyCostNone HistoricalStrategyCost = iota
+	HistoricalStrategyCostFewPages
+	HistoricalStrategyCostAllPages
+)
+
+func (c HistoricalStrategyCost) String() string {
+	switch c {
+	case HistoricalStrategyCostNone:
+		return "none"
+	case HistoricalStrategyCostFewPages:
+		return "few_pages"
+	case HistoricalStrategyCostAllPages:
+		return "all_pages"
+	default:
+		panic(fmt.Errorf("Unknown strategy cost: %d", int(c)))
+	}
+}
+
+type HistoricalStrategy interface {
+	Name() HistoricalStrategyName
+	Cost() HistoricalStrategyCost
+	// Patterns of the results, matched by prefix
+	patternPrefixes() []string
+}
+
+type historicalFeedStrategy interface {
+	HistoricalStrategy
+	// Returns nil if the feed isn't for this strategy. The error is only returned to abort the crawl, a
+	// strategy that took the feed and failed reports it in the result.
+	tryFeed(feed *historicalFeed, crawlCtx *CrawlContext, logger Logger) (*feedHistoricalResult, error)
+}
+
+// What the feed strategies share for a feed
+type historicalFeed struct {
+	FeedLink                *Link
+	ParsedFeed              *ParsedFeed
+	FeedEntryCurisTitlesMap CanonicalUriMap[MaybeLinkTitle]
+	InitialBlogLink         *Link
+	MaybeRecrawlHint        *RecrawlHint
+	CuriEqCfg               *CanonicalEqualityConfig
+	Hardcoded               *hardcodedStrategy
+}
+
+type feedHistoricalResult struct {
+	MaybeResult *HistoricalResult
+	Links       []*maybeTitledLink
+	Error       error
+}
+
+type historicalPageStrategy interface {
+	HistoricalStrategy
+	tryExtract(page *historicalPage, guidedCtx *guidedCrawlContext, logger Logger) []crawlHistoricalResult
+	// Returns false for the results of other strategies
+	postprocess(
+		result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+	) (*postprocessedResult, bool, error)
+}
+
+// What the page strategies share for a fetched page
+type historicalPage struct {
+	FetchLink                    *pristineLink
+	Page                         *htmlPage
+	PageLinks                    []*xpathLink
+	PageCurisSet                 *CanonicalUriSet
+	ExtractionsByStarCount       []starCountExtractions
+	ArchivesAlmostMatchThreshold int
+	// Run by the first archives strategy that asks for it
+	MaybeArchivesPass *archivesPass
+}
+
+type historicalCrawlStrategy interface {
+	HistoricalStrategy
+	crawl(
+		startPage *htmlPage, maybeArchivesLink *Link, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+		logger Logger,
+	) (*postprocessedResult, error)
+}
+
+type registeredHistoricalStrategy struct {
+	Strategy         HistoricalStrategy
+	EnabledByDefault bool
+}
+
+var historicalStrategyRegistry = []registeredHistoricalStrategy{
+	{Strategy: &longFeedStrategy{}, EnabledByDefault: true},
+	{Strategy: &recrawlStrategy{}, EnabledByDefault: true},
+	{Strategy: &tumblrApiStrategy{}, EnabledByDefault: true},
+	// Goes before the strategies it's layered on, so that it gets to their results first
+	{Strategy: &hardcodedStrategy{}, EnabledByDefault: true},
+	{Strategy: &archivesStrategy{}, EnabledByDefault: true},
+	{Strategy: &archivesShuffledStrategy{}, EnabledByDefault: true},
+	{Strategy: &archivesMediumPinnedStrategy{}, EnabledByDefault: true},
+	{Strategy: &archivesCategoriesStrategy{}, EnabledByDefault: true},
+	{Strategy: &pagedStrategy{}, EnabledByDefault: true},
+	{Strategy: &tableOfContentsStrategy{}, EnabledByDefault: true},
+	{Strategy: &chainStrategy{}, EnabledByDefault: true},
+}
+
+func HistoricalStrategies() []HistoricalStrategy {
+	var strategies []HistoricalStrategy
+	for _, registered := range historicalStrategyRegistry {
+		strategies = append(strategies, registered.Strategy)
+	}
+	return strategies
+}
+
+func HistoricalStrategyNames() []string {
+	var names []string
+	for _, registered := range historicalStrategyRegistry {
+		names = append(names, string(registered.Strategy.Name()))
+	}
+	return names
+}
+
+// The longest prefix wins, so that archives_categories isn't counted as archives
+func historicalStrategyForPattern(pattern string) HistoricalStrategyName {
+	var bestName HistoricalStrategyName
+	bestPrefixLength := 0
+	for _, registered := range historicalStrategyRegistry {
+		for _, prefix := range registered.Strategy.patternPrefixes() {
+			if strings.HasPrefix(pattern, prefix) && len(prefix) > bestPrefixLength {
+				bestName = registered.Strategy.Name()
+				bestPrefixLength = len(prefix)
+			}
+		}
+	}
+	return bestName
+}
+
+// Winning results on blogs with hardcoded branches are credited to those branches, and Medium pinned entry
+// results are recognized by their log line
+func historicalStrategyForResult(
+	result *postprocessedResult, strategies *HistoricalStrategySelection, curiEqCfg *CanonicalEqualityConfig,
+) HistoricalStrategyName {
+	if strategies.hardcoded().isHistoricalBlog(result.MainLnk.Curi(), curiEqCfg) {
+		return HistoricalStrategyHardcoded
+	}
+	if historicalStrategyForPattern(result.Pattern) == HistoricalStrategyArchivesShuffled {
+		for _, line := range result.Extra {
+			if strings.HasPrefix(line, mediumPinnedEntryExtraPrefix) {
+				return HistoricalStrategyArchivesMediumPinned
+			}
+		}
+	}
+	return historicalStrategyForPattern(result.Pattern)
+}
+
+// Selection
+
+type HistoricalStrategySelection struct {
+	enabled map[HistoricalStrategyName]bool
+}
+
+func NewDefaultHistoricalStrategySelection() *HistoricalStrategySelection {
+	enabled := make(map[HistoricalStrategyName]bool)
+	for _, registered := range historicalStrategyRegistry {
+		enabled[registered.Strategy.Name()] = registered.EnabledByDefault
+	}
+	return &HistoricalStrategySelection{
+		enabled: enabled,
+	}
+}
+
+// Forcing a strategy disables everything else
+func NewHistoricalStrategySelection(
+	enableNames, disableNames []string, maybeForceName *string,
+) (*HistoricalStrategySelection, error) {
+	selection := NewDefaultHistoricalStrategySelection()
+	validate := func(name string) (HistoricalStrategyName, error) {
+		strategyName := HistoricalStrategyName(name)
+		if _, ok := selection.enabled[strategyName]; !ok {
+			return "", oops.Newf(
+				"Unknown historical strategy: %s (expected one of %s)",
+				name, strings.Join(HistoricalStrategyNames(), ", "),
+			)
+		}
+		return strategyName, nil
+	}
+
+	if maybeForceName != nil {
+		if len(enableNames) > 0 || len(disableNames) > 0 {
+			return nil, oops.New("Forcing a historical strategy can't be combined with enabling or disabling")
+		}
+		forcedName, err := validate(*maybeForceName)
+		if err != nil {
+			return nil, err
+		}
+		for name := range selection.enabled {
+			selection.enabled[name] = name == forcedName
+		}
+		return selection, nil
+	}
+
+	for _, name := range enableNames {
+		strategyName, err := validate(name)
+		if err != nil {
+			return nil, err
+		}
+		selection.enabled[strategyName] = true
+	}
+	for _, name := range disableNames {
+		strategyName, err := validate(name)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(enableNames, name) {
+			return nil, oops.Newf("Historical strategy is both enabled and disabled: %s", name)
+		}
+		selection.enabled[strategyName] = false
+	}
+	return selection, nil
+}
+
+func (s *HistoricalStrategySelection) IsEnabled(name HistoricalStrategyName) bool {
+	return s.enabled[name]
+}
+
+func enabledHistoricalStrategies[T HistoricalStrategy](selection *HistoricalStrategySelection) []T {
+	var result []T
+	for _, registered := range historicalStrategyRegistry {
+		strategy, ok := registered.Strategy.(T)
+		if ok && selection.enabled[registered.Strategy.Name()] {
+			result = append(result, strategy)
+		}
+	}
+	return result
+}
+
+func (s *HistoricalStrategySelection) feedStrategies() []historicalFeedStrategy {
+	return enabledHistoricalStrategies[historicalFeedStrategy](s)
+}
+
+func (s *HistoricalStrategySelection) pageStrategies() []historicalPageStrategy {
+	return enabledHistoricalStrategies[historicalPageStrategy](s)
+}
+
+func (s *HistoricalStrategySelection) crawlStrategies() []historicalCrawlStrategy {
+	return enabledHistoricalStrategies[historicalCrawlStrategy](s)
+}
+
+// Nil when disabled, which the hardcoded branches in the other strategies take as no blog being hardcoded
+func (s *HistoricalStrategySelection) hardcoded() *hardcodedStrategy {
+	strategies := enabledHistoricalStrategies[*hardcodedStrategy](s)
+	if len(strategies) == 0 {
+		return nil
+	}
+	return strategies[0]
+}
+
+// Postprocessed results are put back in line as is, except for the paged ones that still have pages to go
+func (s *HistoricalStrategySelection) postprocess(
+	result crawlHistoricalResult, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext, logger Logger,
+) (*postprocessedResult, error) {
+	if ppResult, ok := result.(*postprocessedResult); ok && ppResult.MaybePartialPagedResult == nil {
+		return ppResult, nil
+	}
+	for _, strategy := range s.pageStrategies() {
+		ppResult, ok, err := strategy.postprocess(result, guidedCtx, crawlCtx, logger)
+		if ok {
+			return ppResult, err
+		}
+	}
+	return nil, oops.Newf("No enabled strategy postprocesses %s", printResult(result))
+}
+
+// Only mentions the strategies that differ from the defaults
+func (s *HistoricalStrategySelection) String() string {
+	var tokens []string
+	for _, registered := range historicalStrategyRegistry {
+		name := registered.Strategy.Name()
+		if s.enabled[name] == registered.EnabledByDefault {
+			continue
+		}
+		if s.enabled[name] {
+			tokens = append(tokens, fmt.Sprintf("+%s", name))
+		} else {
+			tokens = append(tokens, fmt.Sprintf("-%s", name))
+		}
+	}
+	if len(tokens) == 0 {
+		return "default"
+	}
+	return strings.Join(tokens, " ")
+}
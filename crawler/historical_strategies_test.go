@@ -0,0 +1,259 @@
+package crawler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoricalStrategySelection(t *testing.T) {
+	type Test struct {
+		description     string
+		enableNames     []string
+		disableNames    []string
+		maybeForceName  *string
+		expectedEnabled []HistoricalStrategyName
+		expectedError   bool
+	}
+
+	allNames := []HistoricalStrategyName{
+		HistoricalStrategyRecrawl, HistoricalStrategyLongFeed, HistoricalStrategyTumblrApi,
+		HistoricalStrategyArchives, HistoricalStrategyArchivesShuffled, HistoricalStrategyArchivesMediumPinned,
+		HistoricalStrategyArchivesCategories, HistoricalStrategyPaged, HistoricalStrategyTableOfContents,
+		HistoricalStrategyChain, HistoricalStrategyHardcoded,
+	}
+	paged := "paged"
+	unknown := "unknown"
+
+	tests := []Test{
+		{
+			description:     "defaults",
+			enableNames:     nil,
+			disableNames:    nil,
+			maybeForceName:  nil,
+			expectedEnabled: allNames,
+			expectedError:   false,
+		},
+		{
+			description:    "disable",
+			enableNames:    nil,
+			disableNames:   []string{"chain", "long_feed", "hardcoded"},
+			maybeForceName: nil,
+			expectedEnabled: []HistoricalStrategyName{
+				HistoricalStrategyRecrawl, HistoricalStrategyTumblrApi, HistoricalStrategyArchives,
+				HistoricalStrategyArchivesShuffled, HistoricalStrategyArchivesMediumPinned,
+				HistoricalStrategyArchivesCategories, HistoricalStrategyPaged, HistoricalStrategyTableOfContents,
+			},
+			expectedError: false,
+		},
+		{
+			description:     "force",
+			enableNames:     nil,
+			disableNames:    nil,
+			maybeForceName:  &paged,
+			expectedEnabled: []HistoricalStrategyName{HistoricalStrategyPaged},
+			expectedError:   false,
+		},
+		{
+			description:     "unknown",
+			enableNames:     []string{"unknown"},
+			disableNames:    nil,
+			maybeForceName:  nil,
+			expectedEnabled: nil,
+			expectedError:   true,
+		},
+		{
+			description:     "force unknown",
+			enableNames:     nil,
+			disableNames:    nil,
+			maybeForceName:  &unknown,
+			expectedEnabled: nil,
+			expectedError:   true,
+		},
+		{
+			description:     "force and disable",
+			enableNames:     nil,
+			disableNames:    []string{"chain"},
+			maybeForceName:  &paged,
+			expectedEnabled: nil,
+			expectedError:   true,
+		},
+		{
+			description:     "enable and disable",
+			enableNames:     []string{"chain"},
+			disableNames:    []string{"chain"},
+			maybeForceName:  nil,
+			expectedEnabled: nil,
+			expectedError:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			selection, err := NewHistoricalStrategySelection(tc.enableNames, tc.disableNames, tc.maybeForceName)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var enabled []HistoricalStrategyName
+			for _, name := range allNames {
+				if selection.IsEnabled(name) {
+					enabled = append(enabled, name)
+				}
+			}
+			require.Equal(t, tc.expectedEnabled, enabled)
+		})
+	}
+}
+
+func TestHistoricalStrategyForPattern(t *testing.T) {
+	type Test struct {
+		pattern  string
+		expected HistoricalStrategyName
+	}
+
+	tests := []Test{
+		{pattern: "archives", expected: HistoricalStrategyArchives},
+		{pattern: "archives_shuffled_2xpaths", expected: HistoricalStrategyArchivesShuffled},
+		{pattern: "archives_long_feed", expected: HistoricalStrategyArchives},
+		{pattern: "archives_categories_almost", expected: HistoricalStrategyArchivesCategories},
+		{pattern: "paged_partial", expected: HistoricalStrategyPaged},
+		{pattern: "long_feed", expected: HistoricalStrategyLongFeed},
+		{pattern: "tumblr", expected: HistoricalStrategyTumblrApi},
+		{pattern: "chain_prev", expected: HistoricalStrategyChain},
+		{pattern: "table_of_contents", expected: HistoricalStrategyTableOfContents},
+		{pattern: "custom", expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			require.Equal(t, tc.expected, historicalStrategyForPattern(tc.pattern))
+		})
+	}
+}
+
+func TestStrategiesByStage(t *testing.T) {
+	selection := NewDefaultHistoricalStrategySelection()
+	var feedNames []HistoricalStrategyName
+	for _, strategy := range selection.feedStrategies() {
+		feedNames = append(feedNames, strategy.Name())
+	}
+	require.Equal(t, []HistoricalStrategyName{
+		HistoricalStrategyLongFeed, HistoricalStrategyRecrawl, HistoricalStrategyTumblrApi,
+	}, feedNames)
+
+	var pageNames []HistoricalStrategyName
+	for _, strategy := range selection.pageStrategies() {
+		pageNames = append(pageNames, strategy.Name())
+	}
+	require.Equal(t, []HistoricalStrategyName{
+		HistoricalStrategyHardcoded, HistoricalStrategyArchives, HistoricalStrategyArchivesShuffled,
+		HistoricalStrategyArchivesMediumPinned, HistoricalStrategyArchivesCategories, HistoricalStrategyPaged,
+	}, pageNames)
+
+	var crawlNames []HistoricalStrategyName
+	for _, strategy := range selection.crawlStrategies() {
+		crawlNames = append(crawlNames, strategy.Name())
+	}
+	require.Equal(t, []HistoricalStrategyName{
+		HistoricalStrategyTableOfContents, HistoricalStrategyChain,
+	}, crawlNames)
+}
+
+func TestForcedPageStrategy(t *testing.T) {
+	for _, name := range []string{"archives_shuffled", "archives_medium_pinned", "hardcoded"} {
+		t.Run(name, func(t *testing.T) {
+			selection, err := NewHistoricalStrategySelection(nil, nil, &name)
+			require.NoError(t, err)
+			var names []HistoricalStrategyName
+			for _, strategy := range selection.pageStrategies() {
+				names = append(names, strategy.Name())
+			}
+			require.Equal(t, []HistoricalStrategyName{HistoricalStrategyName(name)}, names)
+			require.Empty(t, selection.feedStrategies())
+			require.Empty(t, selection.crawlStrategies())
+			require.Equal(t, name == "hardcoded", selection.hardcoded() != nil)
+		})
+	}
+}
+
+func TestArchivesStepsOwnedOnce(t *testing.T) {
+	owners := make(map[archivesStep][]HistoricalStrategyName)
+	for _, strategy := range NewDefaultHistoricalStrategySelection().pageStrategies() {
+		if archivesStrategy, ok := strategy.(archivesPageStrategy); ok {
+			for step := range archivesStrategy.archivesSteps() {
+				owners[step] = append(owners[step], strategy.Name())
+			}
+		}
+	}
+	for step := archivesStep(0); step < archivesStepCount; step++ {
+		require.Len(t, owners[step], 1, "step %d", step)
+	}
+}
+
+func TestHistoricalStrategyForResult(t *testing.T) {
+	type Test struct {
+		description      string
+		mainUrl          string
+		pattern          string
+		extra            []string
+		disableHardcoded bool
+		expectedStrategy HistoricalStrategyName
+	}
+
+	tests := []Test{
+		{
+			description:      "pattern",
+			mainUrl:          "https://example.com/archives",
+			pattern:          "archives_shuffled",
+			extra:            nil,
+			disableHardcoded: false,
+			expectedStrategy: HistoricalStrategyArchivesShuffled,
+		},
+		{
+			description:      "medium pinned",
+			mainUrl:          "https://medium.com/@someone",
+			pattern:          "archives_shuffled_2xpaths",
+			extra:            []string{"counts: 1 + 20", mediumPinnedEntryExtraPrefix + "/html/body/div[1]/a"},
+			disableHardcoded: false,
+			expectedStrategy: HistoricalStrategyArchivesMediumPinned,
+		},
+		{
+			description:      "hardcoded",
+			mainUrl:          "https://danluu.com",
+			pattern:          "archives",
+			extra:            nil,
+			disableHardcoded: false,
+			expectedStrategy: HistoricalStrategyHardcoded,
+		},
+		{
+			description:      "hardcoded disabled",
+			mainUrl:          "https://danluu.com",
+			pattern:          "archives",
+			extra:            nil,
+			disableHardcoded: true,
+			expectedStrategy: HistoricalStrategyArchives,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			var disableNames []string
+			if tc.disableHardcoded {
+				disableNames = []string{"hardcoded"}
+			}
+			selection, err := NewHistoricalStrategySelection(nil, disableNames, nil)
+			require.NoError(t, err)
+			curiEqCfg := NewCanonicalEqualityConfig()
+			mainLink, ok := ToCanonicalLink(tc.mainUrl, NewDummyLogger(), nil)
+			require.True(t, ok)
+			result := &postprocessedResult{ //nolint:exhaustruct
+				MainLnk: *NewPristineLink(mainLink),
+				Pattern: tc.pattern,
+				Extra:   tc.extra,
+			}
+			require.Equal(t, tc.expectedStrategy, historicalStrategyForResult(result, selection, &curiEqCfg))
+		})
+	}
+}
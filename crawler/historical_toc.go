@@ -0,0 +1,288 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"feedrewind.com/oops"
+)
+
+// Online books, documentation and web serials often have a single table of contents page where the link
+// order is the reading order. The dates on such pages don't sort, so the archives strategies reject them.
+// If the blog has a feed, the table of contents has to end with the feed entries. If it doesn't, the user
+// picks the page and the largest list of links on it is taken.
+
+const tableOfContentsMinLinks = 10
+const tableOfContentsMaxStarCount = 2
+
+type tableOfContentsExtraction struct {
+	MaskedXPath string
+	Links       []*maybeTitledLink // page order
+}
+
+func extractTableOfContents(
+	page *htmlPage, maybeFeedEntryLinks *FeedEntryLinks, curiEqCfg *CanonicalEqualityConfig,
+	redirects map[string]*Link, logger Logger,
+) (*tableOfContentsExtraction, bool) {
+	pageAllLinks := extractLinks(
+		page.Document, page.FetchUri, nil, redirects, logger, includeXPathAndClassXPath,
+	)
+	var pageLinks []*xpathLink
+	for _, link := range pageAllLinks {
+		if link.Uri.Host != page.FetchUri.Host && !curiEqCfg.SameHosts[link.Uri.Host] {
+			continue
+		}
+		if CanonicalUriEqual(link.Curi, page.Curi, curiEqCfg) {
+			continue
+		}
+		pageLinks = append(pageLinks, link)
+	}
+
+	// Masked xpaths are built around the known links. Without a feed, every link is known and its title is
+	// the text.
+	knownCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](curiEqCfg)
+	if maybeFeedEntryLinks != nil {
+		for _, entryLink := range maybeFeedEntryLinks.ToSlice() {
+			knownCurisTitlesMap.Add(entryLink.Link, entryLink.MaybeTitle)
+		}
+	} else {
+		for _, link := range pageLinks {
+			if knownCurisTitlesMap.Contains(link.Curi) {
+				continue
+			}
+			title := NewLinkTitle(getElementTitle(link.Element), LinkTitleSourceInnerText, nil)
+			knownCurisTitlesMap.Add(link.Link, &title)
+		}
+	}
+
+	var bestExtraction *tableOfContentsExtraction
+	for starCount := 1; starCount <= tableOfContentsMaxStarCount; starCount++ {
+		linksGroupings := groupLinksByMaskedXPath(pageLinks, &knownCurisTitlesMap, curiEqCfg, starCount)
+		for _, linksGrouping := range linksGroupings {
+			var links []*maybeTitledLink
+			var curis []CanonicalUri
+			seenCurisSet := NewCanonicalUriSet(nil, curiEqCfg)
+			for _, link := range linksGrouping.Links {
+				if seenCurisSet.Contains(link.Curi) {
+					continue
+				}
+				seenCurisSet.add(link.Curi)
+				links = append(links, &link.maybeTitledLink)
+				curis = append(curis, link.Curi)
+			}
+
+			if len(links) < tableOfContentsMinLinks {
+				continue
+			}
+			if maybeFeedEntryLinks != nil {
+				feedLength := maybeFeedEntryLinks.Length
+				if len(links) <= feedLength {
+					continue
+				}
+				tailCurisSet := NewCanonicalUriSet(curis[len(curis)-feedLength:], curiEqCfg)
+				if !slices.ContainsFunc(maybeFeedEntryLinks.ToSlice(), func(entryLink *FeedEntryLink) bool {
+					return !tailCurisSet.Contains(entryLink.Curi)
+				}) {
+					logger.Info(
+						"Table of contents candidate ends with the feed: %s (%d links)",
+						linksGrouping.MaskedXPath, len(links),
+					)
+				} else {
+					continue
+				}
+			}
+
+			if bestExtraction == nil || len(links) > len(bestExtraction.Links) {
+				bestExtraction = &tableOfContentsExtraction{
+					MaskedXPath: linksGrouping.MaskedXPath,
+					Links:       links,
+				}
+			}
+		}
+	}
+
+	if bestExtraction == nil {
+		logger.Info("Table of contents not found at %s", page.FetchUri)
+		return nil, false
+	}
+	logger.Info(
+		"Table of contents at %s: %s (%d links)",
+		page.FetchUri, bestExtraction.MaskedXPath, len(bestExtraction.Links),
+	)
+	return bestExtraction, true
+}
+
+type tableOfContentsStrategy struct{}
+
+func (*tableOfContentsStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyTableOfContents
+}
+
+func (*tableOfContentsStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostFewPages
+}
+
+func (*tableOfContentsStrategy) patternPrefixes() []string {
+	return []string{"table_of_contents"}
+}
+
+func (*tableOfContentsStrategy) crawl(
+	startPage *htmlPage, maybeArchivesLink *Link, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+	logger Logger,
+) (*postprocessedResult, error) {
+	return guidedCrawlTableOfContents(startPage, maybeArchivesLink, guidedCtx, crawlCtx, logger)
+}
+
+func guidedCrawlTableOfContents(
+	startPage *htmlPage, maybeArchivesLink *Link, guidedCtx *guidedCrawlContext, crawlCtx *CrawlContext,
+	logger Logger,
+) (*postprocessedResult, error) {
+	logger.Info("Table of contents crawl start")
+	err := crawlCtx.PageMemory.unpark(startPage, logger)
+	if err != nil {
+		return nil, err
+	}
+	pages := []*htmlPage{startPage}
+	if maybeArchivesLink != nil {
+		archivesPage, err := crawlHtmlPage(maybeArchivesLink, crawlCtx, logger)
+		err2 := crawlCtx.ProgressLogger.LogAndSavePostprocessing()
+		if err2 != nil {
+			return nil, err2
+		}
+		if errors.Is(err, context.Canceled) {
+			return nil, ErrCrawlCanceled
+		} else if err != nil {
+			logger.Info("Couldn't fetch the provided archives for table of contents: %v", err)
+		} else {
+			pages = []*htmlPage{archivesPage, startPage}
+		}
+	}
+
+	for _, page := range pages {
+		extraction, ok := extractTableOfContents(
+			page, guidedCtx.FeedEntryLinks, guidedCtx.CuriEqCfg, crawlCtx.Redirects, logger,
+		)
+		if !ok {
+			continue
+		}
+
+		links := make([]*pristineMaybeTitledLink, 0, len(extraction.Links))
+		for i := len(extraction.Links) - 1; i >= 0; i-- {
+			links = append(links, NewPristineMaybeTitledLink(extraction.Links[i]))
+		}
+		pageLink, ok := ToCanonicalLink(page.FetchUri.String(), logger, nil)
+		if !ok {
+			return nil, oops.Newf("Couldn't parse table of contents url: %s", page.FetchUri)
+		}
+
+		var extra []string
+		appendLogLinef(&extra, "table_of_contents: %s", extraction.MaskedXPath)
+		logger.Info("Table of contents crawl finish: %d links", len(links))
+		return &postprocessedResult{
+			MainLnk:                 *NewPristineLink(pageLink),
+			Pattern:                 "table_of_contents",
+			Links:                   links,
+			IsMatchingFeed:          true,
+			PostCategories:          nil,
+			Extra:                   extra,
+			MaybePartialPagedResult: nil,
+		}, nil
+	}
+
+	return nil, ErrPatternNotDetected
+}
+
+type DetectedTableOfContents struct {
+	Title      string
+	LinksCount int
+}
+
+func DetectTableOfContents(startPage *DiscoveredStartPage, logger Logger) (*DetectedTableOfContents, bool) {
+	page, err := tableOfContentsPage(startPage, logger)
+	if err != nil {
+		logger.Info("Couldn't parse table of contents page: %v", err)
+		return nil, false
+	}
+	curiEqCfg := NewCanonicalEqualityConfig()
+	extraction, ok := extractTableOfContents(page, nil, &curiEqCfg, nil, logger)
+	if !ok {
+		return nil, false
+	}
+
+	title := findTitle(page.Document)
+	if title == "" {
+		title = page.FetchUri.Host
+	}
+	return &DetectedTableOfContents{
+		Title:      title,
+		LinksCount: len(extraction.Links),
+	}, true
+}
+
+// For the sites without a feed. The result is newest first, same as the other historical results.
+func CrawlTableOfContents(
+	startPage *DiscoveredStartPage, crawlCtx *CrawlContext, logger Logger,
+) (*HistoricalResult, error) {
+	page, err := tableOfContentsPage(startPage, logger)
+	if err != nil {
+		return nil, err
+	}
+	pageLink, ok := ToCanonicalLink(page.FetchUri.String(), logger, nil)
+	if !ok {
+		return nil, oops.Newf("Couldn't parse table of contents url: %s", page.FetchUri)
+	}
+	crawlCtx.RobotsClient = NewRobotsClient(page.FetchUri, crawlCtx.HttpClient, logger)
+	curiEqCfg := NewCanonicalEqualityConfig()
+	extraction, ok := extractTableOfContents(page, nil, &curiEqCfg, crawlCtx.Redirects, logger)
+	if !ok {
+		return nil, ErrPatternNotDetected
+	}
+
+	links := make([]*maybeTitledLink, 0, len(extraction.Links))
+	for i := len(extraction.Links) - 1; i >= 0; i-- {
+		links = append(links, extraction.Links[i])
+	}
+	emptyFeedEntryLinks := newFeedEntryLinks(nil)
+	emptyCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](&curiEqCfg)
+	titledLinks, err := fetchMissingTitles(
+		links, &emptyFeedEntryLinks, &emptyCurisTitlesMap, FeedGeneratorOther, &curiEqCfg, crawlCtx, logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var extra []string
+	appendLogLinef(&extra, "table_of_contents: %s", extraction.MaskedXPath)
+	return &HistoricalResult{
+		BlogLink:               *pageLink,
+		MainLink:               *pageLink,
+		Pattern:                "table_of_contents",
+		Strategy:               HistoricalStrategyTableOfContents,
+		Links:                  titledLinks,
+		DiscardedFeedEntryUrls: nil,
+		PostCategories:         nil,
+		Extra:                  extra,
+	}, nil
+}
+
+func tableOfContentsPage(startPage *DiscoveredStartPage, logger Logger) (*htmlPage, error) {
+	finalLink, ok := ToCanonicalLink(startPage.FinalUrl, logger, nil)
+	if !ok {
+		return nil, oops.Newf("Bad start page final url: %s", startPage.FinalUrl)
+	}
+	document, err := parseHtml(startPage.Content, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &htmlPage{
+		pageBase: pageBase{
+			Curi:     finalLink.Curi,
+			FetchUri: finalLink.Uri,
+			Content:  startPage.Content,
+		},
+		Document:              document,
+		MaybeTopScreenshot:    nil,
+		MaybeBottomScreenshot: nil,
+	}, nil
+}
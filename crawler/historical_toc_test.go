@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractTableOfContents(t *testing.T) {
+	var chapterUrls []string
+	var sb strings.Builder
+	sb.WriteString(`<html><body>
+		<nav><a href="/">Home</a><a href="/about">About</a><a href="/support">Support</a></nav>
+		<ul class="toc">`)
+	for i := 1; i <= 12; i++ {
+		url := fmt.Sprintf("https://serial.com/chapter-%d", i)
+		chapterUrls = append(chapterUrls, url)
+		fmt.Fprintf(&sb, `<li><a href="%s">Chapter %d</a></li>`, url, i)
+	}
+	sb.WriteString(`</ul>
+		<footer><a href="/contact">Contact</a></footer>
+		</body></html>`)
+	content := sb.String()
+
+	buildFeed := func(urls ...string) string {
+		var feedSb strings.Builder
+		feedSb.WriteString("<rss><channel>")
+		for _, url := range urls {
+			fmt.Fprintf(&feedSb, "<item><link>%s</link></item>", url)
+		}
+		feedSb.WriteString("</channel></rss>")
+		return feedSb.String()
+	}
+
+	type Test struct {
+		description  string
+		maybeFeed    *string
+		expectedOk   bool
+		expectedUrls []string
+	}
+
+	latestFeed := buildFeed(chapterUrls[11], chapterUrls[10], chapterUrls[9])
+	earliestFeed := buildFeed(chapterUrls[2], chapterUrls[1], chapterUrls[0])
+	tests := []Test{
+		{
+			description:  "without feed",
+			maybeFeed:    nil,
+			expectedOk:   true,
+			expectedUrls: chapterUrls,
+		},
+		{
+			description:  "with feed at the end",
+			maybeFeed:    &latestFeed,
+			expectedOk:   true,
+			expectedUrls: chapterUrls,
+		},
+		{
+			description:  "with feed at the start",
+			maybeFeed:    &earliestFeed,
+			expectedOk:   false,
+			expectedUrls: nil,
+		},
+	}
+
+	logger := NewDummyLogger()
+	for _, tc := range tests {
+		page, err := tableOfContentsPage(&DiscoveredStartPage{
+			Url:      "https://serial.com/toc",
+			FinalUrl: "https://serial.com/toc",
+			Content:  content,
+		}, logger)
+		require.NoError(t, err, tc.description)
+
+		var maybeFeedEntryLinks *FeedEntryLinks
+		if tc.maybeFeed != nil {
+			feedUri, err := neturl.Parse("https://serial.com/feed")
+			require.NoError(t, err, tc.description)
+			parsedFeed, err := ParseFeed(*tc.maybeFeed, feedUri, logger)
+			require.NoError(t, err, tc.description)
+			maybeFeedEntryLinks = &parsedFeed.EntryLinks
+		}
+
+		curiEqCfg := NewCanonicalEqualityConfig()
+		extraction, ok := extractTableOfContents(page, maybeFeedEntryLinks, &curiEqCfg, nil, logger)
+		require.Equal(t, tc.expectedOk, ok, tc.description)
+		if !ok {
+			continue
+		}
+		var urls []string
+		for _, link := range extraction.Links {
+			urls = append(urls, link.Url)
+		}
+		require.Equal(t, tc.expectedUrls, urls, tc.description)
+		if tc.maybeFeed == nil {
+			require.NotNil(t, extraction.Links[0].MaybeTitle, tc.description)
+			require.Equal(t, "Chapter 1", extraction.Links[0].MaybeTitle.Value, tc.description)
+		}
+	}
+}
@@ -14,6 +14,47 @@ import (
 	"github.com/goccy/go-json"
 )
 
+type tumblrApiStrategy struct{}
+
+func (*tumblrApiStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyTumblrApi
+}
+
+func (*tumblrApiStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostAllPages
+}
+
+func (*tumblrApiStrategy) patternPrefixes() []string {
+	return []string{"tumblr"}
+}
+
+// Takes every Tumblr feed, there is no guided crawl to fall back to if the api fails
+func (s *tumblrApiStrategy) tryFeed(
+	feed *historicalFeed, crawlCtx *CrawlContext, logger Logger,
+) (*feedHistoricalResult, error) {
+	parsedFeed := feed.ParsedFeed
+	if parsedFeed.Generator != FeedGeneratorTumblr {
+		return nil, nil
+	}
+
+	postprocessedResult, err := getTumblrApiHistorical(parsedFeed.RootLink.Uri.Hostname(), crawlCtx, logger)
+	if err != nil {
+		return &feedHistoricalResult{
+			MaybeResult: nil,
+			Links:       nil,
+			Error:       err,
+		}, nil
+	}
+	historicalResult, links := newPostprocessedHistoricalResult(
+		postprocessedResult, s.Name(), parsedFeed, feed.CuriEqCfg,
+	)
+	return &feedHistoricalResult{
+		MaybeResult: historicalResult,
+		Links:       links,
+		Error:       nil,
+	}, nil
+}
+
 func getTumblrApiHistorical(
 	hostname string, crawlCtx *CrawlContext, logger Logger,
 ) (*postprocessedResult, error) {
@@ -2,6 +2,7 @@ package crawler
 
 import (
 	"fmt"
+	"sync"
 
 	"feedrewind.com/log"
 
@@ -42,6 +43,7 @@ func (l *ZeroLogger) Screenshot(url string, source string, data []byte) {
 
 type DummyLogger struct {
 	entries []logEntry
+	mutex   sync.Mutex
 }
 
 type logLevel int
@@ -61,6 +63,7 @@ type logEntry struct {
 func NewDummyLogger() *DummyLogger {
 	return &DummyLogger{
 		entries: nil,
+		mutex:   sync.Mutex{},
 	}
 }
 
@@ -77,6 +80,8 @@ func (d *DummyLogger) Error(format string, args ...any) {
 }
 
 func (d *DummyLogger) log(level logLevel, format string, args ...any) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	d.entries = append(d.entries, logEntry{
 		Level:  level,
 		Format: format,
@@ -0,0 +1,212 @@
+package crawler
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"feedrewind.com/oops"
+)
+
+// A guided crawl of a huge blog can keep hundreds of page 1 candidates around until postprocessing, and
+// each one used to hold on to its parsed document. Candidates are now compacted once their links and xpath
+// groupings are extracted. The few pages that are still needed later (like the start page, which only
+// comes back for the table of contents) are parked against a per-crawl budget, and the oldest ones are
+// spilled to temp files and parsed again when they're unparked.
+
+const DefaultPageMemoryBudget = 64 * 1024 * 1024
+
+// Rough size of the parsed document relative to the html
+const documentSizeFactor = 4
+
+type PageMemory struct {
+	Budget            int64 // bytes, 0 for unlimited
+	KeepParsedPages   bool  // disables compaction, only for comparing memory usage
+	ParkedBytes       int64
+	PeakParkedBytes   int64
+	SpilledPagesCount int
+	parkedPages       []*parkedPage // oldest first
+}
+
+type parkedPage struct {
+	Page           *htmlPage
+	Size           int64
+	MaybeSpillPath *string
+}
+
+func NewPageMemory(budget int64) *PageMemory {
+	return &PageMemory{
+		Budget:            budget,
+		KeepParsedPages:   false,
+		ParkedBytes:       0,
+		PeakParkedBytes:   0,
+		SpilledPagesCount: 0,
+		parkedPages:       nil,
+	}
+}
+
+func estimatePageSize(page *htmlPage) int64 {
+	return int64(len(page.Content))*(1+documentSizeFactor) +
+		int64(len(page.MaybeTopScreenshot)+len(page.MaybeBottomScreenshot))
+}
+
+// The page shouldn't be used until it is unparked
+func (m *PageMemory) park(page *htmlPage, logger Logger) {
+	if slices.ContainsFunc(m.parkedPages, func(parked *parkedPage) bool {
+		return parked.Page == page
+	}) {
+		return
+	}
+
+	size := estimatePageSize(page)
+	m.parkedPages = append(m.parkedPages, &parkedPage{
+		Page:           page,
+		Size:           size,
+		MaybeSpillPath: nil,
+	})
+	m.ParkedBytes += size
+	m.PeakParkedBytes = max(m.PeakParkedBytes, m.ParkedBytes)
+
+	for _, parked := range m.parkedPages {
+		if m.Budget == 0 || m.ParkedBytes <= m.Budget {
+			break
+		}
+		if parked.MaybeSpillPath != nil {
+			continue
+		}
+
+		err := spillPage(parked, logger)
+		if err != nil {
+			// Going over the budget is better than failing the crawl
+			logger.Warn("Couldn't spill page %s: %v", parked.Page.FetchUri, err)
+			break
+		}
+		m.ParkedBytes -= parked.Size
+		m.SpilledPagesCount++
+	}
+}
+
+func spillPage(parked *parkedPage, logger Logger) error {
+	file, err := os.CreateTemp("", "feedrewind_page_*.html")
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	_, err = file.WriteString(parked.Page.Content)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return oops.Wrap(err)
+	}
+	err = file.Close()
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return oops.Wrap(err)
+	}
+
+	spillPath := file.Name()
+	parked.MaybeSpillPath = &spillPath
+	parked.Page.Content = ""
+	parked.Page.Document = nil
+	parked.Page.MaybeTopScreenshot = nil
+	parked.Page.MaybeBottomScreenshot = nil
+	logger.Info("Spilled page %s (%d bytes)", parked.Page.FetchUri, parked.Size)
+	return nil
+}
+
+// Unparking a page that isn't parked is a no-op
+func (m *PageMemory) unpark(page *htmlPage, logger Logger) error {
+	parkedIdx := slices.IndexFunc(m.parkedPages, func(parked *parkedPage) bool {
+		return parked.Page == page
+	})
+	if parkedIdx == -1 {
+		return nil
+	}
+	parked := m.parkedPages[parkedIdx]
+	m.parkedPages = slices.Delete(m.parkedPages, parkedIdx, parkedIdx+1)
+	if parked.MaybeSpillPath == nil {
+		m.ParkedBytes -= parked.Size
+		return nil
+	}
+
+	contentBytes, err := os.ReadFile(*parked.MaybeSpillPath)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	err = os.Remove(*parked.MaybeSpillPath)
+	if err != nil {
+		logger.Warn("Couldn't remove spilled page: %v", err)
+	}
+	content := string(contentBytes)
+	document, err := parseHtml(content, logger)
+	if err != nil {
+		return err
+	}
+	page.Content = content
+	page.Document = document
+	logger.Info("Restored spilled page %s", page.FetchUri)
+	return nil
+}
+
+// Removes the spill files of the pages that were never unparked
+func (m *PageMemory) release(logger Logger) {
+	for _, parked := range m.parkedPages {
+		if parked.MaybeSpillPath == nil {
+			continue
+		}
+		err := os.Remove(*parked.MaybeSpillPath)
+		if err != nil {
+			logger.Warn("Couldn't remove spilled page: %v", err)
+		}
+	}
+	m.parkedPages = nil
+	m.ParkedBytes = 0
+}
+
+// Page 1 results only need the links and the location of page 1 going forward, and dropping the elements
+// lets the document be collected
+func (m *PageMemory) compactResult(result crawlHistoricalResult) {
+	if m.KeepParsedPages {
+		return
+	}
+	page1Result, ok := result.(*page1Result)
+	if !ok {
+		return
+	}
+
+	pagedState := &page1Result.PagedState
+	pagedState.Page1 = compactHtmlPage(pagedState.Page1)
+	pagedState.Page1Links = compactXPathLinks(pagedState.Page1Links)
+	for i := range pagedState.Page1Extractions {
+		extraction := &pagedState.Page1Extractions[i]
+		for j, link := range extraction.Links {
+			extraction.Links[j] = NewPristineMaybeTitledLink(link).Unwrap()
+		}
+	}
+	page1Result.LinkToPage2 = *NewPristineLink(&page1Result.LinkToPage2).Unwrap()
+}
+
+func compactHtmlPage(page *htmlPage) *htmlPage {
+	return &htmlPage{
+		pageBase: pageBase{
+			Curi:     NewPristineCanonicalUri(page.Curi).Curi,
+			FetchUri: &NewPristineUri(page.FetchUri).Uri,
+			Content:  "",
+		},
+		Document:              nil,
+		MaybeTopScreenshot:    nil,
+		MaybeBottomScreenshot: nil,
+	}
+}
+
+func compactXPathLinks(links []*xpathLink) []*xpathLink {
+	result := make([]*xpathLink, len(links))
+	for i, link := range links {
+		result[i] = &xpathLink{
+			Link:       *NewPristineLink(&link.Link).Unwrap(),
+			Element:    nil,
+			XPath:      strings.Clone(link.XPath),
+			ClassXPath: strings.Clone(link.ClassXPath),
+		}
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package crawler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageMemoryPark(t *testing.T) {
+	logger := NewDummyLogger()
+	newPage := func(url string, linksCount int) *htmlPage {
+		var sb strings.Builder
+		sb.WriteString("<html><body>")
+		for i := range linksCount {
+			fmt.Fprintf(&sb, `<a href="/post-%d">Post %d</a>`, i, i)
+		}
+		sb.WriteString("</body></html>")
+		content := sb.String()
+		link, ok := ToCanonicalLink(url, logger, nil)
+		require.True(t, ok)
+		document, err := parseHtml(content, logger)
+		require.NoError(t, err)
+		return &htmlPage{
+			pageBase: pageBase{
+				Curi:     link.Curi,
+				FetchUri: link.Uri,
+				Content:  content,
+			},
+			Document:              document,
+			MaybeTopScreenshot:    nil,
+			MaybeBottomScreenshot: nil,
+		}
+	}
+
+	type Test struct {
+		description         string
+		budget              int64
+		expectedSpilledUrls []string
+	}
+
+	tests := []Test{
+		{
+			description:         "unlimited",
+			budget:              0,
+			expectedSpilledUrls: nil,
+		},
+		{
+			description:         "fits",
+			budget:              1024 * 1024,
+			expectedSpilledUrls: nil,
+		},
+		{
+			description:         "spills the oldest page",
+			budget:              estimatePageSize(newPage("https://blog.com/", 100)) + 1000,
+			expectedSpilledUrls: []string{"https://blog.com/"},
+		},
+		{
+			description:         "spills everything",
+			budget:              1,
+			expectedSpilledUrls: []string{"https://blog.com/", "https://blog.com/archives"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			pageMemory := NewPageMemory(tc.budget)
+			startPage := newPage("https://blog.com/", 100)
+			archivesPage := newPage("https://blog.com/archives", 50)
+			startContent := startPage.Content
+			archivesContent := archivesPage.Content
+			totalSize := estimatePageSize(startPage) + estimatePageSize(archivesPage)
+			pageMemory.park(startPage, logger)
+			pageMemory.park(archivesPage, logger)
+			pageMemory.park(archivesPage, logger)
+
+			var spilledUrls []string
+			var spillPaths []string
+			for _, parked := range pageMemory.parkedPages {
+				if parked.MaybeSpillPath != nil {
+					spilledUrls = append(spilledUrls, parked.Page.FetchUri.String())
+					spillPaths = append(spillPaths, *parked.MaybeSpillPath)
+					require.Nil(t, parked.Page.Document)
+					require.Empty(t, parked.Page.Content)
+				}
+			}
+			require.Equal(t, tc.expectedSpilledUrls, spilledUrls)
+			require.Equal(t, len(tc.expectedSpilledUrls), pageMemory.SpilledPagesCount)
+			require.LessOrEqual(t, pageMemory.PeakParkedBytes, totalSize)
+			if tc.budget > 0 {
+				require.LessOrEqual(t, pageMemory.ParkedBytes, tc.budget)
+			}
+
+			err := pageMemory.unpark(startPage, logger)
+			require.NoError(t, err)
+			require.Equal(t, startContent, startPage.Content)
+			require.NotNil(t, startPage.Document)
+			startLinks := extractLinks(
+				startPage.Document, startPage.FetchUri, nil, nil, logger, includeXPathNone,
+			)
+			require.Len(t, startLinks, 100)
+
+			err = pageMemory.unpark(archivesPage, logger)
+			require.NoError(t, err)
+			require.Equal(t, archivesContent, archivesPage.Content)
+			require.Empty(t, pageMemory.parkedPages)
+			require.Zero(t, pageMemory.ParkedBytes)
+			for _, spillPath := range spillPaths {
+				_, err := os.Stat(spillPath)
+				require.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+}
+
+func TestPageMemoryRelease(t *testing.T) {
+	logger := NewDummyLogger()
+	link, ok := ToCanonicalLink("https://blog.com/", logger, nil)
+	require.True(t, ok)
+	content := `<html><body><a href="/post">Post</a></body></html>`
+	document, err := parseHtml(content, logger)
+	require.NoError(t, err)
+	page := &htmlPage{
+		pageBase: pageBase{
+			Curi:     link.Curi,
+			FetchUri: link.Uri,
+			Content:  content,
+		},
+		Document:              document,
+		MaybeTopScreenshot:    nil,
+		MaybeBottomScreenshot: nil,
+	}
+
+	pageMemory := NewPageMemory(1)
+	pageMemory.park(page, logger)
+	require.Len(t, pageMemory.parkedPages, 1)
+	spillPath := pageMemory.parkedPages[0].MaybeSpillPath
+	require.NotNil(t, spillPath)
+
+	pageMemory.release(logger)
+	_, err = os.Stat(*spillPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Empty(t, pageMemory.parkedPages)
+}
+
+func TestCompactXPathLinks(t *testing.T) {
+	logger := NewDummyLogger()
+	link, ok := ToCanonicalLink("https://blog.com/page/1", logger, nil)
+	require.True(t, ok)
+	document, err := parseHtml(`<html><body><a href="/page/2">Next</a></body></html>`, logger)
+	require.NoError(t, err)
+	links := extractLinks(document, link.Uri, nil, nil, logger, includeXPathAndClassXPath)
+	require.Len(t, links, 1)
+
+	compactLinks := compactXPathLinks(links)
+	require.Len(t, compactLinks, 1)
+	require.Nil(t, compactLinks[0].Element)
+	require.Equal(t, links[0].Url, compactLinks[0].Url)
+	require.Equal(t, links[0].Curi, compactLinks[0].Curi)
+	require.Equal(t, links[0].XPath, compactLinks[0].XPath)
+	require.Equal(t, links[0].ClassXPath, compactLinks[0].ClassXPath)
+
+	pattern := pagingPatternPathTemplate{
+		Host:       "blog.com",
+		PathPrefix: "/page/",
+		PathSuffix: "",
+		IsCertain:  true,
+	}
+	require.Len(t, pattern.FindLinksToNextPage(nil, compactLinks, 2), 1)
+}
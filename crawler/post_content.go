@@ -0,0 +1,189 @@
+package crawler
+
+import (
+	"html"
+	neturl "net/url"
+	"slices"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// Feed content ends up in the feeds we publish, so only the formatting tags are kept and everything that
+// could run code or load something other than an image is dropped. Relative urls are resolved against the
+// post url, as readers would resolve them against our feed instead.
+
+const maxPostContentLength = 200_000
+
+var allowedPostContentAttrs = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          nil,
+	"blockquote": nil,
+	"br":         nil,
+	"code":       nil,
+	"dd":         nil,
+	"del":        nil,
+	"div":        nil,
+	"dl":         nil,
+	"dt":         nil,
+	"em":         nil,
+	"figcaption": nil,
+	"figure":     nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"i":          nil,
+	"img":        {"src", "alt", "title", "width", "height"},
+	"ins":        nil,
+	"li":         nil,
+	"ol":         nil,
+	"p":          nil,
+	"pre":        nil,
+	"s":          nil,
+	"span":       nil,
+	"strong":     nil,
+	"sub":        nil,
+	"sup":        nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"colspan", "rowspan"},
+	"tfoot":      nil,
+	"th":         {"colspan", "rowspan"},
+	"thead":      nil,
+	"tr":         nil,
+	"u":          nil,
+	"ul":         nil,
+}
+
+// The text inside these is dropped too
+var droppedPostContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"form":     true,
+	"noscript": true,
+	"svg":      true,
+	"math":     true,
+	"template": true,
+	"title":    true,
+}
+
+var postContentUrlAttrs = map[string]bool{
+	"href": true,
+	"src":  true,
+}
+
+// Returns false if nothing is left after sanitizing or the content is too long to publish
+func SanitizePostContent(content string, postUri *neturl.URL) (string, bool) {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(content))
+	var sb strings.Builder
+	droppedDepth := 0
+	hasText := false
+	hasImage := false
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		switch tokenType {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if droppedPostContentTags[token.Data] {
+				if tokenType == nethtml.StartTagToken {
+					droppedDepth++
+				}
+				continue
+			}
+			allowedAttrs, ok := allowedPostContentAttrs[token.Data]
+			if !ok || droppedDepth > 0 {
+				continue
+			}
+			if token.Data == "img" {
+				hasImage = true
+			}
+			sb.WriteString("<")
+			sb.WriteString(token.Data)
+			for _, attr := range token.Attr {
+				if attr.Namespace != "" || !slices.Contains(allowedAttrs, attr.Key) {
+					continue
+				}
+				value := attr.Val
+				if postContentUrlAttrs[attr.Key] {
+					var ok bool
+					value, ok = sanitizePostContentUrl(value, postUri)
+					if !ok {
+						continue
+					}
+				}
+				sb.WriteString(" ")
+				sb.WriteString(attr.Key)
+				sb.WriteString(`="`)
+				sb.WriteString(html.EscapeString(value))
+				sb.WriteString(`"`)
+			}
+			sb.WriteString(">")
+		case nethtml.EndTagToken:
+			if droppedPostContentTags[token.Data] {
+				if droppedDepth > 0 {
+					droppedDepth--
+				}
+				continue
+			}
+			if _, ok := allowedPostContentAttrs[token.Data]; !ok || droppedDepth > 0 {
+				continue
+			}
+			if token.Data == "br" || token.Data == "hr" || token.Data == "img" {
+				continue
+			}
+			sb.WriteString("</")
+			sb.WriteString(token.Data)
+			sb.WriteString(">")
+		case nethtml.TextToken:
+			if droppedDepth > 0 {
+				continue
+			}
+			if strings.TrimSpace(token.Data) != "" {
+				hasText = true
+			}
+			sb.WriteString(html.EscapeString(token.Data))
+		default:
+			// Comments and doctypes
+		}
+	}
+
+	result := strings.TrimSpace(sb.String())
+	if !hasText && !hasImage {
+		return "", false
+	}
+	if len(result) > maxPostContentLength {
+		return "", false
+	}
+	return result, true
+}
+
+func sanitizePostContentUrl(value string, postUri *neturl.URL) (string, bool) {
+	uri, err := neturl.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", false
+	}
+	if postUri != nil {
+		uri = postUri.ResolveReference(uri)
+	}
+	switch strings.ToLower(uri.Scheme) {
+	case "http", "https", "mailto":
+		return uri.String(), true
+	case "":
+		if postUri == nil {
+			return uri.String(), true
+		}
+		return "", false
+	default:
+		return "", false
+	}
+}
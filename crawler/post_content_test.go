@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	neturl "net/url"
+	"testing"
+
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizePostContent(t *testing.T) {
+	type Test struct {
+		description     string
+		content         string
+		expectedContent string
+		expectedOk      bool
+	}
+
+	tests := []Test{
+		{
+			description:     "keep formatting",
+			content:         `<p>Hello <em>world</em><br/>again</p>`,
+			expectedContent: `<p>Hello <em>world</em><br>again</p>`,
+			expectedOk:      true,
+		},
+		{
+			description:     "drop scripts with their text",
+			content:         `<p>Hello</p><script>alert("hi")</script><style>p {}</style>`,
+			expectedContent: `<p>Hello</p>`,
+			expectedOk:      true,
+		},
+		{
+			description:     "drop unknown tags but keep their text",
+			content:         `<section><custom-tag>Hello</custom-tag></section>`,
+			expectedContent: `Hello`,
+			expectedOk:      true,
+		},
+		{
+			description:     "drop event handlers and styles",
+			content:         `<p onclick="alert(1)" style="color: red" class="x">Hello</p>`,
+			expectedContent: `<p>Hello</p>`,
+			expectedOk:      true,
+		},
+		{
+			description:     "resolve relative urls against the post",
+			content:         `<a href="../about">About</a><img src="/img.png" alt="Image">`,
+			expectedContent: `<a href="https://blog/about">About</a><img src="https://blog/img.png" alt="Image">`,
+			expectedOk:      true,
+		},
+		{
+			description:     "drop javascript urls",
+			content:         `<a href="javascript:alert(1)">Hello</a>`,
+			expectedContent: `<a>Hello</a>`,
+			expectedOk:      true,
+		},
+		{
+			description:     "escape text",
+			content:         `1 &lt; 2 &amp;&amp; <b>"quoted"</b>`,
+			expectedContent: `1 &lt; 2 &amp;&amp; <b>&#34;quoted&#34;</b>`,
+			expectedOk:      true,
+		},
+		{
+			description:     "keep image only content",
+			content:         `<img src="https://blog/comic.png">`,
+			expectedContent: `<img src="https://blog/comic.png">`,
+			expectedOk:      true,
+		},
+		{
+			description:     "reject empty content",
+			content:         `<div> <iframe src="https://video"></iframe> </div>`,
+			expectedContent: "",
+			expectedOk:      false,
+		},
+	}
+
+	postUri, err := neturl.Parse("https://blog/posts/post")
+	oops.RequireNoError(t, err)
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			content, ok := SanitizePostContent(tc.content, postUri)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedContent, content)
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"slices"
+)
+
+// When a blog is crawled again (after the crawler version is bumped or the blog is downgraded), the previous
+// version usually still has the older posts right. The previous winning strategy is checked first: archives
+// and paged results have to come out of the same main page again and continue into the previous posts, so
+// that only the posts newer than the last known one are taken from the page. Other strategies are checked
+// against the feed. If the check fails, the caller falls back to the full crawl.
+
+type RecrawlHint struct {
+	Pattern    string // empty if the previous version wasn't crawled
+	BlogUrl    string
+	Posts      []RecrawlHintPost // newest first
+	Categories []RecrawlHintCategory
+}
+
+type RecrawlHintPost struct {
+	Url   string
+	Title string
+}
+
+type RecrawlHintCategory struct {
+	Name     string
+	IsTop    bool
+	PostUrls []string
+}
+
+var errRecrawlNotValidated = errors.New("recrawl not validated")
+
+type recrawlStrategy struct{}
+
+func (*recrawlStrategy) Name() HistoricalStrategyName {
+	return HistoricalStrategyRecrawl
+}
+
+func (*recrawlStrategy) Cost() HistoricalStrategyCost {
+	return HistoricalStrategyCostNone
+}
+
+func (*recrawlStrategy) patternPrefixes() []string {
+	return nil
+}
+
+func (s *recrawlStrategy) tryFeed(
+	feed *historicalFeed, crawlCtx *CrawlContext, logger Logger,
+) (*feedHistoricalResult, error) {
+	if feed.MaybeRecrawlHint == nil {
+		return nil, nil
+	}
+
+	postprocessedResult, err := guidedRecrawl(
+		feed.MaybeRecrawlHint, feed.ParsedFeed, feed.FeedEntryCurisTitlesMap, crawlCtx, feed.CuriEqCfg,
+		logger,
+	)
+	if errors.Is(err, ErrCrawlCanceled) {
+		return nil, err
+	} else if err != nil {
+		logger.Info("Recrawl failed, falling back to full crawl")
+		return nil, nil
+	}
+	historicalResult, links := newPostprocessedHistoricalResult(
+		postprocessedResult, s.Name(), feed.ParsedFeed, feed.CuriEqCfg,
+	)
+	return &feedHistoricalResult{
+		MaybeResult: historicalResult,
+		Links:       links,
+		Error:       nil,
+	}, nil
+}
+
+func guidedRecrawl(
+	hint *RecrawlHint, parsedFeed *ParsedFeed, feedEntryCurisTitlesMap CanonicalUriMap[MaybeLinkTitle],
+	crawlCtx *CrawlContext, curiEqCfg *CanonicalEqualityConfig, logger Logger,
+) (*postprocessedResult, error) {
+	logger.Info(
+		"Recrawl start: pattern %q at %s with %d previous posts", hint.Pattern, hint.BlogUrl, len(hint.Posts),
+	)
+	if len(hint.Posts) == 0 {
+		logger.Info("Recrawl not validated: no previous posts")
+		return nil, errRecrawlNotValidated
+	}
+	blogLink, ok := ToCanonicalLink(hint.BlogUrl, logger, nil)
+	if !ok {
+		logger.Info("Recrawl not validated: couldn't parse blog url")
+		return nil, errRecrawlNotValidated
+	}
+	previousLinks := make([]*maybeTitledLink, len(hint.Posts))
+	for i, post := range hint.Posts {
+		link, ok := ToCanonicalLink(post.Url, logger, nil)
+		if !ok {
+			logger.Info("Recrawl not validated: couldn't parse previous post url %s", post.Url)
+			return nil, errRecrawlNotValidated
+		}
+		title := NewLinkTitle(post.Title, LinkTitleSourcePrevious, nil)
+		previousLinks[i] = &maybeTitledLink{
+			Link:       *link,
+			MaybeTitle: &title,
+		}
+	}
+
+	var newLinks []*maybeTitledLink
+	var source string
+	if isRecrawlMainPagePattern(hint.Pattern) {
+		var err error
+		newLinks, err = recrawlMainPage(
+			hint.Pattern, blogLink, previousLinks, parsedFeed, feedEntryCurisTitlesMap, crawlCtx, curiEqCfg,
+			logger,
+		)
+		if err != nil {
+			return nil, err
+		}
+		source = "main page"
+	} else {
+		newLinks, ok = mergeWithPreviousLinks(parsedFeed.EntryLinks.ToMaybeTitledSlice(), previousLinks, curiEqCfg)
+		if !ok {
+			logger.Info("Recrawl not validated: feed doesn't continue into the previous posts")
+			return nil, errRecrawlNotValidated
+		}
+		source = "feed"
+	}
+
+	links := slices.Concat(newLinks, previousLinks)
+	if _, ok := parsedFeed.EntryLinks.sequenceMatch(ToCanonicalUris(links), curiEqCfg); !ok {
+		logger.Info("Recrawl not validated: doesn't match feed")
+		return nil, errRecrawlNotValidated
+	}
+
+	var postCategories []pristineHistoricalBlogPostCategory
+	for _, category := range hint.Categories {
+		var postLinks []Link
+		for _, postUrl := range category.PostUrls {
+			if postLink, ok := ToCanonicalLink(postUrl, logger, nil); ok {
+				postLinks = append(postLinks, *postLink)
+			}
+		}
+		postCategories = append(
+			postCategories, NewPristineHistoricalBlogPostCategory(category.Name, category.IsTop, postLinks),
+		)
+	}
+
+	pattern := hint.Pattern
+	if pattern == "" {
+		pattern = "previous_version"
+	}
+	var extra []string
+	appendLogLinef(&extra, "recrawl: %d new from %s, %d previous", len(newLinks), source, len(previousLinks))
+	if len(postCategories) > 0 {
+		appendLogLinef(&extra, "categories from previous: %s", categoryCountsString(postCategories))
+	}
+	logger.Info("Recrawl finish: %d new posts from %s", len(newLinks), source)
+	return &postprocessedResult{
+		MainLnk:                 *NewPristineLink(blogLink),
+		Pattern:                 pattern,
+		Links:                   NewPristineMaybeTitledLinks(links),
+		IsMatchingFeed:          true,
+		PostCategories:          postCategories,
+		Extra:                   extra,
+		MaybePartialPagedResult: nil,
+	}, nil
+}
+
+func isRecrawlMainPagePattern(pattern string) bool {
+	switch historicalStrategyForPattern(pattern) {
+	case HistoricalStrategyArchives, HistoricalStrategyArchivesShuffled, HistoricalStrategyArchivesCategories,
+		HistoricalStrategyPaged:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRecrawlPagedPattern(pattern string) bool {
+	return historicalStrategyForPattern(pattern) == HistoricalStrategyPaged
+}
+
+// Only the main page is fetched. Paged blogs that have more new posts than fit on page 1 get a full crawl.
+func recrawlMainPage(
+	pattern string, mainLink *Link, previousLinks []*maybeTitledLink, parsedFeed *ParsedFeed,
+	feedEntryCurisTitlesMap CanonicalUriMap[MaybeLinkTitle], crawlCtx *CrawlContext,
+	curiEqCfg *CanonicalEqualityConfig, logger Logger,
+) ([]*maybeTitledLink, error) {
+	page, err := crawlHtmlPage(mainLink, crawlCtx, logger)
+	err2 := crawlCtx.ProgressLogger.SaveStatus()
+	if err2 != nil {
+		return nil, err2
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil, ErrCrawlCanceled
+	} else if err != nil {
+		logger.Info("Recrawl not validated: couldn't fetch main page (%v)", err)
+		return nil, errRecrawlNotValidated
+	}
+
+	allowedHosts := curiEqCfg.SameHosts
+	if len(allowedHosts) == 0 {
+		allowedHosts = map[string]bool{page.FetchUri.Host: true}
+	}
+	var feedRootLinkCuri CanonicalUri
+	if parsedFeed.RootLink != nil {
+		feedRootLinkCuri = parsedFeed.RootLink.Curi
+	}
+	archivesCategoriesState := newArchivesCategoriesState(mainLink)
+	guidedCtx := guidedCrawlContext{
+		SeenCurisSet:                newGuidedSeenCurisSet(curiEqCfg),
+		ArchivesCategoriesState:     &archivesCategoriesState,
+		FeedEntryLinks:              &parsedFeed.EntryLinks,
+		FeedEntryCurisTitlesMap:     feedEntryCurisTitlesMap,
+		FeedGenerator:               parsedFeed.Generator,
+		FeedRootLinkCuri:            feedRootLinkCuri,
+		CuriEqCfg:                   curiEqCfg,
+		AllowedHosts:                allowedHosts,
+		Strategies:                  crawlCtx.HistoricalStrategies,
+		Hardcoded:                   crawlCtx.HistoricalStrategies.hardcoded(),
+		HardcodedError:              nil,
+		MaybeProvidedArchivesCuri:   nil,
+		MaybeProvidedArchivesResult: nil,
+	}
+
+	pageAllLinks := extractLinks(
+		page.Document, page.FetchUri, nil, crawlCtx.Redirects, logger, includeXPathAndClassXPath,
+	)
+	pageCurisSet := NewCanonicalUriSet(ToCanonicalUris(pageAllLinks), curiEqCfg)
+	pageResults := tryExtractHistorical(
+		NewPristineLink(mainLink), page, pageAllLinks, &pageCurisSet, &guidedCtx, logger,
+	)
+	var sortedResults []crawlHistoricalResult
+	for _, pageResult := range pageResults {
+		insertSortedResult(&sortedResults, pageResult)
+	}
+
+	for _, result := range sortedResults {
+		var candidatesLinks [][]*maybeTitledLink
+		if page1Result, ok := result.(*page1Result); ok {
+			if !isRecrawlPagedPattern(pattern) {
+				continue
+			}
+			for _, extraction := range page1Result.PagedState.Page1Extractions {
+				links := extraction.Links
+				if extraction.MaybeExtraFirstLink != nil {
+					links = slices.Concat([]*maybeTitledLink{extraction.MaybeExtraFirstLink}, links)
+				}
+				candidatesLinks = append(candidatesLinks, links)
+			}
+		} else {
+			if isRecrawlPagedPattern(pattern) {
+				continue
+			}
+			ppResult, err := postprocessResults(&[]crawlHistoricalResult{result}, &guidedCtx, crawlCtx, logger)
+			if errors.Is(err, ErrCrawlCanceled) {
+				return nil, err
+			} else if err != nil {
+				continue
+			}
+			if ppResult.Pattern != pattern {
+				logger.Info("Recrawl candidate has a different pattern: %s", ppResult.Pattern)
+				continue
+			}
+			links := make([]*maybeTitledLink, len(ppResult.Links))
+			for i, link := range ppResult.Links {
+				links[i] = link.Unwrap()
+			}
+			candidatesLinks = append(candidatesLinks, links)
+		}
+
+		for _, links := range candidatesLinks {
+			if newLinks, ok := mergeWithPreviousLinks(links, previousLinks, curiEqCfg); ok {
+				logger.Info("Recrawl validated %s on the main page", pattern)
+				return newLinks, nil
+			}
+		}
+	}
+
+	logger.Info("Recrawl not validated: %s doesn't continue into the previous posts", pattern)
+	return nil, errRecrawlNotValidated
+}
+
+// Both lists are newest first. The links have to reach the newest previous post, and everything after it
+// has to match the previous posts.
+func mergeWithPreviousLinks(
+	links []*maybeTitledLink, previousLinks []*maybeTitledLink, curiEqCfg *CanonicalEqualityConfig,
+) ([]*maybeTitledLink, bool) {
+	if len(previousLinks) == 0 {
+		return nil, false
+	}
+	newestPreviousIndex := slices.IndexFunc(links, func(link *maybeTitledLink) bool {
+		return CanonicalUriEqual(link.Curi, previousLinks[0].Curi, curiEqCfg)
+	})
+	if newestPreviousIndex == -1 {
+		return nil, false
+	}
+	overlapLinks := links[newestPreviousIndex:]
+	if len(overlapLinks) > len(previousLinks) {
+		return nil, false
+	}
+	for i, link := range overlapLinks {
+		if !CanonicalUriEqual(link.Curi, previousLinks[i].Curi, curiEqCfg) {
+			return nil, false
+		}
+	}
+
+	newLinks := links[:newestPreviousIndex]
+	previousCurisSet := NewCanonicalUriSet(ToCanonicalUris(previousLinks), curiEqCfg)
+	for _, link := range newLinks {
+		if previousCurisSet.Contains(link.Curi) {
+			return nil, false
+		}
+	}
+	return newLinks, true
+}
@@ -0,0 +1,155 @@
+package crawler
+
+import (
+	neturl "net/url"
+	"testing"
+
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeWithPreviousLinks(t *testing.T) {
+	type Test struct {
+		description         string
+		urls                []string
+		previousUrls        []string
+		expectedNewLinkUrls []string
+		expectedOk          bool
+	}
+
+	tests := []Test{
+		{
+			description:         "no new posts",
+			urls:                []string{"https://blog/post3", "https://blog/post2"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: []string{},
+			expectedOk:          true,
+		},
+		{
+			description:         "new posts",
+			urls:                []string{"https://blog/post5", "https://blog/post4", "https://blog/post3"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: []string{"https://blog/post5", "https://blog/post4"},
+			expectedOk:          true,
+		},
+		{
+			description:         "all previous posts are still listed",
+			urls:                []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			previousUrls:        []string{"https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: []string{"https://blog/post3"},
+			expectedOk:          true,
+		},
+		{
+			description:         "doesn't reach previous posts",
+			urls:                []string{"https://blog/post6", "https://blog/post5", "https://blog/post4"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: nil,
+			expectedOk:          false,
+		},
+		{
+			description:         "previous posts in a different order",
+			urls:                []string{"https://blog/post4", "https://blog/post3", "https://blog/post1"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: nil,
+			expectedOk:          false,
+		},
+		{
+			description:         "more posts than previous after the overlap",
+			urls:                []string{"https://blog/post3", "https://blog/post2", "https://blog/post0"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2"},
+			expectedNewLinkUrls: nil,
+			expectedOk:          false,
+		},
+		{
+			description:         "previous post moved to the top",
+			urls:                []string{"https://blog/post1", "https://blog/post3", "https://blog/post2"},
+			previousUrls:        []string{"https://blog/post3", "https://blog/post2", "https://blog/post1"},
+			expectedNewLinkUrls: nil,
+			expectedOk:          false,
+		},
+	}
+
+	logger := NewDummyLogger()
+	curiEqCfg := NewCanonicalEqualityConfig()
+	toLinks := func(urls []string) []*maybeTitledLink {
+		var links []*maybeTitledLink
+		for _, url := range urls {
+			link, ok := ToCanonicalLink(url, logger, nil)
+			require.True(t, ok)
+			links = append(links, &maybeTitledLink{
+				Link:       *link,
+				MaybeTitle: nil,
+			})
+		}
+		return links
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			newLinks, ok := mergeWithPreviousLinks(toLinks(tc.urls), toLinks(tc.previousUrls), &curiEqCfg)
+			require.Equal(t, tc.expectedOk, ok)
+			if !ok {
+				return
+			}
+			newUrls := []string{}
+			for _, link := range newLinks {
+				newUrls = append(newUrls, link.Url)
+			}
+			require.Equal(t, tc.expectedNewLinkUrls, newUrls)
+		})
+	}
+}
+
+func TestGuidedRecrawlFromFeed(t *testing.T) {
+	logger := NewDummyLogger()
+	curiEqCfg := NewCanonicalEqualityConfig()
+	feedUri, _ := neturl.Parse("https://blog/feed")
+	parsedFeed, err := ParseFeed(`
+		<rss><channel>
+			<item><link>https://blog/post5</link><title>Post 5</title></item>
+			<item><link>https://blog/post4</link><title>Post 4</title></item>
+			<item><link>https://blog/post3</link><title>Post 3</title></item>
+		</channel></rss>
+	`, feedUri, logger)
+	oops.RequireNoError(t, err)
+	feedEntryCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](&curiEqCfg)
+	hint := &RecrawlHint{
+		Pattern: "chain_prev",
+		BlogUrl: "https://blog/",
+		Posts: []RecrawlHintPost{
+			{Url: "https://blog/post3", Title: "Post 3"},
+			{Url: "https://blog/post2", Title: "Post 2"},
+			{Url: "https://blog/post1", Title: "Post 1"},
+		},
+		Categories: []RecrawlHintCategory{{
+			Name:     "Best",
+			IsTop:    true,
+			PostUrls: []string{"https://blog/post1"},
+		}},
+	}
+
+	result, err := guidedRecrawl(hint, parsedFeed, feedEntryCurisTitlesMap, nil, &curiEqCfg, logger)
+	oops.RequireNoError(t, err)
+	require.Equal(t, "chain_prev", result.Pattern)
+	var urls []string
+	var titleSources []linkTitleSource
+	for _, link := range result.Links {
+		unwrapped := link.Unwrap()
+		urls = append(urls, unwrapped.Url)
+		titleSources = append(titleSources, unwrapped.MaybeTitle.Source)
+	}
+	require.Equal(t, []string{
+		"https://blog/post5", "https://blog/post4", "https://blog/post3", "https://blog/post2",
+		"https://blog/post1",
+	}, urls)
+	require.Equal(t, []linkTitleSource{
+		LinkTitleSourceFeed, LinkTitleSourceFeed, LinkTitleSourcePrevious, LinkTitleSourcePrevious,
+		LinkTitleSourcePrevious,
+	}, titleSources)
+	require.Len(t, result.PostCategories, 1)
+
+	hint.Posts = hint.Posts[1:]
+	_, err = guidedRecrawl(hint, parsedFeed, feedEntryCurisTitlesMap, nil, &curiEqCfg, logger)
+	require.ErrorIs(t, err, errRecrawlNotValidated)
+}
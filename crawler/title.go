@@ -28,6 +28,8 @@ const (
 	LinkTitleSourceCollapsed   linkTitleSource = "collapsed"
 	LinkTitleSourceTumblr      linkTitleSource = "tumblr"
 	LinkTitleSourceGroundTruth linkTitleSource = "ground_truth"
+	LinkTitleSourceUser        linkTitleSource = "user"
+	LinkTitleSourcePrevious    linkTitleSource = "previous"
 )
 
 func NewLinkTitle(
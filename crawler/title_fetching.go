@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Title pages are fetched concurrently, which pays off when responses are slower than the crawl delay.
+// Request starts are still spaced by the robots crawl delay, and the number of requests in flight is
+// bounded both overall and per host.
+
+const titleFetchConcurrency = 4
+const titleFetchHostConcurrency = 2
+
+type titleFetchResult struct {
+	LinkIdx    int
+	MaybeTitle *string // nil if the page couldn't be fetched
+	Err        error   // only ErrCrawlCanceled
+	Fork       *CrawlContext
+	Duration   time.Duration
+}
+
+// onFetched is called on the calling goroutine in the order of completion. If it returns an error, the
+// remaining fetches are abandoned.
+func fetchTitlesConcurrently(
+	links []*maybeTitledLink, linkIdxs []int, feedGenerator FeedGenerator, crawlCtx *CrawlContext,
+	logger Logger, onFetched func(result *titleFetchResult) error,
+) error {
+	hostSemaphores := make(map[string]chan struct{})
+	for _, linkIdx := range linkIdxs {
+		host := links[linkIdx].Uri.Host
+		if _, ok := hostSemaphores[host]; !ok {
+			hostSemaphores[host] = make(chan struct{}, titleFetchHostConcurrency)
+		}
+	}
+
+	jobs := make(chan int)
+	results := make(chan *titleFetchResult, len(linkIdxs))
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for _, linkIdx := range linkIdxs {
+			select {
+			case jobs <- linkIdx:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	workersCount := min(titleFetchConcurrency, len(linkIdxs))
+	for range workersCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for linkIdx := range jobs {
+				link := links[linkIdx]
+				hostSemaphore := hostSemaphores[link.Uri.Host]
+				select {
+				case hostSemaphore <- struct{}{}:
+				case <-stop:
+					return
+				}
+
+				fork := crawlCtx.fork()
+				fetchStart := time.Now()
+				result := &titleFetchResult{
+					LinkIdx:    linkIdx,
+					MaybeTitle: nil,
+					Err:        nil,
+					Fork:       fork,
+					Duration:   0,
+				}
+				// Always making a request may produce some duplicate requests, but hopefully not too many
+				page, err := crawlHtmlPage(&link.Link, fork, logger)
+				result.Duration = time.Since(fetchStart)
+				<-hostSemaphore
+				if errors.Is(err, ErrCrawlCanceled) {
+					result.Err = err
+				} else if err != nil {
+					logger.Info("Couldn't fetch link title, going with url: %s (%v)", link.Url, err)
+				} else {
+					pageTitle := strings.Clone(getPageTitle(page, feedGenerator, logger))
+					result.MaybeTitle = &pageTitle
+				}
+				results <- result
+				if result.Err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	for range linkIdxs {
+		result := <-results
+		crawlCtx.join(result.Fork)
+		if result.Err != nil {
+			return result.Err
+		}
+		err := onFetched(result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var titleWords = map[string]string{
+	"post0": "Alpha", "post1": "Bravo", "post2": "Charlie", "post3": "Delta", "post4": "Echo",
+	"post6": "Golf", "post7": "Hotel", "post8": "India", "post9": "Juliett", "post10": "Kilo",
+	"post11": "Lima",
+}
+
+type titlesHttpClient struct {
+	mutex       sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *titlesHttpClient) Request(
+	uri *url.URL, shouldThrottle bool, maybeRobotsClient *RobotsClient, logger Logger,
+) (*HttpResponse, error) {
+	c.mutex.Lock()
+	c.inFlight++
+	c.maxInFlight = max(c.maxInFlight, c.inFlight)
+	c.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mutex.Lock()
+	c.inFlight--
+	c.mutex.Unlock()
+
+	if uri.Path == "/broken" {
+		return newHttpResponse("404"), nil
+	}
+	contentType := "text/html"
+	body := fmt.Sprintf(
+		"<html><head><title>%s | Blog</title></head><body></body></html>", titleWords[uri.Path[1:]],
+	)
+	return &HttpResponse{
+		Code:             "200",
+		MaybeContentType: &contentType,
+		MaybeLocation:    nil,
+		Body:             []byte(body),
+	}, nil
+}
+
+func (c *titlesHttpClient) GetRetryDelay(attemptsMade int) float64 {
+	return 0
+}
+
+type countsProgressSaver struct {
+	MockProgressSaver
+	FetchedCounts []int
+}
+
+func (s *countsProgressSaver) SaveStatusAndCount(status string, maybeCount *int) error {
+	if maybeCount != nil {
+		s.FetchedCounts = append(s.FetchedCounts, *maybeCount)
+	}
+	return nil
+}
+
+func TestFetchMissingTitles(t *testing.T) {
+	logger := NewDummyLogger()
+	curiEqCfg := NewCanonicalEqualityConfig()
+	var links []*maybeTitledLink
+	var expectedTitles []string
+	for i := 0; i < 12; i++ {
+		path := fmt.Sprintf("post%d", i)
+		if i == 5 {
+			path = "broken"
+		}
+		link, ok := ToCanonicalLink("https://blog.com/"+path, logger, nil)
+		require.True(t, ok)
+		var maybeTitle *LinkTitle
+		expectedTitle := titleWords[path]
+		if i%3 == 0 {
+			title := NewLinkTitle(fmt.Sprintf("Known %d", i), LinkTitleSourceInnerText, nil)
+			maybeTitle = &title
+			expectedTitle = title.Value
+		} else if path == "broken" {
+			expectedTitle = link.Url
+		}
+		links = append(links, &maybeTitledLink{
+			Link:       *link,
+			MaybeTitle: maybeTitle,
+		})
+		expectedTitles = append(expectedTitles, expectedTitle)
+	}
+
+	httpClient := &titlesHttpClient{} //nolint:exhaustruct
+	progressSaver := &countsProgressSaver{
+		MockProgressSaver: *NewMockProgressSaver(logger),
+		FetchedCounts:     nil,
+	}
+	crawlCtx := NewCrawlContext(httpClient, nil, NewProgressLogger(progressSaver))
+	crawlCtx.RobotsClient = &RobotsClient{} //nolint:exhaustruct
+	feedEntryLinks := newFeedEntryLinks(nil)
+	feedEntryCurisTitlesMap := NewCanonicalUriMap[MaybeLinkTitle](&curiEqCfg)
+
+	titledLinks, err := fetchMissingTitles(
+		links, &feedEntryLinks, &feedEntryCurisTitlesMap, FeedGeneratorOther, &curiEqCfg, &crawlCtx, logger,
+	)
+	require.NoError(t, err)
+
+	var titles []string
+	for _, link := range titledLinks {
+		titles = append(titles, link.Title.Value)
+	}
+	require.Equal(t, expectedTitles, titles)
+	require.Equal(t, 8, crawlCtx.TitleRequestsMade)
+	require.LessOrEqual(t, httpClient.maxInFlight, titleFetchHostConcurrency)
+	require.IsNonDecreasing(t, progressSaver.FetchedCounts)
+}
@@ -0,0 +1,19 @@
+package migrations
+
+type StartFeedArchivesUrl struct{}
+
+func init() {
+	registerMigration(&StartFeedArchivesUrl{})
+}
+
+func (m *StartFeedArchivesUrl) Version() string {
+	return "20261019120000"
+}
+
+func (m *StartFeedArchivesUrl) Up(tx *Tx) {
+	tx.MustExec(`alter table start_feeds add column archives_url text`)
+}
+
+func (m *StartFeedArchivesUrl) Down(tx *Tx) {
+	tx.MustExec(`alter table start_feeds drop column archives_url`)
+}
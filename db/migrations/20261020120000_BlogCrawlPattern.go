@@ -0,0 +1,19 @@
+package migrations
+
+type BlogCrawlPattern struct{}
+
+func init() {
+	registerMigration(&BlogCrawlPattern{})
+}
+
+func (m *BlogCrawlPattern) Version() string {
+	return "20261020120000"
+}
+
+func (m *BlogCrawlPattern) Up(tx *Tx) {
+	tx.MustExec(`alter table blogs add column crawl_pattern text`)
+}
+
+func (m *BlogCrawlPattern) Down(tx *Tx) {
+	tx.MustExec(`alter table blogs drop column crawl_pattern`)
+}
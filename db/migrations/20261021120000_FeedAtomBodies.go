@@ -0,0 +1,21 @@
+package migrations
+
+type FeedAtomBodies struct{}
+
+func init() {
+	registerMigration(&FeedAtomBodies{})
+}
+
+func (m *FeedAtomBodies) Version() string {
+	return "20261021120000"
+}
+
+func (m *FeedAtomBodies) Up(tx *Tx) {
+	tx.MustExec(`alter table subscription_rsses add column atom_body text`)
+	tx.MustExec(`alter table user_rsses add column atom_body text`)
+}
+
+func (m *FeedAtomBodies) Down(tx *Tx) {
+	tx.MustExec(`alter table user_rsses drop column atom_body`)
+	tx.MustExec(`alter table subscription_rsses drop column atom_body`)
+}
@@ -0,0 +1,21 @@
+package migrations
+
+type FeedJsonBodies struct{}
+
+func init() {
+	registerMigration(&FeedJsonBodies{})
+}
+
+func (m *FeedJsonBodies) Version() string {
+	return "20261022120000"
+}
+
+func (m *FeedJsonBodies) Up(tx *Tx) {
+	tx.MustExec(`alter table subscription_rsses add column json_body text`)
+	tx.MustExec(`alter table user_rsses add column json_body text`)
+}
+
+func (m *FeedJsonBodies) Down(tx *Tx) {
+	tx.MustExec(`alter table user_rsses drop column json_body`)
+	tx.MustExec(`alter table subscription_rsses drop column json_body`)
+}
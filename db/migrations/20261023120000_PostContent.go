@@ -0,0 +1,27 @@
+package migrations
+
+import "feedrewind.com/db/pgw"
+
+type PostContent struct{}
+
+func init() {
+	registerMigration(&PostContent{})
+}
+
+func (m *PostContent) Version() string {
+	return "20261023120000"
+}
+
+func (m *PostContent) Up(tx *Tx) {
+	tx.MustExec(`alter table blog_posts add column content text`)
+	tx.MustExec(`alter table subscriptions add column include_post_content boolean not null default false`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+}
+
+func (m *PostContent) Down(tx *Tx) {
+	tx.MustExec(`drop view subscriptions_without_discarded`)
+	tx.MustExec(`drop view subscriptions_with_discarded`)
+	tx.MustExec(`alter table subscriptions drop column include_post_content`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+	tx.MustExec(`alter table blog_posts drop column content`)
+}
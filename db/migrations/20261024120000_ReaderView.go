@@ -0,0 +1,27 @@
+package migrations
+
+type ReaderView struct{}
+
+func init() {
+	registerMigration(&ReaderView{})
+}
+
+func (m *ReaderView) Version() string {
+	return "20261024120000"
+}
+
+func (m *ReaderView) Up(tx *Tx) {
+	tx.MustExec(`alter table user_settings add column reader_view boolean not null default false`)
+	tx.MustExec(`
+		create table blog_post_articles (
+			blog_post_id bigint primary key references blog_posts(id) on delete cascade,
+			content text
+		)
+	`)
+	tx.MustAddTimestamps("blog_post_articles")
+}
+
+func (m *ReaderView) Down(tx *Tx) {
+	tx.MustExec(`drop table blog_post_articles`)
+	tx.MustExec(`alter table user_settings drop column reader_view`)
+}
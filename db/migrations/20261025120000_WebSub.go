@@ -0,0 +1,32 @@
+package migrations
+
+type WebSub struct{}
+
+func init() {
+	registerMigration(&WebSub{})
+}
+
+func (m *WebSub) Version() string {
+	return "20261025120000"
+}
+
+func (m *WebSub) Up(tx *Tx) {
+	tx.MustExec(`
+		create table websub_subscriptions (
+			id bigserial primary key,
+			topic text not null,
+			callback text not null,
+			user_id bigint not null references users(id) on delete cascade,
+			subscription_id bigint references subscriptions(id) on delete cascade,
+			feed_format text not null,
+			secret text,
+			expires_at timestamp without time zone not null,
+			unique (topic, callback)
+		)
+	`)
+	tx.MustAddTimestamps("websub_subscriptions")
+}
+
+func (m *WebSub) Down(tx *Tx) {
+	tx.MustExec(`drop table websub_subscriptions`)
+}
@@ -0,0 +1,52 @@
+package migrations
+
+type FeedTokens struct{}
+
+func init() {
+	registerMigration(&FeedTokens{})
+}
+
+func (m *FeedTokens) Version() string {
+	return "20261026120000"
+}
+
+func (m *FeedTokens) Up(tx *Tx) {
+	tx.MustExec(`
+		create table feed_tokens (
+			token text primary key,
+			subscription_id bigint references subscriptions(id) on delete cascade,
+			user_id bigint references users(id) on delete cascade,
+			allows_legacy_url boolean not null default false,
+			rotated_at timestamp without time zone,
+			constraint feed_tokens_single_feed check ((subscription_id is null) <> (user_id is null))
+		)
+	`)
+	tx.MustAddTimestamps("feed_tokens")
+	tx.MustExec(`
+		create unique index index_feed_tokens_on_current_subscription_id on feed_tokens (subscription_id)
+		where subscription_id is not null and rotated_at is null
+	`)
+	tx.MustExec(`
+		create unique index index_feed_tokens_on_current_user_id on feed_tokens (user_id)
+		where user_id is not null and rotated_at is null
+	`)
+
+	// Existing feeds keep working at their numeric urls until the user turns them off or rotates the token
+	randomToken := "rtrim(replace(replace(encode(gen_random_bytes(16), 'base64'), '+', '-'), '/', '_'), '=')"
+	tx.MustExec(`
+		insert into feed_tokens (token, subscription_id, allows_legacy_url)
+		select ` + randomToken + `, id, true from subscriptions
+	`)
+	tx.MustExec(`
+		insert into feed_tokens (token, user_id, allows_legacy_url)
+		select ` + randomToken + `, id, true from users
+	`)
+
+	tx.MustExec(`alter table user_settings add column legacy_feed_urls boolean not null default false`)
+	tx.MustExec(`update user_settings set legacy_feed_urls = true`)
+}
+
+func (m *FeedTokens) Down(tx *Tx) {
+	tx.MustExec(`alter table user_settings drop column legacy_feed_urls`)
+	tx.MustExec(`drop table feed_tokens`)
+}
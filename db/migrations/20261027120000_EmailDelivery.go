@@ -0,0 +1,21 @@
+package migrations
+
+type EmailDelivery struct{}
+
+func init() {
+	registerMigration(&EmailDelivery{})
+}
+
+func (m *EmailDelivery) Version() string {
+	return "20261027120000"
+}
+
+func (m *EmailDelivery) Up(tx *Tx) {
+	tx.MustExec(`alter table user_settings add column email_bounced_at timestamp without time zone`)
+	tx.MustExec(`alter table user_settings add column email_bounce_message text`)
+}
+
+func (m *EmailDelivery) Down(tx *Tx) {
+	tx.MustExec(`alter table user_settings drop column email_bounce_message`)
+	tx.MustExec(`alter table user_settings drop column email_bounced_at`)
+}
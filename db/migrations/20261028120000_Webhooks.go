@@ -0,0 +1,50 @@
+package migrations
+
+type Webhooks struct{}
+
+func init() {
+	registerMigration(&Webhooks{})
+}
+
+func (m *Webhooks) Version() string {
+	return "20261028120000"
+}
+
+func (m *Webhooks) Up(tx *Tx) {
+	tx.MustExec(`alter type post_delivery_channel add value 'webhook'`)
+	tx.MustExec(`
+		create table webhooks (
+			user_id bigint primary key references users(id) on delete cascade,
+			url text not null,
+			secret text not null,
+			consecutive_failures integer not null default 0,
+			disabled_at timestamp without time zone
+		)
+	`)
+	tx.MustAddTimestamps("webhooks")
+	tx.MustExec(`
+		create table webhook_deliveries (
+			id bigserial primary key,
+			user_id bigint not null references users(id) on delete cascade,
+			url text not null,
+			payload text not null,
+			posts_count integer not null,
+			status text not null,
+			attempts integer not null default 0,
+			last_response_code integer,
+			last_error text,
+			finished_at timestamp without time zone
+		)
+	`)
+	tx.MustAddTimestamps("webhook_deliveries")
+	tx.MustExec(`create index index_webhook_deliveries_on_user_id on webhook_deliveries (user_id)`)
+}
+
+func (m *Webhooks) Down(tx *Tx) {
+	tx.MustExec(`drop table webhook_deliveries`)
+	tx.MustExec(`drop table webhooks`)
+	tx.MustExec(`
+		update user_settings set delivery_channel = 'multiple_feeds' where delivery_channel = 'webhook'
+	`)
+	// Enum values can't be dropped, the type would have to be recreated
+}
@@ -0,0 +1,44 @@
+package migrations
+
+type OpmlImports struct{}
+
+func init() {
+	registerMigration(&OpmlImports{})
+}
+
+func (m *OpmlImports) Version() string {
+	return "20261029120000"
+}
+
+func (m *OpmlImports) Up(tx *Tx) {
+	tx.MustExec(`
+		create table opml_imports (
+			id bigserial primary key,
+			user_id bigint not null references users(id) on delete cascade
+		)
+	`)
+	tx.MustAddTimestamps("opml_imports")
+	tx.MustExec(`create index index_opml_imports_on_user_id on opml_imports (user_id)`)
+	tx.MustExec(`
+		create table opml_import_items (
+			id bigserial primary key,
+			opml_import_id bigint not null references opml_imports(id) on delete cascade,
+			position integer not null,
+			title text not null,
+			feed_url text not null,
+			html_url text,
+			status text not null,
+			error text,
+			subscription_id bigint references subscriptions(id) on delete set null
+		)
+	`)
+	tx.MustAddTimestamps("opml_import_items")
+	tx.MustExec(`
+		create index index_opml_import_items_on_opml_import_id on opml_import_items (opml_import_id)
+	`)
+}
+
+func (m *OpmlImports) Down(tx *Tx) {
+	tx.MustExec(`drop table opml_import_items`)
+	tx.MustExec(`drop table opml_imports`)
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import "feedrewind.com/db/pgw"
+
+type AdaptivePacing struct{}
+
+func init() {
+	registerMigration(&AdaptivePacing{})
+}
+
+func (m *AdaptivePacing) Version() string {
+	return "20261030120000"
+}
+
+func (m *AdaptivePacing) Up(tx *Tx) {
+	tx.MustExec(`alter table subscription_posts add column opened_at timestamp without time zone`)
+	tx.MustExec(`
+		alter table subscriptions
+			add column adaptive_pacing boolean not null default false,
+			add column pacing_percent integer not null default 100,
+			add column pacing_credit integer not null default 0,
+			add column pacing_changed_at timestamp without time zone
+	`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+}
+
+func (m *AdaptivePacing) Down(tx *Tx) {
+	tx.MustExec(`drop view subscriptions_without_discarded`)
+	tx.MustExec(`drop view subscriptions_with_discarded`)
+	tx.MustExec(`
+		alter table subscriptions
+			drop column adaptive_pacing,
+			drop column pacing_percent,
+			drop column pacing_credit,
+			drop column pacing_changed_at
+	`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+	tx.MustExec(`alter table subscription_posts drop column opened_at`)
+}
@@ -0,0 +1,21 @@
+package migrations
+
+type DeliveredNow struct{}
+
+func init() {
+	registerMigration(&DeliveredNow{})
+}
+
+func (m *DeliveredNow) Version() string {
+	return "20261031120000"
+}
+
+func (m *DeliveredNow) Up(tx *Tx) {
+	tx.MustExec(`
+		alter table subscription_posts add column is_delivered_now boolean not null default false
+	`)
+}
+
+func (m *DeliveredNow) Down(tx *Tx) {
+	tx.MustExec(`alter table subscription_posts drop column is_delivered_now`)
+}
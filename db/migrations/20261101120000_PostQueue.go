@@ -0,0 +1,27 @@
+package migrations
+
+type PostQueue struct{}
+
+func init() {
+	registerMigration(&PostQueue{})
+}
+
+func (m *PostQueue) Version() string {
+	return "20261101120000"
+}
+
+func (m *PostQueue) Up(tx *Tx) {
+	tx.MustExec(`
+		alter table subscription_posts
+			add column queue_order integer,
+			add column skipped_at timestamp without time zone
+	`)
+}
+
+func (m *PostQueue) Down(tx *Tx) {
+	tx.MustExec(`
+		alter table subscription_posts
+			drop column queue_order,
+			drop column skipped_at
+	`)
+}
@@ -0,0 +1,32 @@
+package migrations
+
+type Vacations struct{}
+
+func init() {
+	registerMigration(&Vacations{})
+}
+
+func (m *Vacations) Version() string {
+	return "20261102120000"
+}
+
+func (m *Vacations) Up(tx *Tx) {
+	tx.MustExec(`
+		create table vacations (
+			id bigserial primary key,
+			user_id bigint not null references users(id) on delete cascade,
+			start_date text not null,
+			end_date text not null
+		)
+	`)
+	tx.MustAddTimestamps("vacations")
+	tx.MustExec(`create index index_vacations_on_user_id on vacations (user_id)`)
+	tx.MustExec(`
+		alter table user_settings add column catch_up_after_vacation boolean not null default false
+	`)
+}
+
+func (m *Vacations) Down(tx *Tx) {
+	tx.MustExec(`alter table user_settings drop column catch_up_after_vacation`)
+	tx.MustExec(`drop table vacations`)
+}
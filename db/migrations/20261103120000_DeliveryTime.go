@@ -0,0 +1,19 @@
+package migrations
+
+type DeliveryTime struct{}
+
+func init() {
+	registerMigration(&DeliveryTime{})
+}
+
+func (m *DeliveryTime) Version() string {
+	return "20261103120000"
+}
+
+func (m *DeliveryTime) Up(tx *Tx) {
+	tx.MustExec(`alter table user_settings add column delivery_minute_of_day integer`)
+}
+
+func (m *DeliveryTime) Down(tx *Tx) {
+	tx.MustExec(`alter table user_settings drop column delivery_minute_of_day`)
+}
@@ -0,0 +1,47 @@
+package migrations
+
+import "feedrewind.com/db/pgw"
+
+type ScheduleTypes struct{}
+
+func init() {
+	registerMigration(&ScheduleTypes{})
+}
+
+func (m *ScheduleTypes) Version() string {
+	return "20261104120000"
+}
+
+func (m *ScheduleTypes) Up(tx *Tx) {
+	tx.MustExec(`
+		create type schedule_type as enum ('weekly', 'every_n_days', 'per_month', 'finish_by', 'reading_time')
+	`)
+	tx.MustExec(`
+		alter table subscriptions
+			add column schedule_type schedule_type not null default 'weekly',
+			add column schedule_every_days integer,
+			add column schedule_monthly_count integer,
+			add column schedule_finish_date text,
+			add column schedule_weekly_minutes integer,
+			add column schedule_start_date text,
+			add column schedule_credit integer not null default 0
+	`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+}
+
+func (m *ScheduleTypes) Down(tx *Tx) {
+	tx.MustExec(`drop view subscriptions_without_discarded`)
+	tx.MustExec(`drop view subscriptions_with_discarded`)
+	tx.MustExec(`
+		alter table subscriptions
+			drop column schedule_type,
+			drop column schedule_every_days,
+			drop column schedule_monthly_count,
+			drop column schedule_finish_date,
+			drop column schedule_weekly_minutes,
+			drop column schedule_start_date,
+			drop column schedule_credit
+	`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+	tx.MustExec(`drop type schedule_type`)
+}
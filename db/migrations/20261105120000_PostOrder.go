@@ -0,0 +1,43 @@
+package migrations
+
+import "feedrewind.com/db/pgw"
+
+type PostOrder struct{}
+
+func init() {
+	registerMigration(&PostOrder{})
+}
+
+func (m *PostOrder) Version() string {
+	return "20261105120000"
+}
+
+func (m *PostOrder) Up(tx *Tx) {
+	tx.MustExec(`create type post_order as enum ('chronological', 'reverse', 'shuffle', 'best_of')`)
+	tx.MustExec(`
+		alter table subscriptions add column post_order post_order not null default 'chronological'
+	`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+	tx.MustExec(`alter table subscription_posts add column order_rank integer`)
+	tx.MustExec(`
+		update subscription_posts
+		set order_rank = ranks.order_rank
+		from (
+			select subscription_posts.id,
+				row_number() over (partition by subscription_id order by blog_posts.index asc) as order_rank
+			from subscription_posts
+			join blog_posts on blog_posts.id = subscription_posts.blog_post_id
+		) as ranks
+		where subscription_posts.id = ranks.id
+	`)
+	tx.MustExec(`alter table subscription_posts alter column order_rank set not null`)
+}
+
+func (m *PostOrder) Down(tx *Tx) {
+	tx.MustExec(`alter table subscription_posts drop column order_rank`)
+	tx.MustExec(`drop view subscriptions_without_discarded`)
+	tx.MustExec(`drop view subscriptions_with_discarded`)
+	tx.MustExec(`alter table subscriptions drop column post_order`)
+	tx.MustUpdateDiscardedViews("subscriptions", &pgw.CheckSubscriptionsUsage)
+	tx.MustExec(`drop type post_order`)
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "feedrewind.com/db/pgw"
+
+type BackfillFeedBodies struct{}
+
+func init() {
+	registerMigration(&BackfillFeedBodies{})
+}
+
+func (m *BackfillFeedBodies) Version() string {
+	return "20261106120000"
+}
+
+var BackfillFeedBodiesJob_PerformNowFunc func(pgw.Queryable) error
+
+func (m *BackfillFeedBodies) Up(tx *Tx) {
+	err := BackfillFeedBodiesJob_PerformNowFunc(tx.impl)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *BackfillFeedBodies) Down(tx *Tx) {
+	tx.MustDeleteJobByName("BackfillFeedBodiesJob")
+}
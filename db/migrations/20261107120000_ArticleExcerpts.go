@@ -0,0 +1,19 @@
+package migrations
+
+type ArticleExcerpts struct{}
+
+func init() {
+	registerMigration(&ArticleExcerpts{})
+}
+
+func (m *ArticleExcerpts) Version() string {
+	return "20261107120000"
+}
+
+func (m *ArticleExcerpts) Up(tx *Tx) {
+	tx.MustExec(`alter table blog_post_articles add column excerpt text`)
+}
+
+func (m *ArticleExcerpts) Down(tx *Tx) {
+	tx.MustExec(`alter table blog_post_articles drop column excerpt`)
+}
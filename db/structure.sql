@@ -125,7 +125,20 @@ CREATE TYPE public.old_blog_status AS ENUM (
 CREATE TYPE public.post_delivery_channel AS ENUM (
     'single_feed',
     'multiple_feeds',
-    'email'
+    'email',
+    'webhook'
+);
+
+
+--
+-- Name: post_order; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.post_order AS ENUM (
+    'chronological',
+    'reverse',
+    'shuffle',
+    'best_of'
 );
 
 
@@ -152,6 +165,19 @@ CREATE TYPE public.postmark_message_type AS ENUM (
 );
 
 
+--
+-- Name: schedule_type; Type: TYPE; Schema: public; Owner: -
+--
+
+CREATE TYPE public.schedule_type AS ENUM (
+    'weekly',
+    'every_n_days',
+    'per_month',
+    'finish_by',
+    'reading_time'
+);
+
+
 --
 -- Name: subscription_status; Type: TYPE; Schema: public; Owner: -
 --
@@ -388,6 +414,19 @@ CREATE SEQUENCE public.blog_missing_from_feed_entries_id_seq
 ALTER SEQUENCE public.blog_missing_from_feed_entries_id_seq OWNED BY public.blog_missing_from_feed_entries.id;
 
 
+--
+-- Name: blog_post_articles; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.blog_post_articles (
+    blog_post_id bigint NOT NULL,
+    content text,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    excerpt text
+);
+
+
 --
 -- Name: blog_post_categories; Type: TABLE; Schema: public; Owner: -
 --
@@ -476,7 +515,8 @@ CREATE TABLE public.blog_posts (
     url character varying NOT NULL,
     title character varying NOT NULL,
     created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
-    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    content text
 );
 
 
@@ -514,7 +554,8 @@ CREATE TABLE public.blogs (
     updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
     update_action public.blog_update_action NOT NULL,
     url character varying,
-    start_feed_id bigint
+    start_feed_id bigint,
+    crawl_pattern text
 );
 
 
@@ -557,6 +598,22 @@ CREATE SEQUENCE public.delayed_jobs_id_seq
 ALTER SEQUENCE public.delayed_jobs_id_seq OWNED BY public.delayed_jobs.id;
 
 
+--
+-- Name: feed_tokens; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.feed_tokens (
+    token text NOT NULL,
+    subscription_id bigint,
+    user_id bigint,
+    allows_legacy_url boolean DEFAULT false NOT NULL,
+    rotated_at timestamp without time zone,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    CONSTRAINT feed_tokens_single_feed CHECK (((subscription_id IS NULL) <> (user_id IS NULL)))
+);
+
+
 --
 -- Name: feed_waitlist_emails; Type: TABLE; Schema: public; Owner: -
 --
@@ -584,6 +641,75 @@ CREATE TABLE public.ignored_suggestion_feeds (
 );
 
 
+--
+-- Name: opml_import_items; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.opml_import_items (
+    id bigint NOT NULL,
+    opml_import_id bigint NOT NULL,
+    "position" integer NOT NULL,
+    title text NOT NULL,
+    feed_url text NOT NULL,
+    html_url text,
+    status text NOT NULL,
+    error text,
+    subscription_id bigint,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: opml_import_items_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.opml_import_items_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: opml_import_items_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.opml_import_items_id_seq OWNED BY public.opml_import_items.id;
+
+
+--
+-- Name: opml_imports; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.opml_imports (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: opml_imports_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.opml_imports_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: opml_imports_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.opml_imports_id_seq OWNED BY public.opml_imports.id;
+
+
 --
 -- Name: page_screenshots; Type: TABLE; Schema: public; Owner: -
 --
@@ -740,7 +866,8 @@ CREATE TABLE public.start_feeds (
     url text NOT NULL,
     final_url text,
     title text NOT NULL,
-    start_page_id integer
+    start_page_id integer,
+    archives_url text
 );
 
 
@@ -803,7 +930,12 @@ CREATE TABLE public.subscription_posts (
     published_at timestamp without time zone,
     published_at_local_date character varying,
     publish_status public.post_publish_status,
-    random_id text NOT NULL
+    random_id text NOT NULL,
+    opened_at timestamp without time zone,
+    is_delivered_now boolean DEFAULT false NOT NULL,
+    queue_order integer,
+    skipped_at timestamp without time zone,
+    order_rank integer NOT NULL
 );
 
 
@@ -835,7 +967,9 @@ CREATE TABLE public.subscription_rsses (
     body text NOT NULL,
     created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
     updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
-    subscription_id bigint NOT NULL
+    subscription_id bigint NOT NULL,
+    atom_body text,
+    json_body text
 );
 
 
@@ -879,6 +1013,19 @@ CREATE TABLE public.subscriptions (
     final_item_publish_status public.post_publish_status,
     schedule_version integer NOT NULL,
     anon_product_user_id uuid,
+    include_post_content boolean DEFAULT false NOT NULL,
+    adaptive_pacing boolean DEFAULT false NOT NULL,
+    pacing_percent integer DEFAULT 100 NOT NULL,
+    pacing_credit integer DEFAULT 0 NOT NULL,
+    pacing_changed_at timestamp without time zone,
+    schedule_type public.schedule_type DEFAULT 'weekly'::public.schedule_type NOT NULL,
+    schedule_every_days integer,
+    schedule_monthly_count integer,
+    schedule_finish_date text,
+    schedule_weekly_minutes integer,
+    schedule_start_date text,
+    schedule_credit integer DEFAULT 0 NOT NULL,
+    post_order public.post_order DEFAULT 'chronological'::public.post_order NOT NULL,
     CONSTRAINT subscriptions_refers_to_user CHECK ((NOT ((user_id IS NULL) AND (anon_product_user_id IS NULL))))
 );
 
@@ -903,7 +1050,20 @@ CREATE VIEW public.subscriptions_with_discarded AS
     subscriptions.initial_item_publish_status,
     subscriptions.final_item_publish_status,
     subscriptions.schedule_version,
-    subscriptions.anon_product_user_id
+    subscriptions.anon_product_user_id,
+    subscriptions.include_post_content,
+    subscriptions.adaptive_pacing,
+    subscriptions.pacing_percent,
+    subscriptions.pacing_credit,
+    subscriptions.pacing_changed_at,
+    subscriptions.schedule_type,
+    subscriptions.schedule_every_days,
+    subscriptions.schedule_monthly_count,
+    subscriptions.schedule_finish_date,
+    subscriptions.schedule_weekly_minutes,
+    subscriptions.schedule_start_date,
+    subscriptions.schedule_credit,
+    subscriptions.post_order
    FROM public.subscriptions
   WITH CASCADED CHECK OPTION;
 
@@ -928,7 +1088,20 @@ CREATE VIEW public.subscriptions_without_discarded AS
     subscriptions.initial_item_publish_status,
     subscriptions.final_item_publish_status,
     subscriptions.schedule_version,
-    subscriptions.anon_product_user_id
+    subscriptions.anon_product_user_id,
+    subscriptions.include_post_content,
+    subscriptions.adaptive_pacing,
+    subscriptions.pacing_percent,
+    subscriptions.pacing_credit,
+    subscriptions.pacing_changed_at,
+    subscriptions.schedule_type,
+    subscriptions.schedule_every_days,
+    subscriptions.schedule_monthly_count,
+    subscriptions.schedule_finish_date,
+    subscriptions.schedule_weekly_minutes,
+    subscriptions.schedule_start_date,
+    subscriptions.schedule_credit,
+    subscriptions.post_order
    FROM public.subscriptions
   WHERE (subscriptions.discarded_at IS NULL)
   WITH CASCADED CHECK OPTION;
@@ -989,7 +1162,9 @@ CREATE TABLE public.user_rsses (
     body text NOT NULL,
     created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
     updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
-    user_id bigint NOT NULL
+    user_id bigint NOT NULL,
+    atom_body text,
+    json_body text
 );
 
 
@@ -1003,7 +1178,13 @@ CREATE TABLE public.user_settings (
     created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
     updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
     version integer NOT NULL,
-    delivery_channel public.post_delivery_channel
+    delivery_channel public.post_delivery_channel,
+    reader_view boolean DEFAULT false NOT NULL,
+    legacy_feed_urls boolean DEFAULT false NOT NULL,
+    email_bounced_at timestamp without time zone,
+    email_bounce_message text,
+    catch_up_after_vacation boolean DEFAULT false NOT NULL,
+    delivery_minute_of_day integer
 );
 
 
@@ -1064,6 +1245,130 @@ CREATE VIEW public.users_without_discarded AS
   WITH CASCADED CHECK OPTION;
 
 
+--
+-- Name: vacations; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.vacations (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    start_date text NOT NULL,
+    end_date text NOT NULL,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: vacations_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.vacations_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: vacations_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.vacations_id_seq OWNED BY public.vacations.id;
+
+
+--
+-- Name: webhook_deliveries; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhook_deliveries (
+    id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    url text NOT NULL,
+    payload text NOT NULL,
+    posts_count integer NOT NULL,
+    status text NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_response_code integer,
+    last_error text,
+    finished_at timestamp without time zone,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.webhook_deliveries_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: webhook_deliveries_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.webhook_deliveries_id_seq OWNED BY public.webhook_deliveries.id;
+
+
+--
+-- Name: webhooks; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.webhooks (
+    user_id bigint NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    consecutive_failures integer DEFAULT 0 NOT NULL,
+    disabled_at timestamp without time zone,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: websub_subscriptions; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.websub_subscriptions (
+    id bigint NOT NULL,
+    topic text NOT NULL,
+    callback text NOT NULL,
+    user_id bigint NOT NULL,
+    subscription_id bigint,
+    feed_format text NOT NULL,
+    secret text,
+    expires_at timestamp without time zone NOT NULL,
+    created_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL,
+    updated_at timestamp(6) without time zone DEFAULT public.utc_now() NOT NULL
+);
+
+
+--
+-- Name: websub_subscriptions_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+CREATE SEQUENCE public.websub_subscriptions_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+--
+-- Name: websub_subscriptions_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: -
+--
+
+ALTER SEQUENCE public.websub_subscriptions_id_seq OWNED BY public.websub_subscriptions.id;
+
+
 --
 -- Name: admin_telemetries id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -1120,6 +1425,20 @@ ALTER TABLE ONLY public.blog_posts ALTER COLUMN id SET DEFAULT nextval('public.b
 ALTER TABLE ONLY public.delayed_jobs ALTER COLUMN id SET DEFAULT nextval('public.delayed_jobs_id_seq'::regclass);
 
 
+--
+-- Name: opml_import_items id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_import_items ALTER COLUMN id SET DEFAULT nextval('public.opml_import_items_id_seq'::regclass);
+
+
+--
+-- Name: opml_imports id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_imports ALTER COLUMN id SET DEFAULT nextval('public.opml_imports_id_seq'::regclass);
+
+
 --
 -- Name: page_screenshots id; Type: DEFAULT; Schema: public; Owner: -
 --
@@ -1169,6 +1488,27 @@ ALTER TABLE ONLY public.subscription_rsses ALTER COLUMN id SET DEFAULT nextval('
 ALTER TABLE ONLY public.typed_blog_urls ALTER COLUMN id SET DEFAULT nextval('public.typed_blog_urls_id_seq'::regclass);
 
 
+--
+-- Name: vacations id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.vacations ALTER COLUMN id SET DEFAULT nextval('public.vacations_id_seq'::regclass);
+
+
+--
+-- Name: webhook_deliveries id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries ALTER COLUMN id SET DEFAULT nextval('public.webhook_deliveries_id_seq'::regclass);
+
+
+--
+-- Name: websub_subscriptions id; Type: DEFAULT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.websub_subscriptions ALTER COLUMN id SET DEFAULT nextval('public.websub_subscriptions_id_seq'::regclass);
+
+
 --
 -- Name: admin_telemetries admin_telemetries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1241,6 +1581,14 @@ ALTER TABLE ONLY public.blog_missing_from_feed_entries
     ADD CONSTRAINT blog_missing_from_feed_entries_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: blog_post_articles blog_post_articles_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.blog_post_articles
+    ADD CONSTRAINT blog_post_articles_pkey PRIMARY KEY (blog_post_id);
+
+
 --
 -- Name: blog_post_categories blog_post_categories_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1289,6 +1637,14 @@ ALTER TABLE ONLY public.delayed_jobs
     ADD CONSTRAINT delayed_jobs_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: feed_tokens feed_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.feed_tokens
+    ADD CONSTRAINT feed_tokens_pkey PRIMARY KEY (token);
+
+
 --
 -- Name: feed_waitlist_emails feed_waitlist_emails_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1305,6 +1661,22 @@ ALTER TABLE ONLY public.ignored_suggestion_feeds
     ADD CONSTRAINT ignored_suggestion_feeds_pkey PRIMARY KEY (feed_url);
 
 
+--
+-- Name: opml_import_items opml_import_items_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_import_items
+    ADD CONSTRAINT opml_import_items_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: opml_imports opml_imports_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_imports
+    ADD CONSTRAINT opml_imports_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: page_screenshots page_screenshots_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -1449,6 +1821,46 @@ ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: vacations vacations_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.vacations
+    ADD CONSTRAINT vacations_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: webhooks webhooks_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhooks
+    ADD CONSTRAINT webhooks_pkey PRIMARY KEY (user_id);
+
+
+--
+-- Name: websub_subscriptions websub_subscriptions_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.websub_subscriptions
+    ADD CONSTRAINT websub_subscriptions_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: websub_subscriptions websub_subscriptions_topic_callback_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.websub_subscriptions
+    ADD CONSTRAINT websub_subscriptions_topic_callback_key UNIQUE (topic, callback);
+
+
 --
 -- Name: delayed_jobs_priority; Type: INDEX; Schema: public; Owner: -
 --
@@ -1491,6 +1903,34 @@ CREATE INDEX index_blog_posts_on_blog_id ON public.blog_posts USING btree (blog_
 CREATE UNIQUE INDEX index_blogs_on_feed_url_and_version ON public.blogs USING btree (feed_url, version);
 
 
+--
+-- Name: index_feed_tokens_on_current_subscription_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX index_feed_tokens_on_current_subscription_id ON public.feed_tokens USING btree (subscription_id) WHERE ((subscription_id IS NOT NULL) AND (rotated_at IS NULL));
+
+
+--
+-- Name: index_feed_tokens_on_current_user_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX index_feed_tokens_on_current_user_id ON public.feed_tokens USING btree (user_id) WHERE ((user_id IS NOT NULL) AND (rotated_at IS NULL));
+
+
+--
+-- Name: index_opml_import_items_on_opml_import_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX index_opml_import_items_on_opml_import_id ON public.opml_import_items USING btree (opml_import_id);
+
+
+--
+-- Name: index_opml_imports_on_user_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX index_opml_imports_on_user_id ON public.opml_imports USING btree (user_id);
+
+
 --
 -- Name: index_start_feeds_on_start_page_id; Type: INDEX; Schema: public; Owner: -
 --
@@ -1533,6 +1973,20 @@ CREATE UNIQUE INDEX index_users_on_id ON public.users USING btree (id);
 CREATE UNIQUE INDEX index_users_on_product_user_id ON public.users USING btree (product_user_id);
 
 
+--
+-- Name: index_vacations_on_user_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX index_vacations_on_user_id ON public.vacations USING btree (user_id);
+
+
+--
+-- Name: index_webhook_deliveries_on_user_id; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX index_webhook_deliveries_on_user_id ON public.webhook_deliveries USING btree (user_id);
+
+
 --
 -- Name: users_email_without_discarded; Type: INDEX; Schema: public; Owner: -
 --
@@ -1603,6 +2057,13 @@ CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.blog_discarded_feed_entri
 CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.blog_missing_from_feed_entries FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
 
 
+--
+-- Name: blog_post_articles bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.blog_post_articles FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
 --
 -- Name: blog_post_categories bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1645,6 +2106,13 @@ CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.blogs FOR EACH ROW EXECUT
 CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.delayed_jobs FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
 
 
+--
+-- Name: feed_tokens bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.feed_tokens FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
 --
 -- Name: feed_waitlist_emails bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1659,6 +2127,20 @@ CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.feed_waitlist_emails FOR
 CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.ignored_suggestion_feeds FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
 
 
+--
+-- Name: opml_import_items bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.opml_import_items FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
+--
+-- Name: opml_imports bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.opml_imports FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
 --
 -- Name: page_screenshots bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
 --
@@ -1778,6 +2260,58 @@ CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.user_settings FOR EACH RO
 CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.users FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
 
 
+--
+-- Name: vacations bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.vacations FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
+--
+-- Name: webhook_deliveries bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.webhook_deliveries FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
+--
+-- Name: webhooks bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.webhooks FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
+--
+-- Name: websub_subscriptions bump_updated_at; Type: TRIGGER; Schema: public; Owner: -
+--
+
+CREATE TRIGGER bump_updated_at BEFORE UPDATE ON public.websub_subscriptions FOR EACH ROW EXECUTE FUNCTION public.bump_updated_at_utc();
+
+
+--
+-- Name: blog_post_articles blog_post_articles_blog_post_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.blog_post_articles
+    ADD CONSTRAINT blog_post_articles_blog_post_id_fkey FOREIGN KEY (blog_post_id) REFERENCES public.blog_posts(id) ON DELETE CASCADE;
+
+
+--
+-- Name: feed_tokens feed_tokens_subscription_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.feed_tokens
+    ADD CONSTRAINT feed_tokens_subscription_id_fkey FOREIGN KEY (subscription_id) REFERENCES public.subscriptions(id) ON DELETE CASCADE;
+
+
+--
+-- Name: feed_tokens feed_tokens_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.feed_tokens
+    ADD CONSTRAINT feed_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: feed_waitlist_emails feed_waitlist_emails_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1962,6 +2496,30 @@ ALTER TABLE ONLY public.blog_crawl_votes
     ADD CONSTRAINT fk_rails_f74b6b39ca FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
 
 
+--
+-- Name: opml_import_items opml_import_items_opml_import_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_import_items
+    ADD CONSTRAINT opml_import_items_opml_import_id_fkey FOREIGN KEY (opml_import_id) REFERENCES public.opml_imports(id) ON DELETE CASCADE;
+
+
+--
+-- Name: opml_import_items opml_import_items_subscription_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_import_items
+    ADD CONSTRAINT opml_import_items_subscription_id_fkey FOREIGN KEY (subscription_id) REFERENCES public.subscriptions(id) ON DELETE SET NULL;
+
+
+--
+-- Name: opml_imports opml_imports_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.opml_imports
+    ADD CONSTRAINT opml_imports_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
 --
 -- Name: pricing_offers pricing_offers_plan_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -1986,6 +2544,46 @@ ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_offer_id_fkey FOREIGN KEY (offer_id) REFERENCES public.pricing_offers(id);
 
 
+--
+-- Name: vacations vacations_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.vacations
+    ADD CONSTRAINT vacations_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhook_deliveries webhook_deliveries_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: webhooks webhooks_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.webhooks
+    ADD CONSTRAINT webhooks_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: websub_subscriptions websub_subscriptions_subscription_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.websub_subscriptions
+    ADD CONSTRAINT websub_subscriptions_subscription_id_fkey FOREIGN KEY (subscription_id) REFERENCES public.subscriptions(id) ON DELETE CASCADE;
+
+
+--
+-- Name: websub_subscriptions websub_subscriptions_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.websub_subscriptions
+    ADD CONSTRAINT websub_subscriptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -2198,4 +2796,24 @@ INSERT INTO public.schema_migrations (version) VALUES
 ('20241018153403'),
 ('20250129143507'),
 ('20260524120000'),
-('20260524130000');
+('20260524130000'),
+('20261019120000'),
+('20261020120000'),
+('20261021120000'),
+('20261022120000'),
+('20261023120000'),
+('20261024120000'),
+('20261025120000'),
+('20261026120000'),
+('20261027120000'),
+('20261028120000'),
+('20261029120000'),
+('20261030120000'),
+('20261031120000'),
+('20261101120000'),
+('20261102120000'),
+('20261103120000'),
+('20261104120000'),
+('20261105120000'),
+('20261106120000'),
+('20261107120000');
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+
+	"feedrewind.com/db/migrations"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/publish"
+	"feedrewind.com/util"
+)
+
+func init() {
+	registerJobNameFunc(
+		"BackfillFeedBodiesJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 0 {
+				return oops.Newf("Expected 0 args, got %d: %v", len(args), args)
+			}
+
+			return BackfillFeedBodiesJob_Perform(ctx, pool)
+		},
+	)
+	migrations.BackfillFeedBodiesJob_PerformNowFunc = BackfillFeedBodiesJob_PerformNow
+}
+
+func BackfillFeedBodiesJob_PerformNow(qu pgw.Queryable) error {
+	return performNow(qu, "BackfillFeedBodiesJob", defaultQueue)
+}
+
+// Feeds that weren't published since Atom and JSON Feed were added only have the RSS body, and the feed
+// handlers don't write to fill the rest in
+func BackfillFeedBodiesJob_Perform(ctx context.Context, pool *pgw.Pool) error {
+	logger := pool.Logger()
+	rows, err := pool.Query(`
+		select user_id from user_rsses where atom_body is null or json_body is null
+		union
+		select subscriptions_with_discarded.user_id from subscription_rsses
+		join subscriptions_with_discarded
+			on subscriptions_with_discarded.id = subscription_rsses.subscription_id
+		where (subscription_rsses.atom_body is null or subscription_rsses.json_body is null) and
+			subscriptions_with_discarded.user_id is not null
+	`)
+	if err != nil {
+		return err
+	}
+
+	var userIds []models.UserId
+	for rows.Next() {
+		var userId models.UserId
+		err := rows.Scan(&userId)
+		if err != nil {
+			return err
+		}
+		userIds = append(userIds, userId)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	logger.Info().Msgf("Backfilling feed bodies for %d users", len(userIds))
+	for _, userId := range userIds {
+		if err := ctx.Err(); err != nil {
+			return oops.Wrap(err)
+		}
+		err := util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+			return publish.RegenerateFeeds(tx, userId)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	logger.Info().Msgf("Backfilled feed bodies for %d users", len(userIds))
+
+	return nil
+}
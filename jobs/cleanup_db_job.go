@@ -111,6 +111,22 @@ func CleanupDbJob_Perform(ctx context.Context, pool *pgw.Pool) error {
 		logger.Info().Msgf("Deleted %d stale start pages", result.RowsAffected())
 	}
 
+	{
+		deletedCount, err := models.WebSubSubscription_DeleteExpired(pool)
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("Deleted %d expired WebSub subscriptions", deletedCount)
+	}
+
+	{
+		deletedCount, err := models.WebhookDelivery_DeleteOlderThan(pool, utcNow.AddDate(0, 0, -30))
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("Deleted %d old webhook deliveries", deletedCount)
+	}
+
 	tomorrow := utcNow.Add(24 * time.Hour)
 	runAt := tomorrow.BeginningOfDayIn(time.UTC)
 	err = CleanupDbJob_PerformAt(pool, runAt)
@@ -0,0 +1,272 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/util"
+
+	"github.com/goccy/go-json"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	registerJobNameFunc(
+		"CustomBlogJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 2 {
+				return oops.Newf("Expected 2 args, got %d: %v", len(args), args)
+			}
+
+			blogIdInt64, ok := args[0].(int64)
+			if !ok {
+				blogIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse blogId (expected int64 or int): %v", args[0])
+				}
+				blogIdInt64 = int64(blogIdInt)
+			}
+			blogId := models.BlogId(blogIdInt64)
+
+			argsJson, ok := args[1].(string)
+			if !ok {
+				return oops.Newf("Failed to parse args (expected string): %v", args[1])
+			}
+
+			return CustomBlogJob_Perform(ctx, id, pool, blogId, argsJson)
+		},
+	)
+}
+
+type CustomBlogJobPost struct {
+	Url        string  `json:"url"`
+	MaybeTitle *string `json:"title,omitempty"`
+}
+
+type CustomBlogJobArgs struct {
+	Posts []CustomBlogJobPost `json:"posts"`
+}
+
+// Posts are expected newest first
+func CustomBlogJob_PerformNow(qu pgw.Queryable, blogId models.BlogId, posts []crawler.CustomBlogPost) error {
+	args := CustomBlogJobArgs{
+		Posts: make([]CustomBlogJobPost, len(posts)),
+	}
+	for i, post := range posts {
+		args.Posts[i] = CustomBlogJobPost{
+			Url:        post.Url,
+			MaybeTitle: post.MaybeTitle,
+		}
+	}
+	argsJson, err := json.Marshal(&args)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	return performNow(
+		qu, "CustomBlogJob", guidedCrawlingQueue, int64ToYaml(int64(blogId)), strToYaml(string(argsJson)),
+	)
+}
+
+func CustomBlogJob_Perform(
+	ctx context.Context, id JobId, pool *pgw.Pool, blogId models.BlogId, argsJson string,
+) error {
+	startTime := time.Now().UTC()
+	logger := pool.Logger()
+	var args CustomBlogJobArgs
+	err := json.Unmarshal([]byte(argsJson), &args)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	posts := make([]crawler.CustomBlogPost, len(args.Posts))
+	for i, post := range args.Posts {
+		posts[i] = crawler.CustomBlogPost{
+			Url:        post.Url,
+			MaybeTitle: post.MaybeTitle,
+		}
+	}
+
+	row := pool.QueryRow(`
+		select blogs.feed_url, blogs.name, start_feeds.title, start_feeds.url, start_feeds.final_url,
+			start_feeds.content
+		from blogs
+		left join start_feeds on start_feeds.id = blogs.start_feed_id
+		where blogs.id = $1
+	`, blogId)
+	var blogFeedUrl string
+	var blogName string
+	var maybeStartFeedTitle, maybeStartFeedUrl, maybeStartFeedFinalUrl *string
+	var maybeStartFeedContent *[]byte
+	err = row.Scan(
+		&blogFeedUrl, &blogName, &maybeStartFeedTitle, &maybeStartFeedUrl, &maybeStartFeedFinalUrl,
+		&maybeStartFeedContent,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Subscriptions are deleted along with the blog, there is no one to tell
+		logger.Info().Msg("Blog not found")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	checkCancellationFunc := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row := pool.QueryRow(`select 1 from delayed_jobs where id = $1`, id)
+		var one int
+		err = row.Scan(&one)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return crawler.ErrCrawlCanceled
+		} else if err != nil {
+			logger.Warn().Err(err).Msg("Couldn't check if the job was deleted")
+			return nil
+		}
+		return nil
+	}
+	httpClient := crawler.NewHttpClientImpl(ctx, checkCancellationFunc, true)
+	progressSaver := NewProgressSaver(blogId, blogFeedUrl, logger, pool)
+	progressLogger := crawler.NewProgressLogger(progressSaver)
+	crawlCtx := crawler.NewCrawlContext(httpClient, nil, progressLogger)
+	zLogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+
+	var customBlogResult *crawler.CustomBlogResult
+	var failureMessage string
+	if maybeStartFeedUrl == nil {
+		failureMessage = "no start feed"
+	} else {
+		startFeed := crawler.Feed{
+			Title:    *maybeStartFeedTitle,
+			Url:      *maybeStartFeedUrl,
+			FinalUrl: "",
+			Content:  "",
+		}
+		if maybeStartFeedFinalUrl != nil && maybeStartFeedContent != nil {
+			startFeed.FinalUrl = *maybeStartFeedFinalUrl
+			startFeed.Content = string(*maybeStartFeedContent)
+		} else {
+			// Start feeds from a page with several feeds are saved before they are fetched
+			logger.Info().Msgf("Start feed wasn't fetched, fetching %s", startFeed.Url)
+			fetchFeedResult := crawler.FetchFeedAtUrl(startFeed.Url, false, &crawlCtx, &zLogger)
+			switch fetchResult := fetchFeedResult.(type) {
+			case *crawler.FetchedPage:
+				startFeed.FinalUrl = fetchResult.Page.FetchUri.String()
+				startFeed.Content = fetchResult.Page.Content
+			case *crawler.FetchFeedErrorBadFeed:
+				failureMessage = "bad start feed"
+			case *crawler.FetchFeedErrorCouldNotReach:
+				if errors.Is(fetchResult.Error, crawler.ErrCrawlCanceled) {
+					return fetchResult.Error
+				}
+				failureMessage = "couldn't reach start feed"
+			default:
+				panic("unknown fetch feed result type")
+			}
+		}
+
+		if failureMessage == "" {
+			customBlogResult, err = crawler.CrawlCustomBlog(posts, startFeed, &crawlCtx, &zLogger)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if errors.Is(err, crawler.ErrCrawlCanceled) {
+				return err
+			}
+			if err != nil {
+				logger.Info().Err(err).Msg("Custom blog failed")
+				customBlogResult = nil
+				failureMessage = "crawl failed"
+			}
+		}
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if failureMessage != "" {
+		logger.Warn().Msgf("Custom blog %d failed: %s", blogId, failureMessage)
+	}
+
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		var blogUpdatedAt time.Time
+		var eventType string
+		if customBlogResult != nil {
+			logger.Info().Msgf("Custom blog succeeded, saving %d posts", len(customBlogResult.Links))
+			crawledBlogPosts := make([]models.CrawledBlogPost, len(customBlogResult.Links))
+			for i, link := range customBlogResult.Links {
+				crawledBlogPosts[i] = models.CrawledBlogPost{
+					Url:          link.Url,
+					Title:        link.Title.Value,
+					Categories:   []string{"Everything"},
+					MaybeContent: nil,
+				}
+			}
+			blogUpdatedAt, err = models.Blog_InitManuallyInserted(
+				tx, blogId, crawledBlogPosts, customBlogResult.DiscardedFeedEntryUrls,
+				customBlogResult.MissingFromFeedEntryUrls, customBlogResult.CuriEqCfg,
+			)
+			if err != nil {
+				return err
+			}
+			eventType = "custom blog succeeded"
+		} else {
+			row := tx.QueryRow(`
+				update blogs set status = $1 where id = $2 returning updated_at
+			`, models.BlogStatusCrawlFailed, blogId)
+			err := row.Scan(&blogUpdatedAt)
+			if err != nil {
+				return err
+			}
+			eventType = "custom blog failed"
+		}
+		err := logCrawlFinished(tx, blogId, blogUpdatedAt, eventType)
+		if err != nil {
+			return err
+		}
+
+		elapsedSeconds := time.Since(startTime).Seconds()
+		slackVerb := "succeeded"
+		if customBlogResult == nil {
+			slackVerb = fmt.Sprintf("failed (%s)", failureMessage)
+		}
+		slackText := fmt.Sprintf(
+			"Custom blog *%s* (%d posts) %s in %.1f seconds",
+			NotifySlackJob_Escape(blogName), len(posts), slackVerb, elapsedSeconds,
+		)
+		err = NotifySlackJob_PerformNow(tx, slackText)
+		if err != nil {
+			return err
+		}
+
+		if crawlCtx.TitleFetchDuration > 0 {
+			err := models.AdminTelemetry_Create(
+				tx, "custom_blog_title_fetch_duration", crawlCtx.TitleFetchDuration, map[string]any{
+					"feed_url":      blogFeedUrl,
+					"requests_made": crawlCtx.TitleRequestsMade,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		payload := map[string]any{
+			"blog_id": fmt.Sprint(blogId),
+			"done":    true,
+		}
+		payloadBytes, err := json.Marshal(&payload)
+		if err != nil {
+			return oops.Wrap(err)
+		}
+		_, err = tx.Exec(`select pg_notify($1, $2)`, CrawlProgressChannelName, string(payloadBytes))
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("%s %d done:true", CrawlProgressChannelName, blogId)
+		return nil
+	})
+}
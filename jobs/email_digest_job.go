@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/templates"
+	"feedrewind.com/util"
+	"feedrewind.com/util/mail"
+	"feedrewind.com/util/schedule"
+)
+
+func init() {
+	registerJobNameFunc(
+		"EmailDigestJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 {
+				return oops.Newf("Expected 1 arg, got %d: %v", len(args), args)
+			}
+
+			userIdInt64, ok := args[0].(int64)
+			if !ok {
+				userIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse userId (expected int64 or int): %v", args[0])
+				}
+				userIdInt64 = int64(userIdInt)
+			}
+			userId := models.UserId(userIdInt64)
+
+			return EmailDigestJob_Perform(ctx, pool, userId)
+		},
+	)
+}
+
+func EmailDigestJob_PerformNow(qu pgw.Queryable, userId models.UserId) error {
+	return performNow(qu, "EmailDigestJob", defaultQueue, int64ToYaml(int64(userId)))
+}
+
+// Sends everything that's pending for the user in one email. A transient failure rolls back and lets the
+// worker retry, while the posts stay pending and would also be picked up by the next day's digest.
+// A permanent rejection marks the posts as skipped and stops further emails until the user picks email
+// delivery again.
+func EmailDigestJob_Perform(ctx context.Context, pool *pgw.Pool, userId models.UserId) error {
+	logger := pool.Logger()
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		user, err := models.User_GetById(tx, userId)
+		if errors.Is(err, models.ErrUserNotFound) {
+			logger.Info().Msg("User not found")
+			return nil
+		} else if err != nil {
+			return err
+		}
+		userSettings, err := models.UserSettings_Get(tx, userId)
+		if err != nil {
+			return err
+		}
+
+		subscriptions, err := models.EmailDigest_LockPending(tx, userId)
+		if err != nil {
+			return err
+		}
+		if len(subscriptions) == 0 {
+			logger.Info().Msg("Nothing to send")
+			return nil
+		}
+
+		if userSettings.MaybeEmailBouncedAt != nil {
+			logger.Info().Msgf(
+				"Emails to the user bounced at %s, skipping %d subscriptions",
+				*userSettings.MaybeEmailBouncedAt, len(subscriptions),
+			)
+			return models.EmailDigest_UpdateStatus(tx, subscriptions, models.PostPublishStatusEmailSkipped)
+		}
+
+		message := emailDigestFormat(user.Email, subscriptions)
+		err = mail.Send(message)
+		if mail.IsPermanentError(err) {
+			logger.Warn().Err(err).Msg("Email bounced")
+			err := models.UserSettings_SaveEmailBounce(tx, userId, schedule.UTCNow(), err.Error())
+			if err != nil {
+				return err
+			}
+			models.ProductEvent_MustEmit(tx, user.ProductUserId, "email bounced", nil, nil)
+			return models.EmailDigest_UpdateStatus(tx, subscriptions, models.PostPublishStatusEmailSkipped)
+		} else if err != nil {
+			return err
+		}
+
+		postsCount := 0
+		for _, subscription := range subscriptions {
+			postsCount += len(subscription.Posts)
+		}
+		logger.Info().Msgf("Sent %d posts from %d subscriptions", postsCount, len(subscriptions))
+		return models.EmailDigest_UpdateStatus(tx, subscriptions, models.PostPublishStatusEmailSent)
+	})
+}
+
+func emailDigestFormat(to string, subscriptions []models.EmailDigestSubscription) mail.Message {
+	type DigestPost struct {
+		Title string
+		Url   string
+	}
+	type DigestSubscription struct {
+		Name                 string
+		Url                  string
+		IsInitialItemPending bool
+		IsFinalItemPending   bool
+		RemainingCount       int
+		Posts                []DigestPost
+	}
+
+	var digestSubscriptions []DigestSubscription
+	var postTitles []string
+	var text strings.Builder
+	for _, subscription := range subscriptions {
+		subscriptionUrl := rutil.SubscriptionUrl(subscription.Id)
+		var posts []DigestPost
+		for _, post := range subscription.Posts {
+			posts = append(posts, DigestPost{
+				Title: post.Title,
+				Url:   rutil.SubscriptionPostUrl(post.Title, post.RandomId),
+			})
+			postTitles = append(postTitles, post.Title)
+		}
+		digestSubscriptions = append(digestSubscriptions, DigestSubscription{
+			Name:                 subscription.Name,
+			Url:                  subscriptionUrl,
+			IsInitialItemPending: subscription.IsInitialItemPending,
+			IsFinalItemPending:   subscription.IsFinalItemPending,
+			RemainingCount:       subscription.RemainingCount,
+			Posts:                posts,
+		})
+
+		fmt.Fprintf(&text, "%s\n%s\n\n", subscription.Name, subscriptionUrl)
+		if subscription.IsInitialItemPending {
+			fmt.Fprintf(
+				&text, "%s has been added to FeedRewind. New posts will arrive here on your schedule.\n\n",
+				subscription.Name,
+			)
+		}
+		for _, post := range posts {
+			fmt.Fprintf(&text, "%s\n%s\n\n", post.Title, post.Url)
+		}
+		if subscription.IsFinalItemPending {
+			fmt.Fprintf(
+				&text, "You're all caught up with %s. Want to read something else? %s\n\n", subscription.Name,
+				rutil.SubscriptionAddUrl(),
+			)
+		} else if len(posts) > 0 {
+			fmt.Fprintf(&text, "%d left\n\n", subscription.RemainingCount)
+		}
+	}
+	fmt.Fprintf(
+		&text, "You're getting this because email delivery is on. Switch back to feeds: %s\n",
+		rutil.SettingsUrl(),
+	)
+
+	var subject string
+	switch {
+	case len(postTitles) == 1:
+		subject = postTitles[0]
+	case len(postTitles) > 1 && len(subscriptions) == 1:
+		subject = fmt.Sprintf("%d new posts from %s", len(postTitles), subscriptions[0].Name)
+	case len(postTitles) > 1:
+		subject = fmt.Sprintf("%d new posts", len(postTitles))
+	case subscriptions[0].IsFinalItemPending:
+		subject = fmt.Sprintf("You're all caught up with %s", subscriptions[0].Name)
+	default:
+		subject = fmt.Sprintf("%s added to FeedRewind", subscriptions[0].Name)
+	}
+
+	type DigestResult struct {
+		Subject       string
+		Subscriptions []DigestSubscription
+		AddUrl        string
+		SettingsUrl   string
+	}
+	html := templates.MustFormat("emails/digest", DigestResult{
+		Subject:       subject,
+		Subscriptions: digestSubscriptions,
+		AddUrl:        rutil.SubscriptionAddUrl(),
+		SettingsUrl:   rutil.SettingsUrl(),
+	})
+
+	return mail.Message{
+		To:             to,
+		Subject:        subject,
+		TextBody:       text.String(),
+		HtmlBody:       html,
+		UnsubscribeUrl: rutil.SettingsUrl(),
+	}
+}
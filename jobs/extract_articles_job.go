@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/publish"
+	"feedrewind.com/util"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Articles are extracted from the post pages for the posts in the feed and the ones coming up next, so
+// that the excerpts and the reader view are there by the time the posts are published
+const extractArticlesPublishedCount = 30
+const extractArticlesUnpublishedCount = 10
+
+func init() {
+	registerJobNameFunc(
+		"ExtractArticlesJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 {
+				return oops.Newf("Expected 1 arg, got %d: %v", len(args), args)
+			}
+
+			subscriptionIdInt64, ok := args[0].(int64)
+			if !ok {
+				subscriptionIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse subscriptionId (expected int64 or int): %v", args[0])
+				}
+				subscriptionIdInt64 = int64(subscriptionIdInt)
+			}
+			subscriptionId := models.SubscriptionId(subscriptionIdInt64)
+
+			return ExtractArticlesJob_Perform(ctx, pool, subscriptionId)
+		},
+	)
+}
+
+func ExtractArticlesJob_PerformNow(qu pgw.Queryable, subscriptionId models.SubscriptionId) error {
+	return performNow(qu, "ExtractArticlesJob", defaultQueue, int64ToYaml(int64(subscriptionId)))
+}
+
+// Publishing moves the window of posts forward
+func ExtractArticlesJob_ScheduleForUser(qu pgw.Queryable, userId models.UserId) error {
+	rows, err := qu.Query(`
+		select id from subscriptions_without_discarded
+		where user_id = $1 and (
+			include_post_content or
+			coalesce((select reader_view from user_settings where user_settings.user_id = $1), false)
+		)
+	`, userId)
+	if err != nil {
+		return err
+	}
+
+	var subscriptionIds []models.SubscriptionId
+	for rows.Next() {
+		var subscriptionId models.SubscriptionId
+		err := rows.Scan(&subscriptionId)
+		if err != nil {
+			return err
+		}
+		subscriptionIds = append(subscriptionIds, subscriptionId)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, subscriptionId := range subscriptionIds {
+		err := ExtractArticlesJob_PerformNow(qu, subscriptionId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ExtractArticlesJob_Perform(
+	ctx context.Context, pool *pgw.Pool, subscriptionId models.SubscriptionId,
+) error {
+	logger := pool.Logger()
+	row := pool.QueryRow(`
+		select user_id, include_post_content, coalesce((
+			select reader_view from user_settings
+			where user_settings.user_id = subscriptions_without_discarded.user_id
+		), false)
+		from subscriptions_without_discarded
+		where id = $1
+	`, subscriptionId)
+	var userId models.UserId
+	var includePostContent, readerView bool
+	err := row.Scan(&userId, &includePostContent, &readerView)
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.Info().Msgf("Subscription %d not found", subscriptionId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if !includePostContent && !readerView {
+		logger.Info().Msgf("Subscription %d doesn't need articles", subscriptionId)
+		return nil
+	}
+
+	posts, err := models.BlogPostArticle_ListMissing(
+		pool, subscriptionId, extractArticlesPublishedCount, extractArticlesUnpublishedCount, !readerView,
+	)
+	if err != nil {
+		return err
+	}
+	logger.Info().Msgf("Extracting %d articles for subscription %d", len(posts), subscriptionId)
+	if len(posts) == 0 {
+		return nil
+	}
+
+	httpClient := crawler.NewHttpClientImpl(ctx, nil, false)
+	zlogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+	progressLogger := crawler.NewMockProgressLogger(&zlogger)
+	crawlCtx := crawler.NewCrawlContext(httpClient, nil, progressLogger)
+	extractedCount := 0
+	for _, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return oops.Wrap(err)
+		}
+
+		var maybeContent, maybeExcerpt *string
+		content, err := crawler.ExtractArticleAtUrl(post.Url, &crawlCtx, &zlogger)
+		if err != nil {
+			logger.Info().Err(err).Msgf("Couldn't extract article for blog post %d", post.Id)
+		} else {
+			excerpt := crawler.ArticleExcerpt(content)
+			maybeContent = &content
+			maybeExcerpt = &excerpt
+			extractedCount++
+		}
+		err = models.BlogPostArticle_Upsert(pool, post.Id, maybeContent, maybeExcerpt)
+		if err != nil {
+			return err
+		}
+	}
+	logger.Info().Msgf("Extracted %d/%d articles for subscription %d", extractedCount, len(posts), subscriptionId)
+
+	if !includePostContent || extractedCount == 0 {
+		return nil
+	}
+
+	// The published posts pick up their excerpts
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return publish.RegenerateFeeds(tx, userId)
+	})
+}
@@ -76,15 +76,16 @@ func GuidedCrawlingJob_Perform(
 	}
 
 	row := pool.QueryRow(`
-		select title, url, final_url, content, start_page_id
+		select title, url, final_url, content, start_page_id, archives_url
 		from start_feeds
 		where id = $1
 	`, args.StartFeedId)
 	var startFeed crawler.Feed
 	var content []byte
 	var maybeStartPageId *models.StartPageId
+	var maybeArchivesUrl *string
 	err = row.Scan(
-		&startFeed.Title, &startFeed.Url, &startFeed.FinalUrl, &content, &maybeStartPageId,
+		&startFeed.Title, &startFeed.Url, &startFeed.FinalUrl, &content, &maybeStartPageId, &maybeArchivesUrl,
 	)
 	if err != nil {
 		return err
@@ -179,7 +180,19 @@ func GuidedCrawlingJob_Perform(
 		},
 	}
 
-	guidedCrawlResult, err := crawler.GuidedCrawl(maybeStartPage, startFeed, &crawlCtx, &zLogger)
+	maybeRecrawlHint, err := models.Blog_GetRecrawlHint(pool, blogFeedUrl)
+	if err != nil {
+		return err
+	}
+	if maybeRecrawlHint != nil {
+		logger.Info().Msgf(
+			"Previous version: %d posts, pattern %q", len(maybeRecrawlHint.Posts), maybeRecrawlHint.Pattern,
+		)
+	}
+
+	guidedCrawlResult, err := crawler.GuidedCrawl(
+		maybeStartPage, startFeed, maybeArchivesUrl, maybeRecrawlHint, &crawlCtx, &zLogger,
+	)
 	if ctxErr := ctx.Err(); ctxErr != nil {
 		return ctxErr
 	}
@@ -259,16 +272,21 @@ func GuidedCrawlingJob_Perform(
 					fullLinkCategories = append(fullLinkCategories, *linkCategories...)
 				}
 				fullLinkCategories = append(fullLinkCategories, "Everything")
+				var maybeContent *string
+				if content, ok := guidedCrawlResult.FeedEntryContents.Get(link.Curi); ok {
+					maybeContent = &content
+				}
 				crawledBlogPosts[i] = models.CrawledBlogPost{
-					Url:        link.Url,
-					Title:      link.Title.Value,
-					Categories: fullLinkCategories,
+					Url:          link.Url,
+					Title:        link.Title.Value,
+					Categories:   fullLinkCategories,
+					MaybeContent: maybeContent,
 				}
 			}
 
 			blogUpdatedAt, err := models.Blog_InitCrawled(
 				tx, blogId, *maybeBlogUrl, crawledBlogPosts, categories,
-				historicalResult.DiscardedFeedEntryUrls, guidedCrawlResult.CuriEqCfg,
+				historicalResult.DiscardedFeedEntryUrls, guidedCrawlResult.CuriEqCfg, historicalResult.Pattern,
 			)
 			if err != nil {
 				return err
@@ -338,6 +356,19 @@ func GuidedCrawlingJob_Perform(
 				tx, "crawling_title_fetch_duration", crawlCtx.TitleFetchDuration, map[string]any{
 					"feed_url":      startFeed.Url,
 					"requests_made": crawlCtx.TitleRequestsMade,
+					"time_saved":    crawlCtx.TitleFetchTimeSaved,
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if crawlCtx.PageMemory.SpilledPagesCount > 0 {
+			err := models.AdminTelemetry_Create(
+				tx, "crawling_pages_spilled", float64(crawlCtx.PageMemory.SpilledPagesCount), map[string]any{
+					"feed_url":          startFeed.Url,
+					"peak_parked_bytes": crawlCtx.PageMemory.PeakParkedBytes,
 				},
 			)
 			if err != nil {
@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+)
+
+// Feeds that couldn't be reached are retried by the job itself, so that the item stays in progress on the
+// import page and gives up within the hour
+var opmlImportRetryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute}
+
+func init() {
+	registerJobNameFunc(
+		"OpmlImportJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 && len(args) != 2 {
+				return oops.Newf("Expected 1 or 2 args, got %d: %v", len(args), args)
+			}
+
+			itemIdInt64, ok := args[0].(int64)
+			if !ok {
+				itemIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse itemId (expected int64 or int): %v", args[0])
+				}
+				itemIdInt64 = int64(itemIdInt)
+			}
+			itemId := models.OpmlImportItemId(itemIdInt64)
+
+			attempt := 0
+			if len(args) == 2 {
+				attemptInt64, ok := args[1].(int64)
+				if !ok {
+					attemptInt, ok := args[1].(int)
+					if !ok {
+						return oops.Newf("Failed to parse attempt (expected int64 or int): %v", args[1])
+					}
+					attemptInt64 = int64(attemptInt)
+				}
+				attempt = int(attemptInt64)
+			}
+
+			return OpmlImportJob_Perform(ctx, pool, itemId, attempt)
+		},
+	)
+}
+
+func OpmlImportJob_PerformNow(qu pgw.Queryable, itemId models.OpmlImportItemId) error {
+	return performNow(qu, "OpmlImportJob", defaultQueue, int64ToYaml(int64(itemId)))
+}
+
+func OpmlImportJob_PerformAt(
+	qu pgw.Queryable, runAt schedule.Time, itemId models.OpmlImportItemId, attempt int,
+) error {
+	return performAt(
+		qu, runAt, "OpmlImportJob", defaultQueue, int64ToYaml(int64(itemId)), int64ToYaml(int64(attempt)),
+	)
+}
+
+// Goes through the same steps as adding a feed url by hand. If the blog has been crawled before, all of its
+// posts get selected and a default schedule is created, so that the subscription lands on the schedule step
+// with it filled in.
+func OpmlImportJob_Perform(
+	ctx context.Context, pool *pgw.Pool, itemId models.OpmlImportItemId, attempt int,
+) error {
+	logger := pool.Logger()
+	item, err := models.OpmlImportItem_Get(pool, itemId)
+	if errors.Is(err, models.ErrOpmlImportItemNotFound) {
+		logger.Info().Msgf("OPML import item %d is gone", itemId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if item.Status != models.OpmlImportItemStatusPending {
+		logger.Info().Msgf("OPML import item %d is already %s", itemId, item.Status)
+		return nil
+	}
+	user, err := models.User_GetById(pool, item.UserId)
+	if errors.Is(err, models.ErrUserNotFound) {
+		logger.Info().Msgf("User %d is gone", item.UserId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	httpClient := crawler.NewHttpClientImpl(ctx, nil, false)
+	zlogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+	progressLogger := crawler.NewMockProgressLogger(&zlogger)
+	crawlCtx := crawler.NewCrawlContext(httpClient, nil, progressLogger)
+	fetchFeedResult := crawler.FetchFeedAtUrl(item.FeedUrl, true, &crawlCtx, &zlogger)
+	var startFeed *models.StartFeed
+	switch fetchResult := fetchFeedResult.(type) {
+	case *crawler.FetchedPage:
+		parsedFeed, err := crawler.ParseFeed(fetchResult.Page.Content, fetchResult.Page.FetchUri, &zlogger)
+		if err != nil {
+			logger.Info().Err(err).Msgf("Couldn't parse the feed at %s", item.FeedUrl)
+			return models.OpmlImportItem_MarkFailed(pool, itemId, "Not a feed")
+		}
+		startFeed, err = models.StartFeed_CreateFetched(pool, nil, crawler.DiscoveredFetchedFeed{
+			Title:      parsedFeed.Title,
+			Url:        item.FeedUrl,
+			FinalUrl:   fetchResult.Page.FetchUri.String(),
+			Content:    fetchResult.Page.Content,
+			ParsedFeed: parsedFeed,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	case *crawler.FetchFeedErrorBadFeed:
+		return models.OpmlImportItem_MarkFailed(pool, itemId, "Not a feed")
+	case *crawler.FetchFeedErrorCouldNotReach:
+		if attempt < len(opmlImportRetryDelays) {
+			retryDelay := opmlImportRetryDelays[attempt]
+			logger.Info().Msgf("Couldn't reach %s, retrying in %s", item.FeedUrl, retryDelay)
+			return OpmlImportJob_PerformAt(pool, schedule.UTCNow().Add(retryDelay), itemId, attempt+1)
+		}
+		return models.OpmlImportItem_MarkFailed(pool, itemId, "Couldn't reach the feed")
+	default:
+		return oops.Newf("Unexpected fetch feed result type: %T", fetchFeedResult)
+	}
+
+	blog, err := models.Blog_CreateOrUpdate(pool, startFeed, GuidedCrawlingJob_PerformNow)
+	if err != nil {
+		return err
+	}
+	if models.BlogFailedStatuses[blog.Status] {
+		logger.Info().Msgf("Blog %d is %s", blog.Id, blog.Status)
+		return models.OpmlImportItem_MarkFailed(pool, itemId, "This blog isn't supported yet")
+	}
+
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		subscriptionCreateResult, err := models.Subscription_CreateForBlog(tx, blog, user, user.ProductUserId)
+		if err != nil {
+			return err
+		}
+		subscriptionId := subscriptionCreateResult.Id
+
+		isBlogCrawled := models.BlogCrawledStatuses[blog.Status]
+		if isBlogCrawled {
+			blogPosts, err := models.BlogPost_List(tx, blog.Id)
+			if err != nil {
+				return err
+			}
+			blogPostIds := make(map[models.BlogPostId]bool)
+			for _, blogPost := range blogPosts {
+				blogPostIds[blogPost.Id] = true
+			}
+			err = models.Subscription_CreatePostsFromIds(
+				tx, subscriptionId, blog.Id, blogPostIds, models.PostOrderChronological,
+			)
+			if err != nil {
+				return err
+			}
+			otherSubNamesByDay, err := models.Subscription_GetOtherNamesByDay(tx, subscriptionId, user.Id)
+			if err != nil {
+				return err
+			}
+			err = models.Schedule_Create(
+				tx, subscriptionId, models.Schedule_DefaultCountsByDay(otherSubNamesByDay),
+			)
+			if err != nil {
+				return err
+			}
+			err = models.Subscription_UpdateStatus(tx, subscriptionId, models.SubscriptionStatusSetup)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = models.OpmlImportItem_MarkSubscribed(tx, itemId, subscriptionId)
+		if err != nil {
+			return err
+		}
+		models.ProductEvent_MustEmit(tx, user.ProductUserId, "create subscription", map[string]any{
+			"subscription_id":   subscriptionId,
+			"blog_url":          subscriptionCreateResult.BlogBestUrl,
+			"is_blog_crawled":   isBlogCrawled,
+			"user_is_anonymous": false,
+			"source":            "opml",
+		}, nil)
+		logger.Info().Msgf("OPML import item %d got subscription %d", itemId, subscriptionId)
+		return nil
+	})
+}
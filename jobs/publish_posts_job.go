@@ -89,7 +89,7 @@ func PublishPostsJob_Perform(
 			select count(*) from subscriptions_without_discarded
 			join (
 				select subscription_id, count(*) from subscription_posts
-				where published_at_local_date = $1
+				where published_at_local_date = $1 and not is_delivered_now
 				group by subscription_id
 			) as subscription_posts on subscriptions_without_discarded.id = subscription_posts.subscription_id
 			where user_id = $2 and subscription_posts.count > 0
@@ -114,17 +114,52 @@ func PublishPostsJob_Perform(
 		localTime := utcNow.In(location)
 		localDate := localTime.Date()
 		if date >= localDate {
-			productUserId, err := models.User_GetProductUserId(tx, userId)
+			catchUpFrom := date
+			for i := 0; i < models.VacationMaxCatchUpDays; i++ {
+				catchUpFrom = catchUpFrom.PrevDay()
+			}
+			vacations, err := models.Vacation_ListEndingFrom(tx, userId, catchUpFrom)
 			if err != nil {
 				return err
 			}
 
-			err = publish.PublishForUser(
-				tx, userId, productUserId, *userSettings.MaybeDeliveryChannel, utcNow, localTime, localDate,
-				scheduledForStr,
-			)
-			if err != nil {
-				return err
+			if models.Vacation_Covers(vacations, date) {
+				logger.Info().Msgf("User is on vacation on %s, skipping the update", date)
+			} else {
+				var catchUpDates []schedule.Date
+				if userSettings.CatchUpAfterVacation {
+					missedDate := date.PrevDay()
+					for missedDate >= catchUpFrom && models.Vacation_Covers(vacations, missedDate) {
+						catchUpDates = append(catchUpDates, missedDate)
+						missedDate = missedDate.PrevDay()
+					}
+					if len(catchUpDates) > 0 {
+						logger.Info().Msgf("Catching up on %d vacation days", len(catchUpDates))
+					}
+				}
+
+				productUserId, err := models.User_GetProductUserId(tx, userId)
+				if err != nil {
+					return err
+				}
+
+				err = publish.PublishForUser(
+					tx, userId, productUserId, *userSettings.MaybeDeliveryChannel, utcNow, localTime, localDate,
+					scheduledForStr, catchUpDates,
+				)
+				if err != nil {
+					return err
+				}
+
+				err = PublishPostsJob_SchedulePushes(tx, userId, *userSettings.MaybeDeliveryChannel, utcNow)
+				if err != nil {
+					return err
+				}
+
+				err = ExtractArticlesJob_ScheduleForUser(tx, userId)
+				if err != nil {
+					return err
+				}
 			}
 		} else {
 			logger.Warn().Msgf(
@@ -134,29 +169,20 @@ func PublishPostsJob_Perform(
 		}
 
 		if !isManual {
-			hourOfDay := PublishPostsJob_GetHourOfDay(*userSettings.MaybeDeliveryChannel)
 			nextDate := date.NextDay()
-			jobTime, err := nextDate.TimeIn(location)
-			if err != nil {
-				return err
-			}
-			nextRun := jobTime.Add(time.Duration(hourOfDay) * time.Hour).UTC()
+			nextRun := PublishPostsJob_GetRunAt(nextDate, location, userSettings)
 			daysSkipped := 0
 			for nextRun.Before(utcNow) {
 				daysSkipped++
 				nextDate = nextDate.NextDay()
-				jobTime, err := nextDate.TimeIn(location)
-				if err != nil {
-					return err
-				}
-				nextRun = jobTime.Add(time.Duration(hourOfDay) * time.Hour).UTC()
+				nextRun = PublishPostsJob_GetRunAt(nextDate, location, userSettings)
 			}
 			if daysSkipped > 0 {
 				logger.Warn().Msgf("Skipping %d days", daysSkipped)
 			}
 
 			nextScheduledFor := nextRun.MustUTCString()
-			err = PublishPostsJob_PerformAt(tx, nextRun, userId, nextDate, nextScheduledFor, false)
+			err := PublishPostsJob_PerformAt(tx, nextRun, userId, nextDate, nextScheduledFor, false)
 			if err != nil {
 				return err
 			}
@@ -168,21 +194,40 @@ func PublishPostsJob_Perform(
 	return err
 }
 
+// Pushes the posts published at publishedAt to wherever the delivery channel expects them
+func PublishPostsJob_SchedulePushes(
+	qu pgw.Queryable, userId models.UserId, deliveryChannel models.DeliveryChannel,
+	publishedAt schedule.Time,
+) error {
+	switch deliveryChannel {
+	case models.DeliveryChannelEmail:
+		return EmailDigestJob_PerformNow(qu, userId)
+	case models.DeliveryChannelWebhook:
+		// Feeds keep being published for webhook users too
+		err := WebSubDeliverJob_ScheduleForPublished(qu, userId, publishedAt)
+		if err != nil {
+			return err
+		}
+		return WebhookDeliverJob_ScheduleForPublished(qu, userId, publishedAt)
+	default:
+		return WebSubDeliverJob_ScheduleForPublished(qu, userId, publishedAt)
+	}
+}
+
 func PublishPostsJob_ScheduleInitial(
 	qu pgw.Queryable, userId models.UserId, userSettings *models.UserSettings, isManual bool,
 ) error {
 	utcNow := schedule.UTCNow()
 	location := tzdata.LocationByName[userSettings.Timezone]
-	date := utcNow.BeginningOfDayIn(location).AddDate(0, 0, -1)
-	hourOfDay := PublishPostsJob_GetHourOfDay(*userSettings.MaybeDeliveryChannel)
-	nextRun := date.Add(time.Duration(hourOfDay) * time.Hour).UTC()
+	date := utcNow.In(location).Date().PrevDay()
+	nextRun := PublishPostsJob_GetRunAt(date, location, userSettings)
 	for nextRun.Before(utcNow) {
-		date = date.AddDate(0, 0, 1)
-		nextRun = date.Add(time.Duration(hourOfDay) * time.Hour).UTC()
+		date = date.NextDay()
+		nextRun = PublishPostsJob_GetRunAt(date, location, userSettings)
 	}
 	nextRunDate := nextRun.MustUTCString()
 
-	return PublishPostsJob_PerformAt(qu, nextRun, userId, date.Date(), nextRunDate, isManual)
+	return PublishPostsJob_PerformAt(qu, nextRun, userId, date, nextRunDate, isManual)
 }
 
 func PublishPostsJob_Delete(
@@ -286,10 +331,41 @@ func PublishPostsJob_GetNextScheduledDate(qu pgw.Queryable, userId models.UserId
 	return date, nil
 }
 
+// The job runs at the user's delivery time on the local date. The wall clock time is kept across DST
+// transitions, a time that doesn't exist on the date moves forward by the length of the gap.
+func PublishPostsJob_GetRunAt(
+	date schedule.Date, location *time.Location, userSettings *models.UserSettings,
+) schedule.Time {
+	deliveryMinutes := PublishPostsJob_GetDeliveryMinutes(userSettings)
+	year, month, day := time.Time(date.Time()).Date()
+	runAt := time.Date(year, month, day, deliveryMinutes/60, deliveryMinutes%60, 0, 0, location)
+	// time.Date resolves a skipped wall clock time with either the offset from before the gap or the one
+	// from after it. Before the gap, the time is moved by the size of the gap so that it lands after the
+	// transition
+	if localMinutes := runAt.Hour()*60 + runAt.Minute(); localMinutes < deliveryMinutes {
+		_, offsetBefore := runAt.Zone()
+		_, transitionAt := runAt.ZoneBounds()
+		_, offsetAfter := transitionAt.Zone()
+		runAt = runAt.Add(time.Duration(offsetAfter-offsetBefore) * time.Second)
+	}
+	return schedule.Time(runAt).UTC()
+}
+
+func PublishPostsJob_GetDeliveryMinutes(userSettings *models.UserSettings) int {
+	if userSettings.MaybeDeliveryMinutes != nil {
+		return *userSettings.MaybeDeliveryMinutes
+	}
+	return PublishPostsJob_GetHourOfDay(*userSettings.MaybeDeliveryChannel) * 60
+}
+
+// Default delivery hour for users who haven't picked a time
 func PublishPostsJob_GetHourOfDay(deliveryChannel models.DeliveryChannel) int {
 	switch deliveryChannel {
-	case models.DeliveryChannelMultipleFeeds, models.DeliveryChannelSingleFeed:
+	case models.DeliveryChannelMultipleFeeds, models.DeliveryChannelSingleFeed, models.DeliveryChannelWebhook:
 		return 2
+	case models.DeliveryChannelEmail:
+		// Unlike feeds, emails show up right away and shouldn't arrive in the middle of the night
+		return 5
 	default:
 		panic(fmt.Errorf("UnknownDeliveryChannel: %s", deliveryChannel))
 	}
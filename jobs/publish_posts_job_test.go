@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"testing"
+
+	"feedrewind.com/models"
+	"feedrewind.com/third_party/tzdata"
+	"feedrewind.com/util/schedule"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishPostsJobGetRunAt(t *testing.T) {
+	location := tzdata.LocationByName["America/Los_Angeles"]
+	deliveryChannel := models.DeliveryChannelMultipleFeeds
+	deliveryMinutes := 2*60 + 30
+
+	type Test struct {
+		description          string
+		date                 schedule.Date
+		maybeDeliveryMinutes *int
+		expected             string
+	}
+	tests := []Test{
+		{
+			description:          "channel default",
+			date:                 "2024-01-15",
+			maybeDeliveryMinutes: nil,
+			expected:             "2024-01-15 10:00:00",
+		},
+		{
+			description:          "chosen time",
+			date:                 "2024-01-15",
+			maybeDeliveryMinutes: &deliveryMinutes,
+			expected:             "2024-01-15 10:30:00",
+		},
+		{
+			description:          "day after spring forward keeps the wall clock time",
+			date:                 "2024-03-11",
+			maybeDeliveryMinutes: &deliveryMinutes,
+			expected:             "2024-03-11 09:30:00",
+		},
+		{
+			description:          "time skipped by spring forward moves by the gap",
+			date:                 "2024-03-10",
+			maybeDeliveryMinutes: &deliveryMinutes,
+			expected:             "2024-03-10 10:30:00",
+		},
+		{
+			description:          "fall back day",
+			date:                 "2024-11-03",
+			maybeDeliveryMinutes: &deliveryMinutes,
+			expected:             "2024-11-03 10:30:00",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			userSettings := &models.UserSettings{ //nolint:exhaustruct
+				MaybeDeliveryChannel: &deliveryChannel,
+				MaybeDeliveryMinutes: tc.maybeDeliveryMinutes,
+			}
+			runAt := PublishPostsJob_GetRunAt(tc.date, location, userSettings)
+			require.Equal(t, tc.expected, runAt.Format("2006-01-02 15:04:05"))
+		})
+	}
+}
+
+func TestPublishPostsJobGetRunAtHalfHourGap(t *testing.T) {
+	// Lord Howe Island moves clocks from 02:00 to 02:30
+	location := tzdata.LocationByName["Australia/Lord_Howe"]
+	deliveryChannel := models.DeliveryChannelMultipleFeeds
+
+	type Test struct {
+		description     string
+		date            schedule.Date
+		deliveryMinutes int
+		expected        string
+	}
+	tests := []Test{
+		{
+			description:     "before the gap",
+			date:            "2024-10-06",
+			deliveryMinutes: 1*60 + 45,
+			expected:        "2024-10-05 15:15:00",
+		},
+		{
+			description:     "time skipped by the gap moves by 30 minutes",
+			date:            "2024-10-06",
+			deliveryMinutes: 2*60 + 15,
+			expected:        "2024-10-05 15:45:00",
+		},
+		{
+			description:     "after the gap",
+			date:            "2024-10-06",
+			deliveryMinutes: 2*60 + 45,
+			expected:        "2024-10-05 15:45:00",
+		},
+		{
+			description:     "day after the gap",
+			date:            "2024-10-07",
+			deliveryMinutes: 2*60 + 15,
+			expected:        "2024-10-06 15:15:00",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			userSettings := &models.UserSettings{ //nolint:exhaustruct
+				MaybeDeliveryChannel: &deliveryChannel,
+				MaybeDeliveryMinutes: &tc.deliveryMinutes,
+			}
+			runAt := PublishPostsJob_GetRunAt(tc.date, location, userSettings)
+			require.Equal(t, tc.expected, runAt.Format("2006-01-02 15:04:05"))
+		})
+	}
+}
@@ -233,7 +233,7 @@ feeds:
 			}
 		}
 
-		startFeed, err := models.StartFeed_CreateFetched(pool, nil, discoveredSingleFeed.Feed)
+		startFeed, err := models.StartFeed_CreateFetched(pool, nil, discoveredSingleFeed.Feed, nil)
 		if err != nil {
 			return err
 		}
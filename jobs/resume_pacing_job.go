@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/publish"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+)
+
+func init() {
+	registerJobNameFunc(
+		"ResumePacingJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 {
+				return oops.Newf("Expected 1 arg, got %d: %v", len(args), args)
+			}
+
+			subscriptionIdInt64, ok := args[0].(int64)
+			if !ok {
+				subscriptionIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse subscriptionId (expected int64 or int): %v", args[0])
+				}
+				subscriptionIdInt64 = int64(subscriptionIdInt)
+			}
+			subscriptionId := models.SubscriptionId(subscriptionIdInt64)
+
+			return ResumePacingJob_Perform(ctx, pool, subscriptionId)
+		},
+	)
+}
+
+// Opening a post brings a slowed down subscription back to its schedule. The feeds are regenerated here and
+// not in the request, as the post links are hit by anyone who has them.
+func ResumePacingJob_PerformNow(qu pgw.Queryable, subscriptionId models.SubscriptionId) error {
+	return performNow(qu, "ResumePacingJob", defaultQueue, int64ToYaml(int64(subscriptionId)))
+}
+
+func ResumePacingJob_Perform(
+	ctx context.Context, pool *pgw.Pool, subscriptionId models.SubscriptionId,
+) error {
+	logger := pool.Logger()
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		isResumed, err := models.Subscription_ResumePacing(tx, subscriptionId, schedule.UTCNow())
+		if err != nil {
+			return err
+		}
+		if !isResumed {
+			logger.Info().Msgf("Subscription %d is already on its schedule", subscriptionId)
+			return nil
+		}
+		logger.Info().Msgf("Subscription %d is back to its schedule", subscriptionId)
+
+		row := tx.QueryRow(`
+			select
+				user_id,
+				(select product_user_id from users_without_discarded where users_without_discarded.id = user_id),
+				(select coalesce(url, feed_url) from blogs where blogs.id = blog_id)
+			from subscriptions_without_discarded
+			where id = $1
+		`, subscriptionId)
+		var userId models.UserId
+		var productUserId models.ProductUserId
+		var blogBestUrl string
+		err = row.Scan(&userId, &productUserId, &blogBestUrl)
+		if err != nil {
+			return err
+		}
+
+		// Takes the paused item out of the feeds
+		err = publish.RegenerateFeeds(tx, userId)
+		if err != nil {
+			return err
+		}
+		models.ProductEvent_MustEmit(tx, productUserId, "speed up subscription", map[string]any{
+			"subscription_id": subscriptionId,
+			"blog_url":        blogBestUrl,
+			"source":          "open post",
+		}, nil)
+		return nil
+	})
+}
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"feedrewind.com/crawler"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/util"
+
+	"github.com/goccy/go-json"
+	"github.com/jackc/pgx/v5"
+)
+
+func init() {
+	registerJobNameFunc(
+		"TableOfContentsJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 2 {
+				return oops.Newf("Expected 2 args, got %d: %v", len(args), args)
+			}
+
+			blogIdInt64, ok := args[0].(int64)
+			if !ok {
+				blogIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse blogId (expected int64 or int): %v", args[0])
+				}
+				blogIdInt64 = int64(blogIdInt)
+			}
+			blogId := models.BlogId(blogIdInt64)
+
+			argsJson, ok := args[1].(string)
+			if !ok {
+				return oops.Newf("Failed to parse args (expected string): %v", args[1])
+			}
+
+			return TableOfContentsJob_Perform(ctx, id, pool, blogId, argsJson)
+		},
+	)
+}
+
+type TableOfContentsJobArgs struct {
+	StartPageId models.StartPageId `json:"start_page_id"`
+}
+
+func TableOfContentsJob_PerformNow(
+	qu pgw.Queryable, blogId models.BlogId, startPageId models.StartPageId,
+) error {
+	args := TableOfContentsJobArgs{
+		StartPageId: startPageId,
+	}
+	argsJson, err := json.Marshal(&args)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	return performNow(
+		qu, "TableOfContentsJob", guidedCrawlingQueue, int64ToYaml(int64(blogId)), strToYaml(string(argsJson)),
+	)
+}
+
+func TableOfContentsJob_Perform(
+	ctx context.Context, id JobId, pool *pgw.Pool, blogId models.BlogId, argsJson string,
+) error {
+	startTime := time.Now().UTC()
+	logger := pool.Logger()
+	var args TableOfContentsJobArgs
+	err := json.Unmarshal([]byte(argsJson), &args)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+
+	startPage, err := models.StartPage_Get(pool, args.StartPageId)
+	if err != nil {
+		return err
+	}
+
+	row := pool.QueryRow(`select feed_url, name from blogs where id = $1`, blogId)
+	var blogFeedUrl string
+	var blogName string
+	err = row.Scan(&blogFeedUrl, &blogName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.Info().Msg("Blog not found")
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	checkCancellationFunc := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		row := pool.QueryRow(`select 1 from delayed_jobs where id = $1`, id)
+		var one int
+		err = row.Scan(&one)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return crawler.ErrCrawlCanceled
+		} else if err != nil {
+			logger.Warn().Err(err).Msg("Couldn't check if the job was deleted")
+			return nil
+		}
+		return nil
+	}
+	httpClient := crawler.NewHttpClientImpl(ctx, checkCancellationFunc, true)
+	progressSaver := NewProgressSaver(blogId, blogFeedUrl, logger, pool)
+	progressLogger := crawler.NewProgressLogger(progressSaver)
+	crawlCtx := crawler.NewCrawlContext(httpClient, nil, progressLogger)
+	zLogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+
+	historicalResult, err := crawler.CrawlTableOfContents(startPage, &crawlCtx, &zLogger)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if errors.Is(err, crawler.ErrCrawlCanceled) {
+		return err
+	}
+	if err != nil {
+		logger.Info().Err(err).Msg("Table of contents failed")
+		historicalResult = nil
+	}
+
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		var blogUpdatedAt time.Time
+		var eventType string
+		if historicalResult != nil {
+			logger.Info().Msgf("Table of contents succeeded, saving %d posts", len(historicalResult.Links))
+			categories := []models.NewBlogPostCategory{{
+				Name:      "Everything",
+				Index:     0,
+				TopStatus: models.BlogPostCategoryTopOnly,
+			}}
+			crawledBlogPosts := make([]models.CrawledBlogPost, len(historicalResult.Links))
+			for i, link := range historicalResult.Links {
+				crawledBlogPosts[i] = models.CrawledBlogPost{
+					Url:          link.Url,
+					Title:        link.Title.Value,
+					Categories:   []string{"Everything"},
+					MaybeContent: nil,
+				}
+			}
+			curiEqCfg := crawler.NewCanonicalEqualityConfig()
+			blogUpdatedAt, err = models.Blog_InitCrawled(
+				tx, blogId, historicalResult.BlogLink.Url, crawledBlogPosts, categories, nil, &curiEqCfg,
+				historicalResult.Pattern,
+			)
+			if err != nil {
+				return err
+			}
+			eventType = "table of contents succeeded"
+		} else {
+			row := tx.QueryRow(`
+				update blogs set status = $1 where id = $2 returning updated_at
+			`, models.BlogStatusCrawlFailed, blogId)
+			err := row.Scan(&blogUpdatedAt)
+			if err != nil {
+				return err
+			}
+			eventType = "table of contents failed"
+		}
+		err := logCrawlFinished(tx, blogId, blogUpdatedAt, eventType)
+		if err != nil {
+			return err
+		}
+
+		elapsedSeconds := time.Since(startTime).Seconds()
+		slackVerb := "succeeded"
+		if historicalResult == nil {
+			slackVerb = "failed"
+		}
+		slackText := fmt.Sprintf(
+			"Table of contents *<%s|%s>* %s in %.1f seconds",
+			NotifySlackJob_Escape(startPage.Url), NotifySlackJob_Escape(blogName), slackVerb, elapsedSeconds,
+		)
+		err = NotifySlackJob_PerformNow(tx, slackText)
+		if err != nil {
+			return err
+		}
+
+		payload := map[string]any{
+			"blog_id": fmt.Sprint(blogId),
+			"done":    true,
+		}
+		payloadBytes, err := json.Marshal(&payload)
+		if err != nil {
+			return oops.Wrap(err)
+		}
+		_, err = tx.Exec(`select pg_notify($1, $2)`, CrawlProgressChannelName, string(payloadBytes))
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("%s %d done:true", CrawlProgressChannelName, blogId)
+		return nil
+	})
+}
@@ -0,0 +1,294 @@
+package jobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+
+	"github.com/goccy/go-json"
+)
+
+// Retries are scheduled by the job itself rather than by the worker, so that every attempt shows up in the
+// delivery log and the whole thing gives up within a day
+var webhookRetryDelays = []time.Duration{
+	time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour,
+}
+
+// Failed deliveries in a row, each after all of its retries
+const webhookMaxConsecutiveFailures = 3
+
+// Webhook urls are user input, so they only get to reach the public internet
+var webhookHttpClient = util.NewPublicHttpClient(15 * time.Second)
+
+// Returns false when the delivery should give up after this many attempts
+func webhookRetryDelay(attempts int) (time.Duration, bool) {
+	if attempts >= len(webhookRetryDelays) {
+		return 0, false
+	}
+	return webhookRetryDelays[attempts], true
+}
+
+func webhookShouldDisable(consecutiveFailures int) bool {
+	return consecutiveFailures >= webhookMaxConsecutiveFailures
+}
+
+// The delivery log is shown to the user, so it only gets generic text that doesn't tell anything about our
+// network. The details go to our logs.
+func webhookErrorMessage(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, util.ErrNonPublicAddress):
+		return "The url doesn't lead to a public address"
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return "Timed out"
+	default:
+		return "Couldn't connect"
+	}
+}
+
+func init() {
+	registerJobNameFunc(
+		"WebhookDeliverJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 {
+				return oops.Newf("Expected 1 arg, got %d: %v", len(args), args)
+			}
+
+			deliveryIdInt64, ok := args[0].(int64)
+			if !ok {
+				deliveryIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf("Failed to parse deliveryId (expected int64 or int): %v", args[0])
+				}
+				deliveryIdInt64 = int64(deliveryIdInt)
+			}
+			deliveryId := models.WebhookDeliveryId(deliveryIdInt64)
+
+			return WebhookDeliverJob_Perform(ctx, pool, deliveryId)
+		},
+	)
+}
+
+func WebhookDeliverJob_PerformAt(
+	qu pgw.Queryable, runAt schedule.Time, deliveryId models.WebhookDeliveryId,
+) error {
+	return performAt(qu, runAt, "WebhookDeliverJob", defaultQueue, int64ToYaml(int64(deliveryId)))
+}
+
+type webhookPayload struct {
+	Type        string               `json:"type"`
+	PublishedAt string               `json:"published_at"`
+	Posts       []webhookPayloadPost `json:"posts"`
+}
+
+type webhookPayloadPost struct {
+	Title            string `json:"title"`
+	Url              string `json:"url"`
+	OriginalUrl      string `json:"original_url"`
+	SubscriptionId   int64  `json:"subscription_id"`
+	SubscriptionName string `json:"subscription_name"`
+	SubscriptionUrl  string `json:"subscription_url"`
+}
+
+// Called within the publish transaction, so the delivery only goes out once the posts are committed
+func WebhookDeliverJob_ScheduleForPublished(
+	qu pgw.Queryable, userId models.UserId, publishedAt schedule.Time,
+) error {
+	logger := qu.Logger()
+	webhook, err := models.Webhook_Get(qu, userId)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		logger.Warn().Msg("Webhook delivery without a webhook")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if webhook.MaybeDisabledAt != nil {
+		logger.Info().Msgf("Webhook is disabled since %s", *webhook.MaybeDisabledAt)
+		return nil
+	}
+
+	posts, err := models.WebhookPost_ListPublished(qu, userId, publishedAt)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 {
+		logger.Info().Msg("No new posts for the webhook")
+		return nil
+	}
+
+	payload := webhookPayload{
+		Type:        "posts.published",
+		PublishedAt: publishedAt.Format(time.RFC3339),
+		Posts:       nil,
+	}
+	for _, post := range posts {
+		payload.Posts = append(payload.Posts, webhookPayloadPost{
+			Title:            post.Title,
+			Url:              rutil.SubscriptionPostUrl(post.Title, post.RandomId),
+			OriginalUrl:      post.OriginalUrl,
+			SubscriptionId:   int64(post.SubscriptionId),
+			SubscriptionName: post.SubscriptionName,
+			SubscriptionUrl:  rutil.SubscriptionUrl(post.SubscriptionId),
+		})
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+
+	deliveryId, err := models.WebhookDelivery_Create(qu, userId, webhook.Url, string(payloadBytes), len(posts))
+	if err != nil {
+		return err
+	}
+	err = WebhookDeliverJob_PerformAt(qu, schedule.UTCNow(), deliveryId)
+	if err != nil {
+		return err
+	}
+	logger.Info().Msgf("Scheduled webhook delivery %d with %d posts", deliveryId, len(posts))
+	return nil
+}
+
+// The signature is HMAC-SHA256 over "<timestamp>.<body>" with the webhook secret, so that a captured request
+// can't be replayed with a fresh timestamp
+func WebhookDeliverJob_Sign(secret string, timestamp string, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func WebhookDeliverJob_Perform(
+	ctx context.Context, pool *pgw.Pool, deliveryId models.WebhookDeliveryId,
+) error {
+	logger := pool.Logger()
+	delivery, err := models.WebhookDelivery_Get(pool, deliveryId)
+	if errors.Is(err, models.ErrWebhookDeliveryNotFound) {
+		logger.Info().Msgf("Webhook delivery %d is gone", deliveryId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryStatusPending {
+		logger.Info().Msgf("Webhook delivery %d is already %s", deliveryId, delivery.Status)
+		return nil
+	}
+
+	// Retries go to the current url, in case the user has fixed it in the meantime
+	webhook, err := models.Webhook_Get(pool, delivery.UserId)
+	if errors.Is(err, models.ErrWebhookNotFound) || (err == nil && webhook.MaybeDisabledAt != nil) {
+		logger.Info().Msgf("Webhook for delivery %d is gone or disabled", deliveryId)
+		utcNow := schedule.UTCNow()
+		message := "Webhook was disabled"
+		return models.WebhookDelivery_RecordAttempt(
+			pool, deliveryId, delivery.Url, models.WebhookDeliveryStatusFailed, nil, &message, &utcNow,
+		)
+	} else if err != nil {
+		return err
+	}
+
+	timestamp := fmt.Sprint(time.Now().Unix())
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, webhook.Url, strings.NewReader(delivery.Payload),
+	)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FeedRewind-Webhook")
+	req.Header.Set("X-FeedRewind-Delivery", fmt.Sprint(deliveryId))
+	req.Header.Set("X-FeedRewind-Timestamp", timestamp)
+	req.Header.Set("X-FeedRewind-Signature", WebhookDeliverJob_Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	var maybeResponseCode *int
+	var maybeError *string
+	resp, err := webhookHttpClient.Do(req)
+	if err != nil {
+		logger.Info().Err(err).Msgf("Webhook delivery %d couldn't connect", deliveryId)
+		message := webhookErrorMessage(err)
+		maybeError = &message
+	} else {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		maybeResponseCode = &resp.StatusCode
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			message := fmt.Sprintf("Unexpected status %d", resp.StatusCode)
+			maybeError = &message
+		}
+	}
+
+	return util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		utcNow := schedule.UTCNow()
+		if maybeError == nil {
+			logger.Info().Msgf("Delivered webhook delivery %d", deliveryId)
+			err := models.WebhookDelivery_RecordAttempt(
+				tx, deliveryId, webhook.Url, models.WebhookDeliveryStatusDelivered, maybeResponseCode, nil,
+				&utcNow,
+			)
+			if err != nil {
+				return err
+			}
+			return models.Webhook_RecordSuccess(tx, delivery.UserId)
+		}
+
+		if retryDelay, ok := webhookRetryDelay(delivery.Attempts); ok {
+			logger.Info().Msgf(
+				"Webhook delivery %d failed (%s), retrying in %s", deliveryId, *maybeError, retryDelay,
+			)
+			err := models.WebhookDelivery_RecordAttempt(
+				tx, deliveryId, webhook.Url, models.WebhookDeliveryStatusPending, maybeResponseCode,
+				maybeError, nil,
+			)
+			if err != nil {
+				return err
+			}
+			return WebhookDeliverJob_PerformAt(tx, utcNow.Add(retryDelay), deliveryId)
+		}
+
+		logger.Warn().Msgf("Webhook delivery %d failed (%s), giving up", deliveryId, *maybeError)
+		err := models.WebhookDelivery_RecordAttempt(
+			tx, deliveryId, webhook.Url, models.WebhookDeliveryStatusFailed, maybeResponseCode, maybeError,
+			&utcNow,
+		)
+		if err != nil {
+			return err
+		}
+		consecutiveFailures, err := models.Webhook_RecordFailure(tx, delivery.UserId)
+		if err != nil {
+			return err
+		}
+		if !webhookShouldDisable(consecutiveFailures) {
+			return nil
+		}
+		isDisabled, err := models.Webhook_Disable(tx, delivery.UserId, utcNow)
+		if err != nil {
+			return err
+		}
+		if isDisabled {
+			logger.Warn().Msgf("Disabled the webhook for user %d", delivery.UserId)
+			productUserId, err := models.User_GetProductUserId(tx, delivery.UserId)
+			if err != nil {
+				return err
+			}
+			models.ProductEvent_MustEmit(tx, productUserId, "disable webhook", map[string]any{
+				"consecutive_failures": consecutiveFailures,
+			}, nil)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"feedrewind.com/util"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliverJobSign(t *testing.T) {
+	signature := WebhookDeliverJob_Sign("secret", "1700000000", `{"type":"posts.published"}`)
+	require.Equal(t, "sha256=1349fbc2eb612a01570a22fad15c0ee22a404bbca162ad323b948bbb95651e68", signature)
+
+	require.NotEqual(t, signature, WebhookDeliverJob_Sign("secret", "1700000001", `{"type":"posts.published"}`))
+	require.NotEqual(t, signature, WebhookDeliverJob_Sign("other", "1700000000", `{"type":"posts.published"}`))
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	var delays []time.Duration
+	var total time.Duration
+	for attempts := 0; ; attempts++ {
+		delay, ok := webhookRetryDelay(attempts)
+		if !ok {
+			break
+		}
+		delays = append(delays, delay)
+		total += delay
+	}
+	require.Equal(t, []time.Duration{
+		time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour,
+	}, delays)
+	require.Less(t, total, 24*time.Hour)
+
+	_, ok := webhookRetryDelay(len(delays) + 1)
+	require.False(t, ok)
+}
+
+func TestWebhookShouldDisable(t *testing.T) {
+	require.False(t, webhookShouldDisable(1))
+	require.False(t, webhookShouldDisable(2))
+	require.True(t, webhookShouldDisable(3))
+	require.True(t, webhookShouldDisable(4))
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout at 10.0.0.1:443" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestWebhookErrorMessage(t *testing.T) {
+	require.Equal(
+		t, "The url doesn't lead to a public address",
+		webhookErrorMessage(fmt.Errorf("dial tcp 10.0.0.1:443: %w", util.ErrNonPublicAddress)),
+	)
+	require.Equal(t, "Timed out", webhookErrorMessage(fmt.Errorf("post: %w", context.DeadlineExceeded)))
+	require.Equal(t, "Timed out", webhookErrorMessage(fmt.Errorf("post: %w", timeoutError{})))
+	require.Equal(
+		t, "Couldn't connect", webhookErrorMessage(errors.New("dial tcp 10.0.0.1:443: connection refused")),
+	)
+}
@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/publish"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+)
+
+func init() {
+	registerJobNameFunc(
+		"WebSubDeliverJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 1 {
+				return oops.Newf("Expected 1 arg, got %d: %v", len(args), args)
+			}
+
+			webSubSubscriptionIdInt64, ok := args[0].(int64)
+			if !ok {
+				webSubSubscriptionIdInt, ok := args[0].(int)
+				if !ok {
+					return oops.Newf(
+						"Failed to parse webSubSubscriptionId (expected int64 or int): %v", args[0],
+					)
+				}
+				webSubSubscriptionIdInt64 = int64(webSubSubscriptionIdInt)
+			}
+			webSubSubscriptionId := models.WebSubSubscriptionId(webSubSubscriptionIdInt64)
+
+			return WebSubDeliverJob_Perform(ctx, pool, webSubSubscriptionId)
+		},
+	)
+}
+
+func WebSubDeliverJob_PerformNow(qu pgw.Queryable, webSubSubscriptionId models.WebSubSubscriptionId) error {
+	return performNow(qu, "WebSubDeliverJob", defaultQueue, int64ToYaml(int64(webSubSubscriptionId)))
+}
+
+// Called within the publish transaction, so the deliveries only go out once the feeds are committed
+func WebSubDeliverJob_ScheduleForPublished(
+	qu pgw.Queryable, userId models.UserId, publishedAt schedule.Time,
+) error {
+	logger := qu.Logger()
+	webSubSubscriptionIds, err := models.WebSubSubscription_ListIdsToDeliver(qu, userId, publishedAt)
+	if err != nil {
+		return err
+	}
+	for _, webSubSubscriptionId := range webSubSubscriptionIds {
+		err := WebSubDeliverJob_PerformNow(qu, webSubSubscriptionId)
+		if err != nil {
+			return err
+		}
+	}
+	if len(webSubSubscriptionIds) > 0 {
+		logger.Info().Msgf("Scheduled %d WebSub deliveries", len(webSubSubscriptionIds))
+	}
+	return nil
+}
+
+func WebSubDeliverJob_Perform(
+	ctx context.Context, pool *pgw.Pool, webSubSubscriptionId models.WebSubSubscriptionId,
+) error {
+	logger := pool.Logger()
+	webSubSubscription, err := models.WebSubSubscription_Get(pool, webSubSubscriptionId)
+	if errors.Is(err, models.ErrWebSubSubscriptionNotFound) {
+		logger.Info().Msgf("WebSub subscription %d is gone", webSubSubscriptionId)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if webSubSubscription.ExpiresAt.Before(schedule.UTCNow()) {
+		logger.Info().Msgf("WebSub subscription %d has expired", webSubSubscriptionId)
+		return nil
+	}
+
+	// A rotated feed token revokes the url for WebSub subscribers right away, the grace period is only for
+	// the notice to resubscribe
+	feedTopic, ok := rutil.ParseFeedTopic(webSubSubscription.Topic)
+	if !ok {
+		return oops.Newf("Unknown topic: %s", webSubSubscription.Topic)
+	}
+	resolvedFeed, err := models.FeedToken_Resolve(
+		pool, feedTopic.FeedKey, feedTopic.IsUserFeed, schedule.UTCNow(),
+	)
+	if errors.Is(err, models.ErrFeedNotFound) || errors.Is(err, models.ErrFeedGone) ||
+		(err == nil && resolvedFeed.MaybeRotatedAt != nil) {
+
+		logger.Info().Msgf("WebSub topic is gone, deleting subscription %d", webSubSubscriptionId)
+		return models.WebSubSubscription_DeleteById(pool, webSubSubscriptionId)
+	} else if err != nil {
+		return err
+	}
+
+	maybeBody, err := getWebSubFeedBody(webSubSubscription, pool)
+	if err != nil {
+		return err
+	}
+	if maybeBody == nil {
+		err := util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+			return publish.RegenerateFeeds(tx, webSubSubscription.UserId)
+		})
+		if err != nil {
+			return err
+		}
+		maybeBody, err = getWebSubFeedBody(webSubSubscription, pool)
+		if err != nil {
+			return err
+		}
+		if maybeBody == nil {
+			return oops.Newf("Feed body is still missing for WebSub subscription %d", webSubSubscriptionId)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, webSubSubscription.Callback, strings.NewReader(*maybeBody),
+	)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	req.Header.Set("Content-Type", webSubSubscription.Format.ContentType())
+	req.Header.Set(
+		"Link", fmt.Sprintf(`<%s>; rel="hub", <%s>; rel="self"`, rutil.WebSubHubUrl(), webSubSubscription.Topic),
+	)
+	if webSubSubscription.MaybeSecret != nil {
+		mac := hmac.New(sha256.New, []byte(*webSubSubscription.MaybeSecret))
+		mac.Write([]byte(*maybeBody))
+		req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webSubHttpClient.Do(req)
+	if errors.Is(err, util.ErrNonPublicAddress) {
+		logger.Info().Msgf(
+			"WebSub callback address is not public, deleting subscription %d", webSubSubscriptionId,
+		)
+		return models.WebSubSubscription_DeleteById(pool, webSubSubscriptionId)
+	} else if err != nil {
+		return oops.Wrap(err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		logger.Info().Msgf("Delivered WebSub subscription %d", webSubSubscriptionId)
+		return nil
+	case resp.StatusCode == http.StatusGone:
+		logger.Info().Msgf("WebSub subscriber is gone, deleting subscription %d", webSubSubscriptionId)
+		return models.WebSubSubscription_DeleteById(pool, webSubSubscriptionId)
+	default:
+		// Failing the job makes the worker retry with backoff
+		return oops.Newf("WebSub delivery to %s failed: %d", webSubSubscription.Callback, resp.StatusCode)
+	}
+}
+
+func getWebSubFeedBody(webSubSubscription *models.WebSubSubscription, qu pgw.Queryable) (*string, error) {
+	if webSubSubscription.MaybeSubscriptionId != nil {
+		return models.SubscriptionRss_GetBodyByFormat(
+			qu, *webSubSubscription.MaybeSubscriptionId, webSubSubscription.Format,
+		)
+	}
+	return models.UserRss_GetBodyByFormat(qu, webSubSubscription.UserId, webSubSubscription.Format)
+}
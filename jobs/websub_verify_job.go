@@ -0,0 +1,183 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"time"
+
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+)
+
+const (
+	WebSubModeSubscribe   = "subscribe"
+	WebSubModeUnsubscribe = "unsubscribe"
+)
+
+// Callbacks come from anyone, so they only get to reach the public internet
+var webSubHttpClient = util.NewPublicHttpClient(30 * time.Second)
+
+func init() {
+	registerJobNameFunc(
+		"WebSubVerifyJob",
+		func(ctx context.Context, id JobId, pool *pgw.Pool, args []any) error {
+			if len(args) != 5 {
+				return oops.Newf("Expected 5 args, got %d: %v", len(args), args)
+			}
+
+			mode, ok := args[0].(string)
+			if !ok {
+				return oops.Newf("Failed to parse mode (expected string): %v", args[0])
+			}
+
+			topic, ok := args[1].(string)
+			if !ok {
+				return oops.Newf("Failed to parse topic (expected string): %v", args[1])
+			}
+
+			callback, ok := args[2].(string)
+			if !ok {
+				return oops.Newf("Failed to parse callback (expected string): %v", args[2])
+			}
+
+			leaseSeconds, ok := args[3].(int64)
+			if !ok {
+				leaseSecondsInt, ok := args[3].(int)
+				if !ok {
+					return oops.Newf("Failed to parse leaseSeconds (expected int64 or int): %v", args[3])
+				}
+				leaseSeconds = int64(leaseSecondsInt)
+			}
+
+			secret, ok := args[4].(string)
+			if !ok {
+				return oops.Newf("Failed to parse secret (expected string): %v", args[4])
+			}
+
+			return WebSubVerifyJob_Perform(ctx, pool, mode, topic, callback, leaseSeconds, secret)
+		},
+	)
+}
+
+func WebSubVerifyJob_PerformNow(
+	qu pgw.Queryable, mode string, topic string, callback string, leaseSeconds int64, secret string,
+) error {
+	return performNow(
+		qu, "WebSubVerifyJob", defaultQueue, strToYaml(mode), strToYaml(topic), strToYaml(callback),
+		int64ToYaml(leaseSeconds), strToYaml(secret),
+	)
+}
+
+// Empty secret means the subscriber didn't ask for signatures
+func WebSubVerifyJob_Perform(
+	ctx context.Context, pool *pgw.Pool, mode string, topic string, callback string, leaseSeconds int64,
+	secret string,
+) error {
+	logger := pool.Logger()
+	feedTopic, ok := rutil.ParseFeedTopic(topic)
+	if !ok {
+		return oops.Newf("Unknown topic: %s", topic)
+	}
+	var maybeResolvedFeed *models.ResolvedFeed
+	var maybeUserId *models.UserId
+	resolvedFeed, err := models.FeedToken_Resolve(
+		pool, feedTopic.FeedKey, feedTopic.IsUserFeed, schedule.UTCNow(),
+	)
+	if err == nil {
+		maybeResolvedFeed = resolvedFeed
+		maybeUserId, err = models.WebSub_GetFeedUserId(
+			pool, resolvedFeed.MaybeSubscriptionId, resolvedFeed.MaybeUserId,
+		)
+		if err != nil {
+			return err
+		}
+	} else if !errors.Is(err, models.ErrFeedNotFound) && !errors.Is(err, models.ErrFeedGone) {
+		return err
+	}
+	if maybeUserId == nil && mode == WebSubModeSubscribe {
+		logger.Info().Msgf("WebSub topic is gone before verification: %s", topic)
+		return nil
+	}
+	if maybeResolvedFeed != nil && maybeResolvedFeed.MaybeRotatedAt != nil && mode == WebSubModeSubscribe {
+		logger.Info().Msgf("WebSub topic was rotated before verification: %s", topic)
+		return nil
+	}
+
+	callbackUri, err := neturl.Parse(callback)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	challengeBytes := make([]byte, 24)
+	_, err = rand.Reader.Read(challengeBytes)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	challenge := base64.RawURLEncoding.EncodeToString(challengeBytes)
+	query := callbackUri.Query()
+	query.Set("hub.mode", mode)
+	query.Set("hub.topic", topic)
+	query.Set("hub.challenge", challenge)
+	if mode == WebSubModeSubscribe {
+		query.Set("hub.lease_seconds", fmt.Sprint(leaseSeconds))
+	}
+	callbackUri.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, callbackUri.String(), nil)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	resp, err := webSubHttpClient.Do(req)
+	if errors.Is(err, util.ErrNonPublicAddress) {
+		logger.Info().Msgf("WebSub %s not confirmed by %s: address is not public", mode, callback)
+		return nil
+	} else if err != nil {
+		return oops.Wrap(err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || string(respBody) != challenge {
+		// Not confirming is a legitimate answer, not something to retry
+		logger.Info().Msgf("WebSub %s not confirmed by %s: %d", mode, callback, resp.StatusCode)
+		return nil
+	}
+
+	switch mode {
+	case WebSubModeSubscribe:
+		var maybeSecret *string
+		if secret != "" {
+			maybeSecret = &secret
+		}
+		expiresAt := schedule.UTCNow().Add(time.Duration(leaseSeconds) * time.Second)
+		err := models.WebSubSubscription_Upsert(
+			pool, topic, callback, *maybeUserId, maybeResolvedFeed.MaybeSubscriptionId, feedTopic.Format,
+			maybeSecret, expiresAt,
+		)
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("WebSub subscribed %s to %s until %s", callback, topic, expiresAt)
+	case WebSubModeUnsubscribe:
+		err := models.WebSubSubscription_Delete(pool, topic, callback)
+		if err != nil {
+			return err
+		}
+		logger.Info().Msgf("WebSub unsubscribed %s from %s", callback, topic)
+	default:
+		return oops.Newf("Unknown mode: %s", mode)
+	}
+
+	return nil
+}
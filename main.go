@@ -52,8 +52,10 @@ func main() {
 	rootCmd.AddCommand(cmd.WslStartup)
 	rootCmd.AddCommand(crawl.Crawl)
 	rootCmd.AddCommand(crawl.CrawlRobots)
+	rootCmd.AddCommand(crawl.CrawlStrategies)
 	rootCmd.AddCommand(crawl.PuppeteerScaleTest)
 	rootCmd.AddCommand(crawl.HN1000ScaleTest)
+	rootCmd.AddCommand(crawl.MemoryScaleTest)
 	rootCmd.AddCommand(&cobra.Command{
 		Use: "log-stalled-jobs",
 		Run: func(_ *cobra.Command, _ []string) {
@@ -85,8 +87,9 @@ func runServer(port int) {
 	signalCtx, signalCancel := signal.NotifyContext(parentCtx, syscall.SIGINT, syscall.SIGTERM)
 	defer signalCancel()
 	var wg sync.WaitGroup
-	wg.Add(1)
+	wg.Add(2)
 	models.ProductEvent_StartDummyEventsSync(signalCtx, &wg)
+	models.ProductEvent_StartCountedEventsSync(signalCtx, &wg)
 
 	staticR := chi.NewRouter()
 	staticR.Use(frmiddleware.Logger)
@@ -127,6 +130,7 @@ func runServer(port int) {
 
 		r.Get("/subscriptions/{id:\\d+}/setup", routes.Subscriptions_Setup)
 		r.Post("/subscriptions", routes.Subscriptions_Create)
+		r.Post("/subscriptions/table_of_contents", routes.Subscriptions_CreateTableOfContents)
 		r.Post("/subscriptions/{id:\\d+}/progress", routes.Subscriptions_Progress)
 		r.Post("/subscriptions/{id:\\d+}/submit_progress_times", routes.Subscriptions_SubmitProgressTimes)
 		r.Post("/subscriptions/{id:\\d+}/select_posts", routes.Subscriptions_SelectPosts)
@@ -135,6 +139,8 @@ func runServer(port int) {
 		r.Post("/subscriptions/{id:\\d+}/delete", routes.Subscriptions_Delete)
 		r.Get("/subscriptions/{id:\\d+}/progress_stream", routes.Subscriptions_ProgressStream)
 		r.Post("/subscriptions/{id:\\d+}/notify_when_supported", routes.Subscriptions_NotifyWhenSupported)
+		r.Get("/subscriptions/{id:\\d+}/custom_blog", routes.Subscriptions_CustomBlog)
+		r.Post("/subscriptions/{id:\\d+}/custom_blog", routes.Subscriptions_CreateCustomBlog)
 
 		r.Get("/terms", routes.Misc_Terms)
 		r.Get("/privacy", routes.Misc_Privacy)
@@ -150,9 +156,27 @@ func runServer(port int) {
 			authorized.Post("/subscriptions/{id:\\d+}", routes.Subscriptions_Update)
 			authorized.Post("/subscriptions/{id:\\d+}/pause", routes.Subscriptions_Pause)
 			authorized.Post("/subscriptions/{id:\\d+}/unpause", routes.Subscriptions_Unpause)
+			authorized.Post("/subscriptions/{id:\\d+}/post_content", routes.Subscriptions_UpdatePostContent)
+			authorized.Post("/subscriptions/{id:\\d+}/adaptive_pacing", routes.Subscriptions_UpdateAdaptivePacing)
+			authorized.Post("/subscriptions/{id:\\d+}/resume_pacing", routes.Subscriptions_ResumePacing)
+			authorized.Post("/subscriptions/{id:\\d+}/deliver_now", routes.Subscriptions_DeliverNow)
+			authorized.Post("/subscriptions/{id:\\d+}/queue", routes.Subscriptions_UpdateQueue)
 
 			authorized.Get("/settings", routes.UserSettings_Page)
 			authorized.Post("/settings/save_timezone", routes.UserSettings_SaveTimezone)
+			authorized.Post("/settings/save_delivery_channel", routes.UserSettings_SaveDeliveryChannel)
+			authorized.Post("/settings/save_delivery_time", routes.UserSettings_SaveDeliveryTime)
+			authorized.Post("/settings/save_webhook", routes.UserSettings_SaveWebhook)
+			authorized.Post("/settings/rotate_webhook_secret", routes.UserSettings_RotateWebhookSecret)
+			authorized.Post("/settings/add_vacation", routes.UserSettings_AddVacation)
+			authorized.Post("/settings/vacations/{id:\\d+}/delete", routes.UserSettings_DeleteVacation)
+			authorized.Post("/settings/save_catch_up_after_vacation", routes.UserSettings_SaveCatchUpAfterVacation)
+			authorized.Post("/settings/save_reader_view", routes.UserSettings_SaveReaderView)
+			authorized.Post("/settings/save_legacy_feed_urls", routes.UserSettings_SaveLegacyFeedUrls)
+			authorized.Post("/settings/rotate_feed_token", routes.UserSettings_RotateFeedToken)
+			authorized.Get("/settings/export_opml", routes.Opml_Export)
+			authorized.Post("/settings/import_opml", routes.Opml_Import)
+			authorized.Get("/settings/opml_imports/{id:\\d+}", routes.Opml_ImportProgress)
 			authorized.Post("/delete_account", routes.Users_DeleteAccount)
 		})
 
@@ -182,12 +206,17 @@ func runServer(port int) {
 	})
 
 	staticR.Group(func(anonR chi.Router) {
-		anonR.Get("/subscriptions/{id}/feed", routes.Rss_SubscriptionFeed) // Legacy
-		anonR.Get("/feeds/single/{id}", routes.Rss_UserFeed)
-		anonR.Get("/feeds/{id}", routes.Rss_SubscriptionFeed)
+		anonR.Get("/subscriptions/{token:\\d+}/feed", routes.Rss_SubscriptionFeed) // Legacy
+		anonR.Get("/feeds/single/{token}", routes.Rss_UserFeed)
+		anonR.Get("/feeds/single/{token}.atom", routes.Rss_UserFeed)
+		anonR.Get("/feeds/single/{token}.json", routes.Rss_UserFeed)
+		anonR.Get("/feeds/{token}", routes.Rss_SubscriptionFeed)
+		anonR.Get("/feeds/{token}.atom", routes.Rss_SubscriptionFeed)
+		anonR.Get("/feeds/{token}.json", routes.Rss_SubscriptionFeed)
 
 		anonR.Get("/posts/{slug}/{random_id:[A-Za-z0-9_-]+}", routes.Posts_Post)
 
+		anonR.Post("/websub", routes.WebSub_Hub)
 	})
 
 	staticR.Get(util.StaticRouteTemplate, routes.Static_File)
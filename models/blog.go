@@ -394,14 +394,14 @@ func Blog_CreateOrUpdate(
 					}
 					batch.Queue(`
 						with blog_post_ids as (
-							insert into blog_posts (blog_id, index, url, title)
-							values ($1, $2, $3, $4)
+							insert into blog_posts (blog_id, index, url, title, content)
+							values ($1, $2, $3, $4, $5)
 							returning id
 						),
 						category_ids(id) as (values(`+sb.String()+`))
 						insert into blog_post_category_assignments (blog_post_id, category_id)
 						select (select id from blog_post_ids), id from category_ids
-					`, blog.Id, index, link.Url, title)
+					`, blog.Id, index, link.Url, title, link.MaybeContent)
 				}
 				err = tx.SendBatch(batch).Close()
 				if err != nil {
@@ -528,14 +528,16 @@ func Blog_Downgrade(qu pgw.Queryable, blogId BlogId) (int32, error) {
 }
 
 type CrawledBlogPost struct {
-	Url        string
-	Title      string
-	Categories []string
+	Url          string
+	Title        string
+	Categories   []string
+	MaybeContent *string // sanitized html
 }
 
 func Blog_InitCrawled(
 	tx *pgw.Tx, blogId BlogId, url string, crawledBlogPosts []CrawledBlogPost,
 	categories []NewBlogPostCategory, discardedFeedUrls []string, curiEqCfg *crawler.CanonicalEqualityConfig,
+	crawlPattern string,
 ) (updatedAt time.Time, err error) {
 	row := tx.QueryRow(`select status from blogs where id = $1`, blogId)
 	var status BlogStatus
@@ -549,21 +551,97 @@ func Blog_InitCrawled(
 		)
 	}
 
+	err = blog_InitPosts(tx, blogId, crawledBlogPosts, categories, discardedFeedUrls, curiEqCfg)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	// The feed only has the content of the recent posts, the previous version may have had more of them
+	_, err = tx.Exec(`
+		update blog_posts
+		set content = previous_posts.content
+		from blog_posts as previous_posts
+		where blog_posts.blog_id = $1 and
+			blog_posts.content is null and
+			previous_posts.blog_id = (
+				select id from blogs
+				where feed_url = (select feed_url from blogs where id = $1) and
+					version != $2 and
+					status in `+blog_CrawledStatusesSql()+`
+				order by version desc
+				limit 1
+			) and
+			previous_posts.url = blog_posts.url and
+			previous_posts.content is not null
+	`, blogId, BlogLatestVersion)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	_, err = tx.Exec(`
+		update blogs set url = $1, status = $2, crawl_pattern = $3 where id = $4
+	`, url, BlogStatusCrawledVoting, crawlPattern, blogId)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	row = tx.QueryRow(`
+		select count(1) from blog_posts
+		where blog_id = (
+			select id from blogs
+			where feed_url = (select feed_url from blogs where id = $1) and
+				version != $2 and
+				status in `+blog_CrawledStatusesSql()+`
+			order by version desc
+			limit 1
+		)
+	`, blogId, BlogLatestVersion)
+	var prevPostsCount int
+	err = row.Scan(&prevPostsCount)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	uri, err := neturl.Parse(url)
+	oldNewThingSkipWarning := err == nil &&
+		uri.Host == crawler.HardcodedTheOldNewThingUri.Host &&
+		len(crawledBlogPosts) > 6000
+	if len(crawledBlogPosts) < prevPostsCount && !oldNewThingSkipWarning {
+		tx.Logger().Warn().Msgf(
+			"Blog %d (%s) has fewer posts after recrawling: %d -> %d",
+			blogId, url, prevPostsCount, len(crawledBlogPosts),
+		)
+	}
+
+	row = tx.QueryRow(`select updated_at from blogs where id = $1`, blogId)
+	err = row.Scan(&updatedAt)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	return updatedAt, nil
+}
+
+func blog_InitPosts(
+	tx *pgw.Tx, blogId BlogId, crawledBlogPosts []CrawledBlogPost, categories []NewBlogPostCategory,
+	discardedFeedUrls []string, curiEqCfg *crawler.CanonicalEqualityConfig,
+) error {
 	batch := tx.NewBatch()
 	blogPostIds := make([]BlogPostId, len(crawledBlogPosts))
 	for i, crawledBlogPost := range crawledBlogPosts {
 		batch.Queue(`
-			insert into blog_posts (blog_id, index, url, title)
-			values ($1, $2, $3, $4)
+			insert into blog_posts (blog_id, index, url, title, content)
+			values ($1, $2, $3, $4, $5)
 			returning id
 		`, blogId, len(crawledBlogPosts)-i-1, crawledBlogPost.Url, crawledBlogPost.Title,
+			crawledBlogPost.MaybeContent,
 		).QueryRow(func(row pgw.Row) error {
 			return row.Scan(&blogPostIds[i])
 		})
 	}
-	err = tx.SendBatch(batch).Close()
+	err := tx.SendBatch(batch).Close()
 	if err != nil {
-		return updatedAt, err
+		return err
 	}
 
 	if len(categories) > 0 {
@@ -581,7 +659,7 @@ func Blog_InitCrawled(
 		}
 		err := tx.SendBatch(batch).Close()
 		if err != nil {
-			return updatedAt, err
+			return err
 		}
 
 		categoryIdsByName := make(map[string]BlogPostCategoryId)
@@ -600,7 +678,7 @@ func Blog_InitCrawled(
 		}
 		err = tx.SendBatch(batch).Close()
 		if err != nil {
-			return updatedAt, err
+			return err
 		}
 	}
 
@@ -610,7 +688,7 @@ func Blog_InitCrawled(
 		values ($1, $2, $3)
 	`, blogId, sameHosts, curiEqCfg.ExpectTumblrPaths)
 	if err != nil {
-		return updatedAt, err
+		return err
 	}
 
 	batch = tx.NewBatch()
@@ -622,16 +700,183 @@ func Blog_InitCrawled(
 	}
 	err = tx.SendBatch(batch).Close()
 	if err != nil {
-		return updatedAt, err
+		return err
 	}
 
+	return nil
+}
+
+// Custom blogs are assembled by the user for their subscription after the crawl failed. They take the
+// next non-latest version so that other subscriptions to the same feed don't pick them up.
+func Blog_CreateCustom(tx *pgw.Tx, failedBlogId BlogId) (BlogId, error) {
+	blogIdInt, err := mutil.RandomId(tx, "blogs")
+	if err != nil {
+		return 0, err
+	}
+	blogId := BlogId(blogIdInt)
 	_, err = tx.Exec(`
-		update blogs set url = $1, status = $2 where id = $3
-	`, url, BlogStatusCrawledVoting, blogId)
+		insert into blogs (
+			id, name, feed_url, url, status, status_updated_at, version, update_action, start_feed_id
+		)
+		select $1, name, feed_url, url, $2, utc_now(), (
+			select coalesce(max(version), 0) from blogs
+			where feed_url = failed_blog.feed_url and version != $3
+		) + 1, $4, start_feed_id
+		from blogs as failed_blog
+		where id = $5
+	`, blogId, BlogStatusCrawlInProgress, BlogLatestVersion, BlogUpdateActionNoOp, failedBlogId)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(`insert into blog_crawl_progresses (blog_id, epoch) values ($1, 0)`, blogId)
+	if err != nil {
+		return 0, err
+	}
+	err = BlogPostLock_Create(tx, blogId)
+	if err != nil {
+		return 0, err
+	}
+
+	return blogId, nil
+}
+
+func Blog_InitManuallyInserted(
+	tx *pgw.Tx, blogId BlogId, crawledBlogPosts []CrawledBlogPost, discardedFeedUrls []string,
+	missingFromFeedUrls []string, curiEqCfg *crawler.CanonicalEqualityConfig,
+) (updatedAt time.Time, err error) {
+	row := tx.QueryRow(`select status from blogs where id = $1`, blogId)
+	var status BlogStatus
+	err = row.Scan(&status)
+	if err != nil {
+		return updatedAt, err
+	}
+	if status != BlogStatusCrawlInProgress {
+		return updatedAt, oops.Newf(
+			"Can only init posts when status is %s, got %s instead", BlogStatusCrawlInProgress, status,
+		)
+	}
+
+	categories := []NewBlogPostCategory{{
+		Name:      "Everything",
+		Index:     0,
+		TopStatus: BlogPostCategoryTopOnly,
+	}}
+	err = blog_InitPosts(tx, blogId, crawledBlogPosts, categories, discardedFeedUrls, curiEqCfg)
 	if err != nil {
 		return updatedAt, err
 	}
 
+	batch := tx.NewBatch()
+	for _, missingFromFeedUrl := range missingFromFeedUrls {
+		batch.Queue(`
+			insert into blog_missing_from_feed_entries (blog_id, url)
+			values ($1, $2)
+		`, blogId, missingFromFeedUrl)
+	}
+	err = tx.SendBatch(batch).Close()
+	if err != nil {
+		return updatedAt, err
+	}
+
+	row = tx.QueryRow(`
+		update blogs set status = $1 where id = $2 returning updated_at
+	`, BlogStatusManuallyInserted, blogId)
+	err = row.Scan(&updatedAt)
+	if err != nil {
+		return updatedAt, err
+	}
+
+	return updatedAt, nil
+}
+
+type TableOfContentsJobScheduleFunc func(qu pgw.Queryable, blogId BlogId, startPageId StartPageId) error
+
+const tableOfContentsRetryInterval = 24 * time.Hour
+
+// Table of contents blogs don't have a feed to update from, so feed_url is the page url. Every subscriber
+// shares the crawled blog, and only a failed crawl is retried from scratch once it's old enough.
+func Blog_CreateOrUpdateTableOfContents(
+	qu pgw.Queryable, startPageId StartPageId, name string, pageUrl string,
+	tableOfContentsJobScheduleFunc TableOfContentsJobScheduleFunc,
+) (blog *Blog, err error) {
+	logger := qu.Logger()
+	tx, err := qu.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer util.CommitOrRollbackErr(tx, &err)
+
+	row := tx.QueryRow(`
+		select id, name, status, update_action, start_feed_id, coalesce(url, feed_url), status_updated_at
+		from blogs
+		where feed_url = $1 and version = $2
+		for update
+	`, pageUrl, BlogLatestVersion)
+	var b Blog
+	var statusUpdatedAt time.Time
+	err = row.Scan(
+		&b.Id, &b.Name, &b.Status, &b.UpdateAction, &b.MaybeStartFeedId, &b.BestUrl, &statusUpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		logger.Info().Msgf("Creating a new table of contents blog for %s", pageUrl)
+	} else if err != nil {
+		return nil, err
+	} else if !BlogFailedStatuses[b.Status] || b.Status == BlogStatusKnownBad ||
+		time.Since(statusUpdatedAt) < tableOfContentsRetryInterval {
+
+		return &b, nil
+	} else {
+		version, err := Blog_Downgrade(tx, b.Id)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info().Msgf(
+			"Table of contents blog %s failed, downgraded to version %d and recrawling", pageUrl, version,
+		)
+	}
+
+	blogIdInt, err := mutil.RandomId(tx, "blogs")
+	if err != nil {
+		return nil, err
+	}
+	blogId := BlogId(blogIdInt)
+	status := BlogStatusCrawlInProgress
+	updateAction := BlogUpdateActionNoOp
+	_, err = tx.Exec(`
+		insert into blogs (
+			id, name, feed_url, url, status, status_updated_at, version, update_action, start_feed_id
+		)
+		values ($1, $2, $3, $3, $4, utc_now(), $5, $6, null)
+	`, blogId, name, pageUrl, status, BlogLatestVersion, updateAction)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`insert into blog_crawl_progresses (blog_id, epoch) values ($1, 0)`, blogId)
+	if err != nil {
+		return nil, err
+	}
+	err = BlogPostLock_Create(tx, blogId)
+	if err != nil {
+		return nil, err
+	}
+	err = tableOfContentsJobScheduleFunc(tx, blogId, startPageId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Blog{
+		Id:               blogId,
+		Name:             name,
+		Status:           status,
+		UpdateAction:     updateAction,
+		MaybeStartFeedId: nil,
+		BestUrl:          pageUrl,
+	}, nil
+}
+
+func blog_CrawledStatusesSql() string {
 	var sb strings.Builder
 	sb.WriteString("('")
 	isFirst := true
@@ -643,41 +888,87 @@ func Blog_InitCrawled(
 		sb.WriteString(string(status))
 	}
 	sb.WriteString("')")
-	row = tx.QueryRow(`
-		select count(1) from blog_posts
-		where blog_id = (
-			select id from blogs
-			where feed_url = (select feed_url from blogs where id = $1) and
-				version != $2 and
-				status in `+sb.String()+`
-			order by version desc
-			limit 1
-		)
-	`, blogId, BlogLatestVersion)
-	var prevPostsCount int
-	err = row.Scan(&prevPostsCount)
-	if err != nil {
-		return updatedAt, err
+	return sb.String()
+}
+
+// Returns nil if there is no previous crawled version of the blog. Manually inserted versions don't have a
+// winning strategy to revalidate, so they don't count.
+func Blog_GetRecrawlHint(qu pgw.Queryable, feedUrl string) (*crawler.RecrawlHint, error) {
+	row := qu.QueryRow(`
+		select id, url, crawl_pattern from blogs
+		where feed_url = $1 and version != $2 and status in `+blog_CrawledStatusesSql()+` and
+			status != $3 and crawl_pattern is not null
+		order by version desc
+		limit 1
+	`, feedUrl, BlogLatestVersion, BlogStatusManuallyInserted)
+	var blogId BlogId
+	var maybeUrl *string
+	var crawlPattern string
+	err := row.Scan(&blogId, &maybeUrl, &crawlPattern)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if maybeUrl == nil {
+		return nil, nil
 	}
 
-	uri, err := neturl.Parse(url)
-	oldNewThingSkipWarning := err == nil &&
-		uri.Host == crawler.HardcodedTheOldNewThingUri.Host &&
-		len(crawledBlogPosts) > 6000
-	if len(crawledBlogPosts) < prevPostsCount && !oldNewThingSkipWarning {
-		tx.Logger().Warn().Msgf(
-			"Blog %d (%s) has fewer posts after recrawling: %d -> %d",
-			blogId, url, prevPostsCount, len(crawledBlogPosts),
-		)
+	var hint crawler.RecrawlHint
+	hint.BlogUrl = *maybeUrl
+	hint.Pattern = crawlPattern
+
+	rows, err := qu.Query(`
+		select url, title from blog_posts where blog_id = $1 order by index desc
+	`, blogId)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var post crawler.RecrawlHintPost
+		err := rows.Scan(&post.Url, &post.Title)
+		if err != nil {
+			return nil, err
+		}
+		hint.Posts = append(hint.Posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	row = tx.QueryRow(`select updated_at from blogs where id = $1`, blogId)
-	err = row.Scan(&updatedAt)
+	rows, err = qu.Query(`
+		select blog_post_categories.name, blog_post_categories.top_status, blog_posts.url
+		from blog_post_categories
+		join blog_post_category_assignments on blog_post_category_assignments.category_id = blog_post_categories.id
+		join blog_posts on blog_posts.id = blog_post_category_assignments.blog_post_id
+		where blog_post_categories.blog_id = $1 and blog_post_categories.name != 'Everything'
+		order by blog_post_categories.index asc, blog_posts.index desc
+	`, blogId)
 	if err != nil {
-		return updatedAt, err
+		return nil, err
+	}
+	for rows.Next() {
+		var name, postUrl string
+		var topStatus BlogPostCategoryTopStatus
+		err := rows.Scan(&name, &topStatus, &postUrl)
+		if err != nil {
+			return nil, err
+		}
+		if len(hint.Categories) == 0 || hint.Categories[len(hint.Categories)-1].Name != name {
+			hint.Categories = append(hint.Categories, crawler.RecrawlHintCategory{
+				Name:     name,
+				IsTop:    topStatus.IsTop(),
+				PostUrls: nil,
+			})
+		}
+		category := &hint.Categories[len(hint.Categories)-1]
+		category.PostUrls = append(category.PostUrls, postUrl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return updatedAt, nil
+	return &hint, nil
 }
 
 func Blog_GetBestUrl(qu pgw.Queryable, blogId BlogId) (string, error) {
@@ -837,6 +1128,96 @@ func BlogPost_List(qu pgw.Queryable, blogId BlogId) ([]BlogPost, error) {
 	return result, nil
 }
 
+// BlogPostArticle
+
+type BlogPostArticle struct {
+	MaybeContent *string // nil if the extraction failed, it's retried after a day
+}
+
+var ErrBlogPostArticleNotFound = errors.New("blog post article not found")
+
+func BlogPostArticle_Get(qu pgw.Queryable, blogPostId BlogPostId) (*BlogPostArticle, error) {
+	row := qu.QueryRow(`
+		select content from blog_post_articles where blog_post_id = $1
+	`, blogPostId)
+	var a BlogPostArticle
+	err := row.Scan(&a.MaybeContent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrBlogPostArticleNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func BlogPostArticle_Upsert(
+	qu pgw.Queryable, blogPostId BlogPostId, maybeContent *string, maybeExcerpt *string,
+) error {
+	_, err := qu.Exec(`
+		insert into blog_post_articles (blog_post_id, content, excerpt) values ($1, $2, $3)
+		on conflict (blog_post_id) do update set content = excluded.content, excerpt = excluded.excerpt
+	`, blogPostId, maybeContent, maybeExcerpt)
+	return err
+}
+
+type BlogPostToExtract struct {
+	Id  BlogPostId
+	Url string
+}
+
+// The last published and the next few posts of the subscription that don't have an article yet, or whose
+// extraction failed over a day ago. Only the posts that came without feed content if onlyWithoutContent.
+func BlogPostArticle_ListMissing(
+	qu pgw.Queryable, subscriptionId SubscriptionId, publishedCount int, unpublishedCount int,
+	onlyWithoutContent bool,
+) ([]BlogPostToExtract, error) {
+	rows, err := qu.Query(`
+		select blog_posts.id, blog_posts.url from (
+			(
+				select blog_post_id from subscription_posts
+				where subscription_id = $1 and published_at is not null
+				order by published_at desc, order_rank desc
+				limit $2
+			)
+			union
+			(
+				select blog_post_id from subscription_posts
+				where subscription_id = $1 and published_at is null and skipped_at is null
+				order by queue_order asc nulls last, order_rank asc
+				limit $3
+			)
+		) as posts
+		join blog_posts on blog_posts.id = posts.blog_post_id
+		left join blog_post_articles on blog_post_articles.blog_post_id = blog_posts.id
+		where (
+				blog_post_articles.blog_post_id is null or (
+					blog_post_articles.content is null and
+					blog_post_articles.updated_at < utc_now() - interval '1 day'
+				)
+			) and
+			(not $4 or blog_posts.content is null)
+	`, subscriptionId, publishedCount, unpublishedCount, onlyWithoutContent)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []BlogPostToExtract
+	for rows.Next() {
+		var p BlogPostToExtract
+		err := rows.Scan(&p.Id, &p.Url)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // BlogPostCategory
 
 type BlogPostCategoryId int64
@@ -2,13 +2,19 @@ package models
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"feedrewind.com/crawler"
 	"feedrewind.com/db/pgw"
 	"feedrewind.com/models/mutil"
 	"feedrewind.com/oops"
 	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+
+	"github.com/jackc/pgx/v5"
 )
 
 func MustInit(qu pgw.Queryable) {
@@ -64,6 +70,7 @@ const (
 	TypedBlogUrlResultNoFeeds               TypedBlogUrlResult = "no_feeds"
 	TypedBlogUrlResultCouldNotReach         TypedBlogUrlResult = "could_not_reach"
 	TypedBlogUrlResultBadFeed               TypedBlogUrlResult = "bad_feed"
+	TypedBlogUrlResultTableOfContents       TypedBlogUrlResult = "table_of_contents"
 )
 
 func TypedBlogUrl_Create(
@@ -90,6 +97,7 @@ type StartFeed struct {
 
 func StartFeed_CreateFetched(
 	qu pgw.Queryable, startPageId *StartPageId, discoveredFetchedFeed crawler.DiscoveredFetchedFeed,
+	maybeArchivesUrl *string,
 ) (*StartFeed, error) {
 	idInt, err := mutil.RandomId(qu, "start_feeds")
 	if err != nil {
@@ -97,10 +105,10 @@ func StartFeed_CreateFetched(
 	}
 	id := StartFeedId(idInt)
 	_, err = qu.Exec(`
-		insert into start_feeds (id, start_page_id, title, url, final_url, content)
-		values ($1, $2, $3, $4, $5, $6)
+		insert into start_feeds (id, start_page_id, title, url, final_url, content, archives_url)
+		values ($1, $2, $3, $4, $5, $6, $7)
 	`, id, startPageId, discoveredFetchedFeed.Title, discoveredFetchedFeed.Url,
-		discoveredFetchedFeed.FinalUrl, []byte(discoveredFetchedFeed.Content),
+		discoveredFetchedFeed.FinalUrl, []byte(discoveredFetchedFeed.Content), maybeArchivesUrl,
 	)
 	if err != nil {
 		return nil, err
@@ -115,7 +123,7 @@ func StartFeed_CreateFetched(
 }
 
 func StartFeed_Create(
-	qu pgw.Queryable, startPageId StartPageId, discoveredFeed crawler.DiscoveredFeed,
+	qu pgw.Queryable, startPageId StartPageId, discoveredFeed crawler.DiscoveredFeed, maybeArchivesUrl *string,
 ) (*StartFeed, error) {
 	idInt, err := mutil.RandomId(qu, "start_feeds")
 	if err != nil {
@@ -123,10 +131,10 @@ func StartFeed_Create(
 	}
 	id := StartFeedId(idInt)
 	_, err = qu.Exec(`
-		insert into start_feeds (id, start_page_id, title, url, final_url, content)
-		values ($1, $2, $3, $4, null, null)
+		insert into start_feeds (id, start_page_id, title, url, final_url, content, archives_url)
+		values ($1, $2, $3, $4, null, null, $5)
 		returning id
-	`, id, startPageId, discoveredFeed.Title, discoveredFeed.Url)
+	`, id, startPageId, discoveredFeed.Title, discoveredFeed.Url, maybeArchivesUrl)
 	if err != nil {
 		return nil, err
 	}
@@ -177,16 +185,22 @@ func StartFeed_UpdateFetched(
 
 type StartPageId int64
 
+var ErrStartPageNotFound = errors.New("start page not found")
+
+// Start page ids are handed to the client like start feed ids, so they're random to only be known to the
+// session that discovered them
 func StartPage_Create(
 	qu pgw.Queryable, discoveredStartPage crawler.DiscoveredStartPage,
 ) (StartPageId, error) {
-	row := qu.QueryRow(`
-		insert into start_pages (url, final_url, content)
-		values ($1, $2, $3)
-		returning id
-	`, discoveredStartPage.Url, discoveredStartPage.FinalUrl, []byte(discoveredStartPage.Content))
-	var id StartPageId
-	err := row.Scan(&id)
+	idInt, err := mutil.RandomId(qu, "start_pages")
+	if err != nil {
+		return 0, err
+	}
+	id := StartPageId(idInt)
+	_, err = qu.Exec(`
+		insert into start_pages (id, url, final_url, content)
+		values ($1, $2, $3, $4)
+	`, id, discoveredStartPage.Url, discoveredStartPage.FinalUrl, []byte(discoveredStartPage.Content))
 	if err != nil {
 		return 0, err
 	}
@@ -194,8 +208,59 @@ func StartPage_Create(
 	return id, nil
 }
 
+func StartPage_Get(qu pgw.Queryable, id StartPageId) (*crawler.DiscoveredStartPage, error) {
+	row := qu.QueryRow(`select url, final_url, content from start_pages where id = $1`, id)
+	var startPage crawler.DiscoveredStartPage
+	var content []byte
+	err := row.Scan(&startPage.Url, &startPage.FinalUrl, &content)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStartPageNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	startPage.Content = string(content)
+
+	return &startPage, nil
+}
+
 // RSS
 
+type FeedFormat string
+
+const (
+	FeedFormatRss  FeedFormat = "rss"
+	FeedFormatAtom FeedFormat = "atom"
+	FeedFormatJson FeedFormat = "json"
+)
+
+func (f FeedFormat) ContentType() string {
+	switch f {
+	case FeedFormatRss:
+		return "application/xml"
+	case FeedFormatAtom:
+		return "application/atom+xml"
+	case FeedFormatJson:
+		return "application/feed+json"
+	default:
+		panic(fmt.Errorf("Unknown feed format: %s", f))
+	}
+}
+
+// Null if the feed wasn't published since the format was added
+func UserRss_GetBodyByFormat(qu pgw.Queryable, userId UserId, format FeedFormat) (*string, error) {
+	switch format {
+	case FeedFormatRss:
+		body, err := UserRss_GetBody(qu, userId)
+		return &body, err
+	case FeedFormatAtom:
+		return UserRss_GetAtomBody(qu, userId)
+	case FeedFormatJson:
+		return UserRss_GetJsonBody(qu, userId)
+	default:
+		return nil, oops.Newf("Unknown feed format: %s", format)
+	}
+}
+
 func UserRss_GetBody(qu pgw.Queryable, userId UserId) (string, error) {
 	row := qu.QueryRow(`select body from user_rsses where user_id = $1`, userId)
 	var body string
@@ -203,15 +268,48 @@ func UserRss_GetBody(qu pgw.Queryable, userId UserId) (string, error) {
 	return body, err
 }
 
-func UserRss_Upsert(qu pgw.Queryable, userId UserId, body string) error {
+// Null if the feed wasn't published since Atom was added
+func UserRss_GetAtomBody(qu pgw.Queryable, userId UserId) (*string, error) {
+	row := qu.QueryRow(`select atom_body from user_rsses where user_id = $1`, userId)
+	var maybeAtomBody *string
+	err := row.Scan(&maybeAtomBody)
+	return maybeAtomBody, err
+}
+
+// Null if the feed wasn't published since JSON Feed was added
+func UserRss_GetJsonBody(qu pgw.Queryable, userId UserId) (*string, error) {
+	row := qu.QueryRow(`select json_body from user_rsses where user_id = $1`, userId)
+	var maybeJsonBody *string
+	err := row.Scan(&maybeJsonBody)
+	return maybeJsonBody, err
+}
+
+func UserRss_Upsert(qu pgw.Queryable, userId UserId, body string, atomBody string, jsonBody string) error {
 	_, err := qu.Exec(`
-		insert into user_rsses (user_id, body) values ($1, $2)
+		insert into user_rsses (user_id, body, atom_body, json_body) values ($1, $2, $3, $4)
 		on conflict (user_id)
-		do update set body = $2
-	`, userId, body)
+		do update set body = $2, atom_body = $3, json_body = $4
+	`, userId, body, atomBody, jsonBody)
 	return err
 }
 
+// Null if the feed wasn't published since the format was added
+func SubscriptionRss_GetBodyByFormat(
+	qu pgw.Queryable, subscriptionId SubscriptionId, format FeedFormat,
+) (*string, error) {
+	switch format {
+	case FeedFormatRss:
+		body, err := SubscriptionRss_GetBody(qu, subscriptionId)
+		return &body, err
+	case FeedFormatAtom:
+		return SubscriptionRss_GetAtomBody(qu, subscriptionId)
+	case FeedFormatJson:
+		return SubscriptionRss_GetJsonBody(qu, subscriptionId)
+	default:
+		return nil, oops.Newf("Unknown feed format: %s", format)
+	}
+}
+
 func SubscriptionRss_GetBody(qu pgw.Queryable, subscriptionId SubscriptionId) (string, error) {
 	row := qu.QueryRow(`select body from subscription_rsses where subscription_id = $1`, subscriptionId)
 	var body string
@@ -219,15 +317,888 @@ func SubscriptionRss_GetBody(qu pgw.Queryable, subscriptionId SubscriptionId) (s
 	return body, err
 }
 
-func SubscriptionRss_Upsert(qu pgw.Queryable, subscriptionId SubscriptionId, body string) error {
+// Null if the feed wasn't published since Atom was added
+func SubscriptionRss_GetAtomBody(qu pgw.Queryable, subscriptionId SubscriptionId) (*string, error) {
+	row := qu.QueryRow(`select atom_body from subscription_rsses where subscription_id = $1`, subscriptionId)
+	var maybeAtomBody *string
+	err := row.Scan(&maybeAtomBody)
+	return maybeAtomBody, err
+}
+
+// Null if the feed wasn't published since JSON Feed was added
+func SubscriptionRss_GetJsonBody(qu pgw.Queryable, subscriptionId SubscriptionId) (*string, error) {
+	row := qu.QueryRow(`select json_body from subscription_rsses where subscription_id = $1`, subscriptionId)
+	var maybeJsonBody *string
+	err := row.Scan(&maybeJsonBody)
+	return maybeJsonBody, err
+}
+
+func SubscriptionRss_Upsert(
+	qu pgw.Queryable, subscriptionId SubscriptionId, body string, atomBody string, jsonBody string,
+) error {
 	_, err := qu.Exec(`
-		insert into subscription_rsses (subscription_id, body) values ($1, $2)
+		insert into subscription_rsses (subscription_id, body, atom_body, json_body) values ($1, $2, $3, $4)
 		on conflict (subscription_id)
-		do update set body = $2
-	`, subscriptionId, body)
+		do update set body = $2, atom_body = $3, json_body = $4
+	`, subscriptionId, body, atomBody, jsonBody)
+	return err
+}
+
+// FeedToken
+
+type FeedToken string
+
+// Readers that haven't picked up the new url yet get a notice to resubscribe for a while after rotation
+const FeedTokenGracePeriod = 7 * 24 * time.Hour
+
+var ErrFeedNotFound = errors.New("feed not found")
+var ErrFeedGone = errors.New("feed url is gone")
+
+func FeedToken_CreateForSubscription(qu pgw.Queryable, subscriptionId SubscriptionId) error {
+	_, err := qu.Exec(`
+		insert into feed_tokens (token, subscription_id) values (`+psqlRandomId+`, $1)
+	`, subscriptionId)
+	return err
+}
+
+func FeedToken_CreateForUser(qu pgw.Queryable, userId UserId) error {
+	_, err := qu.Exec(`
+		insert into feed_tokens (token, user_id) values (`+psqlRandomId+`, $1)
+	`, userId)
+	return err
+}
+
+func FeedToken_GetForSubscription(qu pgw.Queryable, subscriptionId SubscriptionId) (FeedToken, error) {
+	row := qu.QueryRow(`
+		select token from feed_tokens where subscription_id = $1 and rotated_at is null
+	`, subscriptionId)
+	var token FeedToken
+	err := row.Scan(&token)
+	return token, err
+}
+
+func FeedToken_GetForUser(qu pgw.Queryable, userId UserId) (FeedToken, error) {
+	row := qu.QueryRow(`select token from feed_tokens where user_id = $1 and rotated_at is null`, userId)
+	var token FeedToken
+	err := row.Scan(&token)
+	return token, err
+}
+
+// The old token starts its grace period, and the legacy numeric url goes away with it
+func FeedToken_RotateForSubscription(qu pgw.Queryable, subscriptionId SubscriptionId) error {
+	_, err := qu.Exec(`
+		update feed_tokens set rotated_at = utc_now() where subscription_id = $1 and rotated_at is null
+	`, subscriptionId)
+	if err != nil {
+		return err
+	}
+	return FeedToken_CreateForSubscription(qu, subscriptionId)
+}
+
+func FeedToken_RotateForUser(qu pgw.Queryable, userId UserId) error {
+	_, err := qu.Exec(`
+		update feed_tokens set rotated_at = utc_now() where user_id = $1 and rotated_at is null
+	`, userId)
+	if err != nil {
+		return err
+	}
+	return FeedToken_CreateForUser(qu, userId)
+}
+
+// Only the users who had feeds before tokens get to choose about the numeric urls
+func FeedToken_UserHasLegacyUrls(qu pgw.Queryable, userId UserId) (bool, error) {
+	row := qu.QueryRow(`
+		select exists (
+			select 1 from feed_tokens
+			where allows_legacy_url and (
+				user_id = $1 or
+				subscription_id in (select id from subscriptions_without_discarded where user_id = $1)
+			)
+		)
+	`, userId)
+	var hasLegacyUrls bool
+	err := row.Scan(&hasLegacyUrls)
+	return hasLegacyUrls, err
+}
+
+type ResolvedFeed struct {
+	MaybeSubscriptionId *SubscriptionId // nil for the user feed
+	MaybeUserId         *UserId         // nil for a subscription feed
+	MaybeRotatedAt      *schedule.Time  // set during the grace period of a rotated token
+}
+
+// Numeric keys are the legacy urls, which only work for the feeds that existed before tokens and only while
+// the user keeps them on
+func FeedToken_Resolve(
+	qu pgw.Queryable, key string, isUserFeed bool, utcNow schedule.Time,
+) (*ResolvedFeed, error) {
+	var row *pgw.Row
+	if legacyId, err := strconv.ParseInt(key, 10, 64); err == nil {
+		feedColumn := "subscription_id"
+		userIdExpr := "(select user_id from subscriptions_without_discarded where id = feed_tokens.subscription_id)"
+		if isUserFeed {
+			feedColumn = "user_id"
+			userIdExpr = "feed_tokens.user_id"
+		}
+		row = qu.QueryRow(`
+			select
+				subscription_id,
+				user_id,
+				rotated_at,
+				coalesce((select legacy_feed_urls from user_settings where user_id = `+userIdExpr+`), false)
+			from feed_tokens
+			where `+feedColumn+` = $1 and allows_legacy_url
+			order by rotated_at desc nulls first
+			limit 1
+		`, legacyId)
+	} else {
+		row = qu.QueryRow(`
+			select subscription_id, user_id, rotated_at, true
+			from feed_tokens
+			where token = $1
+		`, key)
+	}
+
+	var maybeSubscriptionId *SubscriptionId
+	var maybeUserId *UserId
+	var maybeRotatedAt *schedule.Time
+	var isAllowed bool
+	err := row.Scan(&maybeSubscriptionId, &maybeUserId, &maybeRotatedAt, &isAllowed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if isUserFeed != (maybeUserId != nil) {
+		return nil, ErrFeedNotFound
+	}
+	if !isAllowed {
+		return nil, ErrFeedGone
+	}
+	if maybeRotatedAt != nil && maybeRotatedAt.Add(FeedTokenGracePeriod).Before(utcNow) {
+		return nil, ErrFeedGone
+	}
+
+	return &ResolvedFeed{
+		MaybeSubscriptionId: maybeSubscriptionId,
+		MaybeUserId:         maybeUserId,
+		MaybeRotatedAt:      maybeRotatedAt,
+	}, nil
+}
+
+// WebSub
+
+type WebSubSubscriptionId int64
+
+type WebSubSubscription struct {
+	Id                  WebSubSubscriptionId
+	Topic               string
+	Callback            string
+	UserId              UserId
+	MaybeSubscriptionId *SubscriptionId // nil for the user feed
+	Format              FeedFormat
+	MaybeSecret         *string
+	ExpiresAt           schedule.Time
+}
+
+var ErrWebSubSubscriptionNotFound = errors.New("websub subscription not found")
+
+func WebSubSubscription_Get(qu pgw.Queryable, id WebSubSubscriptionId) (*WebSubSubscription, error) {
+	row := qu.QueryRow(`
+		select topic, callback, user_id, subscription_id, feed_format, secret, expires_at
+		from websub_subscriptions
+		where id = $1
+	`, id)
+	var topic, callback string
+	var userId UserId
+	var maybeSubscriptionId *SubscriptionId
+	var format FeedFormat
+	var maybeSecret *string
+	var expiresAt schedule.Time
+	err := row.Scan(&topic, &callback, &userId, &maybeSubscriptionId, &format, &maybeSecret, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebSubSubscriptionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &WebSubSubscription{
+		Id:                  id,
+		Topic:               topic,
+		Callback:            callback,
+		UserId:              userId,
+		MaybeSubscriptionId: maybeSubscriptionId,
+		Format:              format,
+		MaybeSecret:         maybeSecret,
+		ExpiresAt:           expiresAt,
+	}, nil
+}
+
+// Nil if the feed doesn't exist anymore
+func WebSub_GetFeedUserId(
+	qu pgw.Queryable, maybeSubscriptionId *SubscriptionId, maybeUserId *UserId,
+) (*UserId, error) {
+	var row *pgw.Row
+	if maybeSubscriptionId != nil {
+		row = qu.QueryRow(`select user_id from subscriptions_without_discarded where id = $1`, *maybeSubscriptionId)
+	} else {
+		row = qu.QueryRow(`select id from users_without_discarded where id = $1`, *maybeUserId)
+	}
+	var userId UserId
+	err := row.Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &userId, nil
+}
+
+// Resubscribing with the same callback renews the lease and replaces the secret
+func WebSubSubscription_Upsert(
+	qu pgw.Queryable, topic string, callback string, userId UserId, maybeSubscriptionId *SubscriptionId,
+	format FeedFormat, maybeSecret *string, expiresAt schedule.Time,
+) error {
+	_, err := qu.Exec(`
+		insert into websub_subscriptions (
+			topic, callback, user_id, subscription_id, feed_format, secret, expires_at
+		)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		on conflict (topic, callback)
+		do update set secret = $6, expires_at = $7
+	`, topic, callback, userId, maybeSubscriptionId, format, maybeSecret, expiresAt)
+	return err
+}
+
+func WebSubSubscription_Delete(qu pgw.Queryable, topic string, callback string) error {
+	_, err := qu.Exec(`
+		delete from websub_subscriptions where topic = $1 and callback = $2
+	`, topic, callback)
+	return err
+}
+
+func WebSubSubscription_DeleteById(qu pgw.Queryable, id WebSubSubscriptionId) error {
+	_, err := qu.Exec(`delete from websub_subscriptions where id = $1`, id)
+	return err
+}
+
+func WebSubSubscription_DeleteExpired(qu pgw.Queryable) (int64, error) {
+	tag, err := qu.Exec(`delete from websub_subscriptions where expires_at < utc_now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// All feeds are regenerated on every publish, so the changed ones are told apart by what got published at
+// publishedAt. The user feed changes if any of the subscriptions do.
+func WebSubSubscription_ListIdsToDeliver(
+	qu pgw.Queryable, userId UserId, publishedAt schedule.Time,
+) ([]WebSubSubscriptionId, error) {
+	rows, err := qu.Query(`
+		with updated_subscription_ids as (
+			select subscription_id as id from subscription_posts
+			where published_at = $2 and
+				subscription_id in (select id from subscriptions_without_discarded where user_id = $1)
+			union
+			select id from subscriptions_without_discarded
+			where user_id = $1 and final_item_published_at = $2
+		)
+		select id from websub_subscriptions
+		where user_id = $1 and
+			expires_at > utc_now() and
+			(
+				subscription_id in (select id from updated_subscription_ids) or
+				(subscription_id is null and exists (select 1 from updated_subscription_ids))
+			)
+	`, userId, publishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []WebSubSubscriptionId
+	for rows.Next() {
+		var id WebSubSubscriptionId
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// EmailDigest
+
+type EmailDigestPost struct {
+	Id       SubscriptionPostId
+	Title    string
+	RandomId SubscriptionPostRandomId
+}
+
+type EmailDigestSubscription struct {
+	Id                   SubscriptionId
+	Name                 string
+	IsInitialItemPending bool
+	IsFinalItemPending   bool
+	RemainingCount       int
+	Posts                []EmailDigestPost
+}
+
+// Everything that's waiting to go out to the user, locked so that a retried job and the next day's job
+// don't send the same posts twice. Subscriptions are sorted the same way as in the feeds.
+func EmailDigest_LockPending(qu pgw.Queryable, userId UserId) ([]EmailDigestSubscription, error) {
+	rows, err := qu.Query(`
+		select subscription_posts.id, subscription_id, blog_posts.title, random_id
+		from subscription_posts
+		join blog_posts on subscription_posts.blog_post_id = blog_posts.id
+		join subscriptions_without_discarded
+			on subscription_posts.subscription_id = subscriptions_without_discarded.id
+		where user_id = $1 and publish_status = $2
+		order by subscription_posts.order_rank asc
+		for update of subscription_posts
+	`, userId, PostPublishStatusEmailPending)
+	if err != nil {
+		return nil, err
+	}
+	postsBySubscriptionId := make(map[SubscriptionId][]EmailDigestPost)
+	for rows.Next() {
+		var post EmailDigestPost
+		var subscriptionId SubscriptionId
+		err := rows.Scan(&post.Id, &subscriptionId, &post.Title, &post.RandomId)
+		if err != nil {
+			return nil, err
+		}
+		postsBySubscriptionId[subscriptionId] = append(postsBySubscriptionId[subscriptionId], post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = qu.Query(`
+		select
+			id, name, initial_item_publish_status is not distinct from $2,
+			final_item_publish_status is not distinct from $2,
+			(
+				select count(1) from subscription_posts
+				where subscription_id = subscriptions_without_discarded.id and published_at is null and
+					skipped_at is null
+			)
+		from subscriptions_without_discarded
+		where user_id = $1
+		order by finished_setup_at desc, id desc
+		for update
+	`, userId, PostPublishStatusEmailPending)
+	if err != nil {
+		return nil, err
+	}
+	var result []EmailDigestSubscription
+	for rows.Next() {
+		var s EmailDigestSubscription
+		err := rows.Scan(&s.Id, &s.Name, &s.IsInitialItemPending, &s.IsFinalItemPending, &s.RemainingCount)
+		if err != nil {
+			return nil, err
+		}
+		s.Posts = postsBySubscriptionId[s.Id]
+		if !s.IsInitialItemPending && !s.IsFinalItemPending && len(s.Posts) == 0 {
+			continue
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func EmailDigest_UpdateStatus(
+	qu pgw.Queryable, subscriptions []EmailDigestSubscription, status PostPublishStatus,
+) error {
+	for _, subscription := range subscriptions {
+		for _, post := range subscription.Posts {
+			_, err := qu.Exec(`
+				update subscription_posts set publish_status = $1 where id = $2
+			`, status, post.Id)
+			if err != nil {
+				return err
+			}
+		}
+		if subscription.IsInitialItemPending {
+			_, err := qu.Exec(`
+				update subscriptions_without_discarded set initial_item_publish_status = $1 where id = $2
+			`, status, subscription.Id)
+			if err != nil {
+				return err
+			}
+		}
+		if subscription.IsFinalItemPending {
+			_, err := qu.Exec(`
+				update subscriptions_without_discarded set final_item_publish_status = $1 where id = $2
+			`, status, subscription.Id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Webhook
+
+type Webhook struct {
+	UserId              UserId
+	Url                 string
+	Secret              string
+	ConsecutiveFailures int
+	MaybeDisabledAt     *schedule.Time
+}
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+func Webhook_Get(qu pgw.Queryable, userId UserId) (*Webhook, error) {
+	row := qu.QueryRow(`
+		select url, secret, consecutive_failures, disabled_at from webhooks where user_id = $1
+	`, userId)
+	var w Webhook
+	w.UserId = userId
+	err := row.Scan(&w.Url, &w.Secret, &w.ConsecutiveFailures, &w.MaybeDisabledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+const webhook_RandomSecretSql = "encode(gen_random_bytes(24), 'hex')"
+
+// Saving again re-enables a disabled webhook. An existing webhook keeps its secret until rotated.
+func Webhook_Upsert(qu pgw.Queryable, userId UserId, url string) error {
+	_, err := qu.Exec(`
+		insert into webhooks (user_id, url, secret) values ($1, $2, `+webhook_RandomSecretSql+`)
+		on conflict (user_id) do update
+		set url = excluded.url, consecutive_failures = 0, disabled_at = null
+	`, userId, url)
+	return err
+}
+
+func Webhook_RotateSecret(qu pgw.Queryable, userId UserId) error {
+	_, err := qu.Exec(`
+		update webhooks set secret = `+webhook_RandomSecretSql+` where user_id = $1
+	`, userId)
+	return err
+}
+
+func Webhook_RecordSuccess(qu pgw.Queryable, userId UserId) error {
+	_, err := qu.Exec(`update webhooks set consecutive_failures = 0 where user_id = $1`, userId)
+	return err
+}
+
+// Returns the failures in a row including this one, 0 if the webhook is gone
+func Webhook_RecordFailure(qu pgw.Queryable, userId UserId) (int, error) {
+	row := qu.QueryRow(`
+		update webhooks set consecutive_failures = consecutive_failures + 1
+		where user_id = $1
+		returning consecutive_failures
+	`, userId)
+	var consecutiveFailures int
+	err := row.Scan(&consecutiveFailures)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return consecutiveFailures, nil
+}
+
+// Returns false if the webhook was already disabled or is gone
+func Webhook_Disable(qu pgw.Queryable, userId UserId, utcNow schedule.Time) (bool, error) {
+	result, err := qu.Exec(`
+		update webhooks set disabled_at = $2 where user_id = $1 and disabled_at is null
+	`, userId, utcNow)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+type WebhookPost struct {
+	SubscriptionId   SubscriptionId
+	SubscriptionName string
+	Title            string
+	RandomId         SubscriptionPostRandomId
+	OriginalUrl      string
+}
+
+func WebhookPost_ListPublished(
+	qu pgw.Queryable, userId UserId, publishedAt schedule.Time,
+) ([]WebhookPost, error) {
+	rows, err := qu.Query(`
+		select subscriptions_without_discarded.id, subscriptions_without_discarded.name, blog_posts.title,
+			subscription_posts.random_id, blog_posts.url
+		from subscription_posts
+		join blog_posts on subscription_posts.blog_post_id = blog_posts.id
+		join subscriptions_without_discarded
+			on subscription_posts.subscription_id = subscriptions_without_discarded.id
+		where user_id = $1 and subscription_posts.published_at = $2
+		order by subscriptions_without_discarded.finished_setup_at desc, subscriptions_without_discarded.id desc,
+			subscription_posts.order_rank asc
+	`, userId, publishedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WebhookPost
+	for rows.Next() {
+		var p WebhookPost
+		err := rows.Scan(&p.SubscriptionId, &p.SubscriptionName, &p.Title, &p.RandomId, &p.OriginalUrl)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type WebhookDeliveryId int64
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id                    WebhookDeliveryId
+	UserId                UserId
+	Url                   string
+	Payload               string
+	PostsCount            int
+	Status                WebhookDeliveryStatus
+	Attempts              int
+	MaybeLastResponseCode *int
+	MaybeLastError        *string
+	CreatedAt             schedule.Time
+	MaybeFinishedAt       *schedule.Time
+}
+
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+func WebhookDelivery_Create(
+	qu pgw.Queryable, userId UserId, url string, payload string, postsCount int,
+) (WebhookDeliveryId, error) {
+	row := qu.QueryRow(`
+		insert into webhook_deliveries (user_id, url, payload, posts_count, status)
+		values ($1, $2, $3, $4, $5)
+		returning id
+	`, userId, url, payload, postsCount, WebhookDeliveryStatusPending)
+	var id WebhookDeliveryId
+	err := row.Scan(&id)
+	return id, err
+}
+
+const webhookDelivery_Columns = `
+	id, user_id, url, payload, posts_count, status, attempts, last_response_code, last_error, created_at,
+	finished_at
+`
+
+func webhookDelivery_Scan(row pgx.Row) (WebhookDelivery, error) {
+	var d WebhookDelivery
+	err := row.Scan(
+		&d.Id, &d.UserId, &d.Url, &d.Payload, &d.PostsCount, &d.Status, &d.Attempts, &d.MaybeLastResponseCode,
+		&d.MaybeLastError, &d.CreatedAt, &d.MaybeFinishedAt,
+	)
+	return d, err
+}
+
+func WebhookDelivery_Get(qu pgw.Queryable, id WebhookDeliveryId) (*WebhookDelivery, error) {
+	row := qu.QueryRow(`select `+webhookDelivery_Columns+` from webhook_deliveries where id = $1`, id)
+	d, err := webhookDelivery_Scan(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrWebhookDeliveryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func WebhookDelivery_ListRecent(qu pgw.Queryable, userId UserId, limit int) ([]WebhookDelivery, error) {
+	rows, err := qu.Query(`
+		select `+webhookDelivery_Columns+` from webhook_deliveries
+		where user_id = $1
+		order by id desc
+		limit $2
+	`, userId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []WebhookDelivery
+	for rows.Next() {
+		d, err := webhookDelivery_Scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// maybeFinishedAt is nil while there are retries left
+func WebhookDelivery_RecordAttempt(
+	qu pgw.Queryable, id WebhookDeliveryId, url string, status WebhookDeliveryStatus,
+	maybeResponseCode *int, maybeError *string, maybeFinishedAt *schedule.Time,
+) error {
+	_, err := qu.Exec(`
+		update webhook_deliveries
+		set url = $1, status = $2, attempts = attempts + 1, last_response_code = $3, last_error = $4,
+			finished_at = $5
+		where id = $6
+	`, url, status, maybeResponseCode, maybeError, maybeFinishedAt, id)
+	return err
+}
+
+func WebhookDelivery_DeleteOlderThan(qu pgw.Queryable, cutoff schedule.Time) (int64, error) {
+	tag, err := qu.Exec(`delete from webhook_deliveries where created_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// OpmlImport
+
+type OpmlImportId int64
+
+var ErrOpmlImportNotFound = errors.New("opml import not found")
+
+func OpmlImport_Create(qu pgw.Queryable, userId UserId) (OpmlImportId, error) {
+	row := qu.QueryRow(`insert into opml_imports (user_id) values ($1) returning id`, userId)
+	var id OpmlImportId
+	err := row.Scan(&id)
+	return id, err
+}
+
+func OpmlImport_GetUserId(qu pgw.Queryable, id OpmlImportId) (UserId, error) {
+	row := qu.QueryRow(`select user_id from opml_imports where id = $1`, id)
+	var userId UserId
+	err := row.Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrOpmlImportNotFound
+	}
+	return userId, err
+}
+
+type OpmlImportItemId int64
+
+type OpmlImportItemStatus string
+
+const (
+	OpmlImportItemStatusPending    OpmlImportItemStatus = "pending"
+	OpmlImportItemStatusSubscribed OpmlImportItemStatus = "subscribed"
+	OpmlImportItemStatusFailed     OpmlImportItemStatus = "failed"
+)
+
+type OpmlImportItem struct {
+	Id           OpmlImportItemId
+	OpmlImportId OpmlImportId
+	UserId       UserId
+	Title        string
+	FeedUrl      string
+	Status       OpmlImportItemStatus
+}
+
+var ErrOpmlImportItemNotFound = errors.New("opml import item not found")
+
+func OpmlImportItem_Create(
+	qu pgw.Queryable, opmlImportId OpmlImportId, position int, title string, feedUrl string,
+	maybeHtmlUrl *string,
+) (OpmlImportItemId, error) {
+	row := qu.QueryRow(`
+		insert into opml_import_items (opml_import_id, position, title, feed_url, html_url, status)
+		values ($1, $2, $3, $4, $5, $6)
+		returning id
+	`, opmlImportId, position, title, feedUrl, maybeHtmlUrl, OpmlImportItemStatusPending)
+	var id OpmlImportItemId
+	err := row.Scan(&id)
+	return id, err
+}
+
+func OpmlImportItem_Get(qu pgw.Queryable, id OpmlImportItemId) (*OpmlImportItem, error) {
+	row := qu.QueryRow(`
+		select opml_import_id, (select user_id from opml_imports where id = opml_import_id), title, feed_url,
+			status
+		from opml_import_items
+		where id = $1
+	`, id)
+	var item OpmlImportItem
+	item.Id = id
+	err := row.Scan(&item.OpmlImportId, &item.UserId, &item.Title, &item.FeedUrl, &item.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOpmlImportItemNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func OpmlImportItem_MarkSubscribed(
+	qu pgw.Queryable, id OpmlImportItemId, subscriptionId SubscriptionId,
+) error {
+	_, err := qu.Exec(`
+		update opml_import_items set status = $1, subscription_id = $2 where id = $3
+	`, OpmlImportItemStatusSubscribed, subscriptionId, id)
+	return err
+}
+
+func OpmlImportItem_MarkFailed(qu pgw.Queryable, id OpmlImportItemId, message string) error {
+	_, err := qu.Exec(`
+		update opml_import_items set status = $1, error = $2 where id = $3
+	`, OpmlImportItemStatusFailed, message, id)
 	return err
 }
 
+// Imported subscriptions that skipped post selection get a schedule suggested for them
+func OpmlImportItem_ExistsForSubscription(qu pgw.Queryable, subscriptionId SubscriptionId) (bool, error) {
+	row := qu.QueryRow(`
+		select exists(select 1 from opml_import_items where subscription_id = $1)
+	`, subscriptionId)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+type OpmlImportItemProgress struct {
+	Title                   string
+	FeedUrl                 string
+	Status                  OpmlImportItemStatus
+	MaybeError              *string
+	MaybeSubscriptionId     *SubscriptionId
+	MaybeSubscriptionStatus *SubscriptionStatus
+	MaybeBlogStatus         *BlogStatus
+}
+
+// A subscription that was deleted since shows up without its id
+func OpmlImportItem_ListProgress(
+	qu pgw.Queryable, opmlImportId OpmlImportId,
+) ([]OpmlImportItemProgress, error) {
+	rows, err := qu.Query(`
+		select
+			opml_import_items.title, opml_import_items.feed_url, opml_import_items.status,
+			opml_import_items.error, subscriptions_without_discarded.id, subscriptions_without_discarded.status,
+			blogs.status
+		from opml_import_items
+		left join subscriptions_without_discarded
+			on subscriptions_without_discarded.id = opml_import_items.subscription_id
+		left join blogs on blogs.id = subscriptions_without_discarded.blog_id
+		where opml_import_items.opml_import_id = $1
+		order by opml_import_items.position
+	`, opmlImportId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []OpmlImportItemProgress
+	for rows.Next() {
+		var p OpmlImportItemProgress
+		err := rows.Scan(
+			&p.Title, &p.FeedUrl, &p.Status, &p.MaybeError, &p.MaybeSubscriptionId, &p.MaybeSubscriptionStatus,
+			&p.MaybeBlogStatus,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Vacation
+
+type VacationId int64
+
+const VacationMaxDays = 365
+
+// When catching up after a vacation, only this many of the most recent skipped days are made up for
+const VacationMaxCatchUpDays = 7
+
+// Dates are inclusive and local to the user. A single skipped day starts and ends on the same date.
+type Vacation struct {
+	Id        VacationId
+	StartDate schedule.Date
+	EndDate   schedule.Date
+}
+
+func Vacation_Create(qu pgw.Queryable, userId UserId, startDate schedule.Date, endDate schedule.Date) error {
+	_, err := qu.Exec(`
+		insert into vacations (user_id, start_date, end_date) values ($1, $2, $3)
+	`, userId, startDate, endDate)
+	return err
+}
+
+// Returns false if the user doesn't have this vacation
+func Vacation_Delete(qu pgw.Queryable, userId UserId, vacationId VacationId) (bool, error) {
+	tag, err := qu.Exec(`delete from vacations where id = $1 and user_id = $2`, vacationId, userId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Vacations that haven't ended before the date, earliest first
+func Vacation_ListEndingFrom(qu pgw.Queryable, userId UserId, date schedule.Date) ([]Vacation, error) {
+	rows, err := qu.Query(`
+		select id, start_date, end_date from vacations
+		where user_id = $1 and end_date >= $2
+		order by start_date asc, end_date asc
+	`, userId, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Vacation
+	for rows.Next() {
+		var v Vacation
+		err := rows.Scan(&v.Id, &v.StartDate, &v.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func Vacation_Covers(vacations []Vacation, date schedule.Date) bool {
+	for _, vacation := range vacations {
+		if vacation.StartDate <= date && date <= vacation.EndDate {
+			return true
+		}
+	}
+	return false
+}
+
 // Billing
 
 type PlanId string
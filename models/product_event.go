@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
@@ -102,15 +103,20 @@ func ProductEvent_MustEmitFromRequest(
 
 func ProductEvent_MustEmitSchedule(
 	pc ProductEventContext, eventType string, subscriptionId SubscriptionId, blogBestUrl string,
-	weeklyCount int, activeDays int,
+	scheduleType ScheduleType, weeklyCount int, activeDays int,
 ) {
-	ProductEvent_MustEmitFromRequest(pc, eventType, map[string]any{
-		"subscription_id":      subscriptionId,
-		"blog_url":             blogBestUrl,
-		"weekly_count":         weeklyCount,
-		"active_days":          activeDays,
-		"posts_per_active_day": float64(weeklyCount) / float64(activeDays),
-	}, nil)
+	eventProperties := map[string]any{
+		"subscription_id": subscriptionId,
+		"blog_url":        blogBestUrl,
+		"schedule_type":   scheduleType,
+	}
+	// Other types keep the weekly counts around but don't use them
+	if scheduleType == ScheduleTypeWeekly {
+		eventProperties["weekly_count"] = weeklyCount
+		eventProperties["active_days"] = activeDays
+		eventProperties["posts_per_active_day"] = float64(weeklyCount) / float64(activeDays)
+	}
+	ProductEvent_MustEmitFromRequest(pc, eventType, eventProperties, nil)
 }
 
 func ProductEvent_MustEmitVisitAddPage(
@@ -271,6 +277,107 @@ func ProductEvent_StartDummyEventsSync(ctx context.Context, wg *sync.WaitGroup)
 	}()
 }
 
+// Counted events happen too often to be written one by one (e.g. polls answered with 304). They are
+// aggregated in memory and written as one event per distinct set of properties with a count.
+
+type countedEventKey struct {
+	ProductUserId  ProductUserId
+	EventType      string
+	PropertiesJson string
+}
+
+type countedEvent struct {
+	EventProperties map[string]any
+	Count           int
+}
+
+var countedEventsMutex sync.Mutex
+var countedEvents = make(map[countedEventKey]*countedEvent)
+
+func ProductEvent_QueueCounted(
+	productUserId ProductUserId, eventType string, eventProperties map[string]any,
+) error {
+	propertiesJson, err := json.Marshal(eventProperties)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	key := countedEventKey{
+		ProductUserId:  productUserId,
+		EventType:      eventType,
+		PropertiesJson: string(propertiesJson),
+	}
+
+	countedEventsMutex.Lock()
+	defer countedEventsMutex.Unlock()
+	if event, ok := countedEvents[key]; ok {
+		event.Count++
+	} else {
+		countedEvents[key] = &countedEvent{
+			EventProperties: eventProperties,
+			Count:           1,
+		}
+	}
+	return nil
+}
+
+func ProductEvent_StartCountedEventsSync(ctx context.Context, wg *sync.WaitGroup) {
+	go func() {
+		logger := &log.TaskLogger{TaskName: "emit_counted_events"}
+		ticker := time.NewTicker(time.Minute)
+		errorCount := 0
+		defer wg.Done()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+			}
+
+			countedEventsMutex.Lock()
+			events := countedEvents
+			countedEvents = make(map[countedEventKey]*countedEvent)
+			countedEventsMutex.Unlock()
+
+			if len(events) > 0 {
+				pool := db.RootPool
+				batch := pool.NewBatch()
+				for key, event := range events {
+					eventProperties := make(map[string]any, len(event.EventProperties)+1)
+					for name, value := range event.EventProperties {
+						eventProperties[name] = value
+					}
+					eventProperties["count"] = event.Count
+					ProductEvent_EmitToBatch(batch, key.ProductUserId, key.EventType, eventProperties, nil)
+				}
+				results := pool.SendBatch(batch)
+				err := results.Close()
+				if err != nil {
+					errorCount++
+					logger.Error().Err(err).Msgf("Counted event emit error (%d attempts)", errorCount)
+
+					// Put the events back so that they go out with the next tick
+					countedEventsMutex.Lock()
+					for key, event := range events {
+						if existingEvent, ok := countedEvents[key]; ok {
+							existingEvent.Count += event.Count
+						} else {
+							countedEvents[key] = event
+						}
+					}
+					countedEventsMutex.Unlock()
+				} else {
+					logger.Info().Msgf("Emitted %d counted events", len(events))
+					errorCount = 0
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+}
+
 type ProductEventId int64
 
 type ProductEvent struct {
@@ -7,6 +7,7 @@ import (
 
 	"feedrewind.com/db/pgw"
 	"feedrewind.com/models/mutil"
+	"feedrewind.com/util"
 	"feedrewind.com/util/schedule"
 
 	"errors"
@@ -49,6 +50,65 @@ func Subscription_SetIsPaused(qu pgw.Queryable, subscriptionId SubscriptionId, i
 	return err
 }
 
+func Subscription_SetIncludePostContent(
+	qu pgw.Queryable, subscriptionId SubscriptionId, includePostContent bool,
+) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set include_post_content = $1 where id = $2
+	`, includePostContent, subscriptionId)
+	return err
+}
+
+// Turning adaptive pacing on or off starts over at the full schedule, and only the posts delivered from now
+// on count towards slowing down
+func Subscription_SetAdaptivePacing(
+	qu pgw.Queryable, subscriptionId SubscriptionId, adaptivePacing bool, utcNow schedule.Time,
+) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded
+		set adaptive_pacing = $1, pacing_percent = 100, pacing_credit = 0, pacing_changed_at = $2
+		where id = $3
+	`, adaptivePacing, utcNow, subscriptionId)
+	return err
+}
+
+func Subscription_SetPacingPercent(
+	qu pgw.Queryable, subscriptionId SubscriptionId, pacingPercent int, utcNow schedule.Time,
+) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set pacing_percent = $1, pacing_changed_at = $2 where id = $3
+	`, pacingPercent, utcNow, subscriptionId)
+	return err
+}
+
+func Subscription_SetPacingCredit(qu pgw.Queryable, subscriptionId SubscriptionId, pacingCredit int) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set pacing_credit = $1 where id = $2
+	`, pacingCredit, subscriptionId)
+	return err
+}
+
+// Goes back to the full schedule if adaptive pacing has slowed the subscription down. Returns whether it had.
+func Subscription_ResumePacing(
+	qu pgw.Queryable, subscriptionId SubscriptionId, utcNow schedule.Time,
+) (bool, error) {
+	tag, err := qu.Exec(`
+		update subscriptions_without_discarded set pacing_percent = 100, pacing_changed_at = $1
+		where id = $2 and adaptive_pacing and pacing_percent < 100
+	`, utcNow, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func Subscription_SetBlogId(qu pgw.Queryable, subscriptionId SubscriptionId, blogId BlogId) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set blog_id = $1 where id = $2
+	`, blogId, subscriptionId)
+	return err
+}
+
 func Subscription_Delete(qu pgw.Queryable, subscriptionId SubscriptionId) error {
 	// Has to be with_discarded because adding timestamp to without_discarded is a constraint violation
 	_, err := qu.Exec(`
@@ -75,14 +135,15 @@ func Subscription_GetOtherNamesByDay(
 		with user_subscriptions as (
 			select id, name, created_at from subscriptions_without_discarded
 			where user_id = $1 and
-			status = 'live'
+			status = 'live' and
+			schedule_type = 'weekly'
 		)  
 		select name, day_of_week, day_count
 		from user_subscriptions
 		join (
 			select subscription_id,
 				count(published_at) as published_count,
-				count(1) as total_count
+				count(1) filter (where skipped_at is null) as total_count
 			from subscription_posts
 			where subscription_id in (select id from user_subscriptions)
 			group by subscription_id
@@ -121,10 +182,12 @@ func Subscription_GetOtherNamesByDay(
 }
 
 type SchedulePreview struct {
-	PrevPosts   []SchedulePreviewPrevPost
-	NextPosts   []SchedulePreviewNextPost
-	PrevHasMore bool
-	NextHasMore bool
+	PrevPosts        []SchedulePreviewPrevPost
+	NextPosts        []SchedulePreviewNextPost
+	PrevHasMore      bool
+	NextHasMore      bool
+	UnpublishedCount int
+	PostOrder        PostOrder
 }
 
 type SchedulePreviewPrevPost struct {
@@ -134,8 +197,9 @@ type SchedulePreviewPrevPost struct {
 }
 
 type SchedulePreviewNextPost struct {
-	Url   string
-	Title string
+	Url            string
+	Title          string
+	ReadingMinutes int
 }
 
 func Subscription_GetSchedulePreview(
@@ -147,25 +211,32 @@ func Subscription_GetSchedulePreview(
 			url,
 			title,
 			published_at_local_date,
-			null::bigint as count
+			null::bigint as count,
+			null::text as content
 		from subscription_posts
-		join (select id, url, title, index from blog_posts) as blog_posts on blog_posts.id = blog_post_id 
+		join (select id, url, title from blog_posts) as blog_posts on blog_posts.id = blog_post_id 
 		where subscription_id = $1 and published_at is not null
-		order by index desc
+		order by published_at desc, order_rank desc
 		limit 2
 	) UNION ALL (
-		select 'next_post' as tag, url, title, published_at_local_date, null as count
+		select 'next_post' as tag, url, title, published_at_local_date, null as count, content
 		from subscription_posts
-		join (select id, url, title, index from blog_posts) as blog_posts on blog_posts.id = blog_post_id 
-		where subscription_id = $1 and published_at is null
-		order by index asc
+		join (select id, url, title, content from blog_posts) as blog_posts
+			on blog_posts.id = blog_post_id
+		where subscription_id = $1 and published_at is null and skipped_at is null
+		order by queue_order asc nulls last, order_rank asc
 		limit 5
 	) UNION ALL (
-		select 'published_count' as tag, null, null, null, count(published_at) as count from subscription_posts
+		select 'published_count' as tag, null, null, null, count(published_at) as count, null
+		from subscription_posts
 		where subscription_id = $1
 	) UNION ALL (
-		select 'total_count' as tag, null, null, null, count(1) as count from subscription_posts
-		where subscription_id = $1
+		select 'total_count' as tag, null, null, null, count(1) as count, null from subscription_posts
+		where subscription_id = $1 and skipped_at is null
+	) UNION ALL (
+		select 'post_order' as tag, null, post_order::text, null, null, null
+		from subscriptions_without_discarded
+		where id = $1
 	)`, subscriptionId)
 	if err != nil {
 		return nil, err
@@ -178,7 +249,8 @@ func Subscription_GetSchedulePreview(
 		var maybeUrl, maybeTitle *string
 		var maybePublishDate *schedule.Date
 		var maybeCount *int
-		err := rows.Scan(&tag, &maybeUrl, &maybeTitle, &maybePublishDate, &maybeCount)
+		var maybeContent *string
+		err := rows.Scan(&tag, &maybeUrl, &maybeTitle, &maybePublishDate, &maybeCount, &maybeContent)
 		if err != nil {
 			return nil, err
 		}
@@ -192,13 +264,16 @@ func Subscription_GetSchedulePreview(
 			})
 		case "next_post":
 			result.NextPosts = append(result.NextPosts, SchedulePreviewNextPost{
-				Url:   *maybeUrl,
-				Title: *maybeTitle,
+				Url:            *maybeUrl,
+				Title:          *maybeTitle,
+				ReadingMinutes: util.ReadingMinutes(maybeContent),
 			})
 		case "published_count":
 			publishedCount = *maybeCount
 		case "total_count":
 			totalCount = *maybeCount
+		case "post_order":
+			result.PostOrder = PostOrder(*maybeTitle)
 		default:
 			panic(fmt.Errorf("Unknown tag: %s", tag))
 		}
@@ -212,6 +287,7 @@ func Subscription_GetSchedulePreview(
 	}
 
 	unpublishedCount := totalCount - publishedCount
+	result.UnpublishedCount = unpublishedCount
 
 	result.PrevHasMore = (publishedCount - len(result.PrevPosts)) > 0
 	if result.PrevHasMore {
@@ -279,6 +355,11 @@ func Subscription_Create(
 		return nil, err
 	}
 
+	err = FeedToken_CreateForSubscription(qu, id)
+	if err != nil {
+		return nil, err
+	}
+
 	return &SubscriptionCreateResult{
 		Id:          id,
 		BlogBestUrl: blog.BestUrl,
@@ -289,13 +370,75 @@ func Subscription_Create(
 // 16 urlsafe random bytes
 var psqlRandomId = "rtrim(replace(replace(encode(gen_random_bytes(16), 'base64'), '+', '-'), '/', '_'), '=')"
 
+type PostOrder string
+
+const (
+	PostOrderChronological PostOrder = "chronological"
+	PostOrderReverse       PostOrder = "reverse"
+	PostOrderShuffle       PostOrder = "shuffle"
+	PostOrderBestOf        PostOrder = "best_of"
+)
+
+var PostOrders = []PostOrder{PostOrderChronological, PostOrderReverse, PostOrderShuffle, PostOrderBestOf}
+
+func (o PostOrder) Label() string {
+	switch o {
+	case PostOrderChronological:
+		return "Oldest first"
+	case PostOrderReverse:
+		return "Newest first"
+	case PostOrderShuffle:
+		return "Shuffled"
+	case PostOrderBestOf:
+		return "Best of"
+	default:
+		panic(fmt.Errorf("Unknown post order: %s", o))
+	}
+}
+
+// Sql to rank blog_posts for subscription $1. Shuffle hashes the subscription id so that the order doesn't
+// change between queries. Best of goes by how often other subscribers opened the post once it went out, with
+// a smoothing term so that a single lucky open doesn't win, and falls back to the oldest first.
+func subscription_PostOrderRankSql(postOrder PostOrder) string {
+	var orderBySql string
+	switch postOrder {
+	case PostOrderChronological:
+		orderBySql = "blog_posts.index asc"
+	case PostOrderReverse:
+		orderBySql = "blog_posts.index desc"
+	case PostOrderShuffle:
+		orderBySql = "md5($1::bigint::text || ':' || blog_posts.id::text), blog_posts.index asc"
+	case PostOrderBestOf:
+		orderBySql = `(
+			select count(opened_at)::float8 / (count(published_at) + 1) from subscription_posts as others
+			where others.blog_post_id = blog_posts.id
+		) desc, blog_posts.index asc`
+	default:
+		panic(fmt.Errorf("Unknown post order: %s", postOrder))
+	}
+	return "row_number() over (order by " + orderBySql + ")"
+}
+
+func subscription_SavePostOrder(qu pgw.Queryable, subscriptionId SubscriptionId, postOrder PostOrder) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set post_order = $1 where id = $2
+	`, postOrder, subscriptionId)
+	return err
+}
+
 func Subscription_CreatePostsFromCategory(
-	qu pgw.Queryable, subscriptionId SubscriptionId, categoryId BlogPostCategoryId,
+	qu pgw.Queryable, subscriptionId SubscriptionId, categoryId BlogPostCategoryId, postOrder PostOrder,
 ) error {
-	_, err := qu.Exec(`
-		insert into subscription_posts (subscription_id, blog_post_id, random_id, published_at)
-		select $1, blog_post_id, `+psqlRandomId+`, null
+	err := subscription_SavePostOrder(qu, subscriptionId, postOrder)
+	if err != nil {
+		return err
+	}
+
+	_, err = qu.Exec(`
+		insert into subscription_posts (subscription_id, blog_post_id, random_id, published_at, order_rank)
+		select $1, blog_post_id, `+psqlRandomId+`, null, `+subscription_PostOrderRankSql(postOrder)+`
 		from blog_post_category_assignments
+		join blog_posts on blog_posts.id = blog_post_id
 		where category_id = $2
 	`, subscriptionId, categoryId)
 	return err
@@ -303,6 +446,7 @@ func Subscription_CreatePostsFromCategory(
 
 func Subscription_CreatePostsFromIds(
 	qu pgw.Queryable, subscriptionId SubscriptionId, blogId BlogId, blogPostIds map[BlogPostId]bool,
+	postOrder PostOrder,
 ) error {
 	var blogPostIdsStr strings.Builder
 	for blogPostId := range blogPostIds {
@@ -312,9 +456,14 @@ func Subscription_CreatePostsFromIds(
 		fmt.Fprint(&blogPostIdsStr, blogPostId)
 	}
 
-	_, err := qu.Exec(`
-		insert into subscription_posts (subscription_id, blog_post_id, random_id, published_at)
-		select $1, id, `+psqlRandomId+`, null
+	err := subscription_SavePostOrder(qu, subscriptionId, postOrder)
+	if err != nil {
+		return err
+	}
+
+	_, err = qu.Exec(`
+		insert into subscription_posts (subscription_id, blog_post_id, random_id, published_at, order_rank)
+		select $1, id, `+psqlRandomId+`, null, `+subscription_PostOrderRankSql(postOrder)+`
 		from blog_posts
 		where blog_id = $2 and id in (`+blogPostIdsStr.String()+`)
 	`, subscriptionId, blogId)
@@ -349,11 +498,23 @@ type SubscriptionToPublish struct {
 	FinishedSetupAt           schedule.Time
 	MaybeFinalItemPublishedAt *schedule.Time
 	BlogId                    BlogId
+	IncludePostContent        bool
+	FeedToken                 FeedToken
+	AdaptivePacing            bool
+	PacingPercent             int
+	PacingCredit              int
+	MaybePacingChangedAt      *schedule.Time
 }
 
 func Subscription_ListSortedToPublish(qu pgw.Queryable, userId UserId) ([]SubscriptionToPublish, error) {
 	rows, err := qu.Query(`
-		select id, name, is_paused, finished_setup_at, final_item_published_at, blog_id
+		select
+			id, name, is_paused, finished_setup_at, final_item_published_at, blog_id, include_post_content,
+			(
+				select token from feed_tokens
+				where feed_tokens.subscription_id = subscriptions_without_discarded.id and rotated_at is null
+			),
+			adaptive_pacing, pacing_percent, pacing_credit, pacing_changed_at
 		from subscriptions_without_discarded
 		where user_id = $1 and status = $2
 		order by finished_setup_at desc, id desc
@@ -367,6 +528,8 @@ func Subscription_ListSortedToPublish(qu pgw.Queryable, userId UserId) ([]Subscr
 		var s SubscriptionToPublish
 		err := rows.Scan(
 			&s.Id, &s.Name, &s.IsPaused, &s.FinishedSetupAt, &s.MaybeFinalItemPublishedAt, &s.BlogId,
+			&s.IncludePostContent, &s.FeedToken, &s.AdaptivePacing, &s.PacingPercent, &s.PacingCredit,
+			&s.MaybePacingChangedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -380,6 +543,41 @@ func Subscription_ListSortedToPublish(qu pgw.Queryable, userId UserId) ([]Subscr
 	return result, nil
 }
 
+type SubscriptionForOpml struct {
+	Name     string
+	FeedUrl  string
+	MaybeUrl *string
+}
+
+// Includes the subscriptions that are still being set up, they have a feed all the same
+func Subscription_ListForOpml(qu pgw.Queryable, userId UserId) ([]SubscriptionForOpml, error) {
+	rows, err := qu.Query(`
+		select subscriptions_without_discarded.name, blogs.feed_url, blogs.url
+		from subscriptions_without_discarded
+		join blogs on blogs.id = subscriptions_without_discarded.blog_id
+		where user_id = $1
+		order by finished_setup_at desc nulls last, subscriptions_without_discarded.id desc
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []SubscriptionForOpml
+	for rows.Next() {
+		var s SubscriptionForOpml
+		err := rows.Scan(&s.Name, &s.FeedUrl, &s.MaybeUrl)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // SubscriptionPost
 
 type SubscriptionPostId int64
@@ -389,23 +587,53 @@ type SubscriptionBlogPost struct {
 	Id               SubscriptionPostId
 	Title            string
 	RandomId         SubscriptionPostRandomId
+	Index            int // among the subscription posts, in the subscription order
+	MaybeContent     *string
+	MaybeExcerpt     *string // from the post page, for posts whose feed entry had no content
 	MaybePublishedAt *schedule.Time
 }
 
+// Subscription posts of $1 numbered from 0 in the subscription order
+const subscriptionPost_IndexedSql = `
+	select
+		subscription_posts.id, title, random_id, content, published_at, queue_order, skipped_at,
+		(select excerpt from blog_post_articles where blog_post_articles.blog_post_id = blog_posts.id),
+		(row_number() over (order by subscription_posts.order_rank asc) - 1)::integer as index
+	from subscription_posts
+	join blog_posts on subscription_posts.blog_post_id = blog_posts.id
+	where subscription_id = $1
+`
+
 type PostPublishStatus string
 
 const (
 	PostPublishStatusRssPublished PostPublishStatus = "rss_published"
+	PostPublishStatusEmailPending PostPublishStatus = "email_pending"
+	PostPublishStatusEmailSkipped PostPublishStatus = "email_skipped"
+	PostPublishStatusEmailSent    PostPublishStatus = "email_sent"
 )
 
+// Reading time schedules need the post lengths, which are only known when the content is
+func SubscriptionPost_CountUnpublishedWithoutContent(
+	qu pgw.Queryable, subscriptionId SubscriptionId,
+) (int, error) {
+	row := qu.QueryRow(`
+		select count(1) from (`+subscriptionPost_IndexedSql+`) as posts
+		where published_at is null and skipped_at is null and content is null
+	`, subscriptionId)
+	var result int
+	err := row.Scan(&result)
+	return result, err
+}
+
 func SubscriptionPost_GetNextUnpublished(
 	qu pgw.Queryable, subscriptionId SubscriptionId, count int,
 ) ([]SubscriptionBlogPost, error) {
 	rows, err := qu.Query(`
-		select subscription_posts.id, title, random_id, published_at from subscription_posts 
-		join blog_posts on subscription_posts.blog_post_id = blog_posts.id
-		where subscription_id = $1 and published_at is null
-		order by index asc
+		select id, title, random_id, index, content, excerpt, published_at
+		from (`+subscriptionPost_IndexedSql+`) as posts
+		where published_at is null and skipped_at is null
+		order by queue_order asc nulls last, index asc
 		limit $2
 	`, subscriptionId, count)
 	if err != nil {
@@ -415,7 +643,9 @@ func SubscriptionPost_GetNextUnpublished(
 	var result []SubscriptionBlogPost
 	for rows.Next() {
 		var p SubscriptionBlogPost
-		err := rows.Scan(&p.Id, &p.Title, &p.RandomId, &p.MaybePublishedAt)
+		err := rows.Scan(
+			&p.Id, &p.Title, &p.RandomId, &p.Index, &p.MaybeContent, &p.MaybeExcerpt, &p.MaybePublishedAt,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -429,20 +659,23 @@ func SubscriptionPost_GetNextUnpublished(
 }
 
 type PublishedSubscriptionBlogPost struct {
-	Id          SubscriptionPostId
-	Title       string
-	RandomId    SubscriptionPostRandomId
-	PublishedAt schedule.Time
+	Id           SubscriptionPostId
+	Title        string
+	RandomId     SubscriptionPostRandomId
+	Index        int // among the subscription posts, in the subscription order
+	MaybeContent *string
+	MaybeExcerpt *string
+	PublishedAt  schedule.Time
 }
 
 func SubscriptionPost_GetLastPublishedDesc(
 	qu pgw.Queryable, subscriptionId SubscriptionId, count int,
 ) ([]PublishedSubscriptionBlogPost, error) {
 	rows, err := qu.Query(`
-		select subscription_posts.id, title, random_id, published_at from subscription_posts
-		join blog_posts on subscription_posts.blog_post_id = blog_posts.id
-		where subscription_id = $1 and published_at is not null
-		order by index desc
+		select id, title, random_id, index, content, excerpt, published_at
+		from (`+subscriptionPost_IndexedSql+`) as posts
+		where published_at is not null
+		order by published_at desc, index desc
 		limit $2
 	`, subscriptionId, count)
 	if err != nil {
@@ -452,7 +685,9 @@ func SubscriptionPost_GetLastPublishedDesc(
 	var result []PublishedSubscriptionBlogPost
 	for rows.Next() {
 		var p PublishedSubscriptionBlogPost
-		err := rows.Scan(&p.Id, &p.Title, &p.RandomId, &p.PublishedAt)
+		err := rows.Scan(
+			&p.Id, &p.Title, &p.RandomId, &p.Index, &p.MaybeContent, &p.MaybeExcerpt, &p.PublishedAt,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -465,6 +700,57 @@ func SubscriptionPost_GetLastPublishedDesc(
 	return result, nil
 }
 
+type SubscriptionPostPosition struct {
+	Index      int
+	TotalCount int
+	MaybePrev  *PublishedSubscriptionBlogPost
+	MaybeNext  *PublishedSubscriptionBlogPost // only once published
+}
+
+func SubscriptionPost_GetPosition(
+	qu pgw.Queryable, subscriptionId SubscriptionId, postId SubscriptionPostId,
+) (*SubscriptionPostPosition, error) {
+	row := qu.QueryRow(`
+		select index, (select count(1) from subscription_posts where subscription_id = $1)
+		from (`+subscriptionPost_IndexedSql+`) as posts
+		where id = $2
+	`, subscriptionId, postId)
+	var result SubscriptionPostPosition
+	err := row.Scan(&result.Index, &result.TotalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := qu.Query(`
+		select id, title, random_id, index, content, excerpt, published_at
+		from (`+subscriptionPost_IndexedSql+`) as posts
+		where published_at is not null and index in ($2 - 1, $2 + 1)
+	`, subscriptionId, result.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var p PublishedSubscriptionBlogPost
+		err := rows.Scan(
+			&p.Id, &p.Title, &p.RandomId, &p.Index, &p.MaybeContent, &p.MaybeExcerpt, &p.PublishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if p.Index < result.Index {
+			result.MaybePrev = &p
+		} else {
+			result.MaybeNext = &p
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func SubscriptionPost_UpdatePublished(
 	qu pgw.Queryable, postId SubscriptionPostId, publishedAt schedule.Time,
 	publishedAtLocalDate schedule.Date, publishStatus PostPublishStatus,
@@ -477,17 +763,221 @@ func SubscriptionPost_UpdatePublished(
 	return err
 }
 
+// Posts delivered ahead of the schedule are marked so that the daily job doesn't take them for its own run
+func SubscriptionPost_UpdateDeliveredNow(
+	qu pgw.Queryable, postId SubscriptionPostId, publishedAt schedule.Time,
+	publishedAtLocalDate schedule.Date, publishStatus PostPublishStatus,
+) error {
+	_, err := qu.Exec(`
+		update subscription_posts
+		set published_at = $1, published_at_local_date = $2, publish_status = $3, is_delivered_now = true
+		where id = $4
+	`, publishedAt, publishedAtLocalDate, publishStatus, postId)
+	return err
+}
+
+// Only the first open counts
+func SubscriptionPost_MarkOpened(qu pgw.Queryable, id SubscriptionPostId, utcNow schedule.Time) error {
+	_, err := qu.Exec(`
+		update subscription_posts set opened_at = $1 where id = $2 and opened_at is null
+	`, utcNow, id)
+	return err
+}
+
+// Looks at up to limit most recently published posts, skipping those published before maybeSince
+func SubscriptionPost_CountRecentUnopened(
+	qu pgw.Queryable, subscriptionId SubscriptionId, maybeSince *schedule.Time, limit int,
+) (publishedCount int, unopenedCount int, err error) {
+	row := qu.QueryRow(`
+		select count(1), count(1) filter (where opened_at is null)
+		from (
+			select opened_at from subscription_posts
+			where subscription_id = $1 and published_at is not null and
+				($2::timestamp without time zone is null or published_at >= $2)
+			order by published_at desc, id desc
+			limit $3
+		) as recent_posts
+	`, subscriptionId, maybeSince, limit)
+	err = row.Scan(&publishedCount, &unopenedCount)
+	return publishedCount, unopenedCount, err
+}
+
 func SubscriptionPost_GetUnpublishedCount(qu pgw.Queryable, subscriptionId SubscriptionId) (int, error) {
 	row := qu.QueryRow(`
-		select count(1) from subscription_posts where subscription_id = $1 and published_at is null
+		select count(1) from subscription_posts
+		where subscription_id = $1 and published_at is null and skipped_at is null
 	`, subscriptionId)
 	var result int
 	err := row.Scan(&result)
 	return result, err
 }
 
+type SubscriptionQueuePost struct {
+	Id             SubscriptionPostId
+	Title          string
+	Url            string
+	IsMovedToFront bool
+	IsSkipped      bool
+}
+
+// Unpublished posts in the order they're going to go out, followed by the skipped ones
+func SubscriptionPost_ListQueue(
+	qu pgw.Queryable, subscriptionId SubscriptionId,
+) ([]SubscriptionQueuePost, error) {
+	rows, err := qu.Query(`
+		select subscription_posts.id, title, url, queue_order is not null, skipped_at is not null
+		from subscription_posts
+		join (select id, url, title from blog_posts) as blog_posts on blog_posts.id = blog_post_id
+		where subscription_id = $1 and published_at is null
+		order by skipped_at is not null, queue_order asc nulls last, order_rank asc
+	`, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []SubscriptionQueuePost
+	for rows.Next() {
+		var p SubscriptionQueuePost
+		err := rows.Scan(&p.Id, &p.Title, &p.Url, &p.IsMovedToFront, &p.IsSkipped)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Returns false if the post isn't in the subscription queue, same for moving to front and restoring
+func SubscriptionPost_Skip(
+	qu pgw.Queryable, subscriptionId SubscriptionId, postId SubscriptionPostId, utcNow schedule.Time,
+) (bool, error) {
+	tag, err := qu.Exec(`
+		update subscription_posts set skipped_at = $1
+		where id = $2 and subscription_id = $3 and published_at is null
+	`, utcNow, postId, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// The post goes ahead of the ones moved before it
+func SubscriptionPost_MoveToFront(
+	qu pgw.Queryable, subscriptionId SubscriptionId, postId SubscriptionPostId,
+) (bool, error) {
+	tag, err := qu.Exec(`
+		update subscription_posts
+		set queue_order = (
+				select coalesce(min(queue_order), 0) - 1 from subscription_posts where subscription_id = $1
+			),
+			skipped_at = null
+		where id = $2 and subscription_id = $1 and published_at is null
+	`, subscriptionId, postId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// The post goes back to its original place in the queue
+func SubscriptionPost_Restore(
+	qu pgw.Queryable, subscriptionId SubscriptionId, postId SubscriptionPostId,
+) (bool, error) {
+	tag, err := qu.Exec(`
+		update subscription_posts set queue_order = null, skipped_at = null
+		where id = $1 and subscription_id = $2 and published_at is null
+	`, postId, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // Schedule
 
+type ScheduleType string
+
+const (
+	ScheduleTypeWeekly      ScheduleType = "weekly"
+	ScheduleTypeEveryNDays  ScheduleType = "every_n_days"
+	ScheduleTypePerMonth    ScheduleType = "per_month"
+	ScheduleTypeFinishBy    ScheduleType = "finish_by"
+	ScheduleTypeReadingTime ScheduleType = "reading_time"
+)
+
+// Weekly schedules keep their counts in the schedules table, other types only need the fields for the type.
+// The counts are kept around so that switching back to weekly restores them.
+type ScheduleCadence struct {
+	Type          ScheduleType
+	EveryDays     int
+	MonthlyCount  int
+	FinishDate    schedule.Date
+	WeeklyMinutes int
+	// Every n days counts from this date
+	StartDate schedule.Date
+	// Reading time carried over between days, in sevenths of a minute so that a day adds the weekly minutes
+	Credit int
+}
+
+func Schedule_GetCadence(qu pgw.Queryable, subscriptionId SubscriptionId) (*ScheduleCadence, error) {
+	row := qu.QueryRow(`
+		select
+			schedule_type, coalesce(schedule_every_days, 0), coalesce(schedule_monthly_count, 0),
+			coalesce(schedule_finish_date, ''), coalesce(schedule_weekly_minutes, 0),
+			coalesce(schedule_start_date, ''), schedule_credit
+		from subscriptions_without_discarded
+		where id = $1
+	`, subscriptionId)
+	var c ScheduleCadence
+	err := row.Scan(
+		&c.Type, &c.EveryDays, &c.MonthlyCount, &c.FinishDate, &c.WeeklyMinutes, &c.StartDate, &c.Credit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Only the fields of the cadence type are saved, the rest are cleared
+func Schedule_UpdateCadence(qu pgw.Queryable, subscriptionId SubscriptionId, cadence ScheduleCadence) error {
+	var maybeEveryDays, maybeMonthlyCount, maybeWeeklyMinutes *int
+	var maybeFinishDate, maybeStartDate *schedule.Date
+	switch cadence.Type {
+	case ScheduleTypeWeekly:
+	case ScheduleTypeEveryNDays:
+		maybeEveryDays = &cadence.EveryDays
+		maybeStartDate = &cadence.StartDate
+	case ScheduleTypePerMonth:
+		maybeMonthlyCount = &cadence.MonthlyCount
+	case ScheduleTypeFinishBy:
+		maybeFinishDate = &cadence.FinishDate
+	case ScheduleTypeReadingTime:
+		maybeWeeklyMinutes = &cadence.WeeklyMinutes
+	default:
+		panic(fmt.Errorf("Unknown schedule type: %s", cadence.Type))
+	}
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded
+		set schedule_type = $1, schedule_every_days = $2, schedule_monthly_count = $3,
+			schedule_finish_date = $4, schedule_weekly_minutes = $5, schedule_start_date = $6,
+			schedule_credit = $7
+		where id = $8
+	`, cadence.Type, maybeEveryDays, maybeMonthlyCount, maybeFinishDate, maybeWeeklyMinutes, maybeStartDate,
+		cadence.Credit, subscriptionId)
+	return err
+}
+
+func Schedule_SetCredit(qu pgw.Queryable, subscriptionId SubscriptionId, credit int) error {
+	_, err := qu.Exec(`
+		update subscriptions_without_discarded set schedule_credit = $1 where id = $2
+	`, credit, subscriptionId)
+	return err
+}
+
 func Schedule_Create(
 	qu pgw.Queryable, subscriptionId SubscriptionId, countsByDay map[schedule.DayOfWeek]int,
 ) error {
@@ -505,15 +995,25 @@ func Schedule_Create(
 	return err
 }
 
-func Schedule_GetCount(
-	qu pgw.Queryable, subscriptionId SubscriptionId, dayOfWeek schedule.DayOfWeek,
-) (int, error) {
-	row := qu.QueryRow(`
-		select count from schedules where subscription_id = $1 and day_of_week = $2
-	`, subscriptionId, dayOfWeek)
-	var result int
-	err := row.Scan(&result)
-	return result, err
+// Imported subscriptions skip post selection, so they start with a post a week on the least busy day. That
+// keeps a big import from flooding the reader.
+func Schedule_DefaultCountsByDay(
+	otherSubNamesByDay map[schedule.DayOfWeek][]string,
+) map[schedule.DayOfWeek]int {
+	defaultDay := schedule.DaysOfWeek[1]
+	for i := 2; i <= len(schedule.DaysOfWeek); i++ { // Monday first, Sunday last
+		dayOfWeek := schedule.DaysOfWeek[i%len(schedule.DaysOfWeek)]
+		if len(otherSubNamesByDay[dayOfWeek]) < len(otherSubNamesByDay[defaultDay]) {
+			defaultDay = dayOfWeek
+		}
+	}
+
+	countsByDay := make(map[schedule.DayOfWeek]int)
+	for _, dayOfWeek := range schedule.DaysOfWeek {
+		countsByDay[dayOfWeek] = 0
+	}
+	countsByDay[defaultDay] = 1
+	return countsByDay
 }
 
 func Schedule_GetCountsByDay(
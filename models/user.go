@@ -9,6 +9,7 @@ import (
 	"feedrewind.com/db/pgw"
 	"feedrewind.com/models/mutil"
 	"feedrewind.com/oops"
+	"feedrewind.com/util/schedule"
 
 	"github.com/jackc/pgx/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -54,6 +55,22 @@ func user_FindBy(qu pgw.Queryable, column string, value string) (*User, error) {
 	return &user, nil
 }
 
+func User_GetById(qu pgw.Queryable, userId UserId) (*User, error) {
+	row := qu.QueryRow(`
+		select email, name, product_user_id from users_without_discarded where id = $1
+	`, userId)
+	var user User
+	user.Id = userId
+	err := row.Scan(&user.Email, &user.Name, &user.ProductUserId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func User_Exists(qu pgw.Queryable, userId UserId) (bool, error) {
 	row := qu.QueryRow("select 1 from users_without_discarded where id = $1", userId)
 	var one int
@@ -237,6 +254,8 @@ type DeliveryChannel string
 const (
 	DeliveryChannelSingleFeed    DeliveryChannel = "single_feed"
 	DeliveryChannelMultipleFeeds DeliveryChannel = "multiple_feeds"
+	DeliveryChannelEmail         DeliveryChannel = "email"
+	DeliveryChannelWebhook       DeliveryChannel = "webhook"
 )
 
 type UserSettings struct {
@@ -244,6 +263,13 @@ type UserSettings struct {
 	Timezone             string
 	Version              int
 	MaybeDeliveryChannel *DeliveryChannel
+	ReaderView           bool
+	LegacyFeedUrls       bool
+	MaybeEmailBouncedAt  *schedule.Time
+	EmailBounceMessage   string
+	CatchUpAfterVacation bool
+	// Minutes since local midnight, nil means the default for the delivery channel
+	MaybeDeliveryMinutes *int
 }
 
 func UserSettings_Create(qu pgw.Queryable, userId UserId, timezone string) error {
@@ -256,11 +282,19 @@ func UserSettings_Create(qu pgw.Queryable, userId UserId, timezone string) error
 
 func UserSettings_Get(qu pgw.Queryable, userId UserId) (*UserSettings, error) {
 	row := qu.QueryRow(`
-		select timezone, version, delivery_channel from user_settings where user_id = $1
+		select
+			timezone, version, delivery_channel, reader_view, legacy_feed_urls, email_bounced_at,
+			coalesce(email_bounce_message, ''), catch_up_after_vacation, delivery_minute_of_day
+		from user_settings
+		where user_id = $1
 	`, userId)
 	var us UserSettings
 	us.UserId = userId
-	err := row.Scan(&us.Timezone, &us.Version, &us.MaybeDeliveryChannel)
+	err := row.Scan(
+		&us.Timezone, &us.Version, &us.MaybeDeliveryChannel, &us.ReaderView, &us.LegacyFeedUrls,
+		&us.MaybeEmailBouncedAt, &us.EmailBounceMessage, &us.CatchUpAfterVacation,
+		&us.MaybeDeliveryMinutes,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -294,3 +328,52 @@ func UserSettings_SaveDeliveryChannel(
 	`, deliveryChannel, userId)
 	return err
 }
+
+// Also forgets a previous bounce, as picking email again means the user has sorted out their mailbox
+func UserSettings_SaveDeliveryChannelVersionClearBounce(
+	qu pgw.Queryable, userId UserId, deliveryChannel DeliveryChannel, version int,
+) error {
+	_, err := qu.Exec(`
+		update user_settings
+		set delivery_channel = $1, version = $2, email_bounced_at = null, email_bounce_message = null
+		where user_id = $3
+	`, deliveryChannel, version, userId)
+	return err
+}
+
+func UserSettings_SaveEmailBounce(
+	qu pgw.Queryable, userId UserId, bouncedAt schedule.Time, message string,
+) error {
+	_, err := qu.Exec(`
+		update user_settings set email_bounced_at = $1, email_bounce_message = $2 where user_id = $3
+	`, bouncedAt, message, userId)
+	return err
+}
+
+func UserSettings_SaveDeliveryMinutes(qu pgw.Queryable, userId UserId, maybeDeliveryMinutes *int) error {
+	_, err := qu.Exec(`
+		update user_settings set delivery_minute_of_day = $1 where user_id = $2
+	`, maybeDeliveryMinutes, userId)
+	return err
+}
+
+func UserSettings_SaveReaderView(qu pgw.Queryable, userId UserId, readerView bool) error {
+	_, err := qu.Exec(`
+		update user_settings set reader_view = $1 where user_id = $2
+	`, readerView, userId)
+	return err
+}
+
+func UserSettings_SaveLegacyFeedUrls(qu pgw.Queryable, userId UserId, legacyFeedUrls bool) error {
+	_, err := qu.Exec(`
+		update user_settings set legacy_feed_urls = $1 where user_id = $2
+	`, legacyFeedUrls, userId)
+	return err
+}
+
+func UserSettings_SaveCatchUpAfterVacation(qu pgw.Queryable, userId UserId, catchUpAfterVacation bool) error {
+	_, err := qu.Exec(`
+		update user_settings set catch_up_after_vacation = $1 where user_id = $2
+	`, catchUpAfterVacation, userId)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"slices"
@@ -14,11 +15,18 @@ import (
 	"feedrewind.com/models"
 	"feedrewind.com/oops"
 	"feedrewind.com/routes/rutil"
+	"feedrewind.com/util"
 	"feedrewind.com/util/schedule"
 )
 
 const defaultPostsInRss = 30
 
+// With adaptive pacing, this many delivered posts in a row going unopened steps the rate down
+const AdaptivePacingUnopenedThreshold = 5
+
+// Percents of the configured schedule, the last step pauses delivery
+var adaptivePacingSteps = []int{100, 50, 25, 0}
+
 func InitSubscription(
 	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
 	subscriptionId models.SubscriptionId, subscriptionName string, blogBestUrl string,
@@ -31,17 +39,132 @@ func InitSubscription(
 	)
 }
 
+// catchUpDates are the days skipped for a vacation, their posts go out on top of today's
 func PublishForUser(
 	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
 	deliveryChannel models.DeliveryChannel, utcNow schedule.Time, localTime schedule.Time,
-	localDate schedule.Date, scheduledFor string,
+	localDate schedule.Date, scheduledFor string, catchUpDates []schedule.Date,
 ) error {
 	return publishForUserImpl(
 		tx, userId, productUserId, deliveryChannel, utcNow, localTime, localDate, scheduledFor,
+		catchUpDates, defaultPostsInRss,
+	)
+}
+
+// Publishes up to count next posts of the subscription right away, on top of whatever the schedule has in
+// store. Returns how many posts went out.
+func DeliverNow(
+	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
+	subscriptionId models.SubscriptionId, blogBestUrl string, deliveryChannel models.DeliveryChannel,
+	count int, utcNow schedule.Time, localDate schedule.Date,
+) (int, error) {
+	return deliverNowImpl(
+		tx, userId, productUserId, subscriptionId, blogBestUrl, deliveryChannel, count, utcNow, localDate,
 		defaultPostsInRss,
 	)
 }
 
+func deliverNowImpl(
+	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
+	subscriptionId models.SubscriptionId, blogBestUrl string, deliveryChannel models.DeliveryChannel,
+	count int, utcNow schedule.Time, localDate schedule.Date, postsInRss int,
+) (int, error) {
+	logger := tx.Logger()
+	subscriptions, err := models.Subscription_ListSortedToPublish(tx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	var subscription *models.SubscriptionToPublish
+	for i := range subscriptions {
+		if subscriptions[i].Id == subscriptionId {
+			subscription = &subscriptions[i]
+		}
+	}
+	if subscription == nil {
+		return 0, oops.Newf("Subscription %d is not publishable for user %d", subscriptionId, userId)
+	}
+
+	var publishStatus models.PostPublishStatus
+	switch deliveryChannel {
+	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds,
+		models.DeliveryChannelWebhook:
+		publishStatus = models.PostPublishStatusRssPublished
+	case models.DeliveryChannelEmail:
+		publishStatus = models.PostPublishStatusEmailPending
+	default:
+		panic(fmt.Errorf("Unknown delivery channel: %s", deliveryChannel))
+	}
+
+	unpublishedNewPosts, err := models.SubscriptionPost_GetNextUnpublished(tx, subscriptionId, count)
+	if err != nil {
+		return 0, err
+	}
+	newPosts := make([]models.PublishedSubscriptionBlogPost, len(unpublishedNewPosts))
+	for i, post := range unpublishedNewPosts {
+		newPosts[i] = models.PublishedSubscriptionBlogPost{
+			Id:           post.Id,
+			Title:        post.Title,
+			RandomId:     post.RandomId,
+			Index:        post.Index,
+			MaybeContent: post.MaybeContent,
+			MaybeExcerpt: post.MaybeExcerpt,
+			PublishedAt:  utcNow,
+		}
+	}
+	logger.Info().Msgf("Subscription %d: will deliver %d posts now", subscriptionId, len(newPosts))
+	if len(newPosts) == 0 {
+		return 0, nil
+	}
+
+	unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(tx, subscriptionId)
+	if err != nil {
+		return 0, err
+	}
+	isFinal := subscription.MaybeFinalItemPublishedAt == nil && unpublishedCount == len(newPosts)
+	if isFinal {
+		// So that publishRssFeeds() knows about the update too
+		subscription.MaybeFinalItemPublishedAt = &utcNow
+		logger.Info().Msgf("Will publish the final item for subscription %d", subscriptionId)
+
+		models.ProductEvent_MustEmit(tx, productUserId, "finish subscription", map[string]any{
+			"subscription_id": subscriptionId,
+			"blog_url":        blogBestUrl,
+		}, nil)
+	}
+
+	// Emails go out from EmailDigestJob that picks up everything pending
+	if deliveryChannel != models.DeliveryChannelEmail {
+		newPostsBySubscriptionId := map[models.SubscriptionId][]models.PublishedSubscriptionBlogPost{
+			subscriptionId: newPosts,
+		}
+
+		err := publishRssFeeds(tx, userId, subscriptions, newPostsBySubscriptionId, postsInRss)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for _, post := range newPosts {
+		err := models.SubscriptionPost_UpdateDeliveredNow(tx, post.Id, utcNow, localDate, publishStatus)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if isFinal {
+		_, err := tx.Exec(`
+			update subscriptions_without_discarded
+			set final_item_published_at = $1, final_item_publish_status = $2
+			where id = $3
+		`, utcNow, publishStatus, subscriptionId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(newPosts), nil
+}
+
 func initSubscriptionImpl(
 	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
 	subscriptionId models.SubscriptionId, subscriptionName string, blogBestUrl string,
@@ -63,57 +186,68 @@ func initSubscriptionImpl(
 
 	var publishStatus models.PostPublishStatus
 	switch deliveryChannel {
-	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds:
+	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds,
+		models.DeliveryChannelWebhook:
 		publishStatus = models.PostPublishStatusRssPublished
-		var newPosts []models.PublishedSubscriptionBlogPost
-		if shouldPublishRssPosts {
-			dayOfWeek := localTime.DayOfWeek()
-			dayCount, err := models.Schedule_GetCount(tx, subscriptionId, dayOfWeek)
-			if err != nil {
-				return err
-			}
+	case models.DeliveryChannelEmail:
+		publishStatus = models.PostPublishStatusEmailPending
+	default:
+		panic(fmt.Errorf("Unknown delivery channel: %s", deliveryChannel))
+	}
 
-			unpublishedNewPosts, err :=
-				models.SubscriptionPost_GetNextUnpublished(tx, subscriptionId, dayCount)
-			if err != nil {
-				return err
-			}
-			newPosts = make([]models.PublishedSubscriptionBlogPost, len(unpublishedNewPosts))
-			for i, post := range unpublishedNewPosts {
-				newPosts[i] = models.PublishedSubscriptionBlogPost{
-					Id:          post.Id,
-					Title:       post.Title,
-					RandomId:    post.RandomId,
-					PublishedAt: utcNow,
-				}
+	var newPosts []models.PublishedSubscriptionBlogPost
+	if shouldPublishRssPosts {
+		dayCount, err := scheduledCount(tx, subscriptionId, localDate, nil)
+		if err != nil {
+			return err
+		}
+
+		unpublishedNewPosts, err :=
+			models.SubscriptionPost_GetNextUnpublished(tx, subscriptionId, dayCount)
+		if err != nil {
+			return err
+		}
+		newPosts = make([]models.PublishedSubscriptionBlogPost, len(unpublishedNewPosts))
+		for i, post := range unpublishedNewPosts {
+			newPosts[i] = models.PublishedSubscriptionBlogPost{
+				Id:           post.Id,
+				Title:        post.Title,
+				RandomId:     post.RandomId,
+				Index:        post.Index,
+				MaybeContent: post.MaybeContent,
+				MaybeExcerpt: post.MaybeExcerpt,
+				PublishedAt:  utcNow,
 			}
-			logger.Info().Msgf("Subscription %d: will publish %d new posts", subscriptionId, len(newPosts))
+		}
+		logger.Info().Msgf("Subscription %d: will publish %d new posts", subscriptionId, len(newPosts))
+
+		unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(tx, subscriptionId)
+		if err != nil {
+			return err
+		}
+		if subscription.MaybeFinalItemPublishedAt == nil && unpublishedCount == len(newPosts) {
+			// So that publishRssFeeds() knows about the update too
+			subscription.MaybeFinalItemPublishedAt = &utcNow
 
-			unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(tx, subscriptionId)
+			_, err := tx.Exec(`
+				update subscriptions_without_discarded
+				set final_item_published_at = $1, final_item_publish_status = $2
+				where id = $3
+			`, utcNow, publishStatus, subscriptionId)
 			if err != nil {
 				return err
 			}
-			if subscription.MaybeFinalItemPublishedAt == nil && unpublishedCount == len(newPosts) {
-				// So that publishRssFeeds() knows about the update too
-				subscription.MaybeFinalItemPublishedAt = &utcNow
-
-				_, err := tx.Exec(`
-					update subscriptions_without_discarded
-					set final_item_published_at = $1, final_item_publish_status = $2
-					where id = $3
-				`, utcNow, publishStatus, subscriptionId)
-				if err != nil {
-					return err
-				}
-				logger.Info().Msgf("Will publish the final item for subscription %d", subscriptionId)
+			logger.Info().Msgf("Will publish the final item for subscription %d", subscriptionId)
 
-				models.ProductEvent_MustEmit(tx, productUserId, "finish subscription", map[string]any{
-					"subscription_id": subscriptionId,
-					"blog_url":        blogBestUrl,
-				}, nil)
-			}
+			models.ProductEvent_MustEmit(tx, productUserId, "finish subscription", map[string]any{
+				"subscription_id": subscriptionId,
+				"blog_url":        blogBestUrl,
+			}, nil)
 		}
+	}
 
+	// Emails go out from EmailDigestJob that picks up everything pending
+	if deliveryChannel != models.DeliveryChannelEmail {
 		newPostsBySubscriptionId := map[models.SubscriptionId][]models.PublishedSubscriptionBlogPost{
 			subscriptionId: newPosts,
 		}
@@ -122,15 +256,13 @@ func initSubscriptionImpl(
 		if err != nil {
 			return err
 		}
+	}
 
-		for _, post := range newPosts {
-			err := models.SubscriptionPost_UpdatePublished(tx, post.Id, utcNow, localDate, publishStatus)
-			if err != nil {
-				return err
-			}
+	for _, post := range newPosts {
+		err := models.SubscriptionPost_UpdatePublished(tx, post.Id, utcNow, localDate, publishStatus)
+		if err != nil {
+			return err
 		}
-	default:
-		panic(fmt.Errorf("Unknown delivery channel: %s", deliveryChannel))
 	}
 
 	_, err = tx.Exec(`
@@ -142,7 +274,7 @@ func initSubscriptionImpl(
 func publishForUserImpl(
 	tx *pgw.Tx, userId models.UserId, productUserId models.ProductUserId,
 	deliveryChannel models.DeliveryChannel, utcNow schedule.Time, localTime schedule.Time,
-	localDate schedule.Date, scheduledFor string, postsInRss int,
+	localDate schedule.Date, scheduledFor string, catchUpDates []schedule.Date, postsInRss int,
 ) error {
 	logger := tx.Logger()
 	subscriptions, err := models.Subscription_ListSortedToPublish(tx, userId)
@@ -158,11 +290,16 @@ func publishForUserImpl(
 
 		var newPosts []models.PublishedSubscriptionBlogPost
 		if !subscription.IsPaused {
-			dayOfWeek := localTime.DayOfWeek()
-			dayCount, err := models.Schedule_GetCount(tx, subscription.Id, dayOfWeek)
+			dayCount, err := scheduledCount(tx, subscription.Id, localDate, catchUpDates)
 			if err != nil {
 				return err
 			}
+			if subscription.AdaptivePacing {
+				dayCount, err = applyAdaptivePacing(tx, productUserId, subscription, dayCount, utcNow)
+				if err != nil {
+					return err
+				}
+			}
 
 			unpublishedNewPosts, err :=
 				models.SubscriptionPost_GetNextUnpublished(tx, subscription.Id, dayCount)
@@ -172,10 +309,13 @@ func publishForUserImpl(
 			newPosts = make([]models.PublishedSubscriptionBlogPost, len(unpublishedNewPosts))
 			for i, post := range unpublishedNewPosts {
 				newPosts[i] = models.PublishedSubscriptionBlogPost{
-					Id:          post.Id,
-					Title:       post.Title,
-					RandomId:    post.RandomId,
-					PublishedAt: utcNow,
+					Id:           post.Id,
+					Title:        post.Title,
+					RandomId:     post.RandomId,
+					Index:        post.Index,
+					MaybeContent: post.MaybeContent,
+					MaybeExcerpt: post.MaybeExcerpt,
+					PublishedAt:  utcNow,
 				}
 			}
 			logger.Info().Msgf("Subscription %d: will publish %d new posts", subscription.Id, len(newPosts))
@@ -207,12 +347,16 @@ func publishForUserImpl(
 
 	var publishStatus models.PostPublishStatus
 	switch deliveryChannel {
-	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds:
+	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds,
+		models.DeliveryChannelWebhook:
 		err := publishRssFeeds(tx, userId, subscriptions, newPostsBySubscriptionId, postsInRss)
 		if err != nil {
 			return err
 		}
 		publishStatus = models.PostPublishStatusRssPublished
+	case models.DeliveryChannelEmail:
+		// Sent out by EmailDigestJob after this commits
+		publishStatus = models.PostPublishStatusEmailPending
 	default:
 		panic(fmt.Errorf("Unknown delivery channel for user %d: %s", userId, deliveryChannel))
 	}
@@ -239,16 +383,187 @@ func publishForUserImpl(
 	return nil
 }
 
+// Reading time schedules look at most this far ahead in a day
+const readingTimeMaxPosts = 50
+
+// How many posts the subscription's schedule has for the date, plus the ones for the catch up dates
+func scheduledCount(
+	tx *pgw.Tx, subscriptionId models.SubscriptionId, date schedule.Date, catchUpDates []schedule.Date,
+) (int, error) {
+	logger := tx.Logger()
+	cadence, err := models.Schedule_GetCadence(tx, subscriptionId)
+	if err != nil {
+		return 0, err
+	}
+
+	switch cadence.Type {
+	case models.ScheduleTypeWeekly, models.ScheduleTypeEveryNDays, models.ScheduleTypePerMonth:
+		var countsByDay map[schedule.DayOfWeek]int
+		if cadence.Type == models.ScheduleTypeWeekly {
+			countsByDay, err = models.Schedule_GetCountsByDay(tx, subscriptionId)
+			if err != nil {
+				return 0, err
+			}
+		}
+		count := DateScheduledCount(cadence, countsByDay, date)
+		for _, catchUpDate := range catchUpDates {
+			count += DateScheduledCount(cadence, countsByDay, catchUpDate)
+		}
+		return count, nil
+	case models.ScheduleTypeFinishBy:
+		// Skipped days just make the rest of them denser, no need to catch up
+		unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(tx, subscriptionId)
+		if err != nil {
+			return 0, err
+		}
+		return FinishByCount(unpublishedCount, date, cadence.FinishDate), nil
+	case models.ScheduleTypeReadingTime:
+		posts, err := models.SubscriptionPost_GetNextUnpublished(tx, subscriptionId, readingTimeMaxPosts)
+		if err != nil {
+			return 0, err
+		}
+		if len(posts) == 0 {
+			return 0, nil
+		}
+		postsMinutes := make([]int, len(posts))
+		for i, post := range posts {
+			postsMinutes[i] = util.ReadingMinutes(post.MaybeContent)
+		}
+		credit := cadence.Credit + cadence.WeeklyMinutes*(1+len(catchUpDates))
+		count, newCredit := ReadingTimeCount(credit, postsMinutes)
+		if newCredit != cadence.Credit {
+			err := models.Schedule_SetCredit(tx, subscriptionId, newCredit)
+			if err != nil {
+				return 0, err
+			}
+		}
+		logger.Info().Msgf(
+			"Subscription %d: %d posts fit the reading time, %d credit left", subscriptionId, count, newCredit,
+		)
+		return count, nil
+	default:
+		panic(fmt.Errorf("Unknown schedule type: %s", cadence.Type))
+	}
+}
+
+// Count for the schedule types that only depend on the date. Every n days publishes a post on every nth day
+// from the start date, n per month spreads them evenly over the days of the month.
+func DateScheduledCount(
+	cadence *models.ScheduleCadence, countsByDay map[schedule.DayOfWeek]int, date schedule.Date,
+) int {
+	switch cadence.Type {
+	case models.ScheduleTypeWeekly:
+		return countsByDay[date.Time().DayOfWeek()]
+	case models.ScheduleTypeEveryNDays:
+		daysSinceStart := date.DaysSince(cadence.StartDate)
+		if daysSinceStart >= 0 && daysSinceStart%cadence.EveryDays == 0 {
+			return 1
+		}
+		return 0
+	case models.ScheduleTypePerMonth:
+		dateTime := time.Time(date.Time())
+		day := dateTime.Day()
+		daysInMonth := time.Date(dateTime.Year(), dateTime.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return day*cadence.MonthlyCount/daysInMonth - (day-1)*cadence.MonthlyCount/daysInMonth
+	default:
+		panic(fmt.Errorf("Schedule type doesn't depend on the date only: %s", cadence.Type))
+	}
+}
+
+// Spreads the remaining posts evenly over the days left, so the rate adjusts as the subscription goes. Past
+// the finish date, whatever is left goes out at once.
+func FinishByCount(unpublishedCount int, date schedule.Date, finishDate schedule.Date) int {
+	daysLeft := max(1, finishDate.DaysSince(date)+1)
+	return (unpublishedCount + daysLeft - 1) / daysLeft
+}
+
+// Takes the posts in order while the credit covers their reading time. Returns the count and the credit left.
+func ReadingTimeCount(credit int, postsMinutes []int) (int, int) {
+	count := 0
+	for _, minutes := range postsMinutes {
+		if minutes*7 > credit {
+			break
+		}
+		credit -= minutes * 7
+		count++
+	}
+	return count, credit
+}
+
+// Steps the rate down when the recent posts went unopened, then converts the scheduled count to the current
+// rate. The fractions carry over between days so that e.g. half of one post a day comes out as every other
+// day. Opening a post goes back to the full rate right away, so speeding up doesn't happen here.
+func applyAdaptivePacing(
+	tx *pgw.Tx, productUserId models.ProductUserId, subscription *models.SubscriptionToPublish, dayCount int,
+	utcNow schedule.Time,
+) (int, error) {
+	logger := tx.Logger()
+	if subscription.PacingPercent > 0 {
+		publishedCount, unopenedCount, err := models.SubscriptionPost_CountRecentUnopened(
+			tx, subscription.Id, subscription.MaybePacingChangedAt, AdaptivePacingUnopenedThreshold,
+		)
+		if err != nil {
+			return 0, err
+		}
+		if publishedCount == AdaptivePacingUnopenedThreshold && unopenedCount == publishedCount {
+			newPercent := adaptivePacingStepDown(subscription.PacingPercent)
+			err := models.Subscription_SetPacingPercent(tx, subscription.Id, newPercent, utcNow)
+			if err != nil {
+				return 0, err
+			}
+			logger.Info().Msgf(
+				"Subscription %d: %d posts unopened, slowing down from %d%% to %d%%", subscription.Id,
+				unopenedCount, subscription.PacingPercent, newPercent,
+			)
+			models.ProductEvent_MustEmit(tx, productUserId, "slow down subscription", map[string]any{
+				"subscription_id": subscription.Id,
+				"pacing_percent":  newPercent,
+			}, nil)
+			// So that publishRssFeeds() knows about the update too
+			subscription.PacingPercent = newPercent
+			subscription.MaybePacingChangedAt = &utcNow
+		}
+	}
+
+	count, credit := adaptivePacingCount(subscription.PacingCredit, dayCount, subscription.PacingPercent)
+	if credit != subscription.PacingCredit {
+		err := models.Subscription_SetPacingCredit(tx, subscription.Id, credit)
+		if err != nil {
+			return 0, err
+		}
+		subscription.PacingCredit = credit
+	}
+	if count != dayCount {
+		logger.Info().Msgf(
+			"Subscription %d: paced at %d%%, %d posts instead of %d", subscription.Id,
+			subscription.PacingPercent, count, dayCount,
+		)
+	}
+	return count, nil
+}
+
+func adaptivePacingStepDown(percent int) int {
+	for _, step := range adaptivePacingSteps {
+		if step < percent {
+			return step
+		}
+	}
+	return 0
+}
+
+// Credit is in percents of a post and stays under 100
+func adaptivePacingCount(credit int, dayCount int, percent int) (int, int) {
+	credit += dayCount * percent
+	count := credit / 100
+	return count, credit - count*100
+}
+
 func publishRssFeeds(
 	tx *pgw.Tx, userId models.UserId, subscriptions []models.SubscriptionToPublish,
 	newPostsBySubscriptionId map[models.SubscriptionId][]models.PublishedSubscriptionBlogPost, postsInRss int,
 ) error {
 	logger := tx.Logger()
-	type UserDateItem struct {
-		PublishedAt schedule.Time
-		Item        item
-	}
-	var userDatesItems []UserDateItem
+	var userItems []feedItem
 	for _, subscription := range subscriptions {
 		logger.Info().Msgf("Generating RSS for subscription %d", subscription.Id)
 		newPosts := newPostsBySubscriptionId[subscription.Id]
@@ -256,6 +571,11 @@ func publishRssFeeds(
 		if subscription.MaybeFinalItemPublishedAt != nil {
 			remainingPostsCount--
 		}
+		isPacingPaused := subscription.AdaptivePacing && subscription.PacingPercent == 0 &&
+			subscription.MaybePacingChangedAt != nil && subscription.MaybeFinalItemPublishedAt == nil
+		if isPacingPaused {
+			remainingPostsCount--
+		}
 		remainingPosts, err := models.SubscriptionPost_GetLastPublishedDesc(
 			tx, subscription.Id, remainingPostsCount,
 		)
@@ -264,27 +584,54 @@ func publishRssFeeds(
 		}
 		slices.Reverse(remainingPosts)
 
+		// The new posts aren't marked as published yet
+		unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(tx, subscription.Id)
+		if err != nil {
+			return err
+		}
+		remainingCount := unpublishedCount - len(newPosts)
+
 		subscriptionUrl := rutil.SubscriptionUrl(subscription.Id)
-		var subscriptionItems []item
+		var subscriptionItems []feedItem
 		if subscription.MaybeFinalItemPublishedAt != nil {
 			logger.Info().Msgf("Generating final item for subscription %d", subscription.Id)
-			finalItem := item{
+			finalItem := feedItem{
 				Title: fmt.Sprintf("You're all caught up with %s", subscription.Name),
 				Link:  subscriptionUrl,
-				Guid: guid{
-					Guid:        makeGuid(fmt.Sprintf("%d-final", subscription.Id)),
-					IsPermalink: false,
-				},
+				Guid:  makeGuid(fmt.Sprintf("%d-final", subscription.Id)),
 				Description: fmt.Sprintf(
 					`<a href="%s">Want to read something else?</a>`, rutil.SubscriptionAddUrl(),
 				),
-				PubDate: subscription.MaybeFinalItemPublishedAt.Format(time.RFC1123Z),
+				PublishedAt:    *subscription.MaybeFinalItemPublishedAt,
+				SubscriptionId: subscription.Id,
+				MaybePostIndex: nil,
+				RemainingCount: remainingCount,
+				MaybeContent:   nil,
 			}
 			subscriptionItems = append(subscriptionItems, finalItem)
-			userDatesItems = append(userDatesItems, UserDateItem{
-				PublishedAt: *subscription.MaybeFinalItemPublishedAt,
-				Item:        finalItem,
-			})
+			userItems = append(userItems, finalItem)
+		}
+
+		if isPacingPaused {
+			logger.Info().Msgf("Generating pacing paused item for subscription %d", subscription.Id)
+			pausedAt := *subscription.MaybePacingChangedAt
+			pausedItem := feedItem{
+				Title: fmt.Sprintf("%s is paused until you're back", subscription.Name),
+				Link:  subscriptionUrl,
+				Guid:  makeGuid(fmt.Sprintf("%d-paused-%d", subscription.Id, pausedAt.Unix())),
+				Description: fmt.Sprintf(
+					`The last few posts weren't opened, so new ones are on hold. `+
+						`Open any post or <a href="%s">resume</a> to pick up where you left off.`,
+					subscriptionUrl,
+				),
+				PublishedAt:    pausedAt,
+				SubscriptionId: subscription.Id,
+				MaybePostIndex: nil,
+				RemainingCount: remainingCount,
+				MaybeContent:   nil,
+			}
+			subscriptionItems = append(subscriptionItems, pausedItem)
+			userItems = append(userItems, pausedItem)
 		}
 
 		subscriptionPosts := slices.Concat(remainingPosts, newPosts)
@@ -293,67 +640,75 @@ func publishRssFeeds(
 		for _, post := range subscriptionPosts {
 			link := rutil.SubscriptionPostUrl(post.Title, post.RandomId)
 			guidValue := makeGuid(fmt.Sprint(post.Id))
-			pubDate := post.PublishedAt.Format(time.RFC1123Z)
-
-			subscriptionItem := item{
-				Title: post.Title,
-				Link:  link,
-				Guid: guid{
-					Guid:        guidValue,
-					IsPermalink: false,
-				},
-				Description: fmt.Sprintf(`<a href="%s">Manage</a>`, subscriptionUrl),
-				PubDate:     pubDate,
+			postIndex := post.Index
+			var maybeContent *string
+			if subscription.IncludePostContent {
+				if post.MaybeContent != nil {
+					maybeContent = post.MaybeContent
+				} else if post.MaybeExcerpt != nil {
+					excerpt := fmt.Sprintf(`%s<p><a href="%s">Continue reading</a></p>`, *post.MaybeExcerpt, link)
+					maybeContent = &excerpt
+				}
 			}
-			subscriptionItems = append(subscriptionItems, subscriptionItem)
+
+			subscriptionItems = append(subscriptionItems, feedItem{
+				Title:          post.Title,
+				Link:           link,
+				Guid:           guidValue,
+				Description:    fmt.Sprintf(`<a href="%s">Manage</a>`, subscriptionUrl),
+				PublishedAt:    post.PublishedAt,
+				SubscriptionId: subscription.Id,
+				MaybePostIndex: &postIndex,
+				RemainingCount: remainingCount,
+				MaybeContent:   maybeContent,
+			})
 
 			userItemDescription := fmt.Sprintf(
 				`from %s<br><br><a href="%s">Manage</a>`, subscription.Name, subscriptionUrl,
 			)
-			userItem := item{
-				Title: post.Title,
-				Link:  link,
-				Guid: guid{
-					Guid:        guidValue,
-					IsPermalink: false,
-				},
-				Description: userItemDescription,
-				PubDate:     pubDate,
-			}
-			userDatesItems = append(userDatesItems, UserDateItem{
-				PublishedAt: post.PublishedAt,
-				Item:        userItem,
+			userItems = append(userItems, feedItem{
+				Title:          post.Title,
+				Link:           link,
+				Guid:           guidValue,
+				Description:    userItemDescription,
+				PublishedAt:    post.PublishedAt,
+				SubscriptionId: subscription.Id,
+				MaybePostIndex: &postIndex,
+				RemainingCount: remainingCount,
+				MaybeContent:   maybeContent,
 			})
 		}
 
 		if len(subscriptionItems) < postsInRss {
 			logger.Info().Msgf("Generating initial item for subscription %d", subscription.Id)
-			initialItem := item{
-				Title: fmt.Sprintf("%s added to FeedRewind", subscription.Name),
-				Link:  subscriptionUrl,
-				Guid: guid{
-					Guid:        makeGuid(fmt.Sprintf("%d-welcome", subscription.Id)),
-					IsPermalink: false,
-				},
-				Description: fmt.Sprintf(`<a href="%s">Manage</a>`, subscriptionUrl),
-				PubDate:     subscription.FinishedSetupAt.Format(time.RFC1123Z),
+			initialItem := feedItem{
+				Title:          fmt.Sprintf("%s added to FeedRewind", subscription.Name),
+				Link:           subscriptionUrl,
+				Guid:           makeGuid(fmt.Sprintf("%d-welcome", subscription.Id)),
+				Description:    fmt.Sprintf(`<a href="%s">Manage</a>`, subscriptionUrl),
+				PublishedAt:    subscription.FinishedSetupAt,
+				SubscriptionId: subscription.Id,
+				MaybePostIndex: nil,
+				RemainingCount: remainingCount,
+				MaybeContent:   nil,
 			}
 			subscriptionItems = append(subscriptionItems, initialItem)
-			userDatesItems = append(userDatesItems, UserDateItem{
-				PublishedAt: subscription.FinishedSetupAt,
-				Item:        initialItem,
-			})
+			userItems = append(userItems, initialItem)
 		}
 
-		subscriptionRssText, err := generateSubscriptionRss(
-			subscription.Name, subscriptionUrl, subscriptionItems,
+		subscriptionTexts, err := generateFeedTexts(
+			newSubscriptionFeed(
+				subscription.Id, subscription.FeedToken, subscription.Name, remainingCount, subscriptionItems,
+			),
 		)
 		if err != nil {
 			return err
 		}
 		logger.Info().Msgf("Total items for subscription %d: %d", subscription.Id, len(subscriptionItems))
 
-		err = models.SubscriptionRss_Upsert(tx, subscription.Id, subscriptionRssText)
+		err = models.SubscriptionRss_Upsert(
+			tx, subscription.Id, subscriptionTexts.Rss, subscriptionTexts.Atom, subscriptionTexts.Json,
+		)
 		if err != nil {
 			return err
 		}
@@ -361,15 +716,11 @@ func publishRssFeeds(
 	}
 
 	// Date desc, index asc (= publish date desc, sub date desc, post index desc)
-	slices.SortStableFunc(userDatesItems, func(a, b UserDateItem) int {
+	slices.SortStableFunc(userItems, func(a, b feedItem) int {
 		return b.PublishedAt.Compare(a.PublishedAt)
 	})
-	userItems := make([]item, 0, postsInRss)
-	for i, userDateItem := range userDatesItems {
-		if i >= postsInRss {
-			break
-		}
-		userItems = append(userItems, userDateItem.Item)
+	if len(userItems) > postsInRss {
+		userItems = userItems[:postsInRss]
 	}
 
 	newUserItemsCount := 0
@@ -377,12 +728,16 @@ func publishRssFeeds(
 		newUserItemsCount += len(newPosts)
 	}
 	logger.Info().Msgf("Total items for user %d: %d (%d new)", userId, len(userItems), newUserItemsCount)
-	userRssText, err := generateUserRss(userItems)
+	userFeedToken, err := models.FeedToken_GetForUser(tx, userId)
+	if err != nil {
+		return err
+	}
+	userTexts, err := generateFeedTexts(newUserFeed(userId, userFeedToken, userItems))
 	if err != nil {
 		return err
 	}
 
-	err = models.UserRss_Upsert(tx, userId, userRssText)
+	err = models.UserRss_Upsert(tx, userId, userTexts.Rss, userTexts.Atom, userTexts.Json)
 	if err != nil {
 		return err
 	}
@@ -391,6 +746,17 @@ func publishRssFeeds(
 	return nil
 }
 
+// Regenerates the feeds of all subscriptions without publishing anything new, e.g. to fill in a format that
+// didn't exist when the feeds were last published
+func RegenerateFeeds(tx *pgw.Tx, userId models.UserId) error {
+	subscriptions, err := models.Subscription_ListSortedToPublish(tx, userId)
+	if err != nil {
+		return err
+	}
+	newPostsBySubscriptionId := make(map[models.SubscriptionId][]models.PublishedSubscriptionBlogPost)
+	return publishRssFeeds(tx, userId, subscriptions, newPostsBySubscriptionId, defaultPostsInRss)
+}
+
 func makeGuid(value string) string {
 	hashBytes := sha256.Sum256([]byte(value))
 	return hex.EncodeToString(hashBytes[:])
@@ -398,11 +764,15 @@ func makeGuid(value string) string {
 
 func CreateEmptyUserFeed(tx *pgw.Tx, userId models.UserId) error {
 	logger := tx.Logger()
-	userRssText, err := generateUserRss(nil)
+	userFeedToken, err := models.FeedToken_GetForUser(tx, userId)
+	if err != nil {
+		return err
+	}
+	userTexts, err := generateFeedTexts(newUserFeed(userId, userFeedToken, nil))
 	if err != nil {
 		return err
 	}
-	err = models.UserRss_Upsert(tx, userId, userRssText)
+	err = models.UserRss_Upsert(tx, userId, userTexts.Rss, userTexts.Atom, userTexts.Json)
 	if err != nil {
 		return err
 	}
@@ -410,24 +780,175 @@ func CreateEmptyUserFeed(tx *pgw.Tx, userId models.UserId) error {
 	return nil
 }
 
+// All formats are generated from the same feed so that they always have the same items. The id stays the
+// same when the token is rotated.
+type feed struct {
+	Id                  string
+	Title               string
+	Url                 string
+	RssUrl              string
+	AtomUrl             string
+	JsonUrl             string
+	HubUrl              string
+	MaybeSubscriptionId *models.SubscriptionId // nil for the user feed
+	RemainingCount      int
+	Items               []feedItem // newest first
+}
+
+type feedItem struct {
+	Title          string
+	Link           string
+	Guid           string
+	Description    string
+	PublishedAt    schedule.Time
+	SubscriptionId models.SubscriptionId
+	MaybePostIndex *int // nil for the initial, final and paused items
+	RemainingCount int
+	MaybeContent   *string // the original post or its excerpt, if the subscription includes it
+}
+
+func newSubscriptionFeed(
+	subscriptionId models.SubscriptionId, feedToken models.FeedToken, subscriptionName string,
+	remainingCount int, items []feedItem,
+) feed {
+	return feed{
+		Id:                  makeSubscriptionFeedId(subscriptionId),
+		Title:               fmt.Sprintf("%s · FeedRewind", subscriptionName),
+		Url:                 rutil.SubscriptionUrl(subscriptionId),
+		RssUrl:              rutil.SubscriptionRssFeedUrl(feedToken),
+		AtomUrl:             rutil.SubscriptionAtomFeedUrl(feedToken),
+		JsonUrl:             rutil.SubscriptionJsonFeedUrl(feedToken),
+		HubUrl:              rutil.WebSubHubUrl(),
+		MaybeSubscriptionId: &subscriptionId,
+		RemainingCount:      remainingCount,
+		Items:               items,
+	}
+}
+
+func newUserFeed(userId models.UserId, feedToken models.FeedToken, items []feedItem) feed {
+	return feed{
+		Id:                  makeUserFeedId(userId),
+		Title:               "FeedRewind",
+		Url:                 config.Cfg.RootUrl,
+		RssUrl:              rutil.UserRssFeedUrl(feedToken),
+		AtomUrl:             rutil.UserAtomFeedUrl(feedToken),
+		JsonUrl:             rutil.UserJsonFeedUrl(feedToken),
+		HubUrl:              rutil.WebSubHubUrl(),
+		MaybeSubscriptionId: nil,
+		RemainingCount:      0,
+		Items:               items,
+	}
+}
+
+func makeSubscriptionFeedId(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("urn:feedrewind:subscription:%d", subscriptionId)
+}
+
+func makeUserFeedId(userId models.UserId) string {
+	return fmt.Sprintf("urn:feedrewind:user:%d", userId)
+}
+
+// The old url of a rotated token only tells the reader to resubscribe, as whoever the token was rotated
+// away from shouldn't learn the new one. The feed id is the same so that readers see it as the same feed.
+func GenerateRotatedFeed(
+	feedKey string, resolvedFeed *models.ResolvedFeed, format models.FeedFormat,
+) (string, error) {
+	rotatedAt := *resolvedFeed.MaybeRotatedAt
+	feedToken := models.FeedToken(feedKey)
+	var f feed
+	var manageUrl string
+	var subscriptionId models.SubscriptionId
+	if resolvedFeed.MaybeSubscriptionId != nil {
+		subscriptionId = *resolvedFeed.MaybeSubscriptionId
+		manageUrl = rutil.SubscriptionUrl(subscriptionId)
+		f = newSubscriptionFeed(subscriptionId, feedToken, "Feed moved", 0, nil)
+		f.MaybeSubscriptionId = nil
+	} else {
+		manageUrl = rutil.SettingsUrl()
+		f = newUserFeed(*resolvedFeed.MaybeUserId, feedToken, nil)
+	}
+	f.Url = manageUrl
+	f.Items = []feedItem{{
+		Title: "This feed has moved",
+		Link:  manageUrl,
+		Guid:  makeGuid(fmt.Sprintf("%s-rotated-%d", f.Id, rotatedAt.Unix())),
+		Description: fmt.Sprintf(
+			`The feed url was changed and this one stops working on %s. `+
+				`<a href="%s">Sign in</a> to get the new url and resubscribe to it in your reader.`,
+			rotatedAt.Add(models.FeedTokenGracePeriod).UTC().Format("January 2"), manageUrl,
+		),
+		PublishedAt:    rotatedAt,
+		SubscriptionId: subscriptionId,
+		MaybePostIndex: nil,
+		RemainingCount: 0,
+		MaybeContent:   nil,
+	}}
+
+	switch format {
+	case models.FeedFormatRss:
+		return generateRss(f)
+	case models.FeedFormatAtom:
+		return generateAtom(f, rotatedAt)
+	case models.FeedFormatJson:
+		return generateJsonFeed(f)
+	default:
+		panic(fmt.Errorf("Unknown feed format: %s", format))
+	}
+}
+
+type feedTexts struct {
+	Rss  string
+	Atom string
+	Json string
+}
+
+func generateFeedTexts(f feed) (*feedTexts, error) {
+	rssText, err := generateRss(f)
+	if err != nil {
+		return nil, err
+	}
+	atomText, err := generateAtom(f, schedule.UTCNow())
+	if err != nil {
+		return nil, err
+	}
+	jsonText, err := generateJsonFeed(f)
+	if err != nil {
+		return nil, err
+	}
+	return &feedTexts{
+		Rss:  rssText,
+		Atom: atomText,
+		Json: jsonText,
+	}, nil
+}
+
 type rss struct {
 	Version          string  `xml:"version,attr"`
 	ContentNamespace string  `xml:"xmlns:content,attr"`
+	AtomNamespace    string  `xml:"xmlns:atom,attr"`
 	Channel          channel `xml:"channel"`
 }
 
+// WebSub subscribers discover the hub and the canonical feed url through the atom links
 type channel struct {
-	Title string `xml:"title"`
-	Link  string `xml:"link"`
-	Items []item `xml:"item"`
+	Title     string        `xml:"title"`
+	Link      string        `xml:"link"`
+	AtomLinks []rssAtomLink `xml:"atom:link"`
+	Items     []item        `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
 }
 
 type item struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Guid        guid   `xml:"guid"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title               string  `xml:"title"`
+	Link                string  `xml:"link"`
+	Guid                guid    `xml:"guid"`
+	Description         string  `xml:"description"`
+	MaybeContentEncoded *string `xml:"content:encoded,omitempty"`
+	PubDate             string  `xml:"pubDate"`
 }
 
 type guid struct {
@@ -435,16 +956,22 @@ type guid struct {
 	IsPermalink bool   `xml:"isPermaLink,attr"`
 }
 
-func generateSubscriptionRss(subscriptionName string, subscriptionUrl string, items []item) (string, error) {
-	title := fmt.Sprintf("%s · FeedRewind", subscriptionName)
-	return generateRss(title, subscriptionUrl, items)
-}
-
-func generateUserRss(items []item) (string, error) {
-	return generateRss("FeedRewind", config.Cfg.RootUrl, items)
-}
+func generateRss(f feed) (string, error) {
+	items := make([]item, len(f.Items))
+	for i, fItem := range f.Items {
+		items[i] = item{
+			Title: fItem.Title,
+			Link:  fItem.Link,
+			Guid: guid{
+				Guid:        fItem.Guid,
+				IsPermalink: false,
+			},
+			Description:         fItem.Description,
+			MaybeContentEncoded: fItem.MaybeContent,
+			PubDate:             fItem.PublishedAt.Format(time.RFC1123Z),
+		}
+	}
 
-func generateRss(title string, url string, items []item) (string, error) {
 	var buf bytes.Buffer
 	_, _ = fmt.Fprint(&buf, xml.Header)
 	encoder := xml.NewEncoder(&buf)
@@ -452,9 +979,14 @@ func generateRss(title string, url string, items []item) (string, error) {
 	err := encoder.Encode(rss{
 		Version:          "2.0",
 		ContentNamespace: "http://purl.org/rss/1.0/modules/content/",
+		AtomNamespace:    "http://www.w3.org/2005/Atom",
 		Channel: channel{
-			Title: title,
-			Link:  url,
+			Title: f.Title,
+			Link:  f.Url,
+			AtomLinks: []rssAtomLink{
+				{Rel: "self", Href: f.RssUrl},
+				{Rel: "hub", Href: f.HubUrl},
+			},
 			Items: items,
 		},
 	})
@@ -464,3 +996,208 @@ func generateRss(title string, url string, items []item) (string, error) {
 
 	return buf.String(), nil
 }
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Id      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Id           string       `xml:"id"`
+	Title        string       `xml:"title"`
+	Link         atomLink     `xml:"link"`
+	Published    string       `xml:"published"`
+	Updated      string       `xml:"updated"`
+	MaybeSummary *atomContent `xml:"summary,omitempty"`
+	Content      atomContent  `xml:"content"`
+}
+
+type atomContent struct {
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// Entry ids are the same hashes as RSS guids, so a reader that switches formats could match them. The feed
+// is updated when its newest item is, and an empty feed takes the generation time. When the post content is
+// included, the description becomes the summary.
+func generateAtom(f feed, utcNow schedule.Time) (string, error) {
+	updatedAt := utcNow
+	for i, fItem := range f.Items {
+		if i == 0 || fItem.PublishedAt.After(updatedAt) {
+			updatedAt = fItem.PublishedAt
+		}
+	}
+
+	entries := make([]atomEntry, len(f.Items))
+	for i, fItem := range f.Items {
+		timestamp := fItem.PublishedAt.UTC().Format(time.RFC3339)
+		var maybeSummary *atomContent
+		content := atomContent{
+			Type:    "html",
+			Content: fItem.Description,
+		}
+		if fItem.MaybeContent != nil {
+			maybeSummary = &atomContent{
+				Type:    "html",
+				Content: fItem.Description,
+			}
+			content.Content = *fItem.MaybeContent
+		}
+		entries[i] = atomEntry{
+			Id:    makeAtomId(fItem.Guid),
+			Title: fItem.Title,
+			Link: atomLink{
+				Rel:  "alternate",
+				Type: "",
+				Href: fItem.Link,
+			},
+			Published:    timestamp,
+			Updated:      timestamp,
+			MaybeSummary: maybeSummary,
+			Content:      content,
+		}
+	}
+
+	var buf bytes.Buffer
+	_, _ = fmt.Fprint(&buf, xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(atomFeed{
+		XMLName: xml.Name{Space: "", Local: "feed"},
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Id:      f.Id,
+		Title:   f.Title,
+		Updated: updatedAt.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.AtomUrl},
+			{Rel: "alternate", Type: "text/html", Href: f.Url},
+			{Rel: "hub", Type: "", Href: f.HubUrl},
+		},
+		Author: atomAuthor{
+			Name: "FeedRewind",
+		},
+		Entries: entries,
+	})
+	if err != nil {
+		return "", oops.Wrap(err)
+	}
+
+	return buf.String(), nil
+}
+
+func makeAtomId(guid string) string {
+	return fmt.Sprintf("urn:feedrewind:%s", guid)
+}
+
+type jsonFeed struct {
+	Version     string             `json:"version"`
+	Title       string             `json:"title"`
+	HomePageUrl string             `json:"home_page_url"`
+	FeedUrl     string             `json:"feed_url"`
+	Authors     []jsonFeedAuthor   `json:"authors"`
+	Hubs        []jsonFeedHub      `json:"hubs"`
+	Items       []jsonFeedItem     `json:"items"`
+	Extension   *jsonFeedExtension `json:"_feedrewind,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedHub struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+}
+
+type jsonFeedItem struct {
+	Id            string                `json:"id"`
+	Url           string                `json:"url"`
+	Title         string                `json:"title"`
+	ContentHtml   string                `json:"content_html"`
+	DatePublished string                `json:"date_published"`
+	Extension     jsonFeedItemExtension `json:"_feedrewind"`
+}
+
+// Extensions start with an underscore per JSON Feed 1.1
+type jsonFeedExtension struct {
+	SubscriptionId models.SubscriptionId `json:"subscription_id"`
+	RemainingCount int                   `json:"remaining_count"`
+}
+
+type jsonFeedItemExtension struct {
+	SubscriptionId models.SubscriptionId `json:"subscription_id"`
+	PostIndex      *int                  `json:"post_index"`
+	RemainingCount int                   `json:"remaining_count"`
+}
+
+// Item ids are the same as RSS guids. The post content replaces the description when it's included.
+func generateJsonFeed(f feed) (string, error) {
+	items := make([]jsonFeedItem, len(f.Items))
+	for i, fItem := range f.Items {
+		contentHtml := fItem.Description
+		if fItem.MaybeContent != nil {
+			contentHtml = *fItem.MaybeContent
+		}
+		items[i] = jsonFeedItem{
+			Id:            fItem.Guid,
+			Url:           fItem.Link,
+			Title:         fItem.Title,
+			ContentHtml:   contentHtml,
+			DatePublished: fItem.PublishedAt.UTC().Format(time.RFC3339),
+			Extension: jsonFeedItemExtension{
+				SubscriptionId: fItem.SubscriptionId,
+				PostIndex:      fItem.MaybePostIndex,
+				RemainingCount: fItem.RemainingCount,
+			},
+		}
+	}
+
+	var extension *jsonFeedExtension
+	if f.MaybeSubscriptionId != nil {
+		extension = &jsonFeedExtension{
+			SubscriptionId: *f.MaybeSubscriptionId,
+			RemainingCount: f.RemainingCount,
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageUrl: f.Url,
+		FeedUrl:     f.JsonUrl,
+		Authors: []jsonFeedAuthor{{
+			Name: "FeedRewind",
+		}},
+		Hubs: []jsonFeedHub{{
+			Type: "WebSub",
+			Url:  f.HubUrl,
+		}},
+		Items:     items,
+		Extension: extension,
+	})
+	if err != nil {
+		return "", oops.Wrap(err)
+	}
+
+	return buf.String(), nil
+}
@@ -40,10 +40,12 @@ func TestInitSubscription(t *testing.T) {
 			MaybeExistingSubscription: nil,
 			ShouldPublishRssPosts:     true,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -54,10 +56,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -76,10 +80,12 @@ func TestInitSubscription(t *testing.T) {
 			MaybeExistingSubscription: nil,
 			ShouldPublishRssPosts:     false,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -90,10 +96,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -112,10 +120,12 @@ func TestInitSubscription(t *testing.T) {
 			MaybeExistingSubscription: nil,
 			ShouldPublishRssPosts:     true,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -140,10 +150,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -176,10 +188,12 @@ func TestInitSubscription(t *testing.T) {
 			MaybeExistingSubscription: nil,
 			ShouldPublishRssPosts:     true,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -204,10 +218,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -242,10 +258,12 @@ func TestInitSubscription(t *testing.T) {
 			},
 			ShouldPublishRssPosts: true,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -256,10 +274,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -294,10 +314,12 @@ func TestInitSubscription(t *testing.T) {
 			},
 			ShouldPublishRssPosts: false,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -308,10 +330,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -346,10 +370,12 @@ func TestInitSubscription(t *testing.T) {
 			},
 			ShouldPublishRssPosts: true,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -374,10 +400,12 @@ func TestInitSubscription(t *testing.T) {
   </channel>
 </rss>`,
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -488,10 +516,12 @@ func TestPublishForUser(t *testing.T) {
 			Subscription2: subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"thu": 2, "fri": 2},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 2 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/2</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token2"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 4</title>
       <link>http://localhost:3000/posts/post-4/2_4/</link>
@@ -531,10 +561,12 @@ func TestPublishForUser(t *testing.T) {
 </rss>`,
 			},
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 4</title>
       <link>http://localhost:3000/posts/post-4/2_4/</link>
@@ -578,10 +610,12 @@ func TestPublishForUser(t *testing.T) {
 			MaybeSubscription1: &subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"wed": 2, "fri": 2},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 4</title>
       <link>http://localhost:3000/posts/post-4/1_4/</link>
@@ -623,10 +657,12 @@ func TestPublishForUser(t *testing.T) {
 			Subscription2: subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"thu": 1, "fri": 1},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 2 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/2</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token2"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/2_2/</link>
@@ -652,10 +688,12 @@ func TestPublishForUser(t *testing.T) {
 </rss>`,
 			},
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/2_2/</link>
@@ -720,10 +758,12 @@ func TestPublishForUser(t *testing.T) {
 			MaybeSubscription1: &subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"wed": 1, "fri": 1},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -751,10 +791,12 @@ func TestPublishForUser(t *testing.T) {
 			Subscription2: subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"thu": 1},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 2 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/2</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token2"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 1</title>
       <link>http://localhost:3000/posts/post-1/2_1/</link>
@@ -773,10 +815,12 @@ func TestPublishForUser(t *testing.T) {
 </rss>`,
 			},
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 2</title>
       <link>http://localhost:3000/posts/post-2/1_2/</link>
@@ -820,10 +864,12 @@ func TestPublishForUser(t *testing.T) {
 			MaybeSubscription1: &subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"wed": 1},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 1</title>
       <link>http://localhost:3000/posts/post-1/1_1/</link>
@@ -844,10 +890,12 @@ func TestPublishForUser(t *testing.T) {
 			Subscription2: subscriptionDesc{
 				CountByDay: map[schedule.DayOfWeek]int{"thu": 1},
 				ExpectedRssBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 2 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/2</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token2"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 1</title>
       <link>http://localhost:3000/posts/post-1/2_1/</link>
@@ -866,10 +914,12 @@ func TestPublishForUser(t *testing.T) {
 </rss>`,
 			},
 			ExpectedUserBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 1</title>
       <link>http://localhost:3000/posts/post-1/2_1/</link>
@@ -962,7 +1012,7 @@ func TestPublishForUser(t *testing.T) {
 		err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
 			return PublishForUser(
 				tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
-				utcNowScheduledFor,
+				utcNowScheduledFor, nil,
 			)
 		})
 		oops.RequireNoError(t, err, tc.Description)
@@ -1000,10 +1050,12 @@ func TestRssCountLimit(t *testing.T) {
 			TotalPosts:     6,
 			PublishedPosts: 4,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 5</title>
       <link>http://localhost:3000/posts/post-5/1_5/</link>
@@ -1047,10 +1099,12 @@ func TestRssCountLimit(t *testing.T) {
 			TotalPosts:     3,
 			PublishedPosts: 2,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>You&#39;re all caught up with Test Subscription 1</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -1094,10 +1148,12 @@ func TestRssCountLimit(t *testing.T) {
 			TotalPosts:     4,
 			PublishedPosts: 3,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>You&#39;re all caught up with Test Subscription 1</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -1141,10 +1197,12 @@ func TestRssCountLimit(t *testing.T) {
 			TotalPosts:     5,
 			PublishedPosts: 4,
 			ExpectedSubBody: `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>You&#39;re all caught up with Test Subscription 1</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -1224,7 +1282,7 @@ func TestRssCountLimit(t *testing.T) {
 		err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
 			return publishForUserImpl(
 				tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
-				utcNowScheduledFor, postsInRss,
+				utcNowScheduledFor, nil, postsInRss,
 			)
 		})
 		oops.RequireNoError(t, err, tc.Description)
@@ -1281,7 +1339,7 @@ func TestIsPausedHandling(t *testing.T) {
 	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
 		return PublishForUser(
 			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
-			utcNowScheduledFor,
+			utcNowScheduledFor, nil,
 		)
 	})
 	oops.RequireNoError(t, err)
@@ -1290,10 +1348,12 @@ func TestIsPausedHandling(t *testing.T) {
 	oops.RequireNoError(t, err)
 
 	expectedSubBody := `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>Test Subscription 1 · FeedRewind</title>
     <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Test Subscription 1 added to FeedRewind</title>
       <link>http://localhost:3000/subscriptions/1</link>
@@ -1309,6 +1369,297 @@ func TestIsPausedHandling(t *testing.T) {
 	oops.RequireNoError(t, err)
 }
 
+func TestDeliverNow(t *testing.T) {
+	pool := db.RootPool
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu := "2022-05-05 00:00:00+00:00"
+	fri := "2022-05-06 00:00:00+00:00"
+
+	user, err := createUser(pool)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAt, err := schedule.ParseTime(timeFormat, thu)
+	oops.RequireNoError(t, err)
+
+	subscription, err := createSubscription(
+		pool, user.Id, 1, finishedSetupAt, 5, 0, map[schedule.DayOfWeek]int{"fri": 1},
+	)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAtDate := finishedSetupAt.Date()
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return InitSubscription(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.Name,
+			subscription.BlogBestUrl, user.DeliveryChannel, false, finishedSetupAt, finishedSetupAt,
+			finishedSetupAtDate,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	var deliveredCount int
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		var err error
+		deliveredCount, err = DeliverNow(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.BlogBestUrl, user.DeliveryChannel,
+			2, finishedSetupAt, finishedSetupAtDate,
+		)
+		return err
+	})
+	oops.RequireNoError(t, err)
+	require.Equal(t, 2, deliveredCount)
+
+	subBody, err := models.SubscriptionRss_GetBody(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Contains(t, subBody, "<title>Post 2</title>")
+	require.NotContains(t, subBody, "<title>Post 3</title>")
+
+	row := pool.QueryRow(`
+		select count(*) from subscription_posts where subscription_id = $1 and is_delivered_now
+	`, subscription.Id)
+	var deliveredNowCount int
+	err = row.Scan(&deliveredNowCount)
+	oops.RequireNoError(t, err)
+	require.Equal(t, 2, deliveredNowCount)
+
+	// The schedule keeps going from where the delivery left off
+	utcNow, err := schedule.ParseTime(timeFormat, fri)
+	oops.RequireNoError(t, err)
+
+	utcNow = utcNow.UTC()
+	utcNowDate := utcNow.Date()
+	utcNowScheduledFor := utcNow.MustUTCString()
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return PublishForUser(
+			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
+			utcNowScheduledFor, nil,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	subBody, err = models.SubscriptionRss_GetBody(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Contains(t, subBody, "<title>Post 3</title>")
+	require.NotContains(t, subBody, "<title>Post 4</title>")
+
+	err = cleanup(pool)
+	oops.RequireNoError(t, err)
+}
+
+func TestVacationCatchUp(t *testing.T) {
+	pool := db.RootPool
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	wed := "2022-05-04 00:00:00+00:00"
+	fri := "2022-05-06 00:00:00+00:00"
+
+	user, err := createUser(pool)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAt, err := schedule.ParseTime(timeFormat, wed)
+	oops.RequireNoError(t, err)
+
+	subscription, err := createSubscription(
+		pool, user.Id, 1, finishedSetupAt, 5, 0, map[schedule.DayOfWeek]int{"thu": 1, "fri": 1},
+	)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAtDate := finishedSetupAt.Date()
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return InitSubscription(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.Name,
+			subscription.BlogBestUrl, user.DeliveryChannel, false, finishedSetupAt, finishedSetupAt,
+			finishedSetupAtDate,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	// Thursday was skipped for a vacation, Friday gets its post too
+	utcNow, err := schedule.ParseTime(timeFormat, fri)
+	oops.RequireNoError(t, err)
+
+	utcNow = utcNow.UTC()
+	utcNowDate := utcNow.Date()
+	utcNowScheduledFor := utcNow.MustUTCString()
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return PublishForUser(
+			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
+			utcNowScheduledFor, []schedule.Date{"2022-05-05"},
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	subBody, err := models.SubscriptionRss_GetBody(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Contains(t, subBody, "<title>Post 2</title>")
+	require.NotContains(t, subBody, "<title>Post 3</title>")
+
+	err = cleanup(pool)
+	oops.RequireNoError(t, err)
+}
+
+func TestPostQueue(t *testing.T) {
+	pool := db.RootPool
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu := "2022-05-05 00:00:00+00:00"
+	fri := "2022-05-06 00:00:00+00:00"
+
+	user, err := createUser(pool)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAt, err := schedule.ParseTime(timeFormat, thu)
+	oops.RequireNoError(t, err)
+
+	subscription, err := createSubscription(
+		pool, user.Id, 1, finishedSetupAt, 5, 0, map[schedule.DayOfWeek]int{"fri": 1},
+	)
+	oops.RequireNoError(t, err)
+
+	getPostId := func(title string) models.SubscriptionPostId {
+		row := pool.QueryRow(`
+			select subscription_posts.id from subscription_posts
+			join blog_posts on subscription_posts.blog_post_id = blog_posts.id
+			where subscription_id = $1 and title = $2
+		`, subscription.Id, title)
+		var postId models.SubscriptionPostId
+		err := row.Scan(&postId)
+		oops.RequireNoError(t, err)
+		return postId
+	}
+
+	isQueued, err := models.SubscriptionPost_Skip(pool, subscription.Id, getPostId("Post 1"), finishedSetupAt)
+	oops.RequireNoError(t, err)
+	require.True(t, isQueued)
+	isQueued, err = models.SubscriptionPost_MoveToFront(pool, subscription.Id, getPostId("Post 4"))
+	oops.RequireNoError(t, err)
+	require.True(t, isQueued)
+	isQueued, err = models.SubscriptionPost_MoveToFront(pool, subscription.Id, getPostId("Post 5"))
+	oops.RequireNoError(t, err)
+	require.True(t, isQueued)
+	isQueued, err = models.SubscriptionPost_Restore(pool, subscription.Id, getPostId("Post 5"))
+	oops.RequireNoError(t, err)
+	require.True(t, isQueued)
+
+	finishedSetupAtDate := finishedSetupAt.Date()
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return InitSubscription(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.Name,
+			subscription.BlogBestUrl, user.DeliveryChannel, false, finishedSetupAt, finishedSetupAt,
+			finishedSetupAtDate,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	utcNow, err := schedule.ParseTime(timeFormat, fri)
+	oops.RequireNoError(t, err)
+
+	utcNow = utcNow.UTC()
+	utcNowDate := utcNow.Date()
+	utcNowScheduledFor := utcNow.MustUTCString()
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return PublishForUser(
+			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
+			utcNowScheduledFor, nil,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	subBody, err := models.SubscriptionRss_GetBody(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Contains(t, subBody, "<title>Post 4</title>")
+	require.NotContains(t, subBody, "<title>Post 1</title>")
+
+	isQueued, err = models.SubscriptionPost_Skip(pool, subscription.Id, getPostId("Post 4"), utcNow)
+	oops.RequireNoError(t, err)
+	require.False(t, isQueued)
+
+	nextPosts, err := models.SubscriptionPost_GetNextUnpublished(pool, subscription.Id, 5)
+	oops.RequireNoError(t, err)
+	var nextTitles []string
+	for _, post := range nextPosts {
+		nextTitles = append(nextTitles, post.Title)
+	}
+	require.Equal(t, []string{"Post 2", "Post 3", "Post 5"}, nextTitles)
+
+	unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Equal(t, 3, unpublishedCount)
+
+	err = cleanup(pool)
+	oops.RequireNoError(t, err)
+}
+
+func TestReversePostOrder(t *testing.T) {
+	pool := db.RootPool
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu := "2022-05-05 00:00:00+00:00"
+	fri := "2022-05-06 00:00:00+00:00"
+
+	user, err := createUser(pool)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAt, err := schedule.ParseTime(timeFormat, thu)
+	oops.RequireNoError(t, err)
+
+	subscription, err := createSubscription(
+		pool, user.Id, 1, finishedSetupAt, 5, 0, map[schedule.DayOfWeek]int{"fri": 2},
+	)
+	oops.RequireNoError(t, err)
+
+	// Recreate the posts the way setup does
+	_, err = pool.Exec(`delete from subscription_posts where subscription_id = $1`, subscription.Id)
+	oops.RequireNoError(t, err)
+	blogPostIds := make(map[models.BlogPostId]bool)
+	for i := int64(1); i <= 5; i++ {
+		blogPostIds[models.BlogPostId(int64(subscription.Id)*100+i)] = true
+	}
+	err = models.Subscription_CreatePostsFromIds(
+		pool, subscription.Id, models.BlogId(subscription.Id), blogPostIds, models.PostOrderReverse,
+	)
+	oops.RequireNoError(t, err)
+
+	preview, err := models.Subscription_GetSchedulePreview(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Equal(t, models.PostOrderReverse, preview.PostOrder)
+	require.Equal(t, "Post 5", preview.NextPosts[0].Title)
+
+	finishedSetupAtDate := finishedSetupAt.Date()
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return InitSubscription(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.Name,
+			subscription.BlogBestUrl, user.DeliveryChannel, false, finishedSetupAt, finishedSetupAt,
+			finishedSetupAtDate,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	utcNow, err := schedule.ParseTime(timeFormat, fri)
+	oops.RequireNoError(t, err)
+
+	utcNow = utcNow.UTC()
+	utcNowDate := utcNow.Date()
+	utcNowScheduledFor := utcNow.MustUTCString()
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return PublishForUser(
+			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
+			utcNowScheduledFor, nil,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	subBody, err := models.SubscriptionRss_GetBody(pool, subscription.Id)
+	oops.RequireNoError(t, err)
+	require.Contains(t, subBody, "<title>Post 5</title>")
+	require.Contains(t, subBody, "<title>Post 4</title>")
+	require.NotContains(t, subBody, "<title>Post 3</title>")
+	require.NotContains(t, subBody, "<title>Post 1</title>")
+
+	err = cleanup(pool)
+	oops.RequireNoError(t, err)
+}
+
 func TestUserFeedStableSort(t *testing.T) {
 	pool := db.RootPool
 	timeFormat := "2006-01-02 15:04:05-07:00"
@@ -1364,7 +1715,7 @@ func TestUserFeedStableSort(t *testing.T) {
 	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
 		return PublishForUser(
 			tx, user.Id, user.ProductUserId, user.DeliveryChannel, utcNow, utcNow, utcNowDate,
-			utcNowScheduledFor,
+			utcNowScheduledFor, nil,
 		)
 	})
 	oops.RequireNoError(t, err)
@@ -1373,10 +1724,12 @@ func TestUserFeedStableSort(t *testing.T) {
 	oops.RequireNoError(t, err)
 
 	expectedUserBody := `<?xml version="1.0" encoding="UTF-8"?>
-<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:atom="http://www.w3.org/2005/Atom">
   <channel>
     <title>FeedRewind</title>
     <link>http://localhost:3000</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/single/usertoken"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>
     <item>
       <title>Post 1</title>
       <link>http://localhost:3000/posts/post-1/2_1/</link>
@@ -1447,6 +1800,80 @@ type testUser struct {
 	DeliveryChannel models.DeliveryChannel
 }
 
+func TestEmailDelivery(t *testing.T) {
+	pool := db.RootPool
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu := "2022-05-05 00:00:00+00:00"
+	fri := "2022-05-06 00:00:00+00:00"
+
+	user, err := createUser(pool)
+	oops.RequireNoError(t, err)
+
+	err = models.UserSettings_SaveDeliveryChannel(pool, user.Id, models.DeliveryChannelEmail)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAt, err := schedule.ParseTime(timeFormat, thu)
+	oops.RequireNoError(t, err)
+
+	subscription, err := createSubscription(
+		pool, user.Id, 1, finishedSetupAt, 2, 0, map[schedule.DayOfWeek]int{"fri": 1},
+	)
+	oops.RequireNoError(t, err)
+
+	finishedSetupAtDate := finishedSetupAt.Date()
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return InitSubscription(
+			tx, user.Id, user.ProductUserId, subscription.Id, subscription.Name,
+			subscription.BlogBestUrl, models.DeliveryChannelEmail, true, finishedSetupAt, finishedSetupAt,
+			finishedSetupAtDate,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	pending, err := models.EmailDigest_LockPending(pool, user.Id)
+	oops.RequireNoError(t, err)
+	require.Len(t, pending, 1)
+	require.True(t, pending[0].IsInitialItemPending)
+	require.Empty(t, pending[0].Posts)
+
+	err = models.EmailDigest_UpdateStatus(pool, pending, models.PostPublishStatusEmailSent)
+	oops.RequireNoError(t, err)
+
+	utcNow, err := schedule.ParseTime(timeFormat, fri)
+	oops.RequireNoError(t, err)
+
+	utcNow = utcNow.UTC()
+	utcNowDate := utcNow.Date()
+	utcNowScheduledFor := utcNow.MustUTCString()
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		return PublishForUser(
+			tx, user.Id, user.ProductUserId, models.DeliveryChannelEmail, utcNow, utcNow, utcNowDate,
+			utcNowScheduledFor, nil,
+		)
+	})
+	oops.RequireNoError(t, err)
+
+	pending, err = models.EmailDigest_LockPending(pool, user.Id)
+	oops.RequireNoError(t, err)
+	require.Len(t, pending, 1)
+	require.False(t, pending[0].IsInitialItemPending)
+	require.False(t, pending[0].IsFinalItemPending)
+	require.Equal(t, 1, pending[0].RemainingCount)
+	require.Len(t, pending[0].Posts, 1)
+	require.Equal(t, "Post 1", pending[0].Posts[0].Title)
+
+	err = models.EmailDigest_UpdateStatus(pool, pending, models.PostPublishStatusEmailSent)
+	oops.RequireNoError(t, err)
+
+	pending, err = models.EmailDigest_LockPending(pool, user.Id)
+	oops.RequireNoError(t, err)
+	require.Empty(t, pending)
+
+	err = cleanup(pool)
+	oops.RequireNoError(t, err)
+}
+
 func createUser(qu pgw.Queryable) (*testUser, error) {
 	if err := ensureTestDb(qu); err != nil {
 		return nil, err
@@ -1470,6 +1897,13 @@ func createUser(qu pgw.Queryable) (*testUser, error) {
 		return nil, err
 	}
 
+	_, err = qu.Exec(`
+        insert into feed_tokens (token, user_id) values ('usertoken', $1)
+    `, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	deliveryChannel := models.DeliveryChannelMultipleFeeds
 	_, err = qu.Exec(`
         insert into user_settings (user_id, timezone, version, delivery_channel)
@@ -1527,6 +1961,13 @@ func createSubscription(
 		return nil, err
 	}
 
+	_, err = qu.Exec(`
+        insert into feed_tokens (token, subscription_id) values ($1, $2)
+    `, fmt.Sprintf("token%d", id), id)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, dayOfWeek := range schedule.DaysOfWeek {
 		_, err := qu.Exec(`
             insert into schedules (subscription_id, day_of_week, count)
@@ -1554,9 +1995,11 @@ func createSubscription(
 			publishedAt = &finishedSetupAt
 		}
 		_, err = qu.Exec(`
-            insert into subscription_posts (id, blog_post_id, subscription_id, random_id, published_at)
-            values ($1, $2, $3, $4, $5)
-        `, postId, postId, id, randomId, publishedAt)
+            insert into subscription_posts (
+                id, blog_post_id, subscription_id, random_id, published_at, order_rank
+            )
+            values ($1, $2, $3, $4, $5, $6)
+        `, postId, postId, id, randomId, publishedAt, i)
 		if err != nil {
 			return nil, err
 		}
@@ -1601,3 +2044,366 @@ func ensureTestDb(qu pgw.Queryable) error {
 
 	return nil
 }
+
+func TestGenerateAtom(t *testing.T) {
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu, err := schedule.ParseTime(timeFormat, "2022-05-05 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+	fri, err := schedule.ParseTime(timeFormat, "2022-05-06 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+
+	f := newSubscriptionFeed(1, "token1", "Test Subscription", 0, []feedItem{
+		{
+			Title:          "Post 1",
+			Link:           "http://localhost:3000/posts/post-1/1_1/",
+			Guid:           makeGuid("1"),
+			Description:    `<a href="http://localhost:3000/subscriptions/1">Manage</a>`,
+			PublishedAt:    fri,
+			SubscriptionId: 1,
+			MaybePostIndex: nil,
+			RemainingCount: 0,
+			MaybeContent:   nil,
+		},
+		{
+			Title:          "Test Subscription added to FeedRewind",
+			Link:           "http://localhost:3000/subscriptions/1",
+			Guid:           makeGuid("1-welcome"),
+			Description:    `<a href="http://localhost:3000/subscriptions/1">Manage</a>`,
+			PublishedAt:    thu,
+			SubscriptionId: 1,
+			MaybePostIndex: nil,
+			RemainingCount: 0,
+			MaybeContent:   nil,
+		},
+	})
+	atomBody, err := generateAtom(f, schedule.UTCNow())
+	oops.RequireNoError(t, err)
+
+	expectedAtomBody := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>urn:feedrewind:subscription:1</id>
+  <title>Test Subscription · FeedRewind</title>
+  <updated>2022-05-06T00:00:00Z</updated>
+  <link rel="self" type="application/atom+xml" href="http://localhost:3000/feeds/token1.atom"></link>
+  <link rel="alternate" type="text/html" href="http://localhost:3000/subscriptions/1"></link>
+  <link rel="hub" href="http://localhost:3000/websub"></link>
+  <author>
+    <name>FeedRewind</name>
+  </author>
+  <entry>
+    <id>urn:feedrewind:6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b</id>
+    <title>Post 1</title>
+    <link rel="alternate" href="http://localhost:3000/posts/post-1/1_1/"></link>
+    <published>2022-05-06T00:00:00Z</published>
+    <updated>2022-05-06T00:00:00Z</updated>
+    <content type="html">&lt;a href=&#34;http://localhost:3000/subscriptions/1&#34;&gt;Manage&lt;/a&gt;</content>
+  </entry>
+  <entry>
+    <id>urn:feedrewind:02d00b67b9732798e803e344a5e57d80e3f7a620991f9cd5f2256ff8644de37a</id>
+    <title>Test Subscription added to FeedRewind</title>
+    <link rel="alternate" href="http://localhost:3000/subscriptions/1"></link>
+    <published>2022-05-05T00:00:00Z</published>
+    <updated>2022-05-05T00:00:00Z</updated>
+    <content type="html">&lt;a href=&#34;http://localhost:3000/subscriptions/1&#34;&gt;Manage&lt;/a&gt;</content>
+  </entry>
+</feed>`
+	require.Equal(t, expectedAtomBody, atomBody)
+
+	emptyUpdatedAt, err := schedule.ParseTime(timeFormat, "2022-05-07 12:00:00+00:00")
+	oops.RequireNoError(t, err)
+	emptyAtomBody, err := generateAtom(newUserFeed(1, "usertoken", nil), emptyUpdatedAt)
+	oops.RequireNoError(t, err)
+	require.Contains(t, emptyAtomBody, "<updated>2022-05-07T12:00:00Z</updated>")
+	require.Contains(t, emptyAtomBody, `href="http://localhost:3000/feeds/single/usertoken.atom"`)
+	require.Contains(t, emptyAtomBody, "<id>urn:feedrewind:user:1</id>")
+	require.NotContains(t, emptyAtomBody, "<entry>")
+}
+
+func TestGenerateJsonFeed(t *testing.T) {
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	thu, err := schedule.ParseTime(timeFormat, "2022-05-05 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+	fri, err := schedule.ParseTime(timeFormat, "2022-05-06 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+
+	postIndex := 0
+	f := newSubscriptionFeed(1, "token1", "Test Subscription", 4, []feedItem{
+		{
+			Title:          "Post 1",
+			Link:           "http://localhost:3000/posts/post-1/1_1/",
+			Guid:           makeGuid("1"),
+			Description:    `<a href="http://localhost:3000/subscriptions/1">Manage</a>`,
+			PublishedAt:    fri,
+			SubscriptionId: 1,
+			MaybePostIndex: &postIndex,
+			RemainingCount: 4,
+			MaybeContent:   nil,
+		},
+		{
+			Title:          "Test Subscription added to FeedRewind",
+			Link:           "http://localhost:3000/subscriptions/1",
+			Guid:           makeGuid("1-welcome"),
+			Description:    `<a href="http://localhost:3000/subscriptions/1">Manage</a>`,
+			PublishedAt:    thu,
+			SubscriptionId: 1,
+			MaybePostIndex: nil,
+			RemainingCount: 4,
+			MaybeContent:   nil,
+		},
+	})
+	jsonBody, err := generateJsonFeed(f)
+	oops.RequireNoError(t, err)
+
+	expectedJsonBody := `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Test Subscription · FeedRewind",
+  "home_page_url": "http://localhost:3000/subscriptions/1",
+  "feed_url": "http://localhost:3000/feeds/token1.json",
+  "authors": [
+    {
+      "name": "FeedRewind"
+    }
+  ],
+  "hubs": [
+    {
+      "type": "WebSub",
+      "url": "http://localhost:3000/websub"
+    }
+  ],
+  "items": [
+    {
+      "id": "6b86b273ff34fce19d6b804eff5a3f5747ada4eaa22f1d49c01e52ddb7875b4b",
+      "url": "http://localhost:3000/posts/post-1/1_1/",
+      "title": "Post 1",
+      "content_html": "<a href=\"http://localhost:3000/subscriptions/1\">Manage</a>",
+      "date_published": "2022-05-06T00:00:00Z",
+      "_feedrewind": {
+        "subscription_id": 1,
+        "post_index": 0,
+        "remaining_count": 4
+      }
+    },
+    {
+      "id": "02d00b67b9732798e803e344a5e57d80e3f7a620991f9cd5f2256ff8644de37a",
+      "url": "http://localhost:3000/subscriptions/1",
+      "title": "Test Subscription added to FeedRewind",
+      "content_html": "<a href=\"http://localhost:3000/subscriptions/1\">Manage</a>",
+      "date_published": "2022-05-05T00:00:00Z",
+      "_feedrewind": {
+        "subscription_id": 1,
+        "post_index": null,
+        "remaining_count": 4
+      }
+    }
+  ],
+  "_feedrewind": {
+    "subscription_id": 1,
+    "remaining_count": 4
+  }
+}
+`
+	require.Equal(t, expectedJsonBody, jsonBody)
+
+	userJsonBody, err := generateJsonFeed(newUserFeed(1, "usertoken", nil))
+	oops.RequireNoError(t, err)
+	require.Contains(t, userJsonBody, `"feed_url": "http://localhost:3000/feeds/single/usertoken.json"`)
+	require.Contains(t, userJsonBody, `"items": []`)
+	require.NotContains(t, userJsonBody, `"_feedrewind"`)
+}
+
+func TestGenerateWithPostContent(t *testing.T) {
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	fri, err := schedule.ParseTime(timeFormat, "2022-05-06 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+
+	postIndex := 0
+	content := `<p>Hello <a href="https://blog/about">world</a></p>`
+	f := newSubscriptionFeed(1, "token1", "Test Subscription", 0, []feedItem{{
+		Title:          "Post 1",
+		Link:           "http://localhost:3000/posts/post-1/1_1/",
+		Guid:           makeGuid("1"),
+		Description:    `<a href="http://localhost:3000/subscriptions/1">Manage</a>`,
+		PublishedAt:    fri,
+		SubscriptionId: 1,
+		MaybePostIndex: &postIndex,
+		RemainingCount: 0,
+		MaybeContent:   &content,
+	}})
+
+	rssBody, err := generateRss(f)
+	oops.RequireNoError(t, err)
+	require.Contains(t, rssBody, `
+    <link>http://localhost:3000/subscriptions/1</link>
+    <atom:link rel="self" href="http://localhost:3000/feeds/token1"></atom:link>
+    <atom:link rel="hub" href="http://localhost:3000/websub"></atom:link>`)
+	require.Contains(t, rssBody, `
+      <description>&lt;a href=&#34;http://localhost:3000/subscriptions/1&#34;&gt;Manage&lt;/a&gt;</description>
+      <content:encoded>&lt;p&gt;Hello &lt;a href=&#34;https://blog/about&#34;&gt;world&lt;/a&gt;&lt;/p&gt;</content:encoded>
+      <pubDate>Fri, 06 May 2022 00:00:00 +0000</pubDate>`)
+
+	atomBody, err := generateAtom(f, schedule.UTCNow())
+	oops.RequireNoError(t, err)
+	require.Contains(t, atomBody, `
+    <summary type="html">&lt;a href=&#34;http://localhost:3000/subscriptions/1&#34;&gt;Manage&lt;/a&gt;</summary>
+    <content type="html">&lt;p&gt;Hello &lt;a href=&#34;https://blog/about&#34;&gt;world&lt;/a&gt;&lt;/p&gt;</content>`)
+
+	jsonBody, err := generateJsonFeed(f)
+	oops.RequireNoError(t, err)
+	require.Contains(t, jsonBody, `"content_html": "<p>Hello <a href=\"https://blog/about\">world</a></p>"`)
+}
+
+func TestGenerateRotatedFeed(t *testing.T) {
+	timeFormat := "2006-01-02 15:04:05-07:00"
+	rotatedAt, err := schedule.ParseTime(timeFormat, "2022-05-06 00:00:00+00:00")
+	oops.RequireNoError(t, err)
+
+	subscriptionId := models.SubscriptionId(1)
+	subscriptionFeed := models.ResolvedFeed{
+		MaybeSubscriptionId: &subscriptionId,
+		MaybeUserId:         nil,
+		MaybeRotatedAt:      &rotatedAt,
+	}
+	for _, format := range []models.FeedFormat{
+		models.FeedFormatRss, models.FeedFormatAtom, models.FeedFormatJson,
+	} {
+		body, err := GenerateRotatedFeed("oldtoken", &subscriptionFeed, format)
+		oops.RequireNoError(t, err)
+		require.Contains(t, body, "This feed has moved")
+		require.Contains(t, body, "http://localhost:3000/subscriptions/1")
+		require.Contains(t, body, "May 13")
+		require.Contains(t, body, "http://localhost:3000/feeds/oldtoken")
+		require.NotContains(t, body, "/feeds/token")
+	}
+
+	atomBody, err := GenerateRotatedFeed("oldtoken", &subscriptionFeed, models.FeedFormatAtom)
+	oops.RequireNoError(t, err)
+	require.Contains(t, atomBody, "<id>urn:feedrewind:subscription:1</id>")
+	require.Contains(t, atomBody, "<updated>2022-05-06T00:00:00Z</updated>")
+
+	userId := models.UserId(2)
+	userFeed := models.ResolvedFeed{
+		MaybeSubscriptionId: nil,
+		MaybeUserId:         &userId,
+		MaybeRotatedAt:      &rotatedAt,
+	}
+	userAtomBody, err := GenerateRotatedFeed("olduser", &userFeed, models.FeedFormatAtom)
+	oops.RequireNoError(t, err)
+	require.Contains(t, userAtomBody, "<id>urn:feedrewind:user:2</id>")
+	require.Contains(t, userAtomBody, `href="http://localhost:3000/feeds/single/olduser.atom"`)
+	require.Contains(t, userAtomBody, "http://localhost:3000/settings")
+}
+
+func TestDateScheduledCount(t *testing.T) {
+	everyThreeDays := models.ScheduleCadence{ //nolint:exhaustruct
+		Type:      models.ScheduleTypeEveryNDays,
+		EveryDays: 3,
+		StartDate: "2022-05-04",
+	}
+	require.Equal(t, 0, DateScheduledCount(&everyThreeDays, nil, "2022-05-01"))
+	require.Equal(t, 1, DateScheduledCount(&everyThreeDays, nil, "2022-05-04"))
+	require.Equal(t, 0, DateScheduledCount(&everyThreeDays, nil, "2022-05-05"))
+	require.Equal(t, 0, DateScheduledCount(&everyThreeDays, nil, "2022-05-06"))
+	require.Equal(t, 1, DateScheduledCount(&everyThreeDays, nil, "2022-05-07"))
+
+	fourPerMonth := models.ScheduleCadence{ //nolint:exhaustruct
+		Type:         models.ScheduleTypePerMonth,
+		MonthlyCount: 4,
+	}
+	for _, month := range []string{"2022-02", "2022-05"} {
+		monthStart, err := schedule.ParseTime("2006-01", month)
+		oops.RequireNoError(t, err)
+		total := 0
+		for date := monthStart.Date(); strings.HasPrefix(string(date), month); {
+			count := DateScheduledCount(&fourPerMonth, nil, date)
+			require.LessOrEqual(t, count, 1)
+			total += count
+			date = date.NextDay()
+		}
+		require.Equal(t, 4, total, month)
+	}
+
+	weekly := models.ScheduleCadence{Type: models.ScheduleTypeWeekly} //nolint:exhaustruct
+	countsByDay := map[schedule.DayOfWeek]int{"wed": 2}
+	require.Equal(t, 2, DateScheduledCount(&weekly, countsByDay, "2022-05-04"))
+	require.Equal(t, 0, DateScheduledCount(&weekly, countsByDay, "2022-05-05"))
+}
+
+func TestFinishByCount(t *testing.T) {
+	require.Equal(t, 2, FinishByCount(10, "2022-05-01", "2022-05-05"))
+	require.Equal(t, 3, FinishByCount(11, "2022-05-01", "2022-05-04"))
+	require.Equal(t, 1, FinishByCount(3, "2022-05-01", "2022-05-10"))
+	require.Equal(t, 7, FinishByCount(7, "2022-05-05", "2022-05-05"))
+	require.Equal(t, 7, FinishByCount(7, "2022-05-08", "2022-05-05"))
+	require.Equal(t, 0, FinishByCount(0, "2022-05-01", "2022-05-05"))
+}
+
+func TestReadingTimeCount(t *testing.T) {
+	count, credit := ReadingTimeCount(60, []int{5, 3, 10})
+	require.Equal(t, 2, count)
+	require.Equal(t, 4, credit)
+
+	count, credit = ReadingTimeCount(20, []int{5})
+	require.Equal(t, 0, count)
+	require.Equal(t, 20, credit)
+
+	count, credit = ReadingTimeCount(35, nil)
+	require.Equal(t, 0, count)
+	require.Equal(t, 35, credit)
+}
+
+func TestAdaptivePacingStepDown(t *testing.T) {
+	require.Equal(t, 50, adaptivePacingStepDown(100))
+	require.Equal(t, 25, adaptivePacingStepDown(50))
+	require.Equal(t, 0, adaptivePacingStepDown(25))
+	require.Equal(t, 0, adaptivePacingStepDown(0))
+}
+
+func TestAdaptivePacingCount(t *testing.T) {
+	publishDays := func(percent int, credit int, days int) ([]int, int) {
+		var counts []int
+		for i := 0; i < days; i++ {
+			var count int
+			count, credit = adaptivePacingCount(credit, 1, percent)
+			counts = append(counts, count)
+		}
+		return counts, credit
+	}
+
+	counts, credit := publishDays(100, 0, 3)
+	require.Equal(t, []int{1, 1, 1}, counts)
+	require.Equal(t, 0, credit)
+
+	counts, credit = publishDays(50, 0, 4)
+	require.Equal(t, []int{0, 1, 0, 1}, counts)
+	require.Equal(t, 0, credit)
+
+	counts, credit = publishDays(25, 0, 8)
+	require.Equal(t, []int{0, 0, 0, 1, 0, 0, 0, 1}, counts)
+	require.Equal(t, 0, credit)
+
+	counts, credit = publishDays(0, 75, 5)
+	require.Equal(t, []int{0, 0, 0, 0, 0}, counts)
+	require.Equal(t, 75, credit)
+
+	// Credit carries over when the pace changes
+	counts, credit = publishDays(50, 0, 1)
+	require.Equal(t, []int{0}, counts)
+	counts, credit = publishDays(25, credit, 2)
+	require.Equal(t, []int{0, 1}, counts)
+	require.Equal(t, 0, credit)
+
+	// Resuming keeps the credit and publishes the full schedule
+	counts, credit = publishDays(25, 0, 3)
+	require.Equal(t, []int{0, 0, 0}, counts)
+	counts, credit = publishDays(100, credit, 2)
+	require.Equal(t, []int{1, 1}, counts)
+	require.Equal(t, 75, credit)
+
+	count, credit := adaptivePacingCount(75, 2, 100)
+	require.Equal(t, 2, count)
+	require.Equal(t, 75, credit)
+
+	count, credit = adaptivePacingCount(50, 3, 50)
+	require.Equal(t, 2, count)
+	require.Equal(t, 0, credit)
+}
@@ -18,13 +18,48 @@ import (
 )
 
 type feedsData struct {
-	StartUrl        string
-	StartUrlEncoded string
-	Feeds           []*models.StartFeed
-	IsNotAUrl       bool
-	AreNoFeeds      bool
-	CouldNotReach   bool
-	IsBadFeed       bool
+	StartUrl             string
+	StartUrlEncoded      string
+	Feeds                []*models.StartFeed
+	IsNotAUrl            bool
+	AreNoFeeds           bool
+	CouldNotReach        bool
+	IsBadFeed            bool
+	MaybeTableOfContents *tableOfContentsData
+}
+
+type tableOfContentsData struct {
+	StartPageId models.StartPageId
+	Title       string
+	LinksCount  int
+}
+
+func feedsDataFromTableOfContents(startUrl string, toc *discoveredTableOfContents) feedsData {
+	return feedsData{ //nolint:exhaustruct
+		StartUrl:             startUrl,
+		AreNoFeeds:           true,
+		MaybeTableOfContents: tableOfContentsDataFromDiscovered(toc),
+	}
+}
+
+func feedsDataFromFeeds(startUrl string, feeds *discoveredFeeds) feedsData {
+	var maybeTableOfContents *tableOfContentsData
+	if feeds.maybeTableOfContents != nil {
+		maybeTableOfContents = tableOfContentsDataFromDiscovered(feeds.maybeTableOfContents)
+	}
+	return feedsData{ //nolint:exhaustruct
+		StartUrl:             startUrl,
+		Feeds:                feeds.feeds,
+		MaybeTableOfContents: maybeTableOfContents,
+	}
+}
+
+func tableOfContentsDataFromDiscovered(toc *discoveredTableOfContents) *tableOfContentsData {
+	return &tableOfContentsData{
+		StartPageId: toc.startPageId,
+		Title:       toc.title,
+		LinksCount:  toc.linksCount,
+	}
 }
 
 func feedsDataFromTypedResult(startUrl string, typedResult models.TypedBlogUrlResult) feedsData {
@@ -67,6 +102,7 @@ func Onboarding_Add(w http.ResponseWriter, r *http.Request) {
 	type OnboardingResult struct {
 		Title            string
 		Session          *util.Session
+		ArchivesUrl      string
 		MaybeFeedsData   *feedsData
 		MaybeSuggestions *util.Suggestions
 	}
@@ -84,8 +120,9 @@ func Onboarding_Add(w http.ResponseWriter, r *http.Request) {
 		models.ProductEvent_MustEmitVisitAddPage(pc, path, userIsAnonymous, map[string]any{
 			"blog_url": startUrl,
 		})
+		archivesUrl := onboarding_ArchivesUrl(r)
 		discoverFeedsResult, typedResult := onboarding_MustDiscoverFeeds(
-			pool, startUrl, currentUser, productUserId,
+			pool, startUrl, archivesUrl, onboarding_ReadingOrder(r), currentUser, productUserId,
 		)
 		models.ProductEvent_MustEmitDiscoverFeeds(pc, startUrl, typedResult, userIsAnonymous)
 		var maybeUserId *models.UserId
@@ -107,13 +144,21 @@ func Onboarding_Add(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, redirectPath, http.StatusSeeOther)
 			return
 		case *discoveredFeeds:
+			feeds := feedsDataFromFeeds(startUrl, discoverResult)
+			result = OnboardingResult{
+				Title:            title,
+				Session:          rutil.Session(r),
+				ArchivesUrl:      archivesUrl,
+				MaybeFeedsData:   &feeds,
+				MaybeSuggestions: nil,
+			}
+		case *discoveredTableOfContents:
+			feeds := feedsDataFromTableOfContents(startUrl, discoverResult)
 			result = OnboardingResult{
-				Title:   title,
-				Session: rutil.Session(r),
-				MaybeFeedsData: &feedsData{ //nolint:exhaustruct
-					StartUrl: startUrl,
-					Feeds:    discoverResult.feeds,
-				},
+				Title:            title,
+				Session:          rutil.Session(r),
+				ArchivesUrl:      archivesUrl,
+				MaybeFeedsData:   &feeds,
 				MaybeSuggestions: nil,
 			}
 		case *discoverError:
@@ -149,6 +194,7 @@ func Onboarding_Add(w http.ResponseWriter, r *http.Request) {
 			result = OnboardingResult{
 				Title:            title,
 				Session:          rutil.Session(r),
+				ArchivesUrl:      archivesUrl,
 				MaybeFeedsData:   &feeds,
 				MaybeSuggestions: nil,
 			}
@@ -160,6 +206,7 @@ func Onboarding_Add(w http.ResponseWriter, r *http.Request) {
 		result = OnboardingResult{
 			Title:          title,
 			Session:        rutil.Session(r),
+			ArchivesUrl:    "",
 			MaybeFeedsData: nil,
 			MaybeSuggestions: &util.Suggestions{
 				Session:             rutil.Session(r),
@@ -185,6 +232,7 @@ func Onboarding_AddLanding(w http.ResponseWriter, r *http.Request) {
 	type OnboardingResult struct {
 		Title            string
 		Session          *util.Session
+		ArchivesUrl      string
 		MaybeFeedsData   *feedsData
 		MaybeSuggestions *util.Suggestions
 	}
@@ -193,8 +241,9 @@ func Onboarding_AddLanding(w http.ResponseWriter, r *http.Request) {
 
 	typedUrl := util.EnsureParamStr(r, "start_url")
 	startUrl := strings.TrimSpace(typedUrl)
+	archivesUrl := onboarding_ArchivesUrl(r)
 	discoverFeedsResult, typedResult := onboarding_MustDiscoverFeeds(
-		pool, startUrl, currentUser, productUserId,
+		pool, startUrl, archivesUrl, onboarding_ReadingOrder(r), currentUser, productUserId,
 	)
 	models.ProductEvent_MustEmitDiscoverFeeds(pc, startUrl, typedResult, userIsAnonymous)
 	var maybeUserId *models.UserId
@@ -214,13 +263,21 @@ func Onboarding_AddLanding(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, redirectPath, http.StatusSeeOther)
 		return
 	case *discoveredFeeds:
+		feeds := feedsDataFromFeeds(startUrl, discoverResult)
 		result = OnboardingResult{
-			Title:   title,
-			Session: rutil.Session(r),
-			MaybeFeedsData: &feedsData{ //nolint:exhaustruct
-				StartUrl: startUrl,
-				Feeds:    discoverResult.feeds,
-			},
+			Title:            title,
+			Session:          rutil.Session(r),
+			ArchivesUrl:      archivesUrl,
+			MaybeFeedsData:   &feeds,
+			MaybeSuggestions: nil,
+		}
+	case *discoveredTableOfContents:
+		feeds := feedsDataFromTableOfContents(startUrl, discoverResult)
+		result = OnboardingResult{
+			Title:            title,
+			Session:          rutil.Session(r),
+			ArchivesUrl:      archivesUrl,
+			MaybeFeedsData:   &feeds,
 			MaybeSuggestions: nil,
 		}
 	case *discoverError:
@@ -256,6 +313,7 @@ func Onboarding_AddLanding(w http.ResponseWriter, r *http.Request) {
 		result = OnboardingResult{
 			Title:            title,
 			Session:          rutil.Session(r),
+			ArchivesUrl:      archivesUrl,
 			MaybeFeedsData:   &feeds,
 			MaybeSuggestions: nil,
 		}
@@ -277,8 +335,9 @@ func Onboarding_DiscoverFeeds(w http.ResponseWriter, r *http.Request) {
 
 	typedUrl := util.EnsureParamStr(r, "start_url")
 	startUrl := strings.TrimSpace(typedUrl)
+	archivesUrl := onboarding_ArchivesUrl(r)
 	discoverFeedsResult, typedResult := onboarding_MustDiscoverFeeds(
-		pool, startUrl, currentUser, productUserId,
+		pool, startUrl, archivesUrl, onboarding_ReadingOrder(r), currentUser, productUserId,
 	)
 	models.ProductEvent_MustEmitDiscoverFeeds(pc, startUrl, typedResult, userIsAnonymous)
 	var maybeUserId *models.UserId
@@ -299,10 +358,9 @@ func Onboarding_DiscoverFeeds(w http.ResponseWriter, r *http.Request) {
 		util.MustWrite(w, rutil.SubscriptionSetupPath(discoverResult.subscription.Id))
 		return
 	case *discoveredFeeds:
-		result = feedsData{ //nolint:exhaustruct
-			StartUrl: startUrl,
-			Feeds:    discoverResult.feeds,
-		}
+		result = feedsDataFromFeeds(startUrl, discoverResult)
+	case *discoveredTableOfContents:
+		result = feedsDataFromTableOfContents(startUrl, discoverResult)
 	case *discoverError:
 		result = feedsDataFromTypedResult(startUrl, typedResult)
 	default:
@@ -339,7 +397,14 @@ type discoveredSubscription struct {
 }
 
 type discoveredFeeds struct {
-	feeds []*models.StartFeed
+	feeds                []*models.StartFeed
+	maybeTableOfContents *discoveredTableOfContents
+}
+
+type discoveredTableOfContents struct {
+	startPageId models.StartPageId
+	title       string
+	linksCount  int
 }
 
 type discoverError struct{}
@@ -348,14 +413,33 @@ type discoverResult interface {
 	discoverResultTag()
 }
 
-func (*discoveredSubscription) discoverResultTag() {}
-func (*discoveredFeeds) discoverResultTag()        {}
-func (*discoverError) discoverResultTag()          {}
+func (*discoveredSubscription) discoverResultTag()    {}
+func (*discoveredFeeds) discoverResultTag()           {}
+func (*discoveredTableOfContents) discoverResultTag() {}
+func (*discoverError) discoverResultTag()             {}
+
+// The archives url is optional and only serves as a hint for the crawler
+func onboarding_ArchivesUrl(r *http.Request) string {
+	archivesUrl, _ := util.MaybeParamStr(r, "archives_url")
+	return strings.TrimSpace(archivesUrl)
+}
+
+// Most blog homepages have enough links to pass for a table of contents, so a site with a feed is only read
+// in page order when the user asks for it
+func onboarding_ReadingOrder(r *http.Request) bool {
+	readingOrder, _ := util.MaybeParamStr(r, "reading_order")
+	return readingOrder != ""
+}
 
 func onboarding_MustDiscoverFeeds(
-	pool *pgw.Pool, startUrl string, currentUser *models.User, productUserId models.ProductUserId,
+	pool *pgw.Pool, startUrl string, archivesUrl string, readingOrder bool, currentUser *models.User,
+	productUserId models.ProductUserId,
 ) (discoverResult, models.TypedBlogUrlResult) {
 	logger := pool.Logger()
+	var maybeArchivesUrl *string
+	if archivesUrl != "" {
+		maybeArchivesUrl = &archivesUrl
+	}
 	if startUrl == crawler.HardcodedOurMachinery || startUrl == crawler.HardcodedSequences {
 		blog, err := models.Blog_GetLatestByFeedUrl(pool, startUrl)
 		if errors.Is(err, models.ErrBlogNotFound) {
@@ -380,17 +464,36 @@ func onboarding_MustDiscoverFeeds(
 	case *crawler.DiscoveredSingleFeed:
 		logger.Info().Msgf("Discover feeds at %s - found single feed", startUrl)
 		var maybeStartPageId *models.StartPageId
+		var maybeTableOfContents *discoveredTableOfContents
 		if result.MaybeStartPage != nil {
 			startPageId, err := models.StartPage_Create(pool, *result.MaybeStartPage)
 			if err != nil {
 				panic(err)
 			}
 			maybeStartPageId = &startPageId
+			if readingOrder {
+				maybeTableOfContents = onboarding_DetectTableOfContents(
+					startPageId, result.MaybeStartPage, &zlogger,
+				)
+			}
 		}
-		startFeed, err := models.StartFeed_CreateFetched(pool, maybeStartPageId, result.Feed)
+		startFeed, err := models.StartFeed_CreateFetched(pool, maybeStartPageId, result.Feed, maybeArchivesUrl)
 		if err != nil {
 			panic(err)
 		}
+		if maybeTableOfContents != nil {
+			// The user asked for page order, let them confirm the links over the feed
+			logger.Info().Msgf(
+				"Discover feeds at %s - also found table of contents (%d links)",
+				startUrl, maybeTableOfContents.linksCount,
+			)
+			return &discoveredFeeds{
+				feeds:                []*models.StartFeed{startFeed},
+				maybeTableOfContents: maybeTableOfContents,
+			}, models.TypedBlogUrlResultTableOfContents
+		} else if readingOrder {
+			logger.Info().Msgf("Discover feeds at %s - no table of contents, using the feed", startUrl)
+		}
 		updatedBlog, err := models.Blog_CreateOrUpdate(pool, startFeed, jobs.GuidedCrawlingJob_PerformNow)
 		if err != nil {
 			panic(err)
@@ -409,13 +512,16 @@ func onboarding_MustDiscoverFeeds(
 		}
 		var startFeeds []*models.StartFeed
 		for _, discoveredFeed := range result.Feeds {
-			startFeed, err := models.StartFeed_Create(pool, startPageId, discoveredFeed)
+			startFeed, err := models.StartFeed_Create(pool, startPageId, discoveredFeed, maybeArchivesUrl)
 			if err != nil {
 				panic(err)
 			}
 			startFeeds = append(startFeeds, startFeed)
 		}
-		return &discoveredFeeds{feeds: startFeeds}, models.TypedBlogUrlResultPageWithMultipleFeeds
+		return &discoveredFeeds{
+			feeds:                startFeeds,
+			maybeTableOfContents: onboarding_DetectTableOfContents(startPageId, &result.StartPage, &zlogger),
+		}, models.TypedBlogUrlResultPageWithMultipleFeeds
 	case *crawler.DiscoverFeedsErrorNotAUrl:
 		logger.Info().Msgf("Discover feeds at %s - not a url", startUrl)
 		return &discoverError{}, models.TypedBlogUrlResultNotAUrl
@@ -423,6 +529,23 @@ func onboarding_MustDiscoverFeeds(
 		logger.Info().Msgf("Discover feeds at %s - could not reach (%v)", startUrl, result.Error)
 		return &discoverError{}, models.TypedBlogUrlResultCouldNotReach
 	case *crawler.DiscoverFeedsErrorNoFeeds:
+		if result.MaybeStartPage != nil {
+			if toc, ok := crawler.DetectTableOfContents(result.MaybeStartPage, &zlogger); ok {
+				logger.Info().Msgf(
+					"Discover feeds at %s - no feeds but found table of contents (%d links)",
+					startUrl, toc.LinksCount,
+				)
+				startPageId, err := models.StartPage_Create(pool, *result.MaybeStartPage)
+				if err != nil {
+					panic(err)
+				}
+				return &discoveredTableOfContents{
+					startPageId: startPageId,
+					title:       toc.Title,
+					linksCount:  toc.LinksCount,
+				}, models.TypedBlogUrlResultTableOfContents
+			}
+		}
 		logger.Info().Msgf("Discover feeds at %s - no feeds", startUrl)
 		return &discoverError{}, models.TypedBlogUrlResultNoFeeds
 	case *crawler.DiscoverFeedsErrorBadFeed:
@@ -432,3 +555,17 @@ func onboarding_MustDiscoverFeeds(
 		panic("unknown discover feeds result type")
 	}
 }
+
+func onboarding_DetectTableOfContents(
+	startPageId models.StartPageId, startPage *crawler.DiscoveredStartPage, logger crawler.Logger,
+) *discoveredTableOfContents {
+	toc, ok := crawler.DetectTableOfContents(startPage, logger)
+	if !ok {
+		return nil
+	}
+	return &discoveredTableOfContents{
+		startPageId: startPageId,
+		title:       toc.Title,
+		linksCount:  toc.LinksCount,
+	}
+}
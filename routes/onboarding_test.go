@@ -0,0 +1,122 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"feedrewind.com/db"
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/models"
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverFeedsSingleFeed(t *testing.T) {
+	// A blog homepage lists enough posts to look like a table of contents
+	var serverUrl string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sb strings.Builder
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			sb.WriteString(`<html><head><title>Test blog</title>
+				<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+				</head><body><ul>`)
+			for i := 12; i >= 1; i-- {
+				fmt.Fprintf(&sb, `<li><a href="%s/post-%d">Post %d</a></li>`, serverUrl, i, i)
+			}
+			sb.WriteString(`</ul></body></html>`)
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(&sb, `<rss version="2.0"><channel><title>Test blog</title><link>%s/</link>`, serverUrl)
+			for i := 12; i >= 1; i-- {
+				date := time.Date(2024, 1, i, 0, 0, 0, 0, time.UTC).Format(time.RFC1123Z)
+				fmt.Fprintf(
+					&sb, `<item><title>Post %d</title><link>%s/post-%d</link><pubDate>%s</pubDate></item>`,
+					i, serverUrl, i, date,
+				)
+			}
+			sb.WriteString(`</channel></rss>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(sb.String()))
+	}))
+	defer server.Close()
+	serverUrl = server.URL
+	startUrl := serverUrl + "/"
+	feedUrl := serverUrl + "/feed.xml"
+
+	pool := db.RootPool
+	err := ensureTestDb(pool)
+	oops.RequireNoError(t, err)
+	err = db.EnsureLatestMigration()
+	oops.RequireNoError(t, err)
+	productUserId := models.ProductUserId("00000000-0000-0000-0000-000000000000")
+
+	t.Run("subscribes to the feed", func(t *testing.T) {
+		defer func() {
+			oops.RequireNoError(t, cleanupDiscoveredFeed(pool, serverUrl))
+		}()
+		result, typedResult := onboarding_MustDiscoverFeeds(pool, startUrl, "", false, nil, productUserId)
+		require.Equal(t, models.TypedBlogUrlResultFeed, typedResult)
+		subscription, ok := result.(*discoveredSubscription)
+		require.True(t, ok, "expected a subscription, got %T", result)
+		require.Equal(t, models.BlogStatusCrawlInProgress, subscription.subscription.BlogStatus)
+	})
+
+	t.Run("offers reading order when asked", func(t *testing.T) {
+		defer func() {
+			oops.RequireNoError(t, cleanupDiscoveredFeed(pool, serverUrl))
+		}()
+		result, typedResult := onboarding_MustDiscoverFeeds(pool, startUrl, "", true, nil, productUserId)
+		require.Equal(t, models.TypedBlogUrlResultTableOfContents, typedResult)
+		feeds, ok := result.(*discoveredFeeds)
+		require.True(t, ok, "expected feeds, got %T", result)
+		require.Len(t, feeds.feeds, 1)
+		require.Equal(t, feedUrl, feeds.feeds[0].Url)
+		require.NotNil(t, feeds.maybeTableOfContents)
+		require.Equal(t, 12, feeds.maybeTableOfContents.linksCount)
+	})
+}
+
+func cleanupDiscoveredFeed(qu pgw.Queryable, serverUrl string) error {
+	if err := ensureTestDb(qu); err != nil {
+		return err
+	}
+
+	_, err := qu.Exec(`delete from delayed_jobs where handler like E'%class: GuidedCrawlingJob\n%'`)
+	if err != nil {
+		return err
+	}
+	_, err = qu.Exec(`delete from blogs where feed_url like $1 || '%'`, serverUrl)
+	if err != nil {
+		return err
+	}
+	_, err = qu.Exec(`delete from start_feeds where url like $1 || '%'`, serverUrl)
+	if err != nil {
+		return err
+	}
+	_, err = qu.Exec(`delete from start_pages where url like $1 || '%'`, serverUrl)
+	return err
+}
+
+func ensureTestDb(qu pgw.Queryable) error {
+	row := qu.QueryRow(`select current_database()`)
+	var dbName string
+	err := row.Scan(&dbName)
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(dbName, "_test") {
+		return oops.Newf("Running outside of test db: %s", dbName)
+	}
+
+	return nil
+}
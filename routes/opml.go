@@ -0,0 +1,268 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"feedrewind.com/jobs"
+	"feedrewind.com/models"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/templates"
+	"feedrewind.com/util"
+	"feedrewind.com/util/opml"
+)
+
+const opmlMaxFileSize = 2 * 1024 * 1024
+const opmlMaxFeeds = 500
+
+func Opml_Export(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	subscriptions, err := models.Subscription_ListForOpml(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+
+	var outlines []opml.Outline
+	for _, subscription := range subscriptions {
+		htmlUrl := ""
+		if subscription.MaybeUrl != nil {
+			htmlUrl = *subscription.MaybeUrl
+		}
+		outlines = append(outlines, opml.Outline{
+			Title:   subscription.Name,
+			FeedUrl: subscription.FeedUrl,
+			HtmlUrl: htmlUrl,
+		})
+	}
+	content, err := opml.Format("FeedRewind subscriptions", outlines, time.Now())
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "export opml", map[string]any{
+		"subscriptions_count": len(outlines),
+	}, nil)
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="feedrewind.opml"`)
+	_, err = w.Write(content)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Responds with the progress path, or with a message for the user if the file can't be imported
+func Opml_Import(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+
+	writeError := func(message string) {
+		w.WriteHeader(http.StatusBadRequest)
+		util.MustWrite(w, message)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, opmlMaxFileSize)
+	err := r.ParseMultipartForm(opmlMaxFileSize)
+	if err != nil {
+		logger.Info().Err(err).Msg("Couldn't parse the OPML upload")
+		writeError("The file couldn't be uploaded. OPML files up to 2 MB are supported.")
+		return
+	}
+	file, _, err := r.FormFile("opml")
+	if err != nil {
+		writeError("Please pick a file.")
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		panic(err)
+	}
+
+	outlines, err := opml.Parse(content)
+	if errors.Is(err, opml.ErrNotOpml) {
+		writeError("That doesn't look like an OPML file.")
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	existingSubscriptions, err := models.Subscription_ListForOpml(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	existingFeedUrls := make(map[string]bool)
+	for _, subscription := range existingSubscriptions {
+		existingFeedUrls[subscription.FeedUrl] = true
+	}
+	var newOutlines []opml.Outline
+	for _, outline := range outlines {
+		if existingFeedUrls[outline.FeedUrl] {
+			continue
+		}
+		newOutlines = append(newOutlines, outline)
+	}
+	skippedCount := len(outlines) - len(newOutlines)
+	logger.Info().Msgf("OPML has %d feeds, %d are already subscribed", len(outlines), skippedCount)
+
+	if len(outlines) == 0 {
+		writeError("No feeds found in the file.")
+		return
+	}
+	if len(newOutlines) == 0 {
+		writeError("You're already subscribed to every feed in the file.")
+		return
+	}
+	if len(newOutlines) > opmlMaxFeeds {
+		writeError(fmt.Sprintf("Up to %d feeds can be imported at once.", opmlMaxFeeds))
+		return
+	}
+
+	tx, err := pool.Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer util.CommitOrRollbackOnPanic(tx)
+
+	opmlImportId, err := models.OpmlImport_Create(tx, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	for i, outline := range newOutlines {
+		var maybeHtmlUrl *string
+		if outline.HtmlUrl != "" {
+			maybeHtmlUrl = &outline.HtmlUrl
+		}
+		itemId, err := models.OpmlImportItem_Create(
+			tx, opmlImportId, i, outline.Title, outline.FeedUrl, maybeHtmlUrl,
+		)
+		if err != nil {
+			panic(err)
+		}
+		err = jobs.OpmlImportJob_PerformNow(tx, itemId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "import opml", map[string]any{
+		"feeds_count":   len(newOutlines),
+		"skipped_count": skippedCount,
+	}, nil)
+
+	util.MustWrite(w, rutil.OpmlImportPath(opmlImportId))
+}
+
+func Opml_ImportProgress(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	opmlImportIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		util.HttpPanic(http.StatusNotFound, "OPML import not found")
+	}
+	opmlImportId := models.OpmlImportId(opmlImportIdInt)
+	userId, err := models.OpmlImport_GetUserId(pool, opmlImportId)
+	if errors.Is(err, models.ErrOpmlImportNotFound) || (err == nil && userId != currentUser.Id) {
+		util.HttpPanic(http.StatusNotFound, "OPML import not found")
+	} else if err != nil {
+		panic(err)
+	}
+
+	items, err := models.OpmlImportItem_ListProgress(pool, opmlImportId)
+	if err != nil {
+		panic(err)
+	}
+
+	type ItemResult struct {
+		Title      string
+		FeedUrl    string
+		StatusText string
+		IsFailed   bool
+		ActionText string
+		ActionPath string
+	}
+	var itemResults []ItemResult
+	inProgressCount := 0
+	readyCount := 0
+	failedCount := 0
+	for _, item := range items {
+		result := ItemResult{
+			Title:      item.Title,
+			FeedUrl:    item.FeedUrl,
+			StatusText: "",
+			IsFailed:   false,
+			ActionText: "",
+			ActionPath: "",
+		}
+		switch {
+		case item.Status == models.OpmlImportItemStatusPending:
+			result.StatusText = "Checking the feed…"
+			inProgressCount++
+		case item.Status == models.OpmlImportItemStatusFailed:
+			if item.MaybeError != nil {
+				result.StatusText = *item.MaybeError
+			}
+			result.IsFailed = true
+			failedCount++
+		case item.MaybeSubscriptionId == nil:
+			result.StatusText = "Deleted"
+		default:
+			subscriptionId := *item.MaybeSubscriptionId
+			subscriptionStatus := *item.MaybeSubscriptionStatus
+			blogStatus := *item.MaybeBlogStatus
+			switch {
+			case subscriptionStatus == models.SubscriptionStatusLive:
+				result.StatusText = "Subscribed"
+				result.ActionText = "View"
+				result.ActionPath = rutil.SubscriptionPath(subscriptionId)
+				readyCount++
+			case subscriptionStatus == models.SubscriptionStatusSetup:
+				result.StatusText = "Ready"
+				result.ActionText = "Set schedule"
+				result.ActionPath = rutil.SubscriptionSetupPath(subscriptionId)
+				readyCount++
+			case blogStatus == models.BlogStatusCrawlInProgress:
+				result.StatusText = "Looking for all the posts…"
+				inProgressCount++
+			case models.BlogFailedStatuses[blogStatus]:
+				result.StatusText = "This blog isn't supported yet"
+				result.IsFailed = true
+				result.ActionText = "Details"
+				result.ActionPath = rutil.SubscriptionSetupPath(subscriptionId)
+				failedCount++
+			default:
+				result.StatusText = "Posts found"
+				result.ActionText = "Pick posts"
+				result.ActionPath = rutil.SubscriptionSetupPath(subscriptionId)
+				readyCount++
+			}
+		}
+		itemResults = append(itemResults, result)
+	}
+
+	type OpmlImportResult struct {
+		Title           string
+		Session         *util.Session
+		Items           []ItemResult
+		TotalCount      int
+		InProgressCount int
+		ReadyCount      int
+		FailedCount     int
+	}
+	templates.MustWrite(w, "settings/opml_import", OpmlImportResult{
+		Title:           util.DecorateTitle("Import"),
+		Session:         rutil.Session(r),
+		Items:           itemResults,
+		TotalCount:      len(itemResults),
+		InProgressCount: inProgressCount,
+		ReadyCount:      readyCount,
+		FailedCount:     failedCount,
+	})
+}
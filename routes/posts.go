@@ -1,43 +1,184 @@
 package routes
 
 import (
+	"errors"
+	"html/template"
 	"net/http"
+	"strings"
 
+	"feedrewind.com/db/pgw"
+	"feedrewind.com/jobs"
 	"feedrewind.com/models"
 	"feedrewind.com/routes/rutil"
+	"feedrewind.com/templates"
 	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
 )
 
 func Posts_Post(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
 	randomId := models.SubscriptionPostRandomId(util.URLParamStr(r, "random_id"))
 	pool := rutil.DBPool(r)
 	row := pool.QueryRow(`
 		select
+			id,
+			blog_post_id,
 			(select url from blog_posts where blog_posts.id = subscription_posts.blog_post_id),
+			(select title from blog_posts where blog_posts.id = subscription_posts.blog_post_id),
 			subscription_id,
+			(select name from subscriptions_with_discarded where subscriptions_with_discarded.id = subscription_id),
 			(select coalesce(url, feed_url) from blogs where blogs.id = (
 				select blog_id from blog_posts where blog_posts.id = subscription_posts.blog_post_id
 			)),
 			(select product_user_id from users_with_discarded where users_with_discarded.id = (
 				select user_id from subscriptions_with_discarded
 				where subscriptions_with_discarded.id = subscription_id
-			))
+			)),
+			coalesce((select reader_view from user_settings where user_settings.user_id = (
+				select user_id from subscriptions_with_discarded
+				where subscriptions_with_discarded.id = subscription_id
+			)), false),
+			(select adaptive_pacing and pacing_percent < 100 from subscriptions_with_discarded
+				where subscriptions_with_discarded.id = subscription_id)
 		from subscription_posts
 		where random_id = $1
 	`, randomId)
+	var subscriptionPostId models.SubscriptionPostId
+	var blogPostId models.BlogPostId
 	var url string
+	var title string
 	var subscriptionId models.SubscriptionId
+	var subscriptionName string
 	var blogBestUrl string
 	var productUserId models.ProductUserId
-	err := row.Scan(&url, &subscriptionId, &blogBestUrl, &productUserId)
+	var readerView bool
+	var isSlowedDown bool
+	err := row.Scan(
+		&subscriptionPostId, &blogPostId, &url, &title, &subscriptionId, &subscriptionName, &blogBestUrl,
+		&productUserId, &readerView, &isSlowedDown,
+	)
 	if err != nil {
 		panic(err)
 	}
 
-	models.ProductEvent_MustEmit(pool, productUserId, "open post", map[string]any{
-		"subscription_id": subscriptionId,
-		"blog_url":        blogBestUrl,
-	}, nil)
+	if client, ok := posts_PrefetchClient(r); ok {
+		logger.Info().Msgf("Not counting the open by %s", client)
+	} else {
+		posts_MustRecordOpen(pool, subscriptionPostId, subscriptionId, isSlowedDown)
+		models.ProductEvent_MustEmit(pool, productUserId, "open post", map[string]any{
+			"subscription_id": subscriptionId,
+			"blog_url":        blogBestUrl,
+			"reader_view":     readerView,
+		}, nil)
+	}
+
+	if !readerView {
+		http.Redirect(w, r, url, http.StatusSeeOther)
+		return
+	}
+
+	// Articles are extracted in the background when the posts come up, until then it's the original
+	article, err := models.BlogPostArticle_Get(pool, blogPostId)
+	if errors.Is(err, models.ErrBlogPostArticleNotFound) {
+		logger.Info().Msgf("No article yet for blog post %d", blogPostId)
+		http.Redirect(w, r, url, http.StatusSeeOther)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	if article.MaybeContent == nil {
+		http.Redirect(w, r, url, http.StatusSeeOther)
+		return
+	}
+
+	position, err := models.SubscriptionPost_GetPosition(pool, subscriptionId, subscriptionPostId)
+	if err != nil {
+		panic(err)
+	}
+
+	type NeighborResult struct {
+		Title string
+		Url   string
+	}
+	makeNeighbor := func(maybePost *models.PublishedSubscriptionBlogPost) *NeighborResult {
+		if maybePost == nil {
+			return nil
+		}
+		return &NeighborResult{
+			Title: maybePost.Title,
+			Url:   rutil.SubscriptionPostUrl(maybePost.Title, maybePost.RandomId),
+		}
+	}
+
+	type PostResult struct {
+		Title            string
+		Session          *util.Session
+		PostTitle        string
+		OriginalUrl      string
+		SubscriptionName string
+		Content          template.HTML
+		PostNumber       int
+		TotalCount       int
+		MaybePrev        *NeighborResult
+		MaybeNext        *NeighborResult
+	}
+	templates.MustWrite(w, "posts/post", PostResult{
+		Title:            util.DecorateTitle(title),
+		Session:          nil,
+		PostTitle:        title,
+		OriginalUrl:      url,
+		SubscriptionName: subscriptionName,
+		Content:          template.HTML(*article.MaybeContent), // sanitized by the crawler
+		PostNumber:       position.Index + 1,
+		TotalCount:       position.TotalCount,
+		MaybePrev:        makeNeighbor(position.MaybePrev),
+		MaybeNext:        makeNeighbor(position.MaybeNext),
+	})
+}
+
+// Readers that fetch the links ahead of time and link previews would count as opens
+var posts_PrefetchUserAgents = []string{
+	"Slackbot", "Twitterbot", "facebookexternalhit", "Discordbot", "TelegramBot", "WhatsApp", "LinkedInBot",
+	"SkypeUriPreview",
+}
+
+func posts_PrefetchClient(r *http.Request) (string, bool) {
+	if strings.Contains(r.Header.Get("Sec-Purpose"), "prefetch") ||
+		strings.Contains(r.Header.Get("Purpose"), "prefetch") ||
+		r.Header.Get("X-Moz") == "prefetch" {
 
-	http.Redirect(w, r, url, http.StatusSeeOther)
+		return "browser prefetch", true
+	}
+
+	switch client := resolveRssClient(r); client {
+	case "Feedly", "Inoreader":
+		return client, true
+	}
+	userAgent := r.UserAgent()
+	for _, prefetchUserAgent := range posts_PrefetchUserAgents {
+		if strings.Contains(userAgent, prefetchUserAgent) {
+			return prefetchUserAgent, true
+		}
+	}
+	return "", false
+}
+
+// Opens drive adaptive pacing, any of them brings a slowed down subscription back to its schedule
+func posts_MustRecordOpen(
+	pool *pgw.Pool, subscriptionPostId models.SubscriptionPostId, subscriptionId models.SubscriptionId,
+	isSlowedDown bool,
+) {
+	err := models.SubscriptionPost_MarkOpened(pool, subscriptionPostId, schedule.UTCNow())
+	if err != nil {
+		panic(err)
+	}
+	if !isSlowedDown {
+		return
+	}
+
+	err = jobs.ResumePacingJob_PerformNow(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostsPrefetchClient(t *testing.T) {
+	type Test struct {
+		Description    string
+		Headers        map[string]string
+		ExpectedClient string
+		ExpectedOk     bool
+	}
+	tests := []Test{
+		{
+			Description: "browser",
+			Headers: map[string]string{
+				"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36",
+			},
+			ExpectedClient: "",
+			ExpectedOk:     false,
+		},
+		{
+			Description:    "feedly",
+			Headers:        map[string]string{"User-Agent": "Feedly/1.0 (+http://www.feedly.com/fetcher.html)"},
+			ExpectedClient: "Feedly",
+			ExpectedOk:     true,
+		},
+		{
+			Description: "inoreader",
+			Headers: map[string]string{
+				"User-Agent": "Mozilla/5.0 (compatible; inoreader.com; 10 subscribers)",
+			},
+			ExpectedClient: "Inoreader",
+			ExpectedOk:     true,
+		},
+		{
+			Description: "link preview",
+			Headers: map[string]string{
+				"User-Agent": "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			},
+			ExpectedClient: "Slackbot",
+			ExpectedOk:     true,
+		},
+		{
+			Description:    "browser prefetch",
+			Headers:        map[string]string{"Sec-Purpose": "prefetch;prerender"},
+			ExpectedClient: "browser prefetch",
+			ExpectedOk:     true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/posts/post/abc", nil)
+			for name, value := range tc.Headers {
+				r.Header.Set(name, value)
+			}
+			client, ok := posts_PrefetchClient(r)
+			require.Equal(t, tc.ExpectedOk, ok)
+			require.Equal(t, tc.ExpectedClient, client)
+		})
+	}
+}
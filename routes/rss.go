@@ -1,38 +1,57 @@
 package routes
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"feedrewind.com/config"
+	"feedrewind.com/db/pgw"
 	"feedrewind.com/models"
+	"feedrewind.com/publish"
 	"feedrewind.com/routes/rutil"
 	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
 	"github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
 )
 
 func Rss_SubscriptionFeed(w http.ResponseWriter, r *http.Request) {
 	pool := rutil.DBPool(r)
-	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	resolvedFeed, ok := resolveFeed(w, r, false)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	subscriptionId := models.SubscriptionId(subscriptionIdInt)
+	subscriptionId := *resolvedFeed.MaybeSubscriptionId
+	format := resolveFeedFormat(r)
+	if resolvedFeed.MaybeRotatedAt != nil {
+		writeRotatedFeed(w, r, resolvedFeed, format)
+		return
+	}
 	var isPausedOrFinished bool
-	var rss string
+	var updatedAt time.Time
+	var hasAtom bool
+	var hasJson bool
 	var blogBestUrl string
+	var userId models.UserId
 	var productUserId models.ProductUserId
 
 	row := pool.QueryRow(`
 		select
 			(is_paused or (final_item_published_at is not null)),
-			(select body from subscription_rsses where subscription_id = $1),
+			(select updated_at from subscription_rsses where subscription_id = $1),
+			(select atom_body is not null from subscription_rsses where subscription_id = $1),
+			(select json_body is not null from subscription_rsses where subscription_id = $1),
 			(select coalesce(url, feed_url) from blogs where blogs.id = blog_id),
+			user_id,
 			(select product_user_id from users_with_discarded where users_with_discarded.id = user_id)
 		from subscriptions_without_discarded where id = $1
 	`, subscriptionId)
-	err := row.Scan(&isPausedOrFinished, &rss, &blogBestUrl, &productUserId)
+	err := row.Scan(
+		&isPausedOrFinished, &updatedAt, &hasAtom, &hasJson, &blogBestUrl, &userId, &productUserId,
+	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -40,30 +59,51 @@ func Rss_SubscriptionFeed(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
+	if !hasFeedBody(format, hasAtom, hasJson) {
+		writeFeedBodyMissing(w)
+		return
+	}
+
+	writeWebSubLinkHeader(w, r)
+	isNotModified := writeFeedCacheHeaders(w, r, format, updatedAt)
 	if !isPausedOrFinished {
 		productRssClient := resolveRssClient(r)
-		models.ProductEvent_MustEmit(pool, productUserId, "poll feed", map[string]any{
+		emitPollFeed(pool, productUserId, isNotModified, map[string]any{
 			"subscription_id": subscriptionId,
 			"blog_url":        blogBestUrl,
 			"feed_type":       "subscription",
+			"feed_format":     string(format),
 			"client":          productRssClient,
-		}, nil)
+		})
+	}
+	if isNotModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/xml")
-	util.MustWrite(w, rss)
+	maybeBody, err := models.SubscriptionRss_GetBodyByFormat(pool, subscriptionId, format)
+	if err != nil {
+		panic(err)
+	}
+	writeFeed(w, format, *maybeBody)
 }
 
 func Rss_UserFeed(w http.ResponseWriter, r *http.Request) {
 	pool := rutil.DBPool(r)
-	userIdInt, ok := util.URLParamInt64(r, "id")
+	resolvedFeed, ok := resolveFeed(w, r, true)
 	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	userId := models.UserId(userIdInt)
+	userId := *resolvedFeed.MaybeUserId
+	format := resolveFeedFormat(r)
+	if resolvedFeed.MaybeRotatedAt != nil {
+		writeRotatedFeed(w, r, resolvedFeed, format)
+		return
+	}
 	var hasActiveSubscriptions bool
-	var rss string
+	var updatedAt time.Time
+	var hasAtom bool
+	var hasJson bool
 	var productUserId models.ProductUserId
 	row := pool.QueryRow(`
 		select (
@@ -72,13 +112,17 @@ func Rss_UserFeed(w http.ResponseWriter, r *http.Request) {
 				not is_paused and
 				final_item_published_at is null
 		) > 0, (
-			select body from user_rsses where user_id = $1
+			select updated_at from user_rsses where user_id = $1
+		), (
+			select atom_body is not null from user_rsses where user_id = $1
+		), (
+			select json_body is not null from user_rsses where user_id = $1
 		),
 		product_user_id
 		from users_with_discarded
 		where id = $1
 	`, userId)
-	err := row.Scan(&hasActiveSubscriptions, &rss, &productUserId)
+	err := row.Scan(&hasActiveSubscriptions, &updatedAt, &hasAtom, &hasJson, &productUserId)
 	if errors.Is(err, pgx.ErrNoRows) {
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -86,16 +130,196 @@ func Rss_UserFeed(w http.ResponseWriter, r *http.Request) {
 		panic(err)
 	}
 
+	if !hasFeedBody(format, hasAtom, hasJson) {
+		writeFeedBodyMissing(w)
+		return
+	}
+
+	writeWebSubLinkHeader(w, r)
+	isNotModified := writeFeedCacheHeaders(w, r, format, updatedAt)
 	if hasActiveSubscriptions {
 		productRssClient := resolveRssClient(r)
-		models.ProductEvent_MustEmit(pool, productUserId, "poll feed", map[string]any{
-			"feed_type": "user",
-			"client":    productRssClient,
-		}, nil)
+		emitPollFeed(pool, productUserId, isNotModified, map[string]any{
+			"feed_type":   "user",
+			"feed_format": string(format),
+			"client":      productRssClient,
+		})
+	}
+	if isNotModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	maybeBody, err := models.UserRss_GetBodyByFormat(pool, userId, format)
+	if err != nil {
+		panic(err)
+	}
+	writeFeed(w, format, *maybeBody)
+}
+
+// Writes 404 or 410 if the token doesn't lead to a feed
+func resolveFeed(w http.ResponseWriter, r *http.Request, isUserFeed bool) (*models.ResolvedFeed, bool) {
+	pool := rutil.DBPool(r)
+	feedKey := util.URLParamStr(r, "token")
+	resolvedFeed, err := models.FeedToken_Resolve(pool, feedKey, isUserFeed, schedule.UTCNow())
+	if errors.Is(err, models.ErrFeedNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	} else if errors.Is(err, models.ErrFeedGone) {
+		w.WriteHeader(http.StatusGone)
+		return nil, false
+	} else if err != nil {
+		panic(err)
+	}
+	return resolvedFeed, true
+}
+
+// The url extension always wins. Otherwise other formats are only served to the clients that prefer them, as
+// most readers accept everything and have been getting RSS all along.
+func resolveFeedFormat(r *http.Request) models.FeedFormat {
+	switch {
+	case strings.HasSuffix(r.URL.Path, ".atom"):
+		return models.FeedFormatAtom
+	case strings.HasSuffix(r.URL.Path, ".json"):
+		return models.FeedFormatJson
+	}
+
+	atomQuality := 0.0
+	jsonQuality := 0.0
+	rssQuality := 0.0
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		params := strings.Split(mediaRange, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			parsedQuality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err == nil {
+				quality = parsedQuality
+			}
+		}
+		switch mediaType {
+		case "application/atom+xml":
+			atomQuality = max(atomQuality, quality)
+		case "application/feed+json":
+			jsonQuality = max(jsonQuality, quality)
+		case "application/rss+xml", "application/xml", "text/xml":
+			rssQuality = max(rssQuality, quality)
+		}
 	}
+	switch {
+	case jsonQuality > rssQuality && jsonQuality > atomQuality:
+		return models.FeedFormatJson
+	case atomQuality > rssQuality:
+		return models.FeedFormatAtom
+	default:
+		return models.FeedFormatRss
+	}
+}
+
+// False if the feed wasn't published since the format was added
+func hasFeedBody(format models.FeedFormat, hasAtom bool, hasJson bool) bool {
+	switch format {
+	case models.FeedFormatRss:
+		return true
+	case models.FeedFormatAtom:
+		return hasAtom
+	case models.FeedFormatJson:
+		return hasJson
+	default:
+		panic(fmt.Errorf("Unknown feed format: %s", format))
+	}
+}
+
+// Rotated urls only get the notice to resubscribe, and their polls don't count as reading the feed
+func writeRotatedFeed(
+	w http.ResponseWriter, r *http.Request, resolvedFeed *models.ResolvedFeed, format models.FeedFormat,
+) {
+	if writeFeedCacheHeaders(w, r, format, time.Time(*resolvedFeed.MaybeRotatedAt)) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	body, err := publish.GenerateRotatedFeed(util.URLParamStr(r, "token"), resolvedFeed, format)
+	if err != nil {
+		panic(err)
+	}
+	writeFeed(w, format, body)
+}
+
+// Feed bodies are filled in by BackfillFeedBodiesJob, the handlers stay read-only until it gets to them
+func writeFeedBodyMissing(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "60")
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+// WebSub discovery for the clients that don't look inside the feed body
+func writeWebSubLinkHeader(w http.ResponseWriter, r *http.Request) {
+	selfUrl := config.Cfg.RootUrl + r.URL.Path
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="hub", <%s>; rel="self"`, rutil.WebSubHubUrl(), selfUrl))
+}
+
+// Readers poll every few minutes but the feeds change at most a few times a day
+const feedCacheControl = "public, max-age=300"
+
+// Returns true if the client already has the current version and should get 304
+func writeFeedCacheHeaders(
+	w http.ResponseWriter, r *http.Request, format models.FeedFormat, updatedAt time.Time,
+) bool {
+	lastModified := updatedAt.UTC().Truncate(time.Second)
+	etag := makeFeedETag(format, updatedAt)
+	if !strings.HasSuffix(r.URL.Path, ".atom") && !strings.HasSuffix(r.URL.Path, ".json") {
+		w.Header().Set("Vary", "Accept")
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", feedCacheControl)
+
+	// If-None-Match takes precedence when both are present
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince := r.Header.Get("If-Modified-Since"); ifModifiedSince != "" {
+		modifiedSince, err := http.ParseTime(ifModifiedSince)
+		if err != nil {
+			return false
+		}
+		return !lastModified.After(modifiedSince)
+	}
+	return false
+}
+
+// The same updated_at is shared by all formats, so the format goes into the tag too
+func makeFeedETag(format models.FeedFormat, updatedAt time.Time) string {
+	return fmt.Sprintf(`"%s-%s"`, strconv.FormatInt(updatedAt.UnixMicro(), 36), format)
+}
+
+// Not modified polls are counted in memory to not write to the db on every one of them
+func emitPollFeed(
+	pool *pgw.Pool, productUserId models.ProductUserId, isNotModified bool, eventProperties map[string]any,
+) {
+	if isNotModified {
+		eventProperties["not_modified"] = true
+		err := models.ProductEvent_QueueCounted(productUserId, "poll feed", eventProperties)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+	models.ProductEvent_MustEmit(pool, productUserId, "poll feed", eventProperties, nil)
+}
 
-	w.Header().Set("Content-Type", "application/xml")
-	util.MustWrite(w, rss)
+func writeFeed(w http.ResponseWriter, format models.FeedFormat, body string) {
+	w.Header().Set("Content-Type", format.ContentType())
+	util.MustWrite(w, body)
 }
 
 func resolveRssClient(r *http.Request) string {
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"feedrewind.com/models"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFeedFormat(t *testing.T) {
+	type Test struct {
+		Description    string
+		Path           string
+		Accept         string
+		ExpectedFormat models.FeedFormat
+	}
+	tests := []Test{
+		{
+			Description:    "rss by default",
+			Path:           "/feeds/abc",
+			Accept:         "",
+			ExpectedFormat: models.FeedFormatRss,
+		},
+		{
+			Description:    "rss for readers that accept everything",
+			Path:           "/feeds/abc",
+			Accept:         "*/*",
+			ExpectedFormat: models.FeedFormatRss,
+		},
+		{
+			Description:    "atom extension",
+			Path:           "/feeds/abc.atom",
+			Accept:         "application/rss+xml",
+			ExpectedFormat: models.FeedFormatAtom,
+		},
+		{
+			Description:    "json extension",
+			Path:           "/feeds/abc.json",
+			Accept:         "application/atom+xml",
+			ExpectedFormat: models.FeedFormatJson,
+		},
+		{
+			Description:    "atom accept",
+			Path:           "/feeds/abc",
+			Accept:         "application/atom+xml",
+			ExpectedFormat: models.FeedFormatAtom,
+		},
+		{
+			Description:    "json accept",
+			Path:           "/feeds/abc",
+			Accept:         "application/feed+json",
+			ExpectedFormat: models.FeedFormatJson,
+		},
+		{
+			Description:    "rss wins a tie",
+			Path:           "/feeds/abc",
+			Accept:         "application/atom+xml, application/rss+xml",
+			ExpectedFormat: models.FeedFormatRss,
+		},
+		{
+			Description:    "higher quality atom",
+			Path:           "/feeds/abc",
+			Accept:         "application/rss+xml;q=0.5, application/atom+xml;q=0.9",
+			ExpectedFormat: models.FeedFormatAtom,
+		},
+		{
+			Description:    "lower quality atom",
+			Path:           "/feeds/abc",
+			Accept:         "application/atom+xml;q=0.5, application/xml",
+			ExpectedFormat: models.FeedFormatRss,
+		},
+		{
+			Description:    "json over atom",
+			Path:           "/feeds/abc",
+			Accept:         "application/atom+xml; q=0.8, application/feed+json; q=0.9, text/xml; q=0.1",
+			ExpectedFormat: models.FeedFormatJson,
+		},
+		{
+			Description:    "ignore other params and case",
+			Path:           "/feeds/abc",
+			Accept:         "Application/Atom+XML; charset=utf-8",
+			ExpectedFormat: models.FeedFormatAtom,
+		},
+		{
+			Description:    "ignore bad quality",
+			Path:           "/feeds/abc",
+			Accept:         "application/atom+xml;q=abc, application/rss+xml;q=0.5",
+			ExpectedFormat: models.FeedFormatAtom,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.Path, nil)
+			if tc.Accept != "" {
+				r.Header.Set("Accept", tc.Accept)
+			}
+			require.Equal(t, tc.ExpectedFormat, resolveFeedFormat(r))
+		})
+	}
+}
+
+func TestMakeFeedETag(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 12, 0, 0, 123456000, time.UTC)
+	rssETag := makeFeedETag(models.FeedFormatRss, updatedAt)
+	require.Equal(t, rssETag, makeFeedETag(models.FeedFormatRss, updatedAt))
+	require.NotEqual(t, rssETag, makeFeedETag(models.FeedFormatAtom, updatedAt))
+	require.NotEqual(t, rssETag, makeFeedETag(models.FeedFormatRss, updatedAt.Add(time.Microsecond)))
+	require.Regexp(t, `^"[0-9a-z]+-rss"$`, rssETag)
+}
+
+func TestWriteFeedCacheHeaders(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 19, 12, 0, 0, 500000000, time.UTC)
+	etag := makeFeedETag(models.FeedFormatRss, updatedAt)
+	otherETag := makeFeedETag(models.FeedFormatRss, updatedAt.Add(-time.Hour))
+
+	type Test struct {
+		Description         string
+		IfNoneMatch         string
+		IfModifiedSince     string
+		ExpectedNotModified bool
+	}
+	tests := []Test{
+		{
+			Description:         "no conditions",
+			IfNoneMatch:         "",
+			IfModifiedSince:     "",
+			ExpectedNotModified: false,
+		},
+		{
+			Description:         "matching etag",
+			IfNoneMatch:         etag,
+			IfModifiedSince:     "",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "weak matching etag",
+			IfNoneMatch:         "W/" + etag,
+			IfModifiedSince:     "",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "matching etag in a list",
+			IfNoneMatch:         otherETag + ", " + etag,
+			IfModifiedSince:     "",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "star",
+			IfNoneMatch:         "*",
+			IfModifiedSince:     "",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "stale etag",
+			IfNoneMatch:         otherETag,
+			IfModifiedSince:     "",
+			ExpectedNotModified: false,
+		},
+		{
+			Description:         "etag of another format",
+			IfNoneMatch:         makeFeedETag(models.FeedFormatAtom, updatedAt),
+			IfModifiedSince:     "",
+			ExpectedNotModified: false,
+		},
+		{
+			Description:         "stale etag wins over fresh date",
+			IfNoneMatch:         otherETag,
+			IfModifiedSince:     "Mon, 19 Oct 2026 13:00:00 GMT",
+			ExpectedNotModified: false,
+		},
+		{
+			Description:         "same second",
+			IfNoneMatch:         "",
+			IfModifiedSince:     "Mon, 19 Oct 2026 12:00:00 GMT",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "later date",
+			IfNoneMatch:         "",
+			IfModifiedSince:     "Mon, 19 Oct 2026 13:00:00 GMT",
+			ExpectedNotModified: true,
+		},
+		{
+			Description:         "earlier date",
+			IfNoneMatch:         "",
+			IfModifiedSince:     "Mon, 19 Oct 2026 11:59:59 GMT",
+			ExpectedNotModified: false,
+		},
+		{
+			Description:         "bad date",
+			IfNoneMatch:         "",
+			IfModifiedSince:     "yesterday",
+			ExpectedNotModified: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/feeds/abc", nil)
+			if tc.IfNoneMatch != "" {
+				r.Header.Set("If-None-Match", tc.IfNoneMatch)
+			}
+			if tc.IfModifiedSince != "" {
+				r.Header.Set("If-Modified-Since", tc.IfModifiedSince)
+			}
+			w := httptest.NewRecorder()
+			isNotModified := writeFeedCacheHeaders(w, r, models.FeedFormatRss, updatedAt)
+			require.Equal(t, tc.ExpectedNotModified, isNotModified)
+			require.Equal(t, etag, w.Header().Get("ETag"))
+			require.Equal(t, "Mon, 19 Oct 2026 12:00:00 GMT", w.Header().Get("Last-Modified"))
+			require.Equal(t, "Accept", w.Header().Get("Vary"))
+		})
+	}
+
+	t.Run("no vary for extensions", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/feeds/abc.atom", nil)
+		w := httptest.NewRecorder()
+		writeFeedCacheHeaders(w, r, models.FeedFormatAtom, updatedAt)
+		require.Equal(t, "", w.Header().Get("Vary"))
+	})
+}
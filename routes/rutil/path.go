@@ -47,6 +47,10 @@ func SubscriptionNotifyWhenSupportedPath(subscriptionId models.SubscriptionId) s
 	return fmt.Sprintf("/subscriptions/%d/notify_when_supported", subscriptionId)
 }
 
+func SubscriptionCustomBlogPath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/custom_blog", subscriptionId)
+}
+
 func SubscriptionAddFeedPath(feedUrl string) string {
 	return util.SubscriptionAddFeedPath(feedUrl)
 }
@@ -63,6 +67,26 @@ func SubscriptionUnpausePath(subscriptionId models.SubscriptionId) string {
 	return fmt.Sprintf("/subscriptions/%d/unpause", subscriptionId)
 }
 
+func SubscriptionPostContentPath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/post_content", subscriptionId)
+}
+
+func SubscriptionAdaptivePacingPath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/adaptive_pacing", subscriptionId)
+}
+
+func SubscriptionResumePacingPath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/resume_pacing", subscriptionId)
+}
+
+func SubscriptionDeliverNowPath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/deliver_now", subscriptionId)
+}
+
+func SubscriptionQueuePath(subscriptionId models.SubscriptionId) string {
+	return fmt.Sprintf("/subscriptions/%d/queue", subscriptionId)
+}
+
 func SubscriptionUrl(subscriptionId models.SubscriptionId) string {
 	return fmt.Sprintf("%s/subscriptions/%d", config.Cfg.RootUrl, subscriptionId)
 }
@@ -80,13 +104,96 @@ func SubscriptionProgressStreamUrl(r *http.Request, subscriptionId models.Subscr
 	return fmt.Sprintf("%s://%s%s/subscriptions/%d/progress_stream", proto, host, port, subscriptionId)
 }
 
-func SubscriptionFeedUrl(r *http.Request, subscriptionId models.SubscriptionId) string {
+func SubscriptionFeedUrl(r *http.Request, feedToken models.FeedToken) string {
 	proto := "http"
 	if !config.Cfg.Env.IsDevOrTest() {
 		proto = "https"
 	}
 	host, port := parseHostPort(r)
-	return fmt.Sprintf("%s://%s%s/feeds/%d", proto, host, port, subscriptionId)
+	return fmt.Sprintf("%s://%s%s/feeds/%s", proto, host, port, feedToken)
+}
+
+func UserFeedUrl(r *http.Request, feedToken models.FeedToken) string {
+	proto := "http"
+	if !config.Cfg.Env.IsDevOrTest() {
+		proto = "https"
+	}
+	host, port := parseHostPort(r)
+	return fmt.Sprintf("%s://%s%s/feeds/single/%s", proto, host, port, feedToken)
+}
+
+// Feeds link to themselves, so the url can't depend on the request
+func SubscriptionRssFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/%s", config.Cfg.RootUrl, feedToken)
+}
+
+func UserRssFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/single/%s", config.Cfg.RootUrl, feedToken)
+}
+
+func SubscriptionAtomFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/%s.atom", config.Cfg.RootUrl, feedToken)
+}
+
+func UserAtomFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/single/%s.atom", config.Cfg.RootUrl, feedToken)
+}
+
+func SubscriptionJsonFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/%s.json", config.Cfg.RootUrl, feedToken)
+}
+
+func UserJsonFeedUrl(feedToken models.FeedToken) string {
+	return fmt.Sprintf("%s/feeds/single/%s.json", config.Cfg.RootUrl, feedToken)
+}
+
+func SettingsUrl() string {
+	return config.Cfg.RootUrl + "/settings"
+}
+
+func OpmlImportPath(opmlImportId models.OpmlImportId) string {
+	return fmt.Sprintf("/settings/opml_imports/%d", opmlImportId)
+}
+
+func VacationDeletePath(vacationId models.VacationId) string {
+	return fmt.Sprintf("/settings/vacations/%d/delete", vacationId)
+}
+
+func WebSubHubUrl() string {
+	return config.Cfg.RootUrl + "/websub"
+}
+
+type FeedTopic struct {
+	IsUserFeed bool
+	FeedKey    string // token, or the id for the legacy urls
+	Format     models.FeedFormat
+}
+
+var feedTopicRegex = regexp.MustCompile(`^/feeds/(single/)?([A-Za-z0-9_-]+)(\.atom|\.json)?$`)
+
+// The inverse of the feed urls above, for the urls that WebSub subscribers send back
+func ParseFeedTopic(topic string) (*FeedTopic, bool) {
+	path, ok := strings.CutPrefix(topic, config.Cfg.RootUrl)
+	if !ok {
+		return nil, false
+	}
+	match := feedTopicRegex.FindStringSubmatch(path)
+	if match == nil {
+		return nil, false
+	}
+
+	format := models.FeedFormatRss
+	switch match[3] {
+	case ".atom":
+		format = models.FeedFormatAtom
+	case ".json":
+		format = models.FeedFormatJson
+	}
+	return &FeedTopic{
+		IsUserFeed: match[1] != "",
+		FeedKey:    match[2],
+		Format:     format,
+	}, true
 }
 
 func parseHostPort(r *http.Request) (host, port string) {
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"feedrewind.com/models"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -61,3 +63,76 @@ func TestPostSlug(t *testing.T) {
 		require.Equal(t, tc.ExpectedSlug, slug, tc.Description)
 	}
 }
+
+func TestParseFeedTopic(t *testing.T) {
+	feedToken := models.FeedToken("AbC-12_x")
+
+	type Test struct {
+		Description    string
+		Topic          string
+		ExpectedTopic  *FeedTopic
+		ExpectedParsed bool
+	}
+	tests := []Test{
+		{
+			Description: "subscription rss",
+			Topic:       SubscriptionRssFeedUrl(feedToken),
+			ExpectedTopic: &FeedTopic{
+				IsUserFeed: false,
+				FeedKey:    string(feedToken),
+				Format:     models.FeedFormatRss,
+			},
+			ExpectedParsed: true,
+		},
+		{
+			Description: "subscription atom",
+			Topic:       SubscriptionAtomFeedUrl(feedToken),
+			ExpectedTopic: &FeedTopic{
+				IsUserFeed: false,
+				FeedKey:    string(feedToken),
+				Format:     models.FeedFormatAtom,
+			},
+			ExpectedParsed: true,
+		},
+		{
+			Description: "user json",
+			Topic:       UserJsonFeedUrl(feedToken),
+			ExpectedTopic: &FeedTopic{
+				IsUserFeed: true,
+				FeedKey:    string(feedToken),
+				Format:     models.FeedFormatJson,
+			},
+			ExpectedParsed: true,
+		},
+		{
+			Description: "legacy numeric",
+			Topic:       "http://localhost:3000/feeds/single/34",
+			ExpectedTopic: &FeedTopic{
+				IsUserFeed: true,
+				FeedKey:    "34",
+				Format:     models.FeedFormatRss,
+			},
+			ExpectedParsed: true,
+		},
+		{
+			Description:    "other host",
+			Topic:          "https://example.com/feeds/12",
+			ExpectedTopic:  nil,
+			ExpectedParsed: false,
+		},
+		{
+			Description:    "other path",
+			Topic:          SubscriptionUrl(12),
+			ExpectedTopic:  nil,
+			ExpectedParsed: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Description, func(t *testing.T) {
+			topic, ok := ParseFeedTopic(tc.Topic)
+			require.Equal(t, tc.ExpectedParsed, ok)
+			require.Equal(t, tc.ExpectedTopic, topic)
+		})
+	}
+}
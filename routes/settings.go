@@ -1,17 +1,24 @@
 package routes
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"feedrewind.com/jobs"
 	"feedrewind.com/models"
+	"feedrewind.com/publish"
 	"feedrewind.com/routes/rutil"
 	"feedrewind.com/templates"
 	"feedrewind.com/third_party/tzdata"
 	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
 )
 
 func UserSettings_Page(w http.ResponseWriter, r *http.Request) {
@@ -48,6 +55,135 @@ func UserSettings_Page(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	type FeedLinkResult struct {
+		Name           string
+		Url            string
+		SubscriptionId string // empty for the single feed
+	}
+	userFeedToken, err := models.FeedToken_GetForUser(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	feedLinks := []FeedLinkResult{{
+		Name:           "Single feed",
+		Url:            rutil.UserFeedUrl(r, userFeedToken),
+		SubscriptionId: "",
+	}}
+	subscriptions, err := models.Subscription_ListSortedToPublish(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	for _, subscription := range subscriptions {
+		feedLinks = append(feedLinks, FeedLinkResult{
+			Name:           subscription.Name,
+			Url:            rutil.SubscriptionFeedUrl(r, subscription.FeedToken),
+			SubscriptionId: fmt.Sprint(subscription.Id),
+		})
+	}
+	hasLegacyFeedUrls, err := models.FeedToken_UserHasLegacyUrls(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+
+	// Single and multiple feeds only differ in what the onboarding suggested, both get all the feeds
+	feedDeliveryChannel := models.DeliveryChannelMultipleFeeds
+	isEmailDelivery := false
+	isWebhookDelivery := false
+	if userSettings.MaybeDeliveryChannel != nil {
+		switch *userSettings.MaybeDeliveryChannel {
+		case models.DeliveryChannelSingleFeed:
+			feedDeliveryChannel = models.DeliveryChannelSingleFeed
+		case models.DeliveryChannelEmail:
+			isEmailDelivery = true
+		case models.DeliveryChannelWebhook:
+			isWebhookDelivery = true
+		}
+	}
+	emailBounceMessage := ""
+	if userSettings.MaybeEmailBouncedAt != nil {
+		emailBounceMessage = userSettings.EmailBounceMessage
+	}
+
+	type WebhookDeliveryResult struct {
+		CreatedAt  string
+		PostsCount int
+		Status     models.WebhookDeliveryStatus
+		Attempts   int
+		Detail     string
+	}
+	hasWebhook := false
+	webhookUrl := ""
+	webhookSecret := ""
+	webhookDisabledAt := ""
+	var webhookDeliveries []WebhookDeliveryResult
+	webhook, err := models.Webhook_Get(pool, currentUser.Id)
+	if errors.Is(err, models.ErrWebhookNotFound) {
+		// no-op
+	} else if err != nil {
+		panic(err)
+	} else {
+		location := tzdata.LocationByName[userSettings.Timezone]
+		hasWebhook = true
+		webhookUrl = webhook.Url
+		webhookSecret = webhook.Secret
+		if webhook.MaybeDisabledAt != nil {
+			webhookDisabledAt = webhook.MaybeDisabledAt.In(location).Format("January 2")
+		}
+		deliveries, err := models.WebhookDelivery_ListRecent(pool, currentUser.Id, 20)
+		if err != nil {
+			panic(err)
+		}
+		for _, delivery := range deliveries {
+			detail := ""
+			if delivery.MaybeLastError != nil {
+				detail = *delivery.MaybeLastError
+			} else if delivery.MaybeLastResponseCode != nil {
+				detail = fmt.Sprint(*delivery.MaybeLastResponseCode)
+			}
+			webhookDeliveries = append(webhookDeliveries, WebhookDeliveryResult{
+				CreatedAt:  delivery.CreatedAt.In(location).Format("Jan 2, 15:04"),
+				PostsCount: delivery.PostsCount,
+				Status:     delivery.Status,
+				Attempts:   delivery.Attempts,
+				Detail:     detail,
+			})
+		}
+	}
+
+	// The default time follows the delivery channel, before a channel is picked it's the feed one
+	defaultDeliveryChannel := models.DeliveryChannelMultipleFeeds
+	if userSettings.MaybeDeliveryChannel != nil {
+		defaultDeliveryChannel = *userSettings.MaybeDeliveryChannel
+	}
+	defaultDeliveryTime := fmt.Sprintf("%02d:00", jobs.PublishPostsJob_GetHourOfDay(defaultDeliveryChannel))
+	deliveryTime := ""
+	if userSettings.MaybeDeliveryMinutes != nil {
+		deliveryMinutes := *userSettings.MaybeDeliveryMinutes
+		deliveryTime = fmt.Sprintf("%02d:%02d", deliveryMinutes/60, deliveryMinutes%60)
+	}
+
+	type VacationResult struct {
+		Dates      string
+		DeletePath string
+	}
+	location := tzdata.LocationByName[userSettings.Timezone]
+	todayDate := schedule.UTCNow().In(location).Date()
+	vacations, err := models.Vacation_ListEndingFrom(pool, currentUser.Id, todayDate)
+	if err != nil {
+		panic(err)
+	}
+	var vacationResults []VacationResult
+	for _, vacation := range vacations {
+		dates := vacation.StartDate.Time().Format("January 2")
+		if vacation.EndDate != vacation.StartDate {
+			dates += " – " + vacation.EndDate.Time().Format("January 2")
+		}
+		vacationResults = append(vacationResults, VacationResult{
+			Dates:      dates,
+			DeletePath: rutil.VacationDeletePath(vacation.Id),
+		})
+	}
+
 	type SettingsResult struct {
 		Title                                string
 		Session                              *util.Session
@@ -55,6 +191,26 @@ func UserSettings_Page(w http.ResponseWriter, r *http.Request) {
 		Version                              int
 		ShortFriendlyPrefixNameByGroupIdJson template.JS
 		GroupIdByTimezoneIdJson              template.JS
+		ReaderView                           bool
+		FeedLinks                            []FeedLinkResult
+		HasLegacyFeedUrls                    bool
+		LegacyFeedUrls                       bool
+		FeedDeliveryChannel                  models.DeliveryChannel
+		IsEmailDelivery                      bool
+		IsWebhookDelivery                    bool
+		Email                                string
+		EmailBounceMessage                   string
+		HasWebhook                           bool
+		WebhookUrl                           string
+		WebhookSecret                        string
+		WebhookDisabledAt                    string
+		WebhookDeliveries                    []WebhookDeliveryResult
+		DeliveryTime                         string
+		DefaultDeliveryTime                  string
+		Vacations                            []VacationResult
+		TodayDate                            schedule.Date
+		CatchUpAfterVacation                 bool
+		MaxCatchUpDays                       int
 	}
 	templates.MustWrite(w, "settings/settings", SettingsResult{
 		Title:                                util.DecorateTitle("Settings"),
@@ -63,6 +219,26 @@ func UserSettings_Page(w http.ResponseWriter, r *http.Request) {
 		Version:                              userSettings.Version,
 		ShortFriendlyPrefixNameByGroupIdJson: util.ShortFriendlyPrefixNameByGroupIdJson,
 		GroupIdByTimezoneIdJson:              util.GroupIdByTimezoneIdJson,
+		ReaderView:                           userSettings.ReaderView,
+		FeedLinks:                            feedLinks,
+		HasLegacyFeedUrls:                    hasLegacyFeedUrls,
+		LegacyFeedUrls:                       userSettings.LegacyFeedUrls,
+		FeedDeliveryChannel:                  feedDeliveryChannel,
+		IsEmailDelivery:                      isEmailDelivery,
+		IsWebhookDelivery:                    isWebhookDelivery,
+		Email:                                currentUser.Email,
+		EmailBounceMessage:                   emailBounceMessage,
+		HasWebhook:                           hasWebhook,
+		WebhookUrl:                           webhookUrl,
+		WebhookSecret:                        webhookSecret,
+		WebhookDisabledAt:                    webhookDisabledAt,
+		WebhookDeliveries:                    webhookDeliveries,
+		DeliveryTime:                         deliveryTime,
+		DefaultDeliveryTime:                  defaultDeliveryTime,
+		Vacations:                            vacationResults,
+		TodayDate:                            todayDate,
+		CatchUpAfterVacation:                 userSettings.CatchUpAfterVacation,
+		MaxCatchUpDays:                       models.VacationMaxCatchUpDays,
 	})
 }
 
@@ -130,12 +306,7 @@ func UserSettings_SaveTimezone(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				panic(err)
 			}
-			jobTime, err := jobDate.TimeIn(newLocation)
-			if err != nil {
-				panic(err)
-			}
-			newHour := jobs.PublishPostsJob_GetHourOfDay(*oldUserSettings.MaybeDeliveryChannel)
-			newRunAt := jobTime.Add(time.Duration(newHour) * time.Hour).UTC()
+			newRunAt := jobs.PublishPostsJob_GetRunAt(jobDate, newLocation, oldUserSettings)
 			err = jobs.PublishPostsJob_UpdateRunAt(tx, job.Id, newRunAt)
 			if err != nil {
 				panic(err)
@@ -169,3 +340,446 @@ func UserSettings_SaveTimezone(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func UserSettings_SaveDeliveryChannel(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	newDeliveryChannel := models.DeliveryChannel(util.EnsureParamStr(r, "delivery_channel"))
+	newVersion := util.EnsureParamInt(r, "version")
+	currentUser := rutil.CurrentUser(r)
+	switch newDeliveryChannel {
+	case models.DeliveryChannelSingleFeed, models.DeliveryChannelMultipleFeeds, models.DeliveryChannelEmail:
+	case models.DeliveryChannelWebhook:
+		_, err := models.Webhook_Get(pool, currentUser.Id)
+		if errors.Is(err, models.ErrWebhookNotFound) {
+			util.HttpPanic(http.StatusBadRequest, "Webhook url is not set")
+		} else if err != nil {
+			panic(err)
+		}
+	default:
+		util.HttpPanic(http.StatusBadRequest, "Unknown delivery channel")
+	}
+
+	// Same as with the timezone, the hour of the daily job depends on the channel
+	mustSaveDeliveryChannel := func() (result bool) {
+		tx, err := pool.Begin()
+		if err != nil {
+			panic(err)
+		}
+		defer util.CommitOrRollbackMsg(tx, &result, "Unlocked PublishPostsJob")
+
+		logger.Info().Msg("Locking PublishPostsJob")
+		lockedJobs, err := jobs.PublishPostsJob_Lock(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		logger.Info().Msgf("Locked PublishPostsJob %d", len(lockedJobs))
+
+		for _, job := range lockedJobs {
+			if job.LockedBy != "" {
+				logger.Info().Msgf("Some jobs are running, unlocking %d", len(lockedJobs))
+				return false
+			}
+		}
+
+		oldUserSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		if !((oldUserSettings.MaybeDeliveryChannel != nil && len(lockedJobs) == 1) ||
+			(oldUserSettings.MaybeDeliveryChannel == nil && len(lockedJobs) == 0)) {
+			logger.Warn().Msgf("Unexpected amount of job rows for the user: %d", len(lockedJobs))
+			return false
+		}
+
+		if oldUserSettings.Version >= newVersion {
+			logger.Info().Msgf("Version conflict: existing %d, new %d", oldUserSettings.Version, newVersion)
+			rutil.MustWriteJson(w, http.StatusConflict, map[string]any{
+				"version": oldUserSettings.Version,
+			})
+			return true
+		}
+
+		if newDeliveryChannel == models.DeliveryChannelEmail {
+			err = models.UserSettings_SaveDeliveryChannelVersionClearBounce(
+				tx, currentUser.Id, newDeliveryChannel, newVersion,
+			)
+		} else {
+			err = models.UserSettings_SaveDeliveryChannelVersion(
+				tx, currentUser.Id, newDeliveryChannel, newVersion,
+			)
+		}
+		if err != nil {
+			panic(err)
+		}
+		newUserSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+
+		if len(lockedJobs) == 1 {
+			job := lockedJobs[0]
+			jobDate, err := jobs.PublishPostsJob_GetNextScheduledDate(tx, currentUser.Id)
+			if err != nil {
+				panic(err)
+			}
+			location := tzdata.LocationByName[oldUserSettings.Timezone]
+			newRunAt := jobs.PublishPostsJob_GetRunAt(jobDate, location, newUserSettings)
+			err = jobs.PublishPostsJob_UpdateRunAt(tx, job.Id, newRunAt)
+			if err != nil {
+				panic(err)
+			}
+			logger.Info().Msgf("Rescheduled PublishPostsJob for %s", newRunAt)
+		} else {
+			err := jobs.PublishPostsJob_ScheduleInitial(tx, currentUser.Id, newUserSettings, false)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		// Posts that went out by email didn't make it into the feeds
+		oldDeliveryChannel := oldUserSettings.MaybeDeliveryChannel
+		if oldDeliveryChannel != nil && *oldDeliveryChannel == models.DeliveryChannelEmail &&
+			newDeliveryChannel != models.DeliveryChannelEmail {
+			err := publish.RegenerateFeeds(tx, currentUser.Id)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+		models.ProductEvent_MustEmitFromRequest(pc, "pick delivery channel", map[string]any{
+			"channel":     newDeliveryChannel,
+			"old_channel": oldDeliveryChannel,
+		}, map[string]any{
+			"delivery_channel": newDeliveryChannel,
+		})
+
+		w.WriteHeader(http.StatusOK)
+		return true
+	}
+
+	failedLockAttempts := 0
+	for {
+		if failedLockAttempts >= 3 {
+			panic("Couldn't lock the job rows")
+		} else if failedLockAttempts > 0 {
+			time.Sleep(time.Second)
+		}
+
+		if mustSaveDeliveryChannel() {
+			break
+		} else {
+			failedLockAttempts++
+		}
+	}
+}
+
+func UserSettings_SaveDeliveryTime(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	var maybeDeliveryMinutes *int
+	if deliveryTime := util.EnsureParamStr(r, "delivery_time"); deliveryTime != "" {
+		parsedTime, err := time.Parse("15:04", deliveryTime)
+		if err != nil {
+			util.HttpPanic(http.StatusBadRequest, "Bad delivery time")
+		}
+		deliveryMinutes := parsedTime.Hour()*60 + parsedTime.Minute()
+		maybeDeliveryMinutes = &deliveryMinutes
+	}
+
+	// Same as with the timezone, the daily job moves to the new time. If the new time has already passed for
+	// the date the job is scheduled for, that date's posts go out right away rather than getting skipped.
+	mustSaveDeliveryTime := func() (result bool) {
+		tx, err := pool.Begin()
+		if err != nil {
+			panic(err)
+		}
+		defer util.CommitOrRollbackMsg(tx, &result, "Unlocked PublishPostsJob")
+
+		logger.Info().Msg("Locking PublishPostsJob")
+		lockedJobs, err := jobs.PublishPostsJob_Lock(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		logger.Info().Msgf("Locked PublishPostsJob %d", len(lockedJobs))
+
+		for _, job := range lockedJobs {
+			if job.LockedBy != "" {
+				logger.Info().Msgf("Some jobs are running, unlocking %d", len(lockedJobs))
+				return false
+			}
+		}
+
+		oldUserSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		if !((oldUserSettings.MaybeDeliveryChannel != nil && len(lockedJobs) == 1) ||
+			(oldUserSettings.MaybeDeliveryChannel == nil && len(lockedJobs) == 0)) {
+			logger.Warn().Msgf("Unexpected amount of job rows for the user: %d", len(lockedJobs))
+			return false
+		}
+
+		err = models.UserSettings_SaveDeliveryMinutes(tx, currentUser.Id, maybeDeliveryMinutes)
+		if err != nil {
+			panic(err)
+		}
+		newUserSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+
+		if len(lockedJobs) == 1 {
+			job := lockedJobs[0]
+			jobDate, err := jobs.PublishPostsJob_GetNextScheduledDate(tx, currentUser.Id)
+			if err != nil {
+				panic(err)
+			}
+			location := tzdata.LocationByName[newUserSettings.Timezone]
+			newRunAt := jobs.PublishPostsJob_GetRunAt(jobDate, location, newUserSettings)
+			err = jobs.PublishPostsJob_UpdateRunAt(tx, job.Id, newRunAt)
+			if err != nil {
+				panic(err)
+			}
+			logger.Info().Msgf("Rescheduled PublishPostsJob for %s", newRunAt)
+		}
+
+		var deliveryMinutes any
+		if maybeDeliveryMinutes != nil {
+			deliveryMinutes = *maybeDeliveryMinutes
+		}
+		pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+		models.ProductEvent_MustEmitFromRequest(pc, "update delivery time", map[string]any{
+			"delivery_minutes": deliveryMinutes,
+		}, nil)
+
+		w.WriteHeader(http.StatusOK)
+		return true
+	}
+
+	failedLockAttempts := 0
+	for {
+		if failedLockAttempts >= 3 {
+			panic("Couldn't lock the job rows")
+		} else if failedLockAttempts > 0 {
+			time.Sleep(time.Second)
+		}
+
+		if mustSaveDeliveryTime() {
+			break
+		} else {
+			failedLockAttempts++
+		}
+	}
+}
+
+func UserSettings_SaveWebhook(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	webhookUrl := strings.TrimSpace(util.EnsureParamStr(r, "webhook_url"))
+	parsedUrl, err := url.Parse(webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" ||
+		len(webhookUrl) > 2000 {
+		util.HttpPanic(http.StatusBadRequest, "Bad webhook url")
+	}
+	if !util.IsPublicHost(parsedUrl.Hostname()) {
+		util.HttpPanic(http.StatusBadRequest, "Webhook url must be on the public internet")
+	}
+	currentUser := rutil.CurrentUser(r)
+	err = models.Webhook_Upsert(pool, currentUser.Id, webhookUrl)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "save webhook", nil, nil)
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func UserSettings_RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	err := models.Webhook_RotateSecret(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "rotate webhook secret", nil, nil)
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func UserSettings_AddVacation(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	startDateStr := util.EnsureParamStr(r, "start_date")
+	endDateStr := startDateStr
+	if maybeEndDateStr, ok := util.MaybeParamStr(r, "end_date"); ok && maybeEndDateStr != "" {
+		endDateStr = maybeEndDateStr
+	}
+	startTime, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		util.HttpPanic(http.StatusBadRequest, "Bad start_date")
+	}
+	endTime, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		util.HttpPanic(http.StatusBadRequest, "Bad end_date")
+	}
+	startDate := schedule.Date(startDateStr)
+	endDate := schedule.Date(endDateStr)
+
+	userSettings, err := models.UserSettings_Get(pool, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	location := tzdata.LocationByName[userSettings.Timezone]
+	todayDate := schedule.UTCNow().In(location).Date()
+	if startDate < todayDate {
+		util.HttpPanic(http.StatusBadRequest, "Vacation can't start in the past")
+	}
+	if endDate < startDate {
+		util.HttpPanic(http.StatusBadRequest, "Vacation can't end before it starts")
+	}
+	if endTime.Sub(startTime) >= models.VacationMaxDays*24*time.Hour {
+		util.HttpPanic(http.StatusBadRequest, "Vacation is too long")
+	}
+
+	err = models.Vacation_Create(pool, currentUser.Id, startDate, endDate)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "add vacation", map[string]any{
+		"start_date": startDate,
+		"end_date":   endDate,
+	}, nil)
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func UserSettings_DeleteVacation(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	vacationIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		util.HttpPanic(http.StatusBadRequest, "Bad vacation id")
+	}
+	vacationId := models.VacationId(vacationIdInt)
+	ok, err := models.Vacation_Delete(pool, currentUser.Id, vacationId)
+	if err != nil {
+		panic(err)
+	}
+	if !ok {
+		util.HttpPanic(http.StatusNotFound, "Vacation not found")
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "delete vacation", nil, nil)
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func UserSettings_SaveCatchUpAfterVacation(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	catchUpAfterVacation := util.EnsureParamBool(r, "catch_up_after_vacation")
+	currentUser := rutil.CurrentUser(r)
+	err := models.UserSettings_SaveCatchUpAfterVacation(pool, currentUser.Id, catchUpAfterVacation)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "update catch up after vacation", map[string]any{
+		"catch_up_after_vacation": catchUpAfterVacation,
+	}, nil)
+	w.WriteHeader(http.StatusOK)
+}
+
+func UserSettings_SaveReaderView(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	readerView := util.EnsureParamBool(r, "reader_view")
+	currentUser := rutil.CurrentUser(r)
+	err := models.UserSettings_SaveReaderView(pool, currentUser.Id, readerView)
+	if err != nil {
+		panic(err)
+	}
+
+	// Published posts get their articles in the background, the posts open the original until then
+	if readerView {
+		err := jobs.ExtractArticlesJob_ScheduleForUser(pool, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "update reader view", map[string]any{
+		"reader_view": readerView,
+	}, nil)
+	w.WriteHeader(http.StatusOK)
+}
+
+func UserSettings_SaveLegacyFeedUrls(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	legacyFeedUrls := util.EnsureParamBool(r, "legacy_feed_urls")
+	currentUser := rutil.CurrentUser(r)
+	err := models.UserSettings_SaveLegacyFeedUrls(pool, currentUser.Id, legacyFeedUrls)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "update legacy feed urls", map[string]any{
+		"legacy_feed_urls": legacyFeedUrls,
+	}, nil)
+	w.WriteHeader(http.StatusOK)
+}
+
+func UserSettings_RotateFeedToken(w http.ResponseWriter, r *http.Request) {
+	tx, err := rutil.DBPool(r).Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer util.CommitOrRollbackOnPanic(tx)
+
+	currentUser := rutil.CurrentUser(r)
+	feedType := "single"
+	if subscriptionIdStr, ok := util.MaybeParamStr(r, "subscription_id"); ok && subscriptionIdStr != "" {
+		subscriptionIdInt, err := strconv.ParseInt(subscriptionIdStr, 10, 64)
+		if err != nil {
+			util.HttpPanic(http.StatusBadRequest, "Bad subscription_id")
+		}
+		subscriptionId := models.SubscriptionId(subscriptionIdInt)
+		subscriptions, err := models.Subscription_ListSortedToPublish(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		if !slices.ContainsFunc(subscriptions, func(s models.SubscriptionToPublish) bool {
+			return s.Id == subscriptionId
+		}) {
+			util.HttpPanic(http.StatusNotFound, "Subscription not found")
+		}
+
+		err = models.FeedToken_RotateForSubscription(tx, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+		feedType = "subscription"
+	} else {
+		err := models.FeedToken_RotateForUser(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// The feeds link to themselves
+	err = publish.RegenerateFeeds(tx, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "rotate feed token", map[string]any{
+		"feed_type": feedType,
+	}, nil)
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
@@ -51,7 +51,7 @@ func Subscriptions_Index(w http.ResponseWriter, r *http.Request) {
 		left join (
 			select subscription_id,
 				count(published_at) as published_count,
-				count(1) as total_count
+				count(1) filter (where skipped_at is null) as total_count
 			from subscription_posts
 			where subscription_id in (select id from user_subscriptions)
 			group by subscription_id
@@ -179,6 +179,119 @@ type subscriptionsScheduleResult struct {
 	HasOtherSubs       bool
 	OtherSubNamesByDay map[schedule.DayOfWeek][]string
 	DaysOfWeek         []schedule.DayOfWeek
+	Cadence            models.ScheduleCadence
+	MinFinishDate      schedule.Date
+	CanUseReadingTime  bool
+	MaxEveryDays       int
+	MaxMonthlyCount    int
+	MaxWeeklyMinutes   int
+}
+
+const subscriptionsMaxEveryDays = 30
+const subscriptionsMaxMonthlyCount = 31
+const subscriptionsMaxWeeklyMinutes = 1200
+
+// Fields of the other schedule types get defaults so that switching to them starts somewhere reasonable
+func subscriptions_MustGetScheduleResult(
+	pool *pgw.Pool, subscriptionId models.SubscriptionId, name string,
+	currentCountByDay map[schedule.DayOfWeek]int, otherSubNamesByDay map[schedule.DayOfWeek][]string,
+	minFinishDate schedule.Date,
+) subscriptionsScheduleResult {
+	cadence, err := models.Schedule_GetCadence(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	if cadence.EveryDays == 0 {
+		cadence.EveryDays = 2
+	}
+	if cadence.MonthlyCount == 0 {
+		cadence.MonthlyCount = 4
+	}
+	if cadence.WeeklyMinutes == 0 {
+		cadence.WeeklyMinutes = 60
+	}
+	withoutContentCount, err := models.SubscriptionPost_CountUnpublishedWithoutContent(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+
+	return subscriptionsScheduleResult{
+		Name:               name,
+		CurrentCountByDay:  currentCountByDay,
+		HasOtherSubs:       len(otherSubNamesByDay) > 0,
+		OtherSubNamesByDay: otherSubNamesByDay,
+		DaysOfWeek:         schedule.DaysOfWeek,
+		Cadence:            *cadence,
+		MinFinishDate:      minFinishDate,
+		CanUseReadingTime:  withoutContentCount == 0,
+		MaxEveryDays:       subscriptionsMaxEveryDays,
+		MaxMonthlyCount:    subscriptionsMaxMonthlyCount,
+		MaxWeeklyMinutes:   subscriptionsMaxWeeklyMinutes,
+	}
+}
+
+// Reads the schedule type and its fields from the form. Every n days keeps counting from the old start date
+// and reading time keeps its credit unless their settings change, otherwise they start at startDate.
+func subscriptions_MustParseCadence(
+	r *http.Request, qu pgw.Queryable, subscriptionId models.SubscriptionId, startDate schedule.Date,
+) models.ScheduleCadence {
+	oldCadence, err := models.Schedule_GetCadence(qu, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+
+	ensureParamIntInRange := func(name string, maxValue int) int {
+		value := util.EnsureParamInt(r, name)
+		if value < 1 || value > maxValue {
+			util.HttpPanic(http.StatusBadRequest, fmt.Sprintf("Expecting %s to be 1-%d", name, maxValue))
+		}
+		return value
+	}
+
+	cadence := models.ScheduleCadence{
+		Type:          models.ScheduleType(util.EnsureParamStr(r, "schedule_type")),
+		EveryDays:     0,
+		MonthlyCount:  0,
+		FinishDate:    "",
+		WeeklyMinutes: 0,
+		StartDate:     "",
+		Credit:        0,
+	}
+	switch cadence.Type {
+	case models.ScheduleTypeWeekly:
+	case models.ScheduleTypeEveryNDays:
+		cadence.EveryDays = ensureParamIntInRange("every_days", subscriptionsMaxEveryDays)
+		cadence.StartDate = startDate
+		if oldCadence.Type == cadence.Type && oldCadence.EveryDays == cadence.EveryDays {
+			cadence.StartDate = oldCadence.StartDate
+		}
+	case models.ScheduleTypePerMonth:
+		cadence.MonthlyCount = ensureParamIntInRange("monthly_count", subscriptionsMaxMonthlyCount)
+	case models.ScheduleTypeFinishBy:
+		finishDateStr := util.EnsureParamStr(r, "finish_date")
+		if _, err := time.Parse("2006-01-02", finishDateStr); err != nil {
+			util.HttpPanic(http.StatusBadRequest, fmt.Sprintf("Couldn't parse finish date: %s", finishDateStr))
+		}
+		cadence.FinishDate = schedule.Date(finishDateStr)
+		if cadence.FinishDate < startDate && cadence.FinishDate != oldCadence.FinishDate {
+			util.HttpPanic(http.StatusBadRequest, "Finish date has already passed")
+		}
+	case models.ScheduleTypeReadingTime:
+		withoutContentCount, err := models.SubscriptionPost_CountUnpublishedWithoutContent(qu, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+		if withoutContentCount > 0 {
+			util.HttpPanic(http.StatusBadRequest, "Reading time needs the length of every post")
+		}
+		cadence.WeeklyMinutes = ensureParamIntInRange("weekly_minutes", subscriptionsMaxWeeklyMinutes)
+		if oldCadence.Type == cadence.Type && oldCadence.WeeklyMinutes == cadence.WeeklyMinutes {
+			cadence.Credit = oldCadence.Credit
+		}
+	default:
+		util.HttpPanic(http.StatusBadRequest, fmt.Sprintf("Unknown schedule type: %s", cadence.Type))
+	}
+	return cadence
 }
 
 type subscriptionsScheduleJsResult struct {
@@ -218,13 +331,18 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 
 	var name string
 	var isPaused bool
+	var includePostContent bool
 	var scheduleVersion int64
 	var isAddedPastMidnight bool
+	var adaptivePacing bool
+	var pacingPercent int
+	var maybePacingChangedAt *schedule.Time
 	var url string
 	var publishedCount int
 	var totalCount int
 	row = pool.QueryRow(`
-		select name, is_paused, schedule_version, is_added_past_midnight,
+		select name, is_paused, include_post_content, schedule_version, is_added_past_midnight,
+			adaptive_pacing, pacing_percent, pacing_changed_at,
 			(select url from blogs where id = blog_id) as url,
 			(
 				select count(published_at) from subscription_posts
@@ -232,13 +350,14 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 			) as published_count,
 			(
 				select count(1) from subscription_posts
-				where subscription_id = subscriptions_without_discarded.id
+				where subscription_id = subscriptions_without_discarded.id and skipped_at is null
 			) as total_count
 		from subscriptions_without_discarded
 		where id = $1 and user_id = $2
 	`, subscriptionId, currentUser.Id)
 	err = row.Scan(
-		&name, &isPaused, &scheduleVersion, &isAddedPastMidnight, &url, &publishedCount, &totalCount,
+		&name, &isPaused, &includePostContent, &scheduleVersion, &isAddedPastMidnight, &adaptivePacing,
+		&pacingPercent, &maybePacingChangedAt, &url, &publishedCount, &totalCount,
 	)
 	if err != nil {
 		panic(err)
@@ -248,7 +367,11 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		panic(err)
 	}
-	feedUrl := rutil.SubscriptionFeedUrl(r, subscriptionId)
+	feedToken, err := models.FeedToken_GetForSubscription(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	feedUrl := rutil.SubscriptionFeedUrl(r, feedToken)
 	countByDay, err := models.Schedule_GetCountsByDay(pool, subscriptionId)
 	if err != nil {
 		panic(err)
@@ -260,6 +383,50 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 	preview := subscriptions_MustGetSchedulePreview(
 		pool, subscriptionId, status, currentUser.Id, userSettings,
 	)
+	scheduleResult := subscriptions_MustGetScheduleResult(
+		pool, subscriptionId, name, countByDay, otherSubNamesByDay, preview.NextScheduleDate,
+	)
+
+	// Only weekly schedules have a fixed number of posts a week
+	isWeekly := scheduleResult.Cadence.Type == models.ScheduleTypeWeekly
+	weeklyCount := 0
+	for _, count := range countByDay {
+		weeklyCount += count
+	}
+	var pacingRate string
+	pacingPausedSince := ""
+	switch {
+	case (!adaptivePacing || pacingPercent == 100) && !isWeekly:
+		pacingRate = "Full schedule"
+	case !adaptivePacing || pacingPercent == 100:
+		postsWord := "posts"
+		if weeklyCount == 1 {
+			postsWord = "post"
+		}
+		pacingRate = fmt.Sprintf("Full schedule, %d %s a week", weeklyCount, postsWord)
+	case pacingPercent == 0:
+		pacingRate = "Paused"
+		if maybePacingChangedAt != nil {
+			location := tzdata.LocationByName[userSettings.Timezone]
+			pacingPausedSince = maybePacingChangedAt.In(location).Format("January 2")
+		}
+	case !isWeekly:
+		pacingRate = fmt.Sprintf("Slowed down to %d%% of the schedule", pacingPercent)
+	default:
+		weeklyRate := strconv.FormatFloat(float64(weeklyCount*pacingPercent)/100, 'f', -1, 64)
+		pacingRate = fmt.Sprintf(
+			"Slowed down to %d%% of the schedule, about %s posts a week", pacingPercent, weeklyRate,
+		)
+	}
+
+	queue, err := models.SubscriptionPost_ListQueue(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	var deliverNowCounts []int
+	for count := 1; count <= min(subscriptionsDeliverNowMaxCount, totalCount-publishedCount); count++ {
+		deliverNowCounts = append(deliverNowCounts, count)
+	}
 
 	type SubscriptionResult struct {
 		Title           string
@@ -273,6 +440,18 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 		Url             string
 		PausePath       string
 		UnpausePath     string
+		IncludesContent bool
+		PostContentPath string
+		AdaptivePacing  bool
+		PacingRate      string
+		PacingPaused    string
+		PacingThreshold int
+		PacingPath      string
+		ResumePath      string
+		DeliverNowPath  string
+		DeliverCounts   []int
+		Queue           []models.SubscriptionQueuePost
+		QueuePath       string
 		Schedule        subscriptionsScheduleResult
 		ScheduleVersion int64
 		SchedulePreview schedulePreview
@@ -280,24 +459,30 @@ func Subscriptions_Show(w http.ResponseWriter, r *http.Request) {
 		DeletePath      string
 	}
 	templates.MustWrite(w, "subscriptions/show", SubscriptionResult{
-		Title:          util.DecorateTitle(name),
-		Session:        rutil.Session(r),
-		Name:           name,
-		FeedUrl:        feedUrl,
-		IsDone:         publishedCount >= totalCount,
-		IsPaused:       isPaused,
-		PublishedCount: publishedCount,
-		TotalCount:     totalCount,
-		Url:            url,
-		PausePath:      rutil.SubscriptionPausePath(subscriptionId),
-		UnpausePath:    rutil.SubscriptionUnpausePath(subscriptionId),
-		Schedule: subscriptionsScheduleResult{
-			Name:               name,
-			CurrentCountByDay:  countByDay,
-			HasOtherSubs:       len(otherSubNamesByDay) > 0,
-			OtherSubNamesByDay: otherSubNamesByDay,
-			DaysOfWeek:         schedule.DaysOfWeek,
-		},
+		Title:           util.DecorateTitle(name),
+		Session:         rutil.Session(r),
+		Name:            name,
+		FeedUrl:         feedUrl,
+		IsDone:          publishedCount >= totalCount,
+		IsPaused:        isPaused,
+		PublishedCount:  publishedCount,
+		TotalCount:      totalCount,
+		Url:             url,
+		PausePath:       rutil.SubscriptionPausePath(subscriptionId),
+		UnpausePath:     rutil.SubscriptionUnpausePath(subscriptionId),
+		IncludesContent: includePostContent,
+		PostContentPath: rutil.SubscriptionPostContentPath(subscriptionId),
+		AdaptivePacing:  adaptivePacing,
+		PacingRate:      pacingRate,
+		PacingPaused:    pacingPausedSince,
+		PacingThreshold: publish.AdaptivePacingUnopenedThreshold,
+		PacingPath:      rutil.SubscriptionAdaptivePacingPath(subscriptionId),
+		ResumePath:      rutil.SubscriptionResumePacingPath(subscriptionId),
+		DeliverNowPath:  rutil.SubscriptionDeliverNowPath(subscriptionId),
+		DeliverCounts:   deliverNowCounts,
+		Queue:           queue,
+		QueuePath:       rutil.SubscriptionQueuePath(subscriptionId),
+		Schedule:        scheduleResult,
 		ScheduleVersion: scheduleVersion,
 		SchedulePreview: preview,
 		ScheduleJS: subscriptionsScheduleJsResult{
@@ -385,6 +570,45 @@ func Subscriptions_Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func Subscriptions_CreateTableOfContents(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	currentUser := rutil.CurrentUser(r)
+	productUserId := rutil.CurrentProductUserId(r)
+	userIsAnonymous := currentUser == nil
+	pc := models.NewProductEventContext(pool, r, productUserId)
+	startPageId := models.StartPageId(util.EnsureParamInt64(r, "start_page_id"))
+	startPage, err := models.StartPage_Get(pool, startPageId)
+	if errors.Is(err, models.ErrStartPageNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	zlogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+	toc, ok := crawler.DetectTableOfContents(startPage, &zlogger)
+	if !ok {
+		logger.Info().Msgf("Table of contents not found at start page %d", startPageId)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	blog, err := models.Blog_CreateOrUpdateTableOfContents(
+		pool, startPageId, toc.Title, startPage.FinalUrl, jobs.TableOfContentsJob_PerformNow,
+	)
+	if err != nil {
+		panic(err)
+	}
+	subscriptionCreateResult, err := models.Subscription_CreateForBlog(pool, blog, currentUser, productUserId)
+	if err != nil {
+		panic(err)
+	}
+	if !models.BlogFailedStatuses[subscriptionCreateResult.BlogStatus] {
+		models.ProductEvent_MustEmitCreateSubscription(pc, subscriptionCreateResult, userIsAnonymous)
+	}
+	util.MustWrite(w, rutil.SubscriptionSetupPath(subscriptionCreateResult.Id))
+}
+
 func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 	pool := rutil.DBPool(r)
 	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
@@ -520,6 +744,7 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 				SubscriptionDeletePath    string
 				SubscriptionMarkWrongPath string
 				MarkWrongFuncJS           template.JS
+				PostOrders                []models.PostOrder
 			}
 
 			type TopCategoryPosts struct {
@@ -675,6 +900,7 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 							SubscriptionDeletePath:    subscriptionDeletePath,
 							SubscriptionMarkWrongPath: subscrtipionMarkWrongPath,
 							MarkWrongFuncJS:           markWrongFuncJS,
+							PostOrders:                models.PostOrders,
 						},
 					})
 				}
@@ -736,6 +962,7 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 					SubscriptionDeletePath:    subscriptionDeletePath,
 					SubscriptionMarkWrongPath: subscrtipionMarkWrongPath,
 					MarkWrongFuncJS:           markWrongFuncJS,
+					PostOrders:                models.PostOrders,
 				},
 			})
 			return
@@ -764,6 +991,7 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 				SubscriptionNotifyWhenSupportedPath string
 				NotifyWhenSupportedChecked          bool
 				NotifyWhenSupportedVersion          int
+				SubscriptionCustomBlogPath          string
 			}
 			deletePath := rutil.SubscriptionDeletePath(subscriptionId)
 			notifyWhenSupportedPath := rutil.SubscriptionNotifyWhenSupportedPath(subscriptionId)
@@ -776,6 +1004,7 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 				SubscriptionNotifyWhenSupportedPath: notifyWhenSupportedPath,
 				NotifyWhenSupportedChecked:          notifyWhenSupportedChecked,
 				NotifyWhenSupportedVersion:          notifyWhenSupportedVersion,
+				SubscriptionCustomBlogPath:          rutil.SubscriptionCustomBlogPath(subscriptionId),
 			})
 			return
 		default:
@@ -795,6 +1024,23 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 		preview := subscriptions_MustGetSchedulePreview(
 			pool, subscriptionId, subscriptionStatus, currentUser.Id, userSettings,
 		)
+
+		// Imports of crawled blogs come with the default schedule, the ones that went through post selection
+		// get it suggested here
+		currentCountByDay, err := models.Schedule_GetCountsByDay(pool, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+		if len(currentCountByDay) == 0 {
+			isFromOpmlImport, err := models.OpmlImportItem_ExistsForSubscription(pool, subscriptionId)
+			if err != nil {
+				panic(err)
+			}
+			if isFromOpmlImport {
+				currentCountByDay = models.Schedule_DefaultCountsByDay(otherSubNamesByDay)
+			}
+		}
+
 		type SetScheduleResult struct {
 			Title                    string
 			Session                  *util.Session
@@ -810,13 +1056,10 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 			Session:          rutil.Session(r),
 			NameHeaderId:     "name_header",
 			SubscriptionName: subscriptionName,
-			Schedule: subscriptionsScheduleResult{
-				Name:               subscriptionName,
-				CurrentCountByDay:  make(map[schedule.DayOfWeek]int),
-				HasOtherSubs:       len(otherSubNamesByDay) > 0,
-				OtherSubNamesByDay: otherSubNamesByDay,
-				DaysOfWeek:         schedule.DaysOfWeek,
-			},
+			Schedule: subscriptions_MustGetScheduleResult(
+				pool, subscriptionId, subscriptionName, currentCountByDay, otherSubNamesByDay,
+				preview.NextScheduleDate,
+			),
 			SchedulePreview: preview,
 			ScheduleJS: subscriptionsScheduleJsResult{
 				DaysOfWeekJson:        schedule.DaysOfWeekJson,
@@ -830,7 +1073,11 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			panic(err)
 		}
-		feedUrl := rutil.SubscriptionFeedUrl(r, subscriptionId)
+		feedToken, err := models.FeedToken_GetForSubscription(pool, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+		feedUrl := rutil.SubscriptionFeedUrl(r, feedToken)
 		userSettings, err := models.UserSettings_Get(pool, currentUser.Id)
 		if err != nil {
 			panic(err)
@@ -848,10 +1095,6 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 		var willArriveDate string
 		var willArriveOne bool
 		if publishedCount == 0 {
-			countsByDay, err := models.Schedule_GetCountsByDay(pool, subscriptionId)
-			if err != nil {
-				panic(err)
-			}
 			utcNow := schedule.UTCNow()
 			location := tzdata.LocationByName[userSettings.Timezone]
 			localTime := utcNow.In(location)
@@ -864,17 +1107,15 @@ func Subscriptions_Setup(w http.ResponseWriter, r *http.Request) {
 				panic(err)
 			}
 			todaysJobAlreadyRan := nextJobScheduleDate > localDate
-			willArriveDateTime := localDate.Time()
+			fromDate := localDate
 			if todaysJobAlreadyRan {
-				willArriveDateTime = willArriveDateTime.AddDate(0, 0, 1)
+				fromDate = fromDate.NextDay()
 			}
-			for countsByDay[willArriveDateTime.DayOfWeek()] <= 0 {
-				willArriveDateTime = willArriveDateTime.AddDate(0, 0, 1)
-			}
-
+			arriveDate, arriveCount := subscriptions_MustGetFirstArrival(pool, subscriptionId, fromDate)
+			willArriveDateTime := arriveDate.Time()
 			willArriveDate = willArriveDateTime.Format("Monday, January 2") +
 				util.Ordinal(willArriveDateTime.Day())
-			willArriveOne = countsByDay[willArriveDateTime.DayOfWeek()] == 1
+			willArriveOne = arriveCount == 1
 		}
 		type HeresFeedOrEmailResult struct {
 			Title            string
@@ -1191,6 +1432,11 @@ func Subscriptions_SelectPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	postOrder := models.PostOrder(util.EnsureParamStr(r, "post_order"))
+	if !slices.Contains(models.PostOrders, postOrder) {
+		util.HttpPanic(http.StatusBadRequest, fmt.Sprintf("Unknown post order: %s", postOrder))
+	}
+
 	var topCategoryId models.BlogPostCategoryId
 	blogPostIds := make(map[models.BlogPostId]bool)
 	var productSelectedCount int
@@ -1246,6 +1492,7 @@ func Subscriptions_SelectPosts(w http.ResponseWriter, r *http.Request) {
 		"selected_count":    productSelectedCount,
 		"selected_fraction": float64(productSelectedCount) / float64(postsCount),
 		"selection":         productSelection,
+		"post_order":        postOrder,
 		"user_is_anonymous": currentUser == nil,
 	}, nil)
 
@@ -1256,12 +1503,14 @@ func Subscriptions_SelectPosts(w http.ResponseWriter, r *http.Request) {
 	defer util.CommitOrRollbackOnPanic(tx)
 
 	if topCategoryId != 0 {
-		err := models.Subscription_CreatePostsFromCategory(tx, subscriptionId, topCategoryId)
+		err := models.Subscription_CreatePostsFromCategory(tx, subscriptionId, topCategoryId, postOrder)
 		if err != nil {
 			panic(err)
 		}
 	} else {
-		err := models.Subscription_CreatePostsFromIds(tx, subscriptionId, blogId, blogPostIds)
+		err := models.Subscription_CreatePostsFromIds(
+			tx, subscriptionId, blogId, blogPostIds, postOrder,
+		)
 		if err != nil {
 			panic(err)
 		}
@@ -1345,6 +1594,166 @@ func Subscriptions_MarkWrong(w http.ResponseWriter, r *http.Request) {
 	logger.Warn().Msgf("Blog %d (%s) marked as wrong", blogId, blogName)
 }
 
+type subscriptions_CustomBlogResult struct {
+	Title                      string
+	Session                    *util.Session
+	SubscriptionName           string
+	SubscriptionSetupPath      string
+	SubscriptionCustomBlogPath string
+	MaxPostsCount              int
+	Posts                      string
+	IsOldestFirst              bool
+	MaybeError                 *string
+}
+
+func Subscriptions_CustomBlog(w http.ResponseWriter, r *http.Request) {
+	subscriptionId, subscriptionName, _, ok := subscriptions_MustGetCustomBlogSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	templates.MustWrite(w, "subscriptions/setup_custom_blog", subscriptions_CustomBlogResult{
+		Title:                      util.DecorateTitle(subscriptionName),
+		Session:                    rutil.Session(r),
+		SubscriptionName:           subscriptionName,
+		SubscriptionSetupPath:      rutil.SubscriptionSetupPath(subscriptionId),
+		SubscriptionCustomBlogPath: rutil.SubscriptionCustomBlogPath(subscriptionId),
+		MaxPostsCount:              crawler.CustomBlogMaxPosts,
+		Posts:                      "",
+		IsOldestFirst:              false,
+		MaybeError:                 nil,
+	})
+}
+
+func Subscriptions_CreateCustomBlog(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	subscriptionId, subscriptionName, failedBlogId, ok := subscriptions_MustGetCustomBlogSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	postsText := util.EnsureParamStr(r, "posts")
+	postsFile := util.EnsureParamStr(r, "posts_file")
+	isOldestFirst := util.EnsureParamStr(r, "direction") == "oldest_first"
+	content := postsText
+	format := crawler.CustomBlogFormatText
+	if strings.TrimSpace(postsFile) != "" {
+		content = postsFile
+		format = crawler.GetCustomBlogFormat(util.EnsureParamStr(r, "posts_file_name"))
+	}
+
+	zlogger := crawler.ZeroLogger{Logger: logger, MaybeLogScreenshotFunc: nil}
+	posts, err := crawler.ParseCustomBlogPosts(content, format, &zlogger)
+	if err != nil {
+		logger.Info().Err(err).Msgf("Couldn't parse custom blog posts (%s)", format)
+		var errorMessage string
+		switch {
+		case errors.Is(err, crawler.ErrCustomBlogEmpty):
+			errorMessage = "No post links found."
+		case errors.Is(err, crawler.ErrCustomBlogTooManyPosts):
+			errorMessage = fmt.Sprintf("Too many posts, the limit is %d.", crawler.CustomBlogMaxPosts)
+		default:
+			errorMessage = "Couldn't read the post links. Make sure every line starts with a full link."
+		}
+		templates.MustWrite(w, "subscriptions/setup_custom_blog", subscriptions_CustomBlogResult{
+			Title:                      util.DecorateTitle(subscriptionName),
+			Session:                    rutil.Session(r),
+			SubscriptionName:           subscriptionName,
+			SubscriptionSetupPath:      rutil.SubscriptionSetupPath(subscriptionId),
+			SubscriptionCustomBlogPath: rutil.SubscriptionCustomBlogPath(subscriptionId),
+			MaxPostsCount:              crawler.CustomBlogMaxPosts,
+			Posts:                      postsText,
+			IsOldestFirst:              isOldestFirst,
+			MaybeError:                 &errorMessage,
+		})
+		return
+	}
+	if isOldestFirst {
+		slices.Reverse(posts)
+	}
+
+	err = util.Tx(pool, func(tx *pgw.Tx, pool util.Clobber) error {
+		blogId, err := models.Blog_CreateCustom(tx, failedBlogId)
+		if err != nil {
+			return err
+		}
+		err = models.Subscription_SetBlogId(tx, subscriptionId, blogId)
+		if err != nil {
+			return err
+		}
+		return jobs.CustomBlogJob_PerformNow(tx, blogId, posts)
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	pc := models.NewProductEventContext(pool, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "create custom blog", map[string]any{
+		"subscription_id": subscriptionId,
+		"format":          format,
+		"posts_count":     len(posts),
+		"is_oldest_first": isOldestFirst,
+	}, nil)
+
+	http.Redirect(w, r, rutil.SubscriptionSetupPath(subscriptionId), http.StatusSeeOther)
+}
+
+// Custom blogs are only available to the logged in users whose blog couldn't be crawled
+func subscriptions_MustGetCustomBlogSubscription(
+	w http.ResponseWriter, r *http.Request,
+) (subscriptionId models.SubscriptionId, subscriptionName string, blogId models.BlogId, ok bool) {
+	pool := rutil.DBPool(r)
+	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		subscriptions_RedirectNotFound(w, r)
+		return 0, "", 0, false
+	}
+
+	subscriptionId = models.SubscriptionId(subscriptionIdInt)
+	var subscriptionStatus models.SubscriptionStatus
+	var blogStatus models.BlogStatus
+	var maybeStartFeedId *models.StartFeedId
+	var maybeSubscriptionUserId *models.UserId
+	row := pool.QueryRow(`
+		select
+			status,
+			(select status from blogs where id = blog_id),
+			(select start_feed_id from blogs where id = blog_id),
+			name, blog_id, user_id
+		from subscriptions_without_discarded
+		where id = $1
+	`, subscriptionId)
+	err := row.Scan(
+		&subscriptionStatus, &blogStatus, &maybeStartFeedId, &subscriptionName, &blogId,
+		&maybeSubscriptionUserId,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		subscriptions_RedirectNotFound(w, r)
+		return 0, "", 0, false
+	} else if err != nil {
+		panic(err)
+	}
+
+	if subscriptions_RedirectIfUserMismatch(w, r, maybeSubscriptionUserId) {
+		return 0, "", 0, false
+	}
+
+	if rutil.CurrentUser(r) == nil {
+		http.Redirect(w, r, "/signup", http.StatusSeeOther)
+		return 0, "", 0, false
+	}
+
+	if !(subscriptionStatus == models.SubscriptionStatusWaitingForBlog &&
+		models.BlogFailedStatuses[blogStatus] &&
+		maybeStartFeedId != nil) {
+		http.Redirect(w, r, rutil.SubscriptionSetupPath(subscriptionId), http.StatusSeeOther)
+		return 0, "", 0, false
+	}
+
+	return subscriptionId, subscriptionName, blogId, true
+}
+
 func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 	logger := rutil.Logger(r)
 	pool := rutil.DBPool(r)
@@ -1399,7 +1808,9 @@ func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 		countsByDay[dayOfWeek] = count
 		totalCount += count
 	}
-	if totalCount <= 0 {
+	// Weekly counts are saved either way so that switching back to weekly later restores them
+	scheduleType := models.ScheduleType(util.EnsureParamStr(r, "schedule_type"))
+	if totalCount <= 0 && scheduleType == models.ScheduleTypeWeekly {
 		panic("Expecting some count to not be zero")
 	}
 
@@ -1458,7 +1869,15 @@ func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 			deliveryChannel = *oldUserSettings.MaybeDeliveryChannel
 		}
 
-		err = models.Schedule_Create(tx, subscriptionId, countsByDay)
+		existingCountsByDay, err := models.Schedule_GetCountsByDay(tx, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+		if len(existingCountsByDay) > 0 {
+			err = models.Schedule_Update(tx, subscriptionId, countsByDay)
+		} else {
+			err = models.Schedule_Create(tx, subscriptionId, countsByDay)
+		}
 		if err != nil {
 			panic(err)
 		}
@@ -1478,6 +1897,16 @@ func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 		isAddedEarlyMorning := localTime.IsEarlyMorning()
 		shouldPublishRssPosts := todaysJobAlreadyRan && isAddedEarlyMorning
 
+		startDate := localDate
+		if nextJobDate != "" && !shouldPublishRssPosts {
+			startDate = nextJobDate
+		}
+		cadence := subscriptions_MustParseCadence(r, tx, subscriptionId, startDate)
+		err = models.Schedule_UpdateCadence(tx, subscriptionId, cadence)
+		if err != nil {
+			panic(err)
+		}
+
 		err = models.Subscription_FinishSetup(
 			tx, subscriptionId, subscriptionName, models.SubscriptionStatusLive, utcNow, 1,
 			isAddedEarlyMorning,
@@ -1494,6 +1923,13 @@ func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 			panic(err)
 		}
 
+		if deliveryChannel == models.DeliveryChannelEmail {
+			err := jobs.EmailDigestJob_PerformNow(tx, currentUser.Id)
+			if err != nil {
+				panic(err)
+			}
+		}
+
 		productActiveDays := 0
 		for _, count := range countsByDay {
 			if count > 0 {
@@ -1501,7 +1937,7 @@ func Subscriptions_Schedule(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		models.ProductEvent_MustEmitSchedule(
-			pc, "schedule", subscriptionId, blogBestUrl, totalCount, productActiveDays,
+			pc, "schedule", subscriptionId, blogBestUrl, cadence.Type, totalCount, productActiveDays,
 		)
 
 		slackBlogUrl := jobs.NotifySlackJob_Escape(blogBestUrl)
@@ -1590,6 +2026,346 @@ func subscriptions_MustPauseUnpause(
 	w.WriteHeader(http.StatusOK)
 }
 
+const subscriptionsDeliverNowMaxCount = 5
+
+func Subscriptions_DeliverNow(w http.ResponseWriter, r *http.Request) {
+	logger := rutil.Logger(r)
+	pool := rutil.DBPool(r)
+	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	}
+	subscriptionId := models.SubscriptionId(subscriptionIdInt)
+	count := util.EnsureParamInt(r, "count")
+	if count < 1 || count > subscriptionsDeliverNowMaxCount {
+		panic(fmt.Errorf("Expecting count to be 1-%d: %d", subscriptionsDeliverNowMaxCount, count))
+	}
+	currentUser := rutil.CurrentUser(r)
+
+	// Same as with saving the schedule, delivering has to wait if the daily job is running
+	mustDeliverNow := func() (result bool) {
+		tx, err := pool.Begin()
+		if err != nil {
+			panic(err)
+		}
+		defer util.CommitOrRollbackMsg(tx, &result, "Unlocked daily jobs")
+
+		var maybeSubscriptionUserId *models.UserId
+		var status models.SubscriptionStatus
+		var blogBestUrl string
+		row := tx.QueryRow(`
+			select user_id, status, (
+				select coalesce(url, feed_url) from blogs
+				where blogs.id = subscriptions_without_discarded.blog_id
+			) from subscriptions_without_discarded where id = $1
+		`, subscriptionId)
+		err = row.Scan(&maybeSubscriptionUserId, &status, &blogBestUrl)
+		if errors.Is(err, pgx.ErrNoRows) {
+			subscriptions_RedirectNotFound(w, r)
+			return true
+		} else if err != nil {
+			panic(err)
+		}
+
+		if subscriptions_RedirectIfUserMismatch(w, r, maybeSubscriptionUserId) {
+			return true
+		}
+
+		if subscriptions_BadRequestIfNotLive(w, status) {
+			return true
+		}
+
+		logger.Info().Msg("Locking daily jobs")
+		lockedJobs, err := jobs.PublishPostsJob_Lock(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		logger.Info().Msgf("Locked daily jobs %d", len(lockedJobs))
+
+		for _, job := range lockedJobs {
+			if job.LockedBy != "" {
+				logger.Info().Msgf("Some jobs are running, unlocking %d", len(lockedJobs))
+				return false
+			}
+		}
+
+		userSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+		if err != nil {
+			panic(err)
+		}
+		deliveryChannel := *userSettings.MaybeDeliveryChannel
+		utcNow := schedule.UTCNow()
+		location := tzdata.LocationByName[userSettings.Timezone]
+		localDate := utcNow.In(location).Date()
+
+		deliveredCount, err := publish.DeliverNow(
+			tx, currentUser.Id, currentUser.ProductUserId, subscriptionId, blogBestUrl, deliveryChannel, count,
+			utcNow, localDate,
+		)
+		if err != nil {
+			panic(err)
+		}
+		if deliveredCount > 0 {
+			err := jobs.PublishPostsJob_SchedulePushes(tx, currentUser.Id, deliveryChannel, utcNow)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+		models.ProductEvent_MustEmitFromRequest(pc, "deliver posts now", map[string]any{
+			"subscription_id": subscriptionId,
+			"blog_url":        blogBestUrl,
+			"requested_count": count,
+			"delivered_count": deliveredCount,
+		}, nil)
+
+		http.Redirect(w, r, rutil.SubscriptionPath(subscriptionId), http.StatusSeeOther)
+		return true
+	}
+
+	failedLockAttempts := 0
+	for {
+		if failedLockAttempts >= 3 {
+			panic("Couldn't lock the job rows")
+		} else if failedLockAttempts > 0 {
+			time.Sleep(time.Second)
+		}
+
+		if mustDeliverNow() {
+			break
+		} else {
+			failedLockAttempts++
+		}
+	}
+}
+
+func Subscriptions_UpdatePostContent(w http.ResponseWriter, r *http.Request) {
+	tx, err := rutil.DBPool(r).Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer util.CommitOrRollbackOnPanic(tx)
+
+	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	}
+
+	subscriptionId := models.SubscriptionId(subscriptionIdInt)
+	var maybeSubscriptionUserId *models.UserId
+	var status models.SubscriptionStatus
+	var blogBestUrl string
+	row := tx.QueryRow(`
+		select user_id, status, (
+			select coalesce(url, feed_url) from blogs
+			where blogs.id = subscriptions_without_discarded.blog_id
+		) from subscriptions_without_discarded where id = $1
+	`, subscriptionId)
+	err = row.Scan(&maybeSubscriptionUserId, &status, &blogBestUrl)
+	if errors.Is(err, pgx.ErrNoRows) {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	if subscriptions_RedirectIfUserMismatch(w, r, maybeSubscriptionUserId) {
+		return
+	}
+
+	if subscriptions_BadRequestIfNotLive(w, status) {
+		return
+	}
+
+	includePostContent := util.EnsureParamBool(r, "include_post_content")
+	err = models.Subscription_SetIncludePostContent(tx, subscriptionId, includePostContent)
+	if err != nil {
+		panic(err)
+	}
+
+	// The items that are already published switch too
+	err = publish.RegenerateFeeds(tx, *maybeSubscriptionUserId)
+	if err != nil {
+		panic(err)
+	}
+
+	// Posts that came without feed content get an excerpt from the post page
+	if includePostContent {
+		err = jobs.ExtractArticlesJob_PerformNow(tx, subscriptionId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "update post content", map[string]any{
+		"subscription_id":      subscriptionId,
+		"blog_url":             blogBestUrl,
+		"include_post_content": includePostContent,
+	}, nil)
+	w.WriteHeader(http.StatusOK)
+}
+
+func Subscriptions_UpdateQueue(w http.ResponseWriter, r *http.Request) {
+	tx, err := rutil.DBPool(r).Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer util.CommitOrRollbackOnPanic(tx)
+
+	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	}
+
+	subscriptionId := models.SubscriptionId(subscriptionIdInt)
+	var maybeSubscriptionUserId *models.UserId
+	var status models.SubscriptionStatus
+	var blogBestUrl string
+	row := tx.QueryRow(`
+		select user_id, status, (
+			select coalesce(url, feed_url) from blogs
+			where blogs.id = subscriptions_without_discarded.blog_id
+		) from subscriptions_without_discarded where id = $1
+	`, subscriptionId)
+	err = row.Scan(&maybeSubscriptionUserId, &status, &blogBestUrl)
+	if errors.Is(err, pgx.ErrNoRows) {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	if subscriptions_RedirectIfUserMismatch(w, r, maybeSubscriptionUserId) {
+		return
+	}
+
+	if subscriptions_BadRequestIfNotLive(w, status) {
+		return
+	}
+
+	postId := models.SubscriptionPostId(util.EnsureParamInt64(r, "post_id"))
+	action := util.EnsureParamStr(r, "action")
+	var isQueued bool
+	switch action {
+	case "skip":
+		isQueued, err = models.SubscriptionPost_Skip(tx, subscriptionId, postId, schedule.UTCNow())
+	case "move_to_front":
+		isQueued, err = models.SubscriptionPost_MoveToFront(tx, subscriptionId, postId)
+	case "restore":
+		isQueued, err = models.SubscriptionPost_Restore(tx, subscriptionId, postId)
+	default:
+		util.HttpPanic(http.StatusBadRequest, fmt.Sprintf("Unknown queue action: %s", action))
+	}
+	if err != nil {
+		panic(err)
+	}
+	if !isQueued {
+		// Could've been published in the meantime
+		util.HttpPanic(http.StatusBadRequest, "Post is not in the queue")
+	}
+
+	pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+	models.ProductEvent_MustEmitFromRequest(pc, "edit post queue", map[string]any{
+		"subscription_id": subscriptionId,
+		"blog_url":        blogBestUrl,
+		"action":          action,
+	}, nil)
+	w.WriteHeader(http.StatusOK)
+}
+
+func Subscriptions_UpdateAdaptivePacing(w http.ResponseWriter, r *http.Request) {
+	subscriptions_MustUpdatePacing(w, r, func(tx *pgw.Tx, subscriptionId models.SubscriptionId) string {
+		adaptivePacing := util.EnsureParamBool(r, "adaptive_pacing")
+		err := models.Subscription_SetAdaptivePacing(tx, subscriptionId, adaptivePacing, schedule.UTCNow())
+		if err != nil {
+			panic(err)
+		}
+		if adaptivePacing {
+			return "enable adaptive pacing"
+		}
+		return "disable adaptive pacing"
+	})
+}
+
+func Subscriptions_ResumePacing(w http.ResponseWriter, r *http.Request) {
+	subscriptions_MustUpdatePacing(w, r, func(tx *pgw.Tx, subscriptionId models.SubscriptionId) string {
+		isResumed, err := models.Subscription_ResumePacing(tx, subscriptionId, schedule.UTCNow())
+		if err != nil {
+			panic(err)
+		}
+		if !isResumed {
+			return ""
+		}
+		return "speed up subscription"
+	})
+}
+
+// Either change can add or remove the paused item, so the feeds get regenerated. No event is emitted if the
+// update returns an empty name.
+func subscriptions_MustUpdatePacing(
+	w http.ResponseWriter, r *http.Request,
+	update func(tx *pgw.Tx, subscriptionId models.SubscriptionId) (eventName string),
+) {
+	tx, err := rutil.DBPool(r).Begin()
+	if err != nil {
+		panic(err)
+	}
+	defer util.CommitOrRollbackOnPanic(tx)
+
+	subscriptionIdInt, ok := util.URLParamInt64(r, "id")
+	if !ok {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	}
+
+	subscriptionId := models.SubscriptionId(subscriptionIdInt)
+	var maybeSubscriptionUserId *models.UserId
+	var status models.SubscriptionStatus
+	var blogBestUrl string
+	row := tx.QueryRow(`
+		select user_id, status, (
+			select coalesce(url, feed_url) from blogs
+			where blogs.id = subscriptions_without_discarded.blog_id
+		) from subscriptions_without_discarded where id = $1
+	`, subscriptionId)
+	err = row.Scan(&maybeSubscriptionUserId, &status, &blogBestUrl)
+	if errors.Is(err, pgx.ErrNoRows) {
+		subscriptions_RedirectNotFound(w, r)
+		return
+	} else if err != nil {
+		panic(err)
+	}
+
+	if subscriptions_RedirectIfUserMismatch(w, r, maybeSubscriptionUserId) {
+		return
+	}
+
+	if subscriptions_BadRequestIfNotLive(w, status) {
+		return
+	}
+
+	eventName := update(tx, subscriptionId)
+
+	err = publish.RegenerateFeeds(tx, *maybeSubscriptionUserId)
+	if err != nil {
+		panic(err)
+	}
+
+	if eventName != "" {
+		pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
+		models.ProductEvent_MustEmitFromRequest(pc, eventName, map[string]any{
+			"subscription_id": subscriptionId,
+			"blog_url":        blogBestUrl,
+		}, nil)
+	}
+	http.Redirect(w, r, rutil.SubscriptionPath(subscriptionId), http.StatusSeeOther)
+}
+
 var dayCountNames []string
 
 func init() {
@@ -1650,11 +2426,25 @@ func Subscriptions_Update(w http.ResponseWriter, r *http.Request) {
 		dayCount := util.EnsureParamInt64(r, dayCountName)
 		totalCount += dayCount
 	}
-	if totalCount == 0 {
+	scheduleType := models.ScheduleType(util.EnsureParamStr(r, "schedule_type"))
+	if totalCount == 0 && scheduleType == models.ScheduleTypeWeekly {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	// Changed cadences start with the next daily job
+	currentUser := rutil.CurrentUser(r)
+	userSettings, err := models.UserSettings_Get(tx, currentUser.Id)
+	if err != nil {
+		panic(err)
+	}
+	localTime := schedule.UTCNow().In(tzdata.LocationByName[userSettings.Timezone])
+	startDate, err := subscriptions_GetRealisticScheduleDate(tx, currentUser.Id, localTime, localTime.Date())
+	if err != nil {
+		panic(err)
+	}
+	cadence := subscriptions_MustParseCadence(r, tx, subscriptionId, startDate)
+
 	productActiveDays := 0
 	countsByDay := make(map[schedule.DayOfWeek]int)
 	for _, dayCountName := range dayCountNames {
@@ -1669,6 +2459,10 @@ func Subscriptions_Update(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		panic(err)
 	}
+	err = models.Schedule_UpdateCadence(tx, subscriptionId, cadence)
+	if err != nil {
+		panic(err)
+	}
 	err = models.Subscription_UpdateScheduleVersion(tx, subscriptionId, newVersion)
 	if err != nil {
 		panic(err)
@@ -1676,7 +2470,7 @@ func Subscriptions_Update(w http.ResponseWriter, r *http.Request) {
 
 	pc := models.NewProductEventContext(tx, r, rutil.CurrentProductUserId(r))
 	models.ProductEvent_MustEmitSchedule(
-		pc, "update schedule", subscriptionId, blogBestUrl, int(totalCount), productActiveDays,
+		pc, "update schedule", subscriptionId, blogBestUrl, cadence.Type, int(totalCount), productActiveDays,
 	)
 }
 
@@ -1742,6 +2536,12 @@ type schedulePreview struct {
 	Location                             *time.Location
 	ShortFriendlySuffixNameByGroupIdJson template.JS
 	GroupIdByTimezoneIdJson              template.JS
+	VacationsJS                          template.JS
+	CatchUpAfterVacation                 bool
+	MaxCatchUpDays                       int
+	UnpublishedCount                     int
+	CadenceJS                            template.JS
+	PostOrder                            models.PostOrder
 }
 
 func subscriptions_MustGetSchedulePreview(
@@ -1786,6 +2586,42 @@ func subscriptions_MustGetSchedulePreview(
 	}
 	logger.Info().Msgf("Preview next schedule date: %s", nextScheduleDate)
 
+	// Recently ended vacations matter too as the days right after them may catch up
+	vacationsFrom := localDate
+	for i := 0; i < models.VacationMaxCatchUpDays; i++ {
+		vacationsFrom = vacationsFrom.PrevDay()
+	}
+	vacations, err := models.Vacation_ListEndingFrom(pool, userId, vacationsFrom)
+	if err != nil {
+		panic(err)
+	}
+	var vacationsBuf bytes.Buffer
+	vacationsBuf.WriteString("[")
+	for i, vacation := range vacations {
+		if i > 0 {
+			vacationsBuf.WriteString(", ")
+		}
+		fmt.Fprintf(&vacationsBuf, `["%s", "%s"]`, vacation.StartDate, vacation.EndDate)
+	}
+	vacationsBuf.WriteString("]")
+	vacationsJS := template.JS(vacationsBuf.String())
+
+	// The preview continues the saved every n days and reading time progress while they stay unchanged
+	cadence, err := models.Schedule_GetCadence(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	cadenceJson, err := json.Marshal(map[string]any{
+		"type":          cadence.Type,
+		"everyDays":     cadence.EveryDays,
+		"startDate":     cadence.StartDate,
+		"weeklyMinutes": cadence.WeeklyMinutes,
+		"credit":        cadence.Credit,
+	})
+	if err != nil {
+		panic(err)
+	}
+
 	return schedulePreview{
 		PrevPosts:                            preview.PrevPosts,
 		PrevPostDatesJS:                      prevPostDatesJS,
@@ -1798,14 +2634,72 @@ func subscriptions_MustGetSchedulePreview(
 		Location:                             location,
 		ShortFriendlySuffixNameByGroupIdJson: util.ShortFriendlySuffixNameByGroupIdJson,
 		GroupIdByTimezoneIdJson:              util.GroupIdByTimezoneIdJson,
+		VacationsJS:                          vacationsJS,
+		CatchUpAfterVacation:                 userSettings.CatchUpAfterVacation,
+		MaxCatchUpDays:                       models.VacationMaxCatchUpDays,
+		UnpublishedCount:                     preview.UnpublishedCount,
+		CadenceJS:                            template.JS(cadenceJson),
+		PostOrder:                            preview.PostOrder,
+	}
+}
+
+// First date from fromDate that the schedule has posts for, and how many. Vacations aren't accounted for.
+func subscriptions_MustGetFirstArrival(
+	pool *pgw.Pool, subscriptionId models.SubscriptionId, fromDate schedule.Date,
+) (schedule.Date, int) {
+	cadence, err := models.Schedule_GetCadence(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	countsByDay, err := models.Schedule_GetCountsByDay(pool, subscriptionId)
+	if err != nil {
+		panic(err)
 	}
+	unpublishedCount, err := models.SubscriptionPost_GetUnpublishedCount(pool, subscriptionId)
+	if err != nil {
+		panic(err)
+	}
+	var postsMinutes []int
+	if cadence.Type == models.ScheduleTypeReadingTime {
+		posts, err := models.SubscriptionPost_GetNextUnpublished(pool, subscriptionId, 10)
+		if err != nil {
+			panic(err)
+		}
+		for _, post := range posts {
+			postsMinutes = append(postsMinutes, util.ReadingMinutes(post.MaybeContent))
+		}
+	}
+
+	date := fromDate
+	credit := cadence.Credit
+	// A very long post on a small reading budget may take years, no point in looking that far
+	for days := 0; days < 3660; days++ {
+		var count int
+		switch cadence.Type {
+		case models.ScheduleTypeFinishBy:
+			count = publish.FinishByCount(unpublishedCount, date, cadence.FinishDate)
+		case models.ScheduleTypeReadingTime:
+			credit += cadence.WeeklyMinutes
+			count, credit = publish.ReadingTimeCount(credit, postsMinutes)
+		default:
+			count = publish.DateScheduledCount(cadence, countsByDay, date)
+		}
+		if count > 0 {
+			return date, count
+		}
+		date = date.NextDay()
+	}
+	pool.Logger().Warn().Msgf(
+		"Subscription %d has nothing scheduled for years from %s", subscriptionId, fromDate,
+	)
+	return date, 0
 }
 
 func subscriptions_GetRealisticScheduleDate(
-	pool *pgw.Pool, userId models.UserId, localTime schedule.Time, localDate schedule.Date,
+	qu pgw.Queryable, userId models.UserId, localTime schedule.Time, localDate schedule.Date,
 ) (schedule.Date, error) {
-	logger := pool.Logger()
-	nextScheduleDate, err := jobs.PublishPostsJob_GetNextScheduledDate(pool, userId)
+	logger := qu.Logger()
+	nextScheduleDate, err := jobs.PublishPostsJob_GetNextScheduledDate(qu, userId)
 	if err != nil {
 		return "", err
 	}
@@ -297,6 +297,11 @@ func Users_SignUp(w http.ResponseWriter, r *http.Request) {
 			panic(err)
 		}
 
+		err = models.FeedToken_CreateForUser(tx, user.Id)
+		if err != nil {
+			panic(err)
+		}
+
 		err = publish.CreateEmptyUserFeed(tx, user.Id)
 		if err != nil {
 			panic(err)
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"time"
+
+	"feedrewind.com/jobs"
+	"feedrewind.com/models"
+	"feedrewind.com/routes/rutil"
+	"feedrewind.com/util"
+	"feedrewind.com/util/schedule"
+)
+
+// https://www.w3.org/TR/websub/#subscriber-sends-subscription-request
+const (
+	webSubDefaultLease = 10 * 24 * time.Hour
+	webSubMinLease     = time.Hour
+	webSubMaxLease     = 30 * 24 * time.Hour
+	webSubMaxSecretLen = 200
+)
+
+func WebSub_Hub(w http.ResponseWriter, r *http.Request) {
+	pool := rutil.DBPool(r)
+	mode := util.EnsureParamStr(r, "hub.mode")
+	topic := util.EnsureParamStr(r, "hub.topic")
+	callback := util.EnsureParamStr(r, "hub.callback")
+	if mode != jobs.WebSubModeSubscribe && mode != jobs.WebSubModeUnsubscribe {
+		util.HttpPanic(http.StatusBadRequest, "Unknown hub.mode")
+	}
+
+	callbackUri, err := neturl.Parse(callback)
+	if err != nil || (callbackUri.Scheme != "http" && callbackUri.Scheme != "https") || callbackUri.Host == "" {
+		util.HttpPanic(http.StatusBadRequest, "Bad hub.callback")
+	}
+	if !util.IsPublicHost(callbackUri.Hostname()) {
+		util.HttpPanic(http.StatusBadRequest, "hub.callback must be on the public internet")
+	}
+
+	feedTopic, ok := rutil.ParseFeedTopic(topic)
+	if !ok {
+		util.HttpPanic(http.StatusBadRequest, "Unknown hub.topic")
+	}
+	resolvedFeed, err := models.FeedToken_Resolve(
+		pool, feedTopic.FeedKey, feedTopic.IsUserFeed, schedule.UTCNow(),
+	)
+	if errors.Is(err, models.ErrFeedNotFound) || errors.Is(err, models.ErrFeedGone) {
+		util.HttpPanic(http.StatusNotFound, "Unknown hub.topic")
+	} else if err != nil {
+		panic(err)
+	}
+	maybeUserId, err := models.WebSub_GetFeedUserId(
+		pool, resolvedFeed.MaybeSubscriptionId, resolvedFeed.MaybeUserId,
+	)
+	if err != nil {
+		panic(err)
+	}
+	if maybeUserId == nil {
+		util.HttpPanic(http.StatusNotFound, "Unknown hub.topic")
+	}
+	// Rotated urls are on their way out and only serve the notice to resubscribe
+	if resolvedFeed.MaybeRotatedAt != nil && mode == jobs.WebSubModeSubscribe {
+		util.HttpPanic(http.StatusNotFound, "Unknown hub.topic")
+	}
+
+	lease := webSubDefaultLease
+	if leaseSecondsStr, ok := util.MaybeParamStr(r, "hub.lease_seconds"); ok && leaseSecondsStr != "" {
+		leaseSeconds, err := strconv.ParseInt(leaseSecondsStr, 10, 64)
+		if err != nil || leaseSeconds <= 0 {
+			util.HttpPanic(http.StatusBadRequest, "Bad hub.lease_seconds")
+		}
+		lease = min(max(time.Duration(leaseSeconds)*time.Second, webSubMinLease), webSubMaxLease)
+	}
+
+	secret, _ := util.MaybeParamStr(r, "hub.secret")
+	if len(secret) > webSubMaxSecretLen {
+		util.HttpPanic(http.StatusBadRequest, "hub.secret is too long")
+	}
+
+	// The subscriber has to confirm the intent before anything is saved
+	err = jobs.WebSubVerifyJob_PerformNow(pool, mode, topic, callback, int64(lease.Seconds()), secret)
+	if err != nil {
+		panic(err)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,199 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"feedrewind.com/config"
+	"feedrewind.com/oops"
+)
+
+type Message struct {
+	To             string
+	Subject        string
+	TextBody       string
+	HtmlBody       string
+	UnsubscribeUrl string
+}
+
+type Sender interface {
+	Send(message Message) error
+}
+
+// Can be swapped out for tests or a different transport, everything else goes through it
+var DefaultSender Sender = &SmtpSender{Config: config.Cfg.Smtp}
+
+func Send(message Message) error {
+	return DefaultSender.Send(message)
+}
+
+// A permanent (5xx) rejection from the server means retrying won't help, e.g. the mailbox doesn't exist.
+// Transient failures come back as regular errors and are expected to be retried.
+// Bounces that arrive asynchronously as delivery status notifications aren't seen here.
+type PermanentError struct {
+	Code    int
+	Message string
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("Permanent SMTP error %d: %s", e.Code, e.Message)
+}
+
+func IsPermanentError(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+const fromName = "FeedRewind"
+const smtpTimeout = time.Minute
+
+type SmtpSender struct {
+	Config config.SmtpConfig
+}
+
+func (s *SmtpSender) Send(message Message) error {
+	data, err := formatMessage(s.Config.FromAddress, message)
+	if err != nil {
+		return err
+	}
+
+	err = s.send(message.To, data)
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return &PermanentError{
+			Code:    protoErr.Code,
+			Message: protoErr.Msg,
+		}
+	}
+	return err
+}
+
+func (s *SmtpSender) send(to string, data []byte) error {
+	addr := net.JoinHostPort(s.Config.Host, fmt.Sprint(s.Config.Port))
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	err = conn.SetDeadline(time.Now().Add(smtpTimeout))
+	if err != nil {
+		return oops.Wrap(err)
+	}
+
+	client, err := smtp.NewClient(conn, s.Config.Host)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err := client.StartTLS(&tls.Config{ServerName: s.Config.Host}) //nolint:exhaustruct
+		if err != nil {
+			return oops.Wrap(err)
+		}
+	}
+	if s.Config.Username != "" {
+		auth := smtp.PlainAuth("", s.Config.Username, s.Config.Password, s.Config.Host)
+		err := client.Auth(auth)
+		if err != nil {
+			return oops.Wrap(err)
+		}
+	}
+
+	err = client.Mail(s.Config.FromAddress)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	err = client.Rcpt(to)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	_, err = writer.Write(data)
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return oops.Wrap(err)
+	}
+
+	err = client.Quit()
+	if err != nil {
+		return oops.Wrap(err)
+	}
+	return nil
+}
+
+func formatMessage(fromAddress string, message Message) ([]byte, error) {
+	var body bytes.Buffer
+	multipartWriter := multipart.NewWriter(&body)
+	parts := []struct {
+		ContentType string
+		Content     string
+	}{
+		{"text/plain; charset=utf-8", message.TextBody},
+		{"text/html; charset=utf-8", message.HtmlBody},
+	}
+	for _, part := range parts {
+		partWriter, err := multipartWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.ContentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, oops.Wrap(err)
+		}
+		qpWriter := quotedprintable.NewWriter(partWriter)
+		_, err = qpWriter.Write([]byte(part.Content))
+		if err != nil {
+			return nil, oops.Wrap(err)
+		}
+		err = qpWriter.Close()
+		if err != nil {
+			return nil, oops.Wrap(err)
+		}
+	}
+	err := multipartWriter.Close()
+	if err != nil {
+		return nil, oops.Wrap(err)
+	}
+
+	var messageIdBytes [16]byte
+	_, err = rand.Read(messageIdBytes[:])
+	if err != nil {
+		return nil, oops.Wrap(err)
+	}
+	fromDomain := fromAddress[strings.LastIndex(fromAddress, "@")+1:]
+
+	var result bytes.Buffer
+	writeHeader := func(key, value string) {
+		fmt.Fprintf(&result, "%s: %s\r\n", key, value)
+	}
+	writeHeader("From", fmt.Sprintf("%s <%s>", fromName, fromAddress))
+	writeHeader("To", message.To)
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	writeHeader("Date", time.Now().UTC().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(messageIdBytes[:]), fromDomain))
+	if message.UnsubscribeUrl != "" {
+		writeHeader("List-Unsubscribe", fmt.Sprintf("<%s>", message.UnsubscribeUrl))
+	}
+	writeHeader("MIME-Version", "1.0")
+	writeHeader("Content-Type", fmt.Sprintf(`multipart/alternative; boundary="%s"`, multipartWriter.Boundary()))
+	result.WriteString("\r\n")
+	result.Write(body.Bytes())
+	return result.Bytes(), nil
+}
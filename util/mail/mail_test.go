@@ -0,0 +1,122 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"feedrewind.com/config"
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Accepts one session and replies to RCPT with rcptReply, collecting the DATA payload
+func startFakeSmtpServer(t *testing.T, rcptReply string) (port int, received chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	oops.RequireNoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	received = make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) {
+			_, _ = conn.Write([]byte(line + "\r\n"))
+		}
+
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL"):
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT"):
+				reply(rcptReply)
+			case command == "DATA":
+				reply("354 Go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				received <- data.String()
+				reply("250 Queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func newTestSender(port int) *SmtpSender {
+	return &SmtpSender{
+		Config: config.SmtpConfig{
+			Host:        "127.0.0.1",
+			Port:        port,
+			Username:    "",
+			Password:    "",
+			FromAddress: "digest@feedrewind.localhost",
+		},
+	}
+}
+
+func newTestMessage() Message {
+	return Message{
+		To:             "reader@example.com",
+		Subject:        "Today's posts — 2 new",
+		TextBody:       "Post 1\nPost 2",
+		HtmlBody:       "<p>Post 1</p><p>Post 2</p>",
+		UnsubscribeUrl: "http://localhost:3000/settings",
+	}
+}
+
+func TestSendDelivers(t *testing.T) {
+	port, received := startFakeSmtpServer(t, "250 OK")
+	err := newTestSender(port).Send(newTestMessage())
+	oops.RequireNoError(t, err)
+
+	data := <-received
+	require.Contains(t, data, "From: FeedRewind <digest@feedrewind.localhost>\r\n")
+	require.Contains(t, data, "To: reader@example.com\r\n")
+	require.Contains(t, data, "Subject: =?utf-8?q?")
+	require.Contains(t, data, "List-Unsubscribe: <http://localhost:3000/settings>\r\n")
+	require.Contains(t, data, "Content-Type: multipart/alternative;")
+	require.Contains(t, data, "<p>Post 1</p><p>Post 2</p>")
+}
+
+func TestSendPermanentRejection(t *testing.T) {
+	port, _ := startFakeSmtpServer(t, "550 No such user")
+	err := newTestSender(port).Send(newTestMessage())
+	require.Error(t, err)
+	require.True(t, IsPermanentError(err))
+}
+
+func TestSendTransientRejection(t *testing.T) {
+	port, _ := startFakeSmtpServer(t, "451 Try again later")
+	err := newTestSender(port).Send(newTestMessage())
+	require.Error(t, err)
+	require.False(t, IsPermanentError(err))
+}
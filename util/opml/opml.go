@@ -0,0 +1,121 @@
+package opml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+
+	"feedrewind.com/oops"
+
+	"golang.org/x/net/html/charset"
+)
+
+type Outline struct {
+	Title   string
+	FeedUrl string
+	HtmlUrl string
+}
+
+type opmlDoc struct {
+	XMLName xml.Name    `xml:"opml"`
+	Version string      `xml:"version,attr"`
+	Head    opmlHead    `xml:"head"`
+	Body    opmlOutline `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr,omitempty"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XmlUrl   string        `xml:"xmlUrl,attr,omitempty"`
+	HtmlUrl  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+var ErrNotOpml = errors.New("not an OPML file")
+
+// Collects the outlines with a feed url, flattening the folders and skipping duplicate feeds
+func Parse(content []byte) ([]Outline, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	var doc opmlDoc
+	err := decoder.Decode(&doc)
+	if err != nil {
+		return nil, ErrNotOpml
+	}
+
+	var result []Outline
+	seenFeedUrls := make(map[string]bool)
+	var collect func(outlines []opmlOutline)
+	collect = func(outlines []opmlOutline) {
+		for _, outline := range outlines {
+			feedUrl := strings.TrimSpace(outline.XmlUrl)
+			if feedUrl != "" && !seenFeedUrls[feedUrl] {
+				seenFeedUrls[feedUrl] = true
+				title := strings.TrimSpace(outline.Title)
+				if title == "" {
+					title = strings.TrimSpace(outline.Text)
+				}
+				if title == "" {
+					title = feedUrl
+				}
+				result = append(result, Outline{
+					Title:   title,
+					FeedUrl: feedUrl,
+					HtmlUrl: strings.TrimSpace(outline.HtmlUrl),
+				})
+			}
+			collect(outline.Outlines)
+		}
+	}
+	collect(doc.Body.Outlines)
+	return result, nil
+}
+
+func Format(title string, outlines []Outline, dateCreated time.Time) ([]byte, error) {
+	doc := opmlDoc{
+		XMLName: xml.Name{Space: "", Local: "opml"},
+		Version: "2.0",
+		Head: opmlHead{
+			Title:       title,
+			DateCreated: dateCreated.UTC().Format(time.RFC1123Z),
+		},
+		Body: opmlOutline{
+			Text:     "",
+			Title:    "",
+			Type:     "",
+			XmlUrl:   "",
+			HtmlUrl:  "",
+			Outlines: nil,
+		},
+	}
+	for _, outline := range outlines {
+		doc.Body.Outlines = append(doc.Body.Outlines, opmlOutline{
+			Text:     outline.Title,
+			Title:    outline.Title,
+			Type:     "rss",
+			XmlUrl:   outline.FeedUrl,
+			HtmlUrl:  outline.HtmlUrl,
+			Outlines: nil,
+		})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(doc)
+	if err != nil {
+		return nil, oops.Wrap(err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
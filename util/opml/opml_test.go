@@ -0,0 +1,61 @@
+package opml
+
+import (
+	"testing"
+	"time"
+
+	"feedrewind.com/oops"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>Reader subscriptions</title></head>
+  <body>
+    <outline text="Tech" title="Tech">
+      <outline type="rss" text="Blog A" title="Blog A" xmlUrl="https://a.example.com/feed" htmlUrl="https://a.example.com/"/>
+      <outline type="rss" text="Blog B" xmlUrl=" https://b.example.com/rss.xml "/>
+    </outline>
+    <outline type="rss" xmlUrl="https://c.example.com/atom.xml"/>
+    <outline type="rss" text="Blog A again" xmlUrl="https://a.example.com/feed"/>
+    <outline text="Empty folder"/>
+  </body>
+</opml>`
+	outlines, err := Parse([]byte(content))
+	oops.RequireNoError(t, err)
+	require.Equal(t, []Outline{
+		{Title: "Blog A", FeedUrl: "https://a.example.com/feed", HtmlUrl: "https://a.example.com/"},
+		{Title: "Blog B", FeedUrl: "https://b.example.com/rss.xml", HtmlUrl: ""},
+		{Title: "https://c.example.com/atom.xml", FeedUrl: "https://c.example.com/atom.xml", HtmlUrl: ""},
+	}, outlines)
+}
+
+func TestParseNotOpml(t *testing.T) {
+	_, err := Parse([]byte(`<rss version="2.0"><channel><title>Blog</title></channel></rss>`))
+	require.ErrorIs(t, err, ErrNotOpml)
+
+	_, err = Parse([]byte("not xml at all"))
+	require.ErrorIs(t, err, ErrNotOpml)
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	outlines := []Outline{
+		{
+			Title:   "Blog A & friends",
+			FeedUrl: "https://a.example.com/feed?x=1&y=2",
+			HtmlUrl: "https://a.example.com/",
+		},
+		{Title: "Blog B", FeedUrl: "https://b.example.com/rss.xml", HtmlUrl: "https://b.example.com/"},
+	}
+	content, err := Format("FeedRewind subscriptions", outlines, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	oops.RequireNoError(t, err)
+	require.Contains(t, string(content), `<opml version="2.0">`)
+	require.Contains(t, string(content), `<title>FeedRewind subscriptions</title>`)
+	require.Contains(t, string(content), `type="rss"`)
+
+	parsed, err := Parse(content)
+	oops.RequireNoError(t, err)
+	require.Equal(t, outlines, parsed)
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	type Test struct {
+		Addr             string
+		ExpectedIsPublic bool
+	}
+	tests := []Test{
+		{Addr: "93.184.216.34", ExpectedIsPublic: true},
+		{Addr: "2606:2800:220:1:248:1893:25c8:1946", ExpectedIsPublic: true},
+		{Addr: "127.0.0.1", ExpectedIsPublic: false},
+		{Addr: "::1", ExpectedIsPublic: false},
+		{Addr: "0.0.0.0", ExpectedIsPublic: false},
+		{Addr: "::", ExpectedIsPublic: false},
+		{Addr: "10.1.2.3", ExpectedIsPublic: false},
+		{Addr: "172.16.0.1", ExpectedIsPublic: false},
+		{Addr: "192.168.1.1", ExpectedIsPublic: false},
+		{Addr: "169.254.169.254", ExpectedIsPublic: false},
+		{Addr: "100.100.100.200", ExpectedIsPublic: false},
+		{Addr: "fd00:ec2::254", ExpectedIsPublic: false},
+		{Addr: "fe80::1", ExpectedIsPublic: false},
+		{Addr: "::ffff:127.0.0.1", ExpectedIsPublic: false},
+		{Addr: "::ffff:10.0.0.1", ExpectedIsPublic: false},
+		{Addr: "64:ff9b::a00:1", ExpectedIsPublic: false},
+		{Addr: "224.0.0.1", ExpectedIsPublic: false},
+		{Addr: "255.255.255.255", ExpectedIsPublic: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Addr, func(t *testing.T) {
+			require.Equal(t, tc.ExpectedIsPublic, IsPublicAddr(netip.MustParseAddr(tc.Addr)))
+		})
+	}
+}
+
+func TestIsPublicHost(t *testing.T) {
+	require.True(t, IsPublicHost("example.com"))
+	require.True(t, IsPublicHost("93.184.216.34"))
+	require.False(t, IsPublicHost("localhost"))
+	require.False(t, IsPublicHost("LOCALHOST."))
+	require.False(t, IsPublicHost("app.localhost"))
+	require.False(t, IsPublicHost("127.0.0.1"))
+	require.False(t, IsPublicHost("[::1]"))
+	require.False(t, IsPublicHost("169.254.169.254"))
+}
+
+func TestPublicHttpClientRejectsLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewPublicHttpClient(5 * time.Second)
+	_, err := client.Get(server.URL)
+	require.True(t, errors.Is(err, ErrNonPublicAddress), "unexpected error: %v", err)
+}
+
+func TestPublicHttpClientDoesntFollowRedirects(t *testing.T) {
+	client := NewPublicHttpClient(5 * time.Second)
+	client.Transport = http.DefaultTransport // to reach the test server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+}
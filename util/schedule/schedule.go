@@ -36,6 +36,21 @@ func (d Date) NextDay() Date {
 	return Date(nextDay.Format("2006-01-02"))
 }
 
+func (d Date) PrevDay() Date {
+	parsed, err := time.Parse("2006-01-02", string(d))
+	if err != nil {
+		panic(err)
+	}
+
+	prevDay := parsed.AddDate(0, 0, -1)
+	return Date(prevDay.Format("2006-01-02"))
+}
+
+// Whole days from other to d, negative if d is earlier
+func (d Date) DaysSince(other Date) int {
+	return int(time.Time(d.Time()).Sub(time.Time(other.Time())).Hours()) / 24
+}
+
 func (d Date) Time() Time {
 	parsed, err := ParseTime("2006-01-02", string(d))
 	if err != nil {
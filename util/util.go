@@ -9,6 +9,8 @@ import (
 	"html/template"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"feedrewind.com/db/pgw"
@@ -138,6 +140,22 @@ func Ordinal(number int) string {
 	}
 }
 
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+const readingWordsPerMinute = 230
+
+// Posts without content still take some time to read
+const DefaultReadingMinutes = 5
+
+// Rough reading time of the post html, at least a minute
+func ReadingMinutes(maybeContent *string) int {
+	if maybeContent == nil {
+		return DefaultReadingMinutes
+	}
+	words := len(strings.Fields(htmlTagRegex.ReplaceAllString(*maybeContent, " ")))
+	return max(1, (words+readingWordsPerMinute-1)/readingWordsPerMinute)
+}
+
 func RandomInt63() (int64, error) {
 	buf := make([]byte, 8)
 	for {